@@ -0,0 +1,197 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// 统计记录保存的目录
+	analyticsDir = "analytics"
+	// 沿滚动方向每前进多少列记录一次检查点时间
+	analyticsCheckpointColumns = 32
+)
+
+// 统计事件名称
+const (
+	analyticsEventStart      = "start"      // 开始一局
+	analyticsEventDeath      = "death"      // 玩家死亡
+	analyticsEventCheckpoint = "checkpoint" // 到达检查点（从起点沿滚动方向每 analyticsCheckpointColumns 列）
+	analyticsEventRoute      = "route"      // 走上一条高处路线
+	analyticsEventGoal       = "goal"       // 碰到终点通关
+)
+
+// AnalyticsRecord 一条统计记录（JSON Lines 格式的一行）
+// 记录是匿名的：只有随机生成的本局编号，不包含玩家名称、机器信息或文件路径
+type AnalyticsRecord struct {
+	Run    string  `json:"run"`             // 本局编号（随机）
+	Event  string  `json:"event"`           // 事件名称
+	Frame  int     `json:"frame"`           // 本局开始后经过的帧数（暂停和回溯时不计）
+	Column int     `json:"column"`          // 事件发生的列
+	X      float64 `json:"x"`               // 事件发生的位置（世界坐标）
+	Y      float64 `json:"y"`               //
+	Cause  string  `json:"cause,omitempty"` // 死亡原因（只有 death 事件有）
+}
+
+// analyticsMapKey 返回地图的标识，同一张地图的所有记录（以及存档中的成绩）使用同一个标识
+// 游戏自带的关卡按文件名加 stage- 前缀，其他关卡文件按相对 levels/ 的路径（不含扩展名，用 / 分隔）加 level- 前缀
+// （关卡包都叫 level.json，按所在目录区分；社区关卡与自带关卡同名时不共用成绩和解锁；不在 levels/ 中的关卡只用文件名），
+// 随机生成的地图按种子码、列数和滚动方式区分
+func analyticsMapKey(opts GameOptions) string {
+	if opts.LevelPath != "" {
+		name := strings.TrimSuffix(filepath.Base(opts.LevelPath), filepath.Ext(opts.LevelPath))
+		if isStagePath(opts.LevelPath) {
+			return "stage-" + name
+		}
+		if rel, ok := levelRelPath(opts.LevelPath); ok {
+			name = strings.TrimSuffix(filepath.ToSlash(rel), filepath.Ext(rel))
+		}
+		return "level-" + name
+	}
+	key := fmt.Sprintf("seed-%s-%d-%s", SeedCode(opts.Seed, opts.Mutators), opts.MapLength, opts.Scroll)
+	if opts.Vertical {
+		key += "-vertical"
+	}
+	return key
+}
+
+// levelRelPath 返回关卡文件相对 levels/ 的路径，不在 levels/ 中时返回 false
+func levelRelPath(path string) (string, bool) {
+	dir, err := filepath.Abs(levelsDir)
+	if err != nil {
+		return "", false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// analyticsPath 返回地图的统计记录文件路径（关卡包的记录放在与包同名的子目录中）
+func analyticsPath(opts GameOptions) string {
+	return filepath.Join(analyticsDir, filepath.FromSlash(analyticsMapKey(opts))+".jsonl")
+}
+
+// AnalyticsSystem 统计系统（设置中开启后才记录）：记录死亡位置、检查点时间和走过的高处路线，追加到地图的记录文件
+type AnalyticsSystem struct {
+	file  *os.File
+	run   string
+	frame int
+
+	started        bool    // 是否已经记下起点（第一次更新时按玩家的位置）
+	lastCheckpoint int     // 上一个检查点（或起点、转向时玩家所在）的列
+	direction      float64 // 记录检查点时的滚动方向（转向后从玩家所在的列重新计算）
+	onRoute        bool    // 上一帧是否站在高处路线上（走上路线时只记录一次）
+	dead           bool    // 上一帧玩家是否已经死亡（回溯复活后可以再次记录死亡）
+	cleared        bool    // 是否已经记录通关
+}
+
+// NewAnalyticsSystem 创建统计系统，打开（或创建）记录文件
+// 记录文件无法打开时返回错误，游戏照常进行但不记录
+func NewAnalyticsSystem(path string) (*AnalyticsSystem, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 8)
+	rand.Read(id)
+	s := &AnalyticsSystem{file: file, run: hex.EncodeToString(id)}
+
+	s.record(analyticsEventStart, 0, 0, "")
+	return s, nil
+}
+
+// record 写入一条记录，写入失败时给出警告并停止记录
+func (s *AnalyticsSystem) record(event string, x, y float64, cause string) {
+	if s.file == nil {
+		return
+	}
+	data, _ := json.Marshal(AnalyticsRecord{
+		Run:    s.run,
+		Event:  event,
+		Frame:  s.frame,
+		Column: int(x / mapItemWidth),
+		X:      x,
+		Y:      y,
+		Cause:  cause,
+	})
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		log.Printf("警告: 写入统计记录失败，停止记录: %v", err)
+		s.file.Close()
+		s.file = nil
+	}
+}
+
+// Close 关闭记录文件
+func (s *AnalyticsSystem) Close() {
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+}
+
+// Update 计时，记录死亡、到达的检查点、走上的高处路线和通关
+func (s *AnalyticsSystem) Update(g *Game) {
+	if g.isPaused || g.isRewinding || g.Player == nil || s.cleared {
+		return
+	}
+	p := g.Player
+	if g.cleared {
+		s.record(analyticsEventGoal, p.X, p.Y, "")
+		s.cleared = true
+		return
+	}
+	if p.IsDead != s.dead {
+		s.dead = p.IsDead
+		if p.IsDead {
+			s.record(analyticsEventDeath, p.X, p.Y, p.DeathCause.String())
+		}
+	}
+	if p.IsDead {
+		return
+	}
+	s.frame++
+
+	col := int(p.X / mapItemWidth)
+	s.updateCheckpoints(col, g.scroll.Direction, p.Y)
+
+	onRoute := p.IsOnGround && col >= 0 && col < len(g.MapItems) && g.MapItems[col].HasPlatform && p.Y <= g.chunks.layout.platformY
+	if onRoute && !s.onRoute {
+		s.record(analyticsEventRoute, p.X, p.Y, "")
+	}
+	s.onRoute = onRoute
+}
+
+// updateCheckpoints 玩家沿滚动方向每前进 analyticsCheckpointColumns 列记录一个检查点
+// 从玩家的起始列开始计算（向左滚动的地图从右端出发）；交替滚动转向后从玩家当前所在的列重新计算
+func (s *AnalyticsSystem) updateCheckpoints(col int, direction, y float64) {
+	if !s.started || direction != s.direction {
+		s.started = true
+		s.direction = direction
+		s.lastCheckpoint = col
+		return
+	}
+	step := analyticsCheckpointColumns
+	if direction < 0 {
+		step = -step
+	}
+	for next := s.lastCheckpoint + step; (col-next)*step >= 0; next += step {
+		s.record(analyticsEventCheckpoint, float64(next)*mapItemWidth, y, "")
+		s.lastCheckpoint = next
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestAnalyticsMapKey 关卡包（都叫 level.json）按所在目录区分，自带关卡和社区关卡同名时不共用标识
+func TestAnalyticsMapKey(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{filepath.Join(stagesDir, "01-meadow.json"), "stage-01-meadow"},
+		{filepath.Join(levelsDir, "01-meadow.json"), "level-01-meadow"},
+		{filepath.Join(levelsDir, "forest", "level.json"), "level-forest/level"},
+		{filepath.Join(levelsDir, "caves", "level.json"), "level-caves/level"},
+		{filepath.Join("elsewhere", "custom.json"), "level-custom"},
+	}
+	for _, tt := range tests {
+		if got := analyticsMapKey(GameOptions{LevelPath: tt.path}); got != tt.want {
+			t.Errorf("analyticsMapKey(%s) = %q，应为 %q", tt.path, got, tt.want)
+		}
+	}
+
+	want := filepath.Join(analyticsDir, "level-forest", "level.jsonl")
+	if got := analyticsPath(GameOptions{LevelPath: filepath.Join(levelsDir, "forest", "level.json")}); got != want {
+		t.Errorf("analyticsPath = %q，应为 %q", got, want)
+	}
+}
+
+// TestRecordGradePerBundle 两个关卡包的成绩分别记录，不会互相覆盖
+func TestRecordGradePerBundle(t *testing.T) {
+	forest := analyticsMapKey(GameOptions{LevelPath: filepath.Join(levelsDir, "forest", "level.json")})
+	caves := analyticsMapKey(GameOptions{LevelPath: filepath.Join(levelsDir, "caves", "level.json")})
+
+	var p Profile
+	if !p.recordGrade(forest, &RunResults{Score: 500, Grade: GradeA}) {
+		t.Fatal("第一次记录 forest 的成绩应该刷新最好成绩")
+	}
+	if !p.recordGrade(caves, &RunResults{Score: 100, Grade: GradeC}) {
+		t.Fatal("caves 的成绩不应该和 forest 比较")
+	}
+	if got := p.Grades[forest].Score; got != 500 {
+		t.Errorf("forest 的最好分数为 %d，应为 500", got)
+	}
+	if got := p.Grades[caves].Score; got != 100 {
+		t.Errorf("caves 的最好分数为 %d，应为 100", got)
+	}
+}
@@ -1,19 +1,15 @@
 package main
 
 import (
-	"image"
-	"log"
+	"fmt"
 
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"my_ai_game/internal/engine"
 )
 
-const (
-	// 游戏帧率（假设60fps）
-	gameFPS = 60.0
-)
+// playerSkeletons 已加载的骨骼动画数据（按文件路径缓存，重新创建玩家时共享图片）
+var playerSkeletons = map[string]*engine.SkeletonData{}
 
-// AnimationState 动画状态
+// AnimationState 玩家动画状态
 type AnimationState int
 
 const (
@@ -24,191 +20,75 @@ const (
 	StateJumpEnd
 	StateDie
 	StateFly
+	StateSwing
 )
 
-// Animation 动画结构体
-type Animation struct {
-	Image         *ebiten.Image // 动画图片（精灵表）
-	FrameCount    int           // 总帧数
-	FrameWidth    int           // 每帧宽度
-	FrameHeight   int           // 每帧高度
-	Loop          bool          // 是否循环播放
-	FPS           float64       // 动画播放速度（帧/秒）
-	OriginOffsetY float64       // 动画原点Y偏移（相对于帧底部，正数向上偏移）
+// animationStateNames 动画状态名称（动画预览中显示）
+var animationStateNames = map[AnimationState]string{
+	StateIdle:       "idle",
+	StateMove:       "move",
+	StateJumpBefore: "jump_before",
+	StateJumpLoop:   "jump_loop",
+	StateJumpEnd:    "jump_end",
+	StateDie:        "die",
+	StateFly:        "fly",
+	StateSwing:      "swing",
 }
 
-// NewAnimation 创建新动画
-// imagePath: 图片路径
-// frameCount: 帧数
-// loop: 是否循环播放
-// onComplete: 播放完成回调
-// fps: 动画播放速度（帧/秒）
-// originOffsetY: 动画原点Y偏移（相对于帧底部，正数向上偏移）
-func NewAnimation(imagePath string, frameCount int, loop bool, fps float64, originOffsetY float64) *Animation {
-	img, _, err := ebitenutil.NewImageFromFile(imagePath)
-	if err != nil {
-		log.Fatalf("加载动画图片失败 %s: %v", imagePath, err)
-	}
-
-	bounds := img.Bounds()
-	width := bounds.Dx()
-	height := bounds.Dy()
-
-	// 计算每帧宽度（水平均等拆分）
-	frameWidth := width / frameCount
-
-	return &Animation{
-		Image:         img,
-		FrameCount:    frameCount,
-		FrameWidth:    frameWidth,
-		FrameHeight:   height,
-		Loop:          loop,
-		FPS:           fps,
-		OriginOffsetY: originOffsetY,
-	}
-}
-
-// GetFrame 获取指定帧的图片
-func (a *Animation) GetFrame(frameIndex int) *ebiten.Image {
-	if frameIndex < 0 || frameIndex >= a.FrameCount {
-		return nil
-	}
-
-	// 从精灵表中提取单帧
-	frameRect := image.Rect(
-		frameIndex*a.FrameWidth,
-		0,
-		(frameIndex+1)*a.FrameWidth,
-		a.FrameHeight,
-	)
-
-	return a.Image.SubImage(frameRect).(*ebiten.Image)
+// String 返回动画状态名称
+func (s AnimationState) String() string {
+	return animationStateNames[s]
 }
 
-// AnimationController 动画控制器
-// 只负责更新当前动画的下一帧和判断是否动画结束
-type AnimationController struct {
-	currentState AnimationState
-	currentFrame float64 // 当前帧（浮点数，用于平滑播放）
-	animations   map[AnimationState]*Animation
-}
+// AnimationController 玩家动画控制器
+type AnimationController = engine.AnimationController[AnimationState]
 
-// NewAnimationController 创建动画控制器
-func NewAnimationController() *AnimationController {
-	controller := &AnimationController{
-		currentState: StateIdle,
-		currentFrame: 0,
-		animations:   make(map[AnimationState]*Animation),
-	}
+// NewPlayerAnimationController 创建玩家动画控制器并加载所有玩家动画
+// skin: 玩家皮肤（调色板名称，为空时使用原色）
+func NewPlayerAnimationController(skin string) (*AnimationController, error) {
+	controller := engine.NewAnimationController(StateIdle)
 
 	// 加载所有动画（不设置回调，由Player控制状态切换）
-	// 参数：图片路径, 帧数, 是否循环, 完成回调, 播放速度(FPS), 原点Y偏移
-	controller.animations[StateIdle] = NewAnimation("res/image/idle.png", 39, true, 20.0, 22)
-	controller.animations[StateMove] = NewAnimation("res/image/move.png", 26, true, 20.0, 45)
-	controller.animations[StateJumpBefore] = NewAnimation("res/image/jump_before.png", 10, false, 27.0, 16)
-	controller.animations[StateJumpLoop] = NewAnimation("res/image/jump_loop.png", 1, true, 1.0, 35)
-	controller.animations[StateJumpEnd] = NewAnimation("res/image/jump_end.png", 7, false, 27.0, 13)
-	controller.animations[StateDie] = NewAnimation("res/image/die.png", 30, false, 20.0, 18)
-	controller.animations[StateFly] = NewAnimation("res/image/fly.png", 22, true, 20.0, 0.0)
-
-	return controller
-}
-
-// SetState 设置动画状态
-func (ac *AnimationController) SetState(state AnimationState) {
-	if ac.currentState != state {
-		ac.currentState = state
-		ac.currentFrame = 0
-	}
-}
-
-// GetState 获取当前动画状态
-func (ac *AnimationController) GetState() AnimationState {
-	return ac.currentState
-}
-
-// Update 更新动画帧（只更新当前动画的下一帧）
-func (ac *AnimationController) Update() {
-	anim := ac.animations[ac.currentState]
-	if anim == nil {
-		return
+	// 参数：图片路径, 帧数, 是否循环, 播放速度(FPS), 原点Y偏移（原图像素，按美术清单的缩放一起缩小）
+	// 摆动没有单独的精灵表，使用 jump_loop 的画面（骨骼动画可以提供 swing 片段）
+	sheets := []struct {
+		state         AnimationState
+		path          string
+		frames        int
+		loop          bool
+		fps           float64
+		originOffsetY float64
+	}{
+		{StateIdle, "res/image/idle.png", 39, true, 20.0, 22},
+		{StateMove, "res/image/move.png", 26, true, 20.0, 45},
+		{StateJumpBefore, "res/image/jump_before.png", 10, false, 27.0, 16},
+		{StateJumpLoop, "res/image/jump_loop.png", 1, true, 1.0, 35},
+		{StateJumpEnd, "res/image/jump_end.png", 7, false, 27.0, 13},
+		{StateDie, "res/image/die.png", 30, false, 20.0, 18},
+		{StateFly, "res/image/fly.png", 22, true, 20.0, 0.0},
+		{StateSwing, "res/image/jump_loop.png", 1, true, 1.0, 35},
 	}
-
-	// 使用当前动画的FPS计算帧步进
-	frameStep := anim.FPS / gameFPS
-	ac.currentFrame += frameStep
-
-	// 处理帧数溢出
-	if ac.currentFrame >= float64(anim.FrameCount) {
-		if anim.Loop {
-			// 循环播放
-			ac.currentFrame = ac.currentFrame - float64(anim.FrameCount)
-		} else {
-			// 非循环动画，保持在最后一帧
-			ac.currentFrame = float64(anim.FrameCount) - 1
+	for _, sheet := range sheets {
+		anim, err := loadAnimation(sheet.path, sheet.frames, sheet.loop, sheet.fps, sheet.originOffsetY, skin)
+		if err != nil {
+			return nil, fmt.Errorf("加载玩家动画 %s 失败: %w", sheet.state, err)
 		}
+		controller.AddAnimation(sheet.state, anim)
 	}
-}
-
-// IsFinished 判断当前动画是否播放完毕（仅对非循环动画有效）
-func (ac *AnimationController) IsFinished() bool {
-	anim := ac.animations[ac.currentState]
-	if anim == nil || anim.Loop {
-		return false
-	}
-	return ac.currentFrame >= float64(anim.FrameCount)-0.1 // 允许小的浮点误差
-}
 
-// GetCurrentFrame 获取当前帧图片
-func (ac *AnimationController) GetCurrentFrame() *ebiten.Image {
-	anim := ac.animations[ac.currentState]
-	if anim == nil {
-		return nil
-	}
-
-	frameIndex := int(ac.currentFrame)
-	return anim.GetFrame(frameIndex)
-}
-
-// GetFrameSize 获取当前动画帧的尺寸
-func (ac *AnimationController) GetFrameSize() (width, height int) {
-	anim := ac.animations[ac.currentState]
-	if anim == nil {
-		return 0, 0
-	}
-	return anim.FrameWidth, anim.FrameHeight
+	return controller, nil
 }
 
-// GetCurrentFPS 获取当前动画的播放速度（帧/秒）
-func (ac *AnimationController) GetCurrentFPS() float64 {
-	anim := ac.animations[ac.currentState]
-	if anim == nil {
-		return 0
-	}
-	return anim.FPS
-}
-
-// GetCurrentOriginOffsetY 获取当前动画的原点Y偏移
-func (ac *AnimationController) GetCurrentOriginOffsetY() float64 {
-	anim := ac.animations[ac.currentState]
-	if anim == nil {
-		return 0
-	}
-	return anim.OriginOffsetY
-}
-
-// SetAnimationFPS 设置指定动画的播放速度
-func (ac *AnimationController) SetAnimationFPS(state AnimationState, fps float64) {
-	anim := ac.animations[state]
-	if anim != nil {
-		anim.FPS = fps
-	}
-}
-
-// SetAnimationOriginOffsetY 设置指定动画的原点Y偏移
-func (ac *AnimationController) SetAnimationOriginOffsetY(state AnimationState, offsetY float64) {
-	anim := ac.animations[state]
-	if anim != nil {
-		anim.OriginOffsetY = offsetY
+// LoadPlayerSkeleton 加载玩家的骨骼动画（片段名称与 AnimationState 的名称相同）
+// 骨骼动画和精灵表动画共用一个动画控制器：控制器决定当前状态和播放进度，有对应片段的状态绘制骨骼动画
+func LoadPlayerSkeleton(path string) (*engine.Skeleton, error) {
+	data := playerSkeletons[path]
+	if data == nil {
+		var err error
+		if data, err = engine.LoadSkeleton(path); err != nil {
+			return nil, err
+		}
+		playerSkeletons[path] = data
 	}
+	return engine.NewSkeleton(data), nil
 }
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 美术清单路径
+	artManifestPath = "res/data/art.json"
+	// 调色板目录路径
+	paletteCatalogPath = "res/data/palettes.json"
+)
+
+// ArtAsset 一张图片的美术元数据
+type ArtAsset struct {
+	Scale float64 `json:"scale"` // 美术缩放（绘制尺寸 / 原图尺寸），0 表示按原始尺寸绘制
+	Lazy  bool    `json:"lazy"`  // 是否延迟加载（第一次使用时才加载，长时间不用时可以被纹理预算卸载）
+}
+
+// ArtManifest 美术清单：图片路径 → 美术元数据
+// 原图按高分辨率绘制时在清单里写明缩放，加载时用 mipmap 缩小到绘制尺寸，而不是每帧在运行时双线性缩小
+type ArtManifest map[string]ArtAsset
+
+// artManifest 第一次加载动画时读取的美术清单
+var artManifest ArtManifest
+
+// paletteCatalog 第一次加载换色动画时读取的调色板目录
+var paletteCatalog map[string]*PaletteDef
+
+// artAnimations 已加载的动画（按图片路径和调色板缓存，玩家每次创建时不再重新换色和缩小）
+var artAnimations = map[string]*engine.Animation{}
+
+// PaletteDef 调色板定义（从调色板目录 JSON 加载）
+// 怪物变体和玩家皮肤使用同一张精灵表，加载时按调色板换色
+type PaletteDef struct {
+	Name  string           `json:"name"`  // 调色板名称（怪物目录的 palette 和 -skin 使用）
+	Swaps []PaletteSwapDef `json:"swaps"` // 换色表（按顺序匹配）
+
+	swaps []engine.PaletteSwap // 解析后的换色表
+}
+
+// PaletteSwapDef 一组换色（颜色为 #rrggbb）
+type PaletteSwapDef struct {
+	From      string `json:"from"`      // 原色
+	To        string `json:"to"`        // 目标色
+	Tolerance int    `json:"tolerance"` // 容差：每个通道与原色的差都不超过容差时换色，阴影和高光保持与原色的差值
+}
+
+// LoadPaletteCatalog 从 JSON 文件加载调色板目录
+func LoadPaletteCatalog(path string) (map[string]*PaletteDef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var defs []*PaletteDef
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("解析调色板目录失败: %w", err)
+	}
+	catalog := make(map[string]*PaletteDef, len(defs))
+	for _, def := range defs {
+		for _, swap := range def.Swaps {
+			from, err := parseHexColor(swap.From)
+			if err != nil {
+				return nil, fmt.Errorf("调色板 %s: %w", def.Name, err)
+			}
+			to, err := parseHexColor(swap.To)
+			if err != nil {
+				return nil, fmt.Errorf("调色板 %s: %w", def.Name, err)
+			}
+			def.swaps = append(def.swaps, engine.PaletteSwap{From: from, To: to, Tolerance: swap.Tolerance})
+		}
+		catalog[def.Name] = def
+	}
+	return catalog, nil
+}
+
+// parseHexColor 解析 #rrggbb 格式的颜色
+func parseHexColor(s string) (color.NRGBA, error) {
+	var c color.NRGBA
+	if _, err := fmt.Sscanf(s, "#%02x%02x%02x", &c.R, &c.G, &c.B); err != nil || len(s) != 7 {
+		return c, fmt.Errorf("无效的颜色: %q", s)
+	}
+	c.A = 0xff
+	return c, nil
+}
+
+// paletteSwaps 返回调色板的换色表；palette 为空时不换色，调色板不存在时给出警告并不换色
+func paletteSwaps(palette string) []engine.PaletteSwap {
+	if palette == "" {
+		return nil
+	}
+	if paletteCatalog == nil {
+		catalog, err := LoadPaletteCatalog(paletteCatalogPath)
+		if err != nil {
+			log.Printf("警告: 加载调色板目录失败，不换色: %v", err)
+			catalog = map[string]*PaletteDef{}
+		}
+		paletteCatalog = catalog
+	}
+	def := paletteCatalog[palette]
+	if def == nil {
+		log.Printf("警告: 调色板不存在，不换色: %s", palette)
+		return nil
+	}
+	return def.swaps
+}
+
+// LoadArtManifest 从 JSON 文件加载美术清单
+func LoadArtManifest(path string) (ArtManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := ArtManifest{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("解析美术清单失败: %w", err)
+	}
+	for path, asset := range manifest {
+		if asset.Scale < 0 || asset.Scale > 1 {
+			return nil, fmt.Errorf("美术清单中 %s 的缩放必须在 0 到 1 之间: %v", path, asset.Scale)
+		}
+	}
+	return manifest, nil
+}
+
+// Scale 返回图片的美术缩放（清单中没有该图片时为 1）
+func (m ArtManifest) Scale(path string) float64 {
+	if asset, ok := m[path]; ok && asset.Scale > 0 {
+		return asset.Scale
+	}
+	return 1
+}
+
+// DisposeArtAnimations 释放所有缓存的动画并清空缓存（退出时调用；之后再创建玩家和怪物时重新加载）
+func DisposeArtAnimations() {
+	for _, anim := range artAnimations {
+		anim.Dispose()
+	}
+	clear(artAnimations)
+}
+
+// loadAnimation 按美术清单中的缩放和调色板加载动画（其余参数同 engine.NewAnimation）
+// palette: 调色板名称（为空时使用原色）
+// 在加载协程中调用，失败时返回错误（由 LoadGame 交给加载场景显示），不退出程序
+func loadAnimation(imagePath string, frameCount int, loop bool, fps float64, originOffsetY float64, palette string) (*engine.Animation, error) {
+	key := imagePath + "#" + palette
+	if anim, ok := artAnimations[key]; ok {
+		return anim, nil
+	}
+	if artManifest == nil {
+		manifest, err := LoadArtManifest(artManifestPath)
+		if err != nil {
+			return nil, fmt.Errorf("加载美术清单失败: %w", err)
+		}
+		artManifest = manifest
+	}
+	art := engine.AnimationArt{
+		Scale:   artManifest.Scale(imagePath),
+		Palette: paletteSwaps(palette),
+	}
+	var anim *engine.Animation
+	var err error
+	if artManifest[imagePath].Lazy {
+		anim, err = engine.NewLazyArtAnimation(imagePath, frameCount, loop, fps, originOffsetY, art)
+	} else {
+		anim, err = engine.NewArtAnimation(imagePath, frameCount, loop, fps, originOffsetY, art)
+	}
+	if err != nil {
+		return nil, err
+	}
+	artAnimations[key] = anim
+	return anim, nil
+}
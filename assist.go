@@ -0,0 +1,70 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 落点预测最多模拟的帧数（超过后视为落不到地面）
+	landingPredictFrames = 180
+	// 落点标记的半宽和高度（像素）
+	landingMarkerHalfWidth = 24.0
+	landingMarkerHeight    = 6.0
+)
+
+// landingMarkerColor 落点标记的颜色（半透明灰色，尽量不干扰画面）
+var landingMarkerColor = color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0x80}
+
+// PredictLanding 按当前速度、重力和本帧水平输入逐帧积分玩家的轨迹，返回第一次落到障碍物顶部的位置
+// 积分顺序与 Player.Update 一致（先水平移动，再加重力、更新 Y），落地判定与 checkCollisionWithObstacles 一致
+// 不考虑水平方向的阻挡；落进缺口（在 landingPredictFrames 帧内落不到地面）时返回 false
+func PredictLanding(player *Player, input PlayerInput, obstacles []*Obstacle) (x, y float64, ok bool) {
+	velocityX := 0.0
+	if input.Left {
+		velocityX -= player.Physics.Speed
+	}
+	if input.Right {
+		velocityX += player.Physics.Speed
+	}
+
+	x, y, velocityY := player.X, player.Y, player.VelocityY
+	for frame := 0; frame < landingPredictFrames; frame++ {
+		x += velocityX
+		velocityY += player.Physics.Gravity
+		prevY := y
+		y += velocityY
+		if velocityY < 0 {
+			continue
+		}
+		for _, obstacle := range obstacles {
+			if !obstacle.Solidity.Has(SolidFromAbove) {
+				continue
+			}
+			left, right, top, _ := obstacle.GetCollisionBox()
+			if x < left || x > right || prevY > top || y < top {
+				continue
+			}
+			return x, top, true
+		}
+	}
+	return 0, 0, false
+}
+
+// drawLandingMarker 玩家在空中时，在预测的落点画一个淡淡的标记（辅助功能设置中开启）
+func drawLandingMarker(screen *ebiten.Image, camera *engine.Camera, player *Player, input PlayerInput, obstacles []*Obstacle) {
+	if player.IsDead || player.IsFlying || player.IsOnGround {
+		return
+	}
+	x, y, ok := PredictLanding(player, input, obstacles)
+	if !ok {
+		return
+	}
+	screenX, screenY := camera.WorldToScreen(x, y)
+	vector.FillRect(screen, float32(screenX-landingMarkerHalfWidth), float32(screenY-landingMarkerHeight),
+		landingMarkerHalfWidth*2, landingMarkerHeight, landingMarkerColor, false)
+}
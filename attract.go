@@ -0,0 +1,190 @@
+package main
+
+import (
+	"log"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 演示录像目录（可选，游戏没有自带录像；把 replays/ 中录制的回放复制到这里后按文件名顺序轮流播放）
+const attractDemosDir = "res/demos"
+
+const (
+	// 开始界面无操作多少帧后进入吸引模式
+	attractIdleFrames = 15 * 60
+	// 机器人演示一局最多播放的帧数，之后换一张地图重新开始
+	attractDemoFrames = 60 * 60
+	// 机器人演示中玩家死亡后、录像播放完后等待多少帧换下一局
+	attractRestartFrames = 120
+)
+
+// AttractMode 吸引模式：开始界面（标题、存档选择、关卡浏览）无操作 15 秒后播放演示，
+// 叠加 "PRESS ANY KEY" 提示，任意按键、手柄按钮、摇杆或鼠标点击回到开始界面
+// 演示默认使用随机地图，由机器人（Bot）操作玩家；res/demos/ 中放了回放文件时改为按顺序播放这些回放
+// （ReplayRecorder 录制的输入，由 ReplayPlayer 逐帧操作玩家）
+type AttractMode struct {
+	idle    int           // 开始界面无操作的帧数
+	demo    *Game         // 正在播放的演示（为 nil 时显示开始界面）
+	loading *LoadingScene // 正在后台加载的下一局演示（加载完成后替换 demo）
+	player  *ReplayPlayer // 正在播放的演示录像（机器人演示时为 nil）
+	length  int           // 演示录像的总帧数
+	frames  int           // 本局演示已播放的帧数
+	blink   int           // 提示文字闪烁计时
+	demos   []string      // 演示录像文件（第一次进入吸引模式时读取目录）
+	scanned bool          // 是否已经读取过演示录像目录
+	next    int           // 下一局播放的演示录像
+}
+
+// Update 每帧由开始界面调用，演示播放中返回 true（开始界面本帧不处理输入）
+// opts: 开始界面的启动选项（演示使用其中的主题、画面质量等设置）
+// 演示和正常开始游戏一样通过 LoadingScene 在后台加载：第一局加载期间继续显示开始界面，
+// 之后的每一局加载期间停在上一局的最后一帧
+func (a *AttractMode) Update(opts GameOptions) bool {
+	// 包括手柄（按住的按键同样算作有操作：用手柄浏览菜单时不计入无操作时间）
+	pressed := AnyInputPressed()
+	if pressed && (a.demo != nil || a.loading != nil) {
+		// 演示中任意按键、手柄按钮或鼠标点击回到开始界面（这一次按键不传给开始界面；还在显示开始界面时照常处理）
+		playing := a.demo != nil
+		a.stop()
+		return playing
+	}
+	if a.loading != nil {
+		a.poll()
+	}
+	if a.demo == nil {
+		if a.loading != nil {
+			return false
+		}
+		a.idle++
+		if pressed {
+			a.idle = 0
+		}
+		if a.idle >= attractIdleFrames {
+			a.start(opts)
+		}
+		return false
+	}
+
+	a.blink++
+	if a.loading != nil {
+		return true
+	}
+	a.frames++
+	a.demo.Update()
+	if a.finished() {
+		a.start(opts)
+	}
+	return true
+}
+
+// finished 本局演示是否结束：演示录像播放完 attractRestartFrames 帧后（停留在结算界面），
+// 机器人演示超过 attractDemoFrames 帧或玩家死亡 attractRestartFrames 帧后
+func (a *AttractMode) finished() bool {
+	if a.player != nil {
+		return a.frames >= a.length+attractRestartFrames
+	}
+	player := a.demo.Player
+	return a.frames >= attractDemoFrames || player == nil || player.IsDead && player.deathFrames >= attractRestartFrames
+}
+
+// start 开始加载下一局演示：有演示录像时播放下一个录像，否则用新的随机种子开始机器人演示（随机地图、无突变，见 autoplayOptions）
+func (a *AttractMode) start(opts GameOptions) {
+	opts = autoplayOptions(opts)
+	if replay := a.nextDemo(); replay != nil {
+		demoOpts := replay.Options(opts)
+		demoOpts.autoplay = true
+		demoOpts.replay = NewReplayPlayer(replay)
+		a.loading = NewLoadingScene(demoOpts)
+		return
+	}
+	opts.Seed = time.Now().UnixNano()
+	opts.Mutators = 0
+	opts.LevelPath = ""
+	a.loading = NewLoadingScene(opts)
+}
+
+// poll 推进后台加载：加载完成时释放上一局并开始播放新的一局；加载失败时（LoadingScene 已经写入日志）回到开始界面
+func (a *AttractMode) poll() {
+	a.loading.Update()
+	game, err := a.loading.Result()
+	if game == nil && err == nil {
+		return
+	}
+	a.loading = nil
+	if err != nil {
+		a.stop()
+		return
+	}
+	if a.demo != nil {
+		a.demo.Close()
+	}
+	a.demo = game
+	a.frames = 0
+	a.player = game.options.replay
+	if a.player != nil {
+		a.length = a.player.replay.Frames()
+	}
+}
+
+// nextDemo 按顺序读取下一个演示录像，无法读取的文件给出警告并不再播放；没有演示录像时返回 nil
+func (a *AttractMode) nextDemo() *Replay {
+	if !a.scanned {
+		a.scanned = true
+		paths, err := filepath.Glob(filepath.Join(attractDemosDir, "*.json"))
+		if err != nil {
+			log.Printf("警告: 读取演示录像目录 %s 失败: %v", attractDemosDir, err)
+		}
+		slices.Sort(paths)
+		a.demos = paths
+	}
+	for len(a.demos) > 0 {
+		a.next %= len(a.demos)
+		replay, err := LoadReplay(a.demos[a.next])
+		if err == nil {
+			a.next++
+			return replay
+		}
+		log.Printf("警告: 跳过演示录像: %v", err)
+		a.demos = slices.Delete(a.demos, a.next, a.next+1)
+	}
+	return nil
+}
+
+// stop 结束演示，释放演示的音频；正在加载的演示在后台等待加载完成后释放（不阻塞游戏线程）
+func (a *AttractMode) stop() {
+	if a.loading != nil {
+		go a.loading.Close()
+		a.loading = nil
+	}
+	if a.demo != nil {
+		a.demo.Close()
+		a.demo = nil
+	}
+	a.player = nil
+	a.idle = 0
+}
+
+// Close 结束正在播放的演示（开始界面被释放时调用），等待正在加载的演示加载完成后释放
+func (a *AttractMode) Close() {
+	if a.loading != nil {
+		a.loading.Close()
+		a.loading = nil
+	}
+	a.stop()
+}
+
+// Draw 演示播放中绘制演示和提示并返回 true，否则返回 false（由开始界面自己绘制）
+func (a *AttractMode) Draw(screen *ebiten.Image) bool {
+	if a.demo == nil {
+		return false
+	}
+	a.demo.Draw(screen)
+	DrawTextCentered(screen, "DEMO", windowWidth/2, 60)
+	if a.blink/30%2 == 0 {
+		DrawTextCentered(screen, "PRESS ANY KEY", windowWidth/2, windowHeight/2+40)
+	}
+	return true
+}
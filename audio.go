@@ -1,177 +1,280 @@
 package main
 
 import (
-	"bytes"
-	"io"
+	"image/color"
 	"log"
-	"os"
+	"path/filepath"
+	"strings"
 
-	"github.com/hajimehoshi/ebiten/v2/audio"
-	"github.com/hajimehoshi/ebiten/v2/audio/mp3"
-	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
 )
 
 const (
-	// 音频采样率
+	// 默认的音频输出采样率（可以用 -sample-rate 修改）
 	audioSampleRate = 44100
 	// 背景音乐音量
 	bgmVolume = 0.4
 	// 跳跃音效音量
 	soundVolume = 1
+	// 压低音量时的音量比例
+	duckVolumeRatio = 0.2
+	// “正在播放”提示显示的帧数（包括滑入和滑出）和滑入、滑出各用的帧数
+	nowPlayingFrames      = 180
+	nowPlayingSlideFrames = 20
+	// 每条字幕显示的帧数和同时显示的最大条数
+	captionFrames = 120
+	maxCaptions   = 3
+	// 怪物靠近提示：距离（像素）和两次提示之间至少间隔的帧数
+	monsterNearbyDistance       = 400.0
+	monsterNearbyCooldownFrames = 120
+	// 播放 1UP 旋律时压低背景音乐的帧数（大致是旋律的长度）
+	oneUpDuckFrames = 90
+
+	// 音频资源路径
+	bgmPath       = "res/audio/bgm.mp3"
+	jumpSoundPath = "res/audio/jump.wav"
+	dieSoundPath  = "res/audio/die.mp3"
+	// 可选的音效（文件不存在时只显示字幕）
+	powerUpSoundPath       = "res/audio/powerup.wav"
+	monsterNearbySoundPath = "res/audio/monster.wav"
+	shieldPopSoundPath     = "res/audio/shield_pop.wav"
+	oneUpSoundPath         = "res/audio/oneup.wav"
 )
 
-// AudioManager 音频管理器
-type AudioManager struct {
-	context   *audio.Context // 音频上下文
-	bgmPlayer *audio.Player  // 背景音乐播放器
-}
+// captionBackgroundColor 字幕背景色
+var captionBackgroundColor = color.RGBA{A: 0xb0}
 
-// NewAudioManager 创建音频管理器
-func NewAudioManager() *AudioManager {
-	manager := &AudioManager{
-		context: audio.NewContext(audioSampleRate),
-	}
+// 音效注册表中的音效名称
+const (
+	sfxJump          = "jump"
+	sfxDie           = "die"
+	sfxPowerUp       = "powerup"
+	sfxMonsterNearby = "monster_nearby"
+	sfxShieldPop     = "shield_pop"
+	sfxOneUp         = "oneup"
+)
 
-	// 加载并播放背景音乐
-	manager.loadBGM()
+// SFXDef 音效注册表中的一项：音效文件、声部、冷却和字幕
+type SFXDef struct {
+	Name           string
+	Path           string
+	Volume         float64
+	Voices         int    // 声部数量（同时播放的最大次数）
+	CooldownFrames int    // 同一音效两次播放之间至少间隔的帧数
+	Caption        string // 播放时在 HUD 上显示的字幕（为空时不显示）
+}
 
-	return manager
+// sfxRegistry 游戏音效注册表（SubscribeAudioEvents 加载）
+var sfxRegistry = []SFXDef{
+	{Name: sfxJump, Path: jumpSoundPath, Volume: soundVolume, Voices: 1, Caption: "[jump]"},
+	{Name: sfxDie, Path: dieSoundPath, Volume: soundVolume, Voices: 1, Caption: "[death]"},
+	{Name: sfxPowerUp, Path: powerUpSoundPath, Volume: soundVolume, Voices: 1, Caption: "[power-up]"},
+	{Name: sfxMonsterNearby, Path: monsterNearbySoundPath, Volume: soundVolume, Voices: 1, CooldownFrames: monsterNearbyCooldownFrames, Caption: "[monster nearby]"},
+	{Name: sfxShieldPop, Path: shieldPopSoundPath, Volume: soundVolume, Voices: 1, Caption: "[shield pop]"},
+	{Name: sfxOneUp, Path: oneUpSoundPath, Volume: soundVolume, Voices: 1, Caption: "[1-up]"},
 }
 
-// GetContext 获取音频上下文
-func (am *AudioManager) GetContext() *audio.Context {
-	return am.context
+// SFXPool 按名称管理的音效池，每个音效有自己的声部数量、播放冷却和字幕
+// 音效文件是可选的，加载失败的音效播放时静音（有字幕时仍然显示字幕）
+type SFXPool struct {
+	manager  *engine.AudioManager
+	clips    map[string]*engine.SoundPool
+	captions map[string]string
+	Captions *CaptionHUD // 字幕显示（为 nil 时不显示字幕）
 }
 
-// loadBGM 加载并播放背景音乐
-func (am *AudioManager) loadBGM() {
-	// 打开背景音乐文件
-	f, err := os.Open("res/audio/bgm.mp3")
-	if err != nil {
-		log.Printf("警告: 无法加载背景音乐: %v", err)
-		return
+// NewSFXPool 创建音效池
+func NewSFXPool(manager *engine.AudioManager) *SFXPool {
+	return &SFXPool{
+		manager:  manager,
+		clips:    make(map[string]*engine.SoundPool),
+		captions: make(map[string]string),
 	}
+}
+
+// Load 加载音效，文件不存在时返回 false
+// voices: 声部数量；cooldownFrames: 同一音效两次播放之间至少间隔的帧数
+func (p *SFXPool) Load(name, path string, volume float64, voices, cooldownFrames int) bool {
+	return p.Register(SFXDef{Name: name, Path: path, Volume: volume, Voices: voices, CooldownFrames: cooldownFrames})
+}
 
-	// 读取整个文件到内存
-	data, err := io.ReadAll(f)
-	f.Close() // 立即关闭文件
+// Register 按注册表中的定义加载音效并记录字幕，文件不存在时返回 false（字幕仍然记录）
+func (p *SFXPool) Register(def SFXDef) bool {
+	if def.Caption != "" {
+		p.captions[def.Name] = def.Caption
+	}
+	pool, err := p.manager.LoadSoundPool(def.Path, def.Volume, def.Voices, def.CooldownFrames)
 	if err != nil {
-		log.Printf("警告: 无法读取背景音乐文件: %v", err)
-		return
+		return false
 	}
+	p.clips[def.Name] = pool
+	return true
+}
 
-	// 从内存中的数据创建 Reader
-	reader := bytes.NewReader(data)
-
-	// 解码 MP3 文件
-	stream, err := mp3.DecodeWithoutResampling(reader)
-	if err != nil {
-		log.Printf("警告: 无法解码背景音乐: %v", err)
-		return
+// Play 播放音效并显示字幕，音效未加载或冷却中时返回 false
+// 音效文件缺少时也显示字幕（字幕代表游戏中发生的声音提示，不依赖声音本身）
+func (p *SFXPool) Play(name string) bool {
+	clip, loaded := p.clips[name]
+	played := clip.Play()
+	if caption := p.captions[name]; caption != "" && (played || !loaded) {
+		p.Captions.Show(caption)
 	}
+	return played
+}
 
-	// 创建循环播放器（使用 InfiniteLoop 实现循环）
-	loop := audio.NewInfiniteLoop(stream, stream.Length())
-	player, err := am.context.NewPlayer(loop)
-	if err != nil {
-		log.Printf("警告: 无法创建背景音乐播放器: %v", err)
-		return
+// Update 每帧推进所有音效的冷却计时
+func (p *SFXPool) Update() {
+	for _, clip := range p.clips {
+		clip.Update()
 	}
+}
 
-	am.bgmPlayer = player
-	player.SetVolume(bgmVolume) // 设置音量（0.0 到 1.0）
-	player.Play()               // 开始播放
+// caption 一条正在显示的字幕
+type caption struct {
+	text   string
+	frames int // 剩余显示的帧数
 }
 
-// SetBGMVolume 设置背景音乐音量
-func (am *AudioManager) SetBGMVolume(volume float64) {
-	if am.bgmPlayer != nil {
-		am.bgmPlayer.SetVolume(volume)
-	}
+// CaptionHUD 声音提示的字幕（辅助功能，听障玩家使用），在屏幕下方居中显示最近的几条
+// 所有方法都允许在 nil 上调用；Settings.Accessibility.Captions 关闭时不显示
+type CaptionHUD struct {
+	enabled bool
+	lines   []caption // 按显示顺序排列，最新的在最后
 }
 
-// PauseBGM 暂停背景音乐
-func (am *AudioManager) PauseBGM() {
-	if am.bgmPlayer != nil && am.bgmPlayer.IsPlaying() {
-		am.bgmPlayer.Pause()
-	}
+// NewCaptionHUD 创建字幕显示
+func NewCaptionHUD(enabled bool) *CaptionHUD {
+	return &CaptionHUD{enabled: enabled}
 }
 
-// ResumeBGM 恢复背景音乐
-func (am *AudioManager) ResumeBGM() {
-	if am.bgmPlayer != nil && !am.bgmPlayer.IsPlaying() {
-		am.bgmPlayer.Play()
+// Show 显示一条字幕；同样的字幕正在显示时重新计时并移到最新，不重复显示
+func (h *CaptionHUD) Show(text string) {
+	if h == nil || !h.enabled {
+		return
+	}
+	for i, line := range h.lines {
+		if line.text == text {
+			h.lines = append(h.lines[:i], h.lines[i+1:]...)
+			break
+		}
+	}
+	h.lines = append(h.lines, caption{text: text, frames: captionFrames})
+	if len(h.lines) > maxCaptions {
+		h.lines = h.lines[len(h.lines)-maxCaptions:]
 	}
 }
 
-// LoadJumpSound 加载跳跃音效
-// 返回音频播放器，如果加载失败返回 nil
-func (am *AudioManager) LoadJumpSound() *audio.Player {
-	// 打开跳跃音效文件
-	f, err := os.Open("res/audio/jump.wav")
-	if err != nil {
-		// 如果文件不存在，只记录警告，不中断游戏
-		return nil
+// Update 每帧推进显示计时，移除显示完的字幕
+func (h *CaptionHUD) Update() {
+	if h == nil {
+		return
 	}
-
-	// 读取整个文件到内存
-	data, err := io.ReadAll(f)
-	f.Close() // 立即关闭文件
-	if err != nil {
-		return nil
+	visible := h.lines[:0]
+	for _, line := range h.lines {
+		if line.frames--; line.frames > 0 {
+			visible = append(visible, line)
+		}
 	}
+	h.lines = visible
+}
 
-	// 从内存中的数据创建 Reader
-	reader := bytes.NewReader(data)
-
-	// 解码 WAV 文件
-	stream, err := wav.DecodeWithoutResampling(reader)
-	if err != nil {
-		return nil
+// Draw 在屏幕下方居中绘制字幕（半透明黑底，在任何背景上都能看清），最新的一条在最下面
+func (h *CaptionHUD) Draw(screen *ebiten.Image) {
+	if h == nil {
+		return
 	}
-
-	// 创建播放器
-	player, err := am.context.NewPlayer(stream)
-	if err != nil {
-		return nil
+	for i, line := range h.lines {
+		width := TextWidth(line.text) + 8
+		x := windowWidth/2 - width/2
+		y := windowHeight - 60 - (len(h.lines)-1-i)*20
+		vector.FillRect(screen, float32(x), float32(y-2), float32(width), 18, captionBackgroundColor, false)
+		DrawText(screen, line.text, x+4, y)
 	}
+}
 
-	player.SetVolume(soundVolume) // 设置音量（0.0 到 1.0）
-	return player
+// NowPlayingToast 背景音乐播放列表切换曲目时，在屏幕右下角显示曲目名称（从右侧滑入，停留后滑出）
+type NowPlayingToast struct {
+	text    string
+	visible bool
+	offset  float64        // 相对停留位置向右的偏移（像素，滑出屏幕时为文字宽度加边距）
+	tweens  engine.Tweener // 滑入、停留和滑出的补间（界面时间，暂停时也播放）
 }
 
-// LoadDieSound 加载死亡音效
-// 返回音频播放器，如果加载失败返回 nil
-func (am *AudioManager) LoadDieSound() *audio.Player {
-	// 打开死亡音效文件
-	f, err := os.Open("res/audio/die.mp3")
-	if err != nil {
-		// 如果文件不存在，只记录警告，不中断游戏
-		return nil
+// Show 显示曲目名称（文件名去掉扩展名），正在显示时从当前位置重新滑入
+func (t *NowPlayingToast) Show(path string) {
+	t.text = "NOW PLAYING: " + strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	hidden := float64(TextWidth(t.text) + 10)
+	if !t.visible {
+		t.offset = hidden
 	}
+	t.visible = true
 
-	// 读取整个文件到内存
-	data, err := io.ReadAll(f)
-	f.Close() // 立即关闭文件
-	if err != nil {
-		return nil
-	}
+	t.tweens.Clear()
+	slideIn := engine.NewTween(nowPlayingSlideFrames, engine.EaseOutCubic).Float(&t.offset, 0)
+	slideIn.Then(engine.NewTween(nowPlayingSlideFrames, engine.EaseInQuad).
+		Float(&t.offset, hidden).
+		Delay(nowPlayingFrames - 2*nowPlayingSlideFrames).
+		OnComplete(func() { t.visible = false }))
+	t.tweens.Start(slideIn)
+}
 
-	// 从内存中的数据创建 Reader
-	reader := bytes.NewReader(data)
+// Update 每帧推进滑动
+func (t *NowPlayingToast) Update() {
+	t.tweens.Update(1)
+}
 
-	// 解码 MP3 文件
-	stream, err := mp3.DecodeWithoutResampling(reader)
-	if err != nil {
-		return nil
+// Draw 绘制提示
+func (t *NowPlayingToast) Draw(screen *ebiten.Image) {
+	if !t.visible {
+		return
 	}
+	DrawText(screen, t.text, windowWidth-TextWidth(t.text)-10+int(t.offset), windowHeight-26)
+}
 
-	// 创建播放器
-	player, err := am.context.NewPlayer(stream)
-	if err != nil {
-		return nil
+// NewAudioManager 创建音频管理器并开始播放背景音乐
+// sampleRate: 输出采样率，音频文件的采样率不同时加载时自动重采样
+func NewAudioManager(sampleRate int) *engine.AudioManager {
+	manager := engine.NewAudioManager(sampleRate, duckVolumeRatio)
+	if err := manager.PlayBGM(bgmPath, bgmVolume); err != nil {
+		log.Printf("警告: 无法加载背景音乐: %v", err)
+	}
+	return manager
+}
+
+// SubscribeAudioEvents 加载音效注册表，订阅游戏事件：起跳、死亡和拾取道具时播放音效（1UP 道具播放旋律并短暂压低背景音乐），
+// 死亡时暂停背景音乐，回溯复活或接关后恢复背景音乐
+// 音效文件不存在时不中断游戏，对应事件静音（字幕照常显示）
+func SubscribeAudioEvents(am *engine.AudioManager, sfx *SFXPool, bus *EventBus) {
+	for _, def := range sfxRegistry {
+		sfx.Register(def)
 	}
 
-	player.SetVolume(soundVolume) // 设置音量（0.0 到 1.0）
-	return player
+	bus.Subscribe(EventPlayerJumped, func(e Event) {
+		sfx.Play(sfxJump)
+	})
+	bus.Subscribe(EventPlayerDied, func(e Event) {
+		am.PauseBGM()
+		sfx.Play(sfxDie)
+	})
+	bus.Subscribe(EventToolCollected, func(e Event) {
+		// 1UP 道具播放专门的旋律，播放期间压低背景音乐
+		if e.Obstacle != nil && e.Obstacle.PowerUp == PowerUpExtraLife {
+			am.DuckBGM(oneUpDuckFrames)
+			sfx.Play(sfxOneUp)
+			return
+		}
+		sfx.Play(sfxPowerUp)
+	})
+	bus.Subscribe(EventShieldPopped, func(e Event) {
+		sfx.Play(sfxShieldPop)
+	})
+	bus.Subscribe(EventPlayerRewound, func(e Event) {
+		am.ResumeBGM()
+	})
+	bus.Subscribe(EventPlayerContinued, func(e Event) {
+		am.ResumeBGM()
+	})
 }
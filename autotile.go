@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"my_ai_game/internal/engine"
+)
+
+// RoadTile 道路图块的种类（按左右两侧是否有相邻的道路选择）
+type RoadTile int
+
+const (
+	RoadTileMiddle RoadTile = iota // 两侧都有道路
+	RoadTileLeft                   // 左端（左侧没有道路）
+	RoadTileRight                  // 右端（右侧没有道路）
+	RoadTileSingle                 // 单独一块（两侧都没有道路）
+	roadTileCount
+)
+
+// roadTileNames 道路图块名称
+var roadTileNames = map[RoadTile]string{
+	RoadTileMiddle: "middle",
+	RoadTileLeft:   "left",
+	RoadTileRight:  "right",
+	RoadTileSingle: "single",
+}
+
+// String 返回道路图块名称
+func (t RoadTile) String() string {
+	return roadTileNames[t]
+}
+
+// roadTileFor 按左右两侧是否有相邻图块选择图块种类
+func roadTileFor(left, right bool) RoadTile {
+	switch {
+	case left && right:
+		return RoadTileMiddle
+	case right:
+		return RoadTileLeft
+	case left:
+		return RoadTileRight
+	default:
+		return RoadTileSingle
+	}
+}
+
+// AutoTileDef 自动拼接定义：道路按相邻的道路自动选择端点图块，不再是一整片相同的图片
+// 端点图块可以在数据中指定图片；没有指定时由原图生成（露出的一侧顶角切成圆角、边缘加深）
+type AutoTileDef struct {
+	Left         string  `json:"left"`         // 左端图块图片（为空时生成）
+	Right        string  `json:"right"`        // 右端图块图片（为空时生成）
+	Single       string  `json:"single"`       // 单独图块图片（为空时生成）
+	CornerRadius int     `json:"cornerRadius"` // 生成端点图块时顶角的圆角半径（像素）
+	EdgeShade    float64 `json:"edgeShade"`    // 生成端点图块时边缘最暗处的亮度倍数（0 表示 1，不加深）
+}
+
+// loadTiles 加载或生成所有种类的图块（尺寸必须与原图一致，碰撞盒不变）
+// src: 原图（生成端点图块使用）
+func (d *ObstacleDef) loadTiles(src image.Image) error {
+	shade := d.AutoTile.EdgeShade
+	if shade == 0 {
+		shade = 1
+	}
+	d.tiles[RoadTileMiddle] = d.image
+	paths := map[RoadTile]string{RoadTileLeft: d.AutoTile.Left, RoadTileRight: d.AutoTile.Right, RoadTileSingle: d.AutoTile.Single}
+	for tile := RoadTileLeft; tile < roadTileCount; tile++ {
+		if path := paths[tile]; path != "" {
+			img, _, err := ebitenutil.NewImageFromFile(path)
+			if err != nil {
+				return fmt.Errorf("加载 %s 图块失败: %w", tile, err)
+			}
+			if img.Bounds().Size() != d.image.Bounds().Size() {
+				return fmt.Errorf("%s 图块的尺寸与原图不同: %s", tile, path)
+			}
+			d.tiles[tile] = img
+			continue
+		}
+		left := tile == RoadTileLeft || tile == RoadTileSingle
+		right := tile == RoadTileRight || tile == RoadTileSingle
+		d.tiles[tile] = ebiten.NewImageFromImage(engine.EdgeTile(src, left, right, d.AutoTile.CornerRadius, shade))
+	}
+	return nil
+}
+
+// Tile 返回指定种类的图块，没有自动拼接定义时返回原图
+func (d *ObstacleDef) Tile(tile RoadTile) *ebiten.Image {
+	if d.tiles[tile] == nil {
+		return d.image
+	}
+	return d.tiles[tile]
+}
+
+// ApplyTile 把放置好的障碍物换成指定种类的图块（使用自己图片的变体保持不变，颜色调整保留）
+func (d *ObstacleDef) ApplyTile(obstacle *Obstacle, variant *ObstacleVariant, tile RoadTile) {
+	if variant != nil && variant.ImagePath != "" {
+		return
+	}
+	obstacle.Image = d.Tile(tile)
+}
+
+// roadTile 按左右两列是否有道路选择道路图块（地图两端之外视为没有道路）
+func (g *Game) roadTile(col int) RoadTile {
+	hasRoad := func(c int) bool { return c >= 0 && c < len(g.MapItems) && g.MapItems[c].HasRoad }
+	return roadTileFor(hasRoad(col-1), hasRoad(col+1))
+}
+
+// platformTile 按左右两列是否有高处路线平台选择平台图块
+func (g *Game) platformTile(col int) RoadTile {
+	hasPlatform := func(c int) bool { return c >= 0 && c < len(g.MapItems) && g.MapItems[c].HasPlatform }
+	return roadTileFor(hasPlatform(col-1), hasPlatform(col+1))
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 压力场景的障碍物数量
+	stressObstacles = 10000
+	// 压力场景的粒子数量
+	stressParticles = 500
+)
+
+// benchParticle 压力场景中的粒子（模拟粒子效果每帧的积分负载）
+type benchParticle struct {
+	X, Y, VelocityX, VelocityY float64
+	Life                       int
+}
+
+// benchCatalog 加载障碍物目录，失败时结束基准测试
+func benchCatalog(b *testing.B) *ObstacleCatalog {
+	b.Helper()
+	catalog, err := LoadObstacleCatalog(obstacleCatalogPath)
+	if err != nil {
+		b.Fatalf("加载障碍物目录失败: %v", err)
+	}
+	return catalog
+}
+
+// benchObstacles 创建 n 个连续排列的道路块
+func benchObstacles(catalog *ObstacleCatalog, n int) []*Obstacle {
+	def := catalog.Get(obstacleDefGrass)
+	width, height := def.Size()
+	obstacles := make([]*Obstacle, 0, n)
+	for i := 0; i < n; i++ {
+		obstacles = append(obstacles, def.NewObstacle(float64(i)*width, float64(windowHeight)-height, ObstacleTypeGrass))
+	}
+	return obstacles
+}
+
+// BenchmarkCheckCollision 玩家与 100/1000/10000 个障碍物的碰撞检测
+func BenchmarkCheckCollision(b *testing.B) {
+	catalog := benchCatalog(b)
+	for _, n := range []int{100, 1000, 10000} {
+		obstacles := benchObstacles(catalog, n)
+		player := &Player{X: 600, Y: 600}
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, obstacle := range obstacles {
+					engine.CheckCollision(player, obstacle)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkGenMap 生成 1000/10000/100000 列的地图
+func BenchmarkGenMap(b *testing.B) {
+	for _, n := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				GenMap(n, rand.New(rand.NewSource(int64(i))))
+			}
+		})
+	}
+}
+
+// BenchmarkAnimationGetFrame 从精灵表提取动画帧
+func BenchmarkAnimationGetFrame(b *testing.B) {
+	animation, err := engine.NewAnimation("res/image/idle.png", 39, true, 20.0, 22)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		animation.GetFrame(i % animation.FrameCount)
+	}
+}
+
+// BenchmarkDrawCull 绘制剔除判断（与 Obstacle.Draw 使用同一判断）
+func BenchmarkDrawCull(b *testing.B) {
+	catalog := benchCatalog(b)
+	for _, n := range []int{1000, 10000} {
+		obstacles := benchObstacles(catalog, n)
+		camera := engine.NewCamera(windowWidth, windowHeight)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				camera.X = float64(i%n) * mapItemWidth
+				for _, obstacle := range obstacles {
+					obstacle.onScreen(camera)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkStressScene 合成压力场景：玩家在 10000 个障碍物上奔跑，同时更新 500 个粒子并做绘制剔除
+// 每次迭代相当于一帧
+func BenchmarkStressScene(b *testing.B) {
+	obstacles := benchObstacles(benchCatalog(b), stressObstacles)
+	mapWidth := float64(stressObstacles) * mapItemWidth
+	startX, startY := 200.0, obstacles[0].Y
+	clock := engine.NewClock()
+	player, err := NewPlayer(startX, startY, NewEventBus(), clock)
+	if err != nil {
+		b.Fatal(err)
+	}
+	initial := *player
+	camera := engine.NewCamera(windowWidth, windowHeight)
+	input := PlayerInput{Right: true}
+
+	random := rand.New(rand.NewSource(1))
+	particles := make([]benchParticle, stressParticles)
+	spawn := func(p *benchParticle) {
+		*p = benchParticle{
+			X:         player.X,
+			Y:         player.Y,
+			VelocityX: random.Float64()*4 - 2,
+			VelocityY: -random.Float64() * 6,
+			Life:      30 + random.Intn(30),
+		}
+	}
+	for i := range particles {
+		spawn(&particles[i])
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// 相机跟随玩家，跑到地图尽头后回到起点
+		camera.X = player.X - startX
+		clock.Tick()
+		player.Update(input, obstacles, mapWidth, camera, 1)
+		player.Animate()
+		if player.IsDead || player.X > mapWidth-float64(windowWidth) {
+			*player = initial
+		}
+
+		for j := range particles {
+			p := &particles[j]
+			p.VelocityY += gravity
+			p.X += p.VelocityX
+			p.Y += p.VelocityY
+			if p.Life--; p.Life <= 0 {
+				spawn(p)
+			}
+		}
+
+		for _, obstacle := range obstacles {
+			obstacle.onScreen(camera)
+		}
+	}
+}
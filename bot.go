@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// 机器人向前查看危险的距离超出一次跳跃距离的部分（像素）
+	botLookaheadMargin = mapItemWidth
+	// 机器人不跑出屏幕中间偏前的位置（沿滚动方向的屏幕比例）
+	botScreenLimit = 0.55
+	// 浸泡测试中玩家死亡后等待多少帧开始下一局（与吸引模式相同，让死亡动画播放完）
+	soakRestartFrames = attractRestartFrames
+	// 浸泡测试中一局最多运行的帧数
+	soakRunFrames = 20000
+)
+
+// Bot 自动操作玩家的机器人：按地图数据和怪物位置找到前方的危险（缺口、障碍物、怪物），
+// 让跳跃轨迹的中心对准危险的中心时起跳；用于吸引模式的演示和浸泡测试
+type Bot struct {
+	jumpDistance float64 // 一次跳跃的水平距离（第一次使用时按玩家的移动参数计算）
+}
+
+// NewBot 创建机器人
+func NewBot() *Bot {
+	return &Bot{}
+}
+
+// Input 返回本帧的操作：沿滚动方向前进（不超过屏幕中间偏前的位置），
+// 在空中或前方有危险时一直前进，跳跃轨迹能越过危险时起跳
+func (b *Bot) Input(g *Game) PlayerInput {
+	p := g.Player
+	if p == nil || p.IsDead {
+		return PlayerInput{}
+	}
+	if b.jumpDistance == 0 {
+		arc := jumpArc(p.Physics)
+		b.jumpDistance = p.Physics.Speed
+		if len(arc) > 0 {
+			b.jumpDistance += arc[len(arc)-1].X
+		}
+	}
+	dir := g.scroll.Direction
+
+	// 玩家在屏幕中的位置（沿滚动方向，0 为屏幕后方边缘）
+	screenX := p.X - g.Camera.X
+	if dir < 0 {
+		screenX = float64(windowWidth) - screenX
+	}
+
+	near, far, hazard := b.nextHazard(g, p.X, dir)
+	airborne := !p.IsOnGround || p.IsFlying
+	var input PlayerInput
+	if airborne || hazard || screenX < float64(windowWidth)*botScreenLimit {
+		input.Right = dir > 0
+		input.Left = dir < 0
+	}
+	if hazard && !airborne && ((near+far)/2 <= b.jumpDistance/2 || near <= playerCollisionWidth/2) {
+		input.Jump = true
+	}
+	return input
+}
+
+// nextHazard 返回前方最近的危险到玩家中心的距离范围（沿滚动方向），前方查看范围内没有危险时 ok 为 false
+// 连续的缺口和障碍物列算作一个危险；怪物按碰撞盒计算（高处平台上的怪物不算）
+func (b *Bot) nextHazard(g *Game, x, dir float64) (near, far float64, ok bool) {
+	lookahead := b.jumpDistance + botLookaheadMargin
+	blocked := func(col int) bool {
+		item := g.MapItems[col]
+		return !item.HasRoad || item.HasObstacle
+	}
+
+	// 地图数据中的缺口和障碍物
+	col := int(x / mapItemWidth)
+	step := int(dir)
+	for c := col; c >= 0 && c < len(g.MapItems); c += step {
+		edge := float64(c) * mapItemWidth
+		if dir < 0 {
+			edge += mapItemWidth
+		}
+		distance := (edge - x) * dir
+		if distance > lookahead {
+			break
+		}
+		if !blocked(c) {
+			continue
+		}
+		end := c
+		for end+step >= 0 && end+step < len(g.MapItems) && blocked(end+step) {
+			end += step
+		}
+		near = max(distance, 0)
+		far = distance + float64((end-c)*step+1)*mapItemWidth
+		ok = true
+		break
+	}
+
+	// 怪物（比地图中的危险更近时使用怪物）
+	top := g.Player.Y - playerCollisionHeight*2
+	for _, obstacle := range g.Obstacles {
+		if obstacle.Monster == nil || obstacle.Monster.IsDead || obstacle.Y+obstacle.Height < top {
+			continue
+		}
+		start := obstacle.X - x
+		if dir < 0 {
+			start = x - (obstacle.X + obstacle.Width)
+		}
+		end := start + obstacle.Width
+		if end <= 0 || start > lookahead || ok && start >= near {
+			continue
+		}
+		near, far, ok = max(start, 0), end, true
+	}
+	return near, far, ok
+}
+
+// autoplayOptions 由机器人操作玩家时的启动选项：静音、不使用存档、不记录统计、不报告在线状态
+func autoplayOptions(opts GameOptions) GameOptions {
+	opts.Profile = ""
+	opts.Editor = false
+	opts.Analytics = false
+	opts.Presence = false
+	opts.Debug = false
+	opts.Mute = true
+	opts.autoplay = true
+	opts.scenes = nil
+	return opts
+}
+
+// soakRun 浸泡测试中一局的结果
+type soakRun struct {
+	seedCode string
+	frames   int
+	distance int    // 离起点的列数
+	cause    string // 死亡原因（没有死亡时为空）
+}
+
+// RunSoak 浸泡测试：不打开窗口，由机器人连续玩 frames 帧（每局死亡、通关或超过 soakRunFrames 帧后换下一个种子），
+// 检查玩家坐标没有变成 NaN 或无穷大，结果写入 w；种子从启动选项的种子开始依次加一，失败的局可以用种子码复现
+func RunSoak(w io.Writer, opts GameOptions, frames int) {
+	opts = autoplayOptions(opts)
+	baseSeed := opts.Seed
+	started := time.Now()
+
+	var runs []soakRun
+	var game *Game
+	var run *soakRun
+	var startX float64
+	finish := func(cause string) {
+		run.distance = int(math.Abs(game.Player.X-startX) / mapItemWidth)
+		run.cause = cause
+		fmt.Fprintf(w, "run %-4d seed %s  frames %6d  distance %4d  %s\n", len(runs), run.seedCode, run.frames, run.distance, cause)
+		game.Close()
+		game = nil
+	}
+
+	for frame := 0; frame < frames; frame++ {
+		if game == nil {
+			opts.Seed = baseSeed + int64(len(runs))
+			game = NewGame(opts)
+			runs = append(runs, soakRun{seedCode: SeedCode(opts.Seed, opts.Mutators)})
+			run = &runs[len(runs)-1]
+			startX = game.Player.X
+		}
+
+		if err := game.Update(); err != nil {
+			log.Fatalf("浸泡测试第 %d 局出错: %v", len(runs), err)
+		}
+		run.frames++
+		p := game.Player
+		if math.IsNaN(p.X) || math.IsNaN(p.Y) || math.IsInf(p.X, 0) || math.IsInf(p.Y, 0) {
+			log.Fatalf("浸泡测试第 %d 局（种子码 %s）第 %d 帧玩家坐标无效: %v, %v", len(runs), run.seedCode, run.frames, p.X, p.Y)
+		}
+		switch {
+		case p.IsDead && p.deathFrames >= soakRestartFrames:
+			finish(p.DeathCause.String())
+		case game.cleared:
+			finish("")
+		case run.frames >= soakRunFrames:
+			finish("")
+		}
+	}
+	if game != nil {
+		finish("")
+	}
+
+	// 汇总：局数、死亡原因分布、平均距离和模拟速度
+	causes := make(map[string]int)
+	totalDistance := 0
+	for _, r := range runs {
+		if r.cause != "" {
+			causes[r.cause]++
+		}
+		totalDistance += r.distance
+	}
+	names := make([]string, 0, len(causes))
+	for name := range causes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	elapsed := time.Since(started)
+	fmt.Fprintf(w, "soak: %d frames, %d runs, average distance %.1f columns, %.0f frames/s\n",
+		frames, len(runs), float64(totalDistance)/float64(max(len(runs), 1)), float64(frames)/elapsed.Seconds())
+	for _, name := range names {
+		fmt.Fprintf(w, "  deaths by %-12s %d\n", name, causes[name])
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 巨石直径（像素，碰撞盒和图片相同）
+	boulderSize = 100.0
+	// 有障碍物的道路块换成巨石的概率（单独的随机数流，不改变其他生成结果）
+	boulderChance = 0.15
+	// 玩家推动时巨石的水平速度（像素/帧，比玩家慢，推动时玩家跟着巨石走）
+	boulderPushSpeed = 3.0
+	// 在地面上滚动时每帧保留的水平速度（滚动摩擦），低于 boulderStopSpeed 时停下
+	boulderRollFriction = 0.985
+	boulderStopSpeed    = 0.05
+	// 水平速度上限（像素/帧）
+	boulderMaxSpeed = 9.0
+	// 水平速度或下落速度超过这个值时巨石会压扁碰到的怪物和玩家；更慢时碰到玩家只会停下
+	boulderCrushSpeed = 4.0
+)
+
+// attackBoulder 巨石碾压：滚动或下落的巨石压扁怪物（比护盾撞击更重，顿帧更长）
+var attackBoulder = &AttackDef{
+	Name:          "boulder",
+	Damage:        5,
+	HitStopFrames: 4,
+	NumberColor:   color.RGBA{R: 0xd0, G: 0xc8, B: 0xc0, A: 0xff},
+}
+
+// boulderImage 巨石图片（第一次创建巨石时绘制）
+var boulderImage *ebiten.Image
+
+// Boulder 巨石的运行时状态（挂在 Obstacle 上）
+// 巨石是从上方和侧面阻挡的障碍物，和静态障碍物一样可以站在上面、挡住玩家和怪物，
+// 但受重力影响，被玩家推动后滚动，滚过边缘时落下
+type Boulder struct {
+	VelocityX  float64 // 水平速度
+	VelocityY  float64 // 垂直速度
+	IsOnGround bool    // 是否在地面上
+	Gone       bool    // 是否掉出地图，需要从场景中移除
+}
+
+// NewBoulder 创建巨石障碍物
+// centerX: 底部中心的 X 坐标；bottomY: 底部的 Y 坐标
+func NewBoulder(centerX, bottomY float64) *Obstacle {
+	if boulderImage == nil {
+		boulderImage = ebiten.NewImage(int(boulderSize), int(boulderSize))
+		half := float32(boulderSize / 2.0)
+		vector.FillCircle(boulderImage, half, half, half, color.RGBA{R: 0x70, G: 0x68, B: 0x60, A: 0xff}, true)
+		// 几块深色的斑点，滚动时能看出旋转
+		spot := color.RGBA{R: 0x50, G: 0x4a, B: 0x44, A: 0xff}
+		vector.FillCircle(boulderImage, half*0.6, half*0.7, half*0.22, spot, true)
+		vector.FillCircle(boulderImage, half*1.4, half*1.1, half*0.28, spot, true)
+		vector.FillCircle(boulderImage, half*0.9, half*1.5, half*0.16, spot, true)
+	}
+
+	left := centerX - boulderSize/2.0
+	top := bottomY - boulderSize
+	obstacle := NewObstacle(left, top, left, top, boulderSize, boulderSize, boulderImage, ObstacleTypeObstacle)
+	obstacle.Boulder = &Boulder{IsOnGround: true}
+	return obstacle
+}
+
+// Push 玩家向 direction（-1 向左，1 向右）推动巨石：在地面上时至少以推动速度滚动
+func (b *Boulder) Push(direction float64) {
+	if !b.IsOnGround || b.VelocityX*direction >= boulderPushSpeed {
+		return
+	}
+	b.VelocityX = direction * boulderPushSpeed
+}
+
+// dangerous 巨石是否快到足以压扁碰到的怪物和玩家
+func (b *Boulder) dangerous() bool {
+	return math.Abs(b.VelocityX) >= boulderCrushSpeed || (!b.IsOnGround && b.VelocityY >= boulderCrushSpeed)
+}
+
+// Update 模拟一帧：滚动、下落和碰撞，压扁碰到的怪物和玩家
+// 每次调用模拟一帧的游戏时间（由物理系统在模拟步中调用）
+func (b *Boulder) Update(o *Obstacle, g *Game) {
+	// 水平：在地面上受滚动摩擦，碰到从侧面阻挡的障碍物时停下
+	if b.IsOnGround {
+		b.VelocityX *= boulderRollFriction
+		if math.Abs(b.VelocityX) < boulderStopSpeed {
+			b.VelocityX = 0
+		}
+	}
+	b.VelocityX = max(-boulderMaxSpeed, min(boulderMaxSpeed, b.VelocityX))
+	if b.VelocityX != 0 {
+		o.Move(b.VelocityX, 0)
+		// 按滚过的距离旋转图片
+		o.Rotation += b.VelocityX / (boulderSize / 2.0)
+	}
+	b.pushOut(o, g.Obstacles)
+
+	// 垂直：重力，中心在道路或平台上方时落在上面（和怪物一样按中心判断，超过边缘一半就滚落）
+	b.VelocityY += gravity
+	o.Move(0, b.VelocityY)
+	b.land(o, g.Obstacles)
+
+	// 掉出屏幕下方的巨石从场景中移除
+	if o.Y > float64(windowHeight) {
+		b.Gone = true
+		return
+	}
+
+	b.crush(o, g)
+}
+
+// pushOut 与从侧面阻挡的障碍物重叠时，推到中心所在的一侧并停止水平滚动
+func (b *Boulder) pushOut(o *Obstacle, obstacles []*Obstacle) {
+	for _, other := range obstacles {
+		if other == o || !other.Solidity.Has(SolidFromSides) || !engine.CheckCollision(o, other) {
+			continue
+		}
+		left, right, top, _ := other.GetCollisionBox()
+		// 只是底部贴着（站在上面）时不算挡住
+		if o.Y+o.Height <= top+groundSnapDistance && b.IsOnGround {
+			continue
+		}
+		if o.X+o.Width/2.0 < (left+right)/2.0 {
+			o.Move(left-(o.X+o.Width), 0)
+		} else {
+			o.Move(right-o.X, 0)
+		}
+		b.VelocityX = 0
+	}
+}
+
+// land 下落时落到中心下方的道路、障碍物或平台上
+func (b *Boulder) land(o *Obstacle, obstacles []*Obstacle) {
+	b.IsOnGround = false
+	if b.VelocityY < 0 {
+		return
+	}
+	centerX := o.X + o.Width/2.0
+	bottom := o.Y + o.Height
+	prevBottom := bottom - b.VelocityY
+	for _, other := range obstacles {
+		if other == o || !other.Solidity.Has(SolidFromAbove) {
+			continue
+		}
+		left, right, top, _ := other.GetCollisionBox()
+		if centerX < left || centerX >= right || prevBottom > top || bottom < top {
+			continue
+		}
+		o.Move(0, top-bottom)
+		bottom = top
+		b.VelocityY = 0
+		b.IsOnGround = true
+	}
+}
+
+// crush 压扁碰到的怪物（发布怪物受击事件，子弹和落物只是被挡掉）和玩家；不够快时碰到玩家只会停下
+// 被压扁不是怪物的触碰，护盾不能抵挡
+func (b *Boulder) crush(o *Obstacle, g *Game) {
+	dangerous := b.dangerous()
+	for _, other := range g.Obstacles {
+		m := other.Monster
+		if m == nil || m.IsDead || !engine.CheckCollision(o, other) {
+			continue
+		}
+		if m.Def.missile {
+			m.IsDead = true
+			continue
+		}
+		if !dangerous {
+			continue
+		}
+		m.IsDead = true
+		left, right, top, _ := other.GetCollisionBox()
+		g.events.Publish(Event{Type: EventMonsterHit, X: (left + right) / 2, Y: top, Obstacle: other, Value: attackBoulder.Damage, Attack: attackBoulder})
+	}
+
+	p := g.Player
+	if p.IsDead || p.IsFlying || !engine.CheckCollision(o, p) {
+		return
+	}
+	if dangerous {
+		p.handleDeath(DeathCauseCrush)
+		return
+	}
+	// 慢慢滚到玩家身上时停在玩家旁边
+	if o.X+o.Width/2.0 < p.X {
+		o.Move(p.X-playerCollisionWidth/2.0-(o.X+o.Width), 0)
+	} else {
+		o.Move(p.X+playerCollisionWidth/2.0-o.X, 0)
+	}
+	b.VelocityX = 0
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// 社区关卡目录
+	levelsDir = "levels"
+	// 下载远程关卡索引和关卡文件的超时时间
+	levelIndexTimeout = 5 * time.Second
+	// 下载的远程关卡索引和关卡文件的大小上限（远程内容不可信，防止占满内存）
+	levelDownloadMaxSize = 8 << 20
+	// 关卡列表每页显示的行数
+	browserPageRows = 20
+)
+
+// LevelInfo 关卡列表中的一项
+type LevelInfo struct {
+	Name       string `json:"name"`       // 关卡名称
+	Author     string `json:"author"`     // 作者
+	Length     int    `json:"length"`     // 列数
+	Difficulty string `json:"difficulty"` // 难度
+	Path       string `json:"-"`          // 本地关卡文件路径（远程关卡下载后才有）
+	URL        string `json:"url"`        // 远程关卡的下载地址（本地关卡为空）
+}
+
+// EstimateDifficulty 按缺口、障碍物和怪物所占的比例估计关卡难度（关卡文件没有填写难度时使用）
+func EstimateDifficulty(items []*MapItem) string {
+	if len(items) == 0 {
+		return "easy"
+	}
+	hazards := 0
+	for _, item := range items {
+		if !item.HasRoad || item.HasObstacle || item.HasMonster {
+			hazards++
+		}
+	}
+	switch ratio := float64(hazards) / float64(len(items)); {
+	case ratio < 0.15:
+		return "easy"
+	case ratio < 0.3:
+		return "normal"
+	default:
+		return "hard"
+	}
+}
+
+// levelInfo 从已加载的关卡生成列表项，没有名称时使用文件名
+func levelInfo(path string, level *Level) LevelInfo {
+	info := LevelInfo{
+		Name:       level.Name,
+		Author:     level.Author,
+		Length:     len(level.Items),
+		Difficulty: level.Difficulty,
+		Path:       path,
+	}
+	if info.Name == "" {
+		info.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if info.Author == "" {
+		info.Author = "unknown"
+	}
+	if info.Difficulty == "" {
+		info.Difficulty = EstimateDifficulty(level.Items)
+	}
+	return info
+}
+
+// ScanLevels 读取关卡目录中的所有关卡文件（*.json，以及关卡包安装的子目录中的 */*.json），
+// 无法加载的文件跳过并给出警告
+func ScanLevels(dir string) []LevelInfo {
+	var paths []string
+	for _, pattern := range []string{"*.json", filepath.Join("*", "*.json")} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			log.Printf("警告: 读取关卡目录 %s 失败: %v", dir, err)
+			return nil
+		}
+		paths = append(paths, matches...)
+	}
+	var infos []LevelInfo
+	for _, path := range paths {
+		level, err := LoadLevel(path)
+		if err != nil {
+			log.Printf("警告: 跳过关卡文件: %v", err)
+			continue
+		}
+		infos = append(infos, levelInfo(path, level))
+	}
+	return infos
+}
+
+// FetchLevelIndex 下载远程关卡索引（LevelInfo 的 JSON 数组，每项带 url）
+func FetchLevelIndex(url string) ([]LevelInfo, error) {
+	data, err := httpGet(url)
+	if err != nil {
+		return nil, err
+	}
+	var infos []LevelInfo
+	if err := json.Unmarshal(data, &infos); err != nil {
+		return nil, fmt.Errorf("解析关卡索引失败: %w", err)
+	}
+	return infos, nil
+}
+
+// 同名的本地关卡内容不同时，下载的关卡文件名最多尝试的后缀数
+const downloadNameAttempts = 100
+
+// downloadLevel 把远程关卡下载到关卡目录，校验能够加载后返回本地路径
+// 先写入关卡目录中的临时文件并校验，通过后才改名为正式的文件名，校验失败只删除临时文件；
+// 文件名只取下载地址路径的最后一段（不含查询参数），不会写到关卡目录以外；
+// 已有同名的关卡时不覆盖：内容相同直接使用已有的文件，否则加上 -2、-3… 后缀
+func downloadLevel(info LevelInfo, dir string) (string, error) {
+	name, err := downloadFileName(info.URL)
+	if err != nil {
+		return "", err
+	}
+	data, err := httpGet(info.URL)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(dir, ".download-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		_, err = LoadLevel(tmp.Name())
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	base, ext := strings.TrimSuffix(name, ".json"), ".json"
+	for i := 1; i <= downloadNameAttempts; i++ {
+		path := filepath.Join(dir, base+ext)
+		if i > 1 {
+			path = filepath.Join(dir, fmt.Sprintf("%s-%d%s", base, i, ext))
+		}
+		existing, err := os.ReadFile(path)
+		switch {
+		case err == nil && bytes.Equal(existing, data):
+			os.Remove(tmp.Name())
+			return path, nil
+		case err == nil:
+			continue
+		case !errors.Is(err, os.ErrNotExist):
+			os.Remove(tmp.Name())
+			return "", err
+		}
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			os.Remove(tmp.Name())
+			return "", err
+		}
+		return path, nil
+	}
+	os.Remove(tmp.Name())
+	return "", fmt.Errorf("关卡目录中已有太多名为 %s 的关卡", name)
+}
+
+// downloadFileName 从下载地址的路径取关卡文件名（去掉查询参数和片段，没有 .json 扩展名时加上）
+// 路径中编码的反斜杠（%5C）在 Windows 上也是分隔符，先按本地的分隔符取最后一段，
+// 仍然含有分隔符、盘符或 .. 的文件名（例如其他平台上的 a\..\evil.json）视为无效，保证不会写到关卡目录以外
+func downloadFileName(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("关卡下载地址 %q 无效: %w", rawURL, err)
+	}
+	name := filepath.Base(filepath.FromSlash(path.Base(u.Path)))
+	if name == "." || name == string(filepath.Separator) || strings.HasPrefix(name, ".") {
+		name = "level"
+	}
+	if strings.ContainsAny(name, `\/:`) || strings.Contains(name, "..") || !filepath.IsLocal(name) {
+		return "", fmt.Errorf("关卡下载地址 %q 的文件名 %q 无效", rawURL, name)
+	}
+	if path.Ext(name) != ".json" {
+		name += ".json"
+	}
+	return name, nil
+}
+
+// httpGet 带超时地下载 url 的内容，超过 levelDownloadMaxSize 时报错
+func httpGet(url string) ([]byte, error) {
+	client := &http.Client{Timeout: levelIndexTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载 %s 失败: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, levelDownloadMaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > levelDownloadMaxSize {
+		return nil, fmt.Errorf("下载 %s 失败: 超过 %d MiB", url, levelDownloadMaxSize>>20)
+	}
+	return data, nil
+}
+
+// downloadResult 后台下载远程关卡的结果
+type downloadResult struct {
+	level int    // 关卡在列表中的下标
+	path  string // 下载后的本地路径
+	err   error
+}
+
+// LevelBrowser 社区关卡浏览场景（-levels 启动）
+// 列出关卡目录中的关卡（以及可选的远程索引），选中后通过加载界面按该关卡创建 Game
+// 远程索引和远程关卡都在后台协程中下载（每次最多 5 秒），期间界面照常响应并显示正在下载
+type LevelBrowser struct {
+	opts     GameOptions
+	levels   []LevelInfo
+	selected int
+	message  string              // 正在下载、下载或加载失败的提示
+	index    chan []LevelInfo    // 正在下载的远程索引（缓冲 1，为 nil 时没有在下载）
+	download chan downloadResult // 正在下载的远程关卡（缓冲 1，为 nil 时没有在下载）
+	attract  AttractMode         // 无操作时播放的演示
+}
+
+// NewLevelBrowser 创建关卡浏览场景，在后台下载远程索引
+// indexURL: 远程关卡索引地址，为空时只列出本地关卡
+func NewLevelBrowser(opts GameOptions, indexURL string) *LevelBrowser {
+	b := &LevelBrowser{opts: opts, levels: ScanLevels(levelsDir)}
+	if indexURL != "" {
+		b.index = make(chan []LevelInfo, 1)
+		go func() {
+			remote, err := FetchLevelIndex(indexURL)
+			if err != nil {
+				log.Printf("警告: 下载远程关卡索引失败: %v", err)
+			}
+			b.index <- remote
+		}()
+	}
+	return b
+}
+
+// Update 选择关卡，返回键回到标题（正在下载的索引和关卡在后台继续，结果丢弃）
+func (b *LevelBrowser) Update() error {
+	b.poll()
+	if b.attract.Update(b.opts) {
+		return nil
+	}
+
+	switch {
+	case ActionJustPressed(ActionBack):
+		b.opts.scenes.ToTitle()
+	case titleMenuUp():
+		b.selected--
+	case titleMenuDown():
+		b.selected++
+	case ActionJustPressed(ActionConfirm) && len(b.levels) > 0 && b.download == nil:
+		b.launch(b.selected)
+	}
+	if len(b.levels) > 0 {
+		b.selected = (b.selected + len(b.levels)) % len(b.levels)
+	}
+	return nil
+}
+
+// poll 取回后台下载的结果：远程索引追加到列表末尾，远程关卡下载完成后开始游戏
+func (b *LevelBrowser) poll() {
+	select {
+	case remote := <-b.index:
+		b.levels = append(b.levels, remote...)
+		b.index = nil
+	default:
+	}
+	select {
+	case result := <-b.download:
+		b.download = nil
+		info := &b.levels[result.level]
+		if result.err != nil {
+			log.Printf("警告: 下载关卡 %s 失败: %v", info.Name, result.err)
+			b.message = "DOWNLOAD FAILED: " + info.Name
+			return
+		}
+		info.Path = result.path
+		b.launch(result.level)
+	default:
+	}
+}
+
+// launch 启动列表中第 level 个关卡，远程关卡先在后台下载到关卡目录
+func (b *LevelBrowser) launch(level int) {
+	info := b.levels[level]
+	if info.Path == "" {
+		b.message = "DOWNLOADING " + info.Name + "..."
+		b.download = make(chan downloadResult, 1)
+		go func() {
+			path, err := downloadLevel(info, levelsDir)
+			b.download <- downloadResult{level: level, path: path, err: err}
+		}()
+		return
+	}
+	b.message = ""
+	opts := b.opts
+	opts.LevelPath = info.Path
+	b.opts.scenes.Switch(NewLoadingScene(opts))
+}
+
+// Draw 绘制关卡列表
+func (b *LevelBrowser) Draw(screen *ebiten.Image) {
+	if b.attract.Draw(screen) {
+		return
+	}
+
+	DrawPrompt(screen, "LEVELS  ({navigate} SELECT, {confirm} PLAY, {back} TITLE)", 40, 40)
+	if len(b.levels) == 0 {
+		if b.index != nil {
+			DrawText(screen, "LOADING REMOTE LEVELS...", 40, 72)
+		} else {
+			DrawText(screen, "NO LEVELS IN "+levelsDir+"/", 40, 72)
+		}
+		return
+	}
+
+	DrawText(screen, fmt.Sprintf("  %-24s %-16s %6s  %s", "NAME", "AUTHOR", "LENGTH", "DIFFICULTY"), 40, 72)
+	first := b.selected / browserPageRows * browserPageRows
+	for i := first; i < len(b.levels) && i < first+browserPageRows; i++ {
+		info := b.levels[i]
+		cursor := " "
+		if i == b.selected {
+			cursor = ">"
+		}
+		length := fmt.Sprint(info.Length)
+		if info.Length == 0 && info.Path == "" {
+			length = "REMOTE"
+		}
+		line := fmt.Sprintf("%s %-24s %-16s %6s  %s", cursor, info.Name, info.Author, length, info.Difficulty)
+		DrawText(screen, line, 40, 92+(i-first)*16)
+	}
+	message := b.message
+	if message == "" && b.index != nil {
+		message = "LOADING REMOTE LEVELS..."
+	}
+	if message != "" {
+		DrawText(screen, message, 40, 92+browserPageRows*16+16)
+	}
+}
+
+// Close 结束正在播放的演示
+func (b *LevelBrowser) Close() {
+	b.attract.Close()
+}
+
+// Layout 返回游戏逻辑尺寸
+func (b *LevelBrowser) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return windowWidth, windowHeight
+}
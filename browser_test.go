@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDownloadFileName 文件名只取下载地址路径的最后一段，编码的分隔符和 .. 不能让文件名离开关卡目录
+func TestDownloadFileName(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://example.com/levels/meadow.json", "meadow.json"},
+		{"https://example.com/levels/meadow.json?v=2#top", "meadow.json"},
+		{"https://example.com/levels/meadow", "meadow.json"},
+		{"https://example.com/", "level.json"},
+		{"https://example.com/levels/..", "level.json"},
+		{"https://example.com/levels/.hidden", "level.json"},
+	}
+	for _, tt := range tests {
+		got, err := downloadFileName(tt.url)
+		if err != nil || got != tt.want {
+			t.Errorf("downloadFileName(%q) = %q, %v，应为 %q", tt.url, got, err, tt.want)
+		}
+	}
+
+	for _, rawURL := range []string{
+		"https://example.com/a%5C..%5C..%5Cevil.json",
+		"https://example.com/..%5Cevil.json",
+		"https://example.com/C:evil.json",
+		"https://example.com/a..b.json",
+	} {
+		// Windows 上反斜杠是分隔符，只留下最后一段；其他平台上含有反斜杠的文件名无效
+		got, err := downloadFileName(rawURL)
+		if err == nil && (strings.ContainsAny(got, `\/:`) || strings.Contains(got, "..") || !filepath.IsLocal(got)) {
+			t.Errorf("downloadFileName(%q) = %q，会写到关卡目录以外", rawURL, got)
+		}
+	}
+}
+
+// TestHTTPGetLimit 远程内容超过 levelDownloadMaxSize 时报错，不读入整个响应
+func TestHTTPGetLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		size := levelDownloadMaxSize
+		if r.URL.Path == "/big" {
+			size++
+		}
+		w.Write(bytes.Repeat([]byte{' '}, size))
+	}))
+	defer server.Close()
+
+	if data, err := httpGet(server.URL + "/ok"); err != nil || len(data) != levelDownloadMaxSize {
+		t.Errorf("httpGet 上限以内的内容返回 %d 字节, %v", len(data), err)
+	}
+	if _, err := httpGet(server.URL + "/big"); err == nil {
+		t.Error("httpGet 超过上限的内容应返回错误")
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// bundleFormat 关卡包清单的格式标识
+	bundleFormat = "my_ai_game/bundle"
+	// BundleVersion 当前的关卡包版本
+	BundleVersion = 1
+	// 关卡包中清单文件的名称
+	bundleManifestName = "manifest.json"
+	// 关卡包中单个文件的大小上限（防止压缩炸弹）
+	bundleMaxFileSize = 64 << 20
+)
+
+// BundleManifest 关卡包清单
+// 关卡包是一个 zip 文件：根目录下是清单、关卡文件和关卡自带的资源（路径与关卡文件中的相对路径一致）
+type BundleManifest struct {
+	Format  string   `json:"format"`  // 格式标识，固定为 bundleFormat
+	Version int      `json:"version"` // 关卡包版本
+	Name    string   `json:"name"`    // 关卡名称
+	Author  string   `json:"author"`  // 作者
+	Level   string   `json:"level"`   // 关卡文件在包中的路径
+	Assets  []string `json:"assets"`  // 关卡自带资源在包中的路径
+}
+
+// bundleEntryPath 检查包内路径是否安全（相对路径、不包含 ..），返回清理后的路径
+func bundleEntryPath(name string) (string, error) {
+	cleaned := path.Clean(filepath.ToSlash(name))
+	if cleaned == "." || path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") || strings.Contains(cleaned, ":") {
+		return "", fmt.Errorf("关卡包中的路径不安全: %s", name)
+	}
+	return cleaned, nil
+}
+
+// ExportBundle 把关卡文件和它自带的资源打包成关卡包
+func ExportBundle(levelPath, bundlePath string) error {
+	level, err := LoadLevel(levelPath)
+	if err != nil {
+		return err
+	}
+
+	manifest := BundleManifest{
+		Format:  bundleFormat,
+		Version: BundleVersion,
+		Name:    level.Name,
+		Author:  level.Author,
+		Level:   filepath.Base(levelPath),
+	}
+	for _, asset := range level.Assets() {
+		name, err := bundleEntryPath(asset)
+		if err != nil {
+			return err
+		}
+		manifest.Assets = append(manifest.Assets, name)
+	}
+
+	file, err := os.Create(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := zip.NewWriter(file)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeBundleEntry(w, bundleManifestName, data); err != nil {
+		return err
+	}
+	files := map[string]string{manifest.Level: levelPath}
+	for _, asset := range manifest.Assets {
+		files[asset] = level.AssetPath(asset)
+	}
+	for name, src := range files {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return fmt.Errorf("读取关卡包文件 %s 失败: %w", src, err)
+		}
+		if err := writeBundleEntry(w, name, data); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// writeBundleEntry 向关卡包写入一个文件
+func writeBundleEntry(w *zip.Writer, name string, data []byte) error {
+	entry, err := w.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = entry.Write(data)
+	return err
+}
+
+// ImportBundle 把关卡包安装到关卡目录下的子目录（以关卡包文件名命名），返回安装后的关卡文件路径
+// 只解压清单中列出的文件；路径不安全、文件过大、目标目录已存在或关卡无法加载时拒绝安装，不留下半成品
+func ImportBundle(bundlePath, dir string) (string, error) {
+	r, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	entries := make(map[string]*zip.File, len(r.File))
+	for _, f := range r.File {
+		name, err := bundleEntryPath(f.Name)
+		if err != nil {
+			return "", err
+		}
+		entries[name] = f
+	}
+
+	manifestFile := entries[bundleManifestName]
+	if manifestFile == nil {
+		return "", fmt.Errorf("关卡包 %s 缺少 %s", bundlePath, bundleManifestName)
+	}
+	data, err := readBundleEntry(manifestFile)
+	if err != nil {
+		return "", err
+	}
+	var manifest BundleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return "", fmt.Errorf("解析关卡包清单失败: %w", err)
+	}
+	if manifest.Format != bundleFormat {
+		return "", fmt.Errorf("不是关卡包（格式标识为 %q）", manifest.Format)
+	}
+	if manifest.Version < 1 || manifest.Version > BundleVersion {
+		return "", fmt.Errorf("不支持的关卡包版本 %d（当前版本 %d）", manifest.Version, BundleVersion)
+	}
+
+	target := filepath.Join(dir, strings.TrimSuffix(filepath.Base(bundlePath), filepath.Ext(bundlePath)))
+	if _, err := os.Stat(target); err == nil {
+		return "", fmt.Errorf("关卡目录 %s 已存在", target)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	levelPath, err := installBundle(entries, manifest, target)
+	if err != nil {
+		os.RemoveAll(target)
+		return "", err
+	}
+	return levelPath, nil
+}
+
+// installBundle 解压清单中列出的文件到目标目录，并检查关卡能够加载
+func installBundle(entries map[string]*zip.File, manifest BundleManifest, target string) (string, error) {
+	names := append([]string{manifest.Level}, manifest.Assets...)
+	for _, name := range names {
+		name, err := bundleEntryPath(name)
+		if err != nil {
+			return "", err
+		}
+		f := entries[name]
+		if f == nil {
+			return "", fmt.Errorf("关卡包缺少清单中的文件 %s", name)
+		}
+		data, err := readBundleEntry(f)
+		if err != nil {
+			return "", err
+		}
+		dst := filepath.Join(target, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(dst, data, 0o644); err != nil {
+			return "", err
+		}
+	}
+
+	levelPath := filepath.Join(target, filepath.FromSlash(path.Clean(manifest.Level)))
+	if _, err := LoadLevel(levelPath); err != nil {
+		return "", err
+	}
+	return levelPath, nil
+}
+
+// readBundleEntry 读取关卡包中的一个文件，超过 bundleMaxFileSize 时报错
+func readBundleEntry(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > bundleMaxFileSize {
+		return nil, fmt.Errorf("关卡包中的文件 %s 过大", f.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, bundleMaxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > bundleMaxFileSize {
+		return nil, fmt.Errorf("关卡包中的文件 %s 过大", f.Name)
+	}
+	return data, nil
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// 每个地图区块的列数
+	chunkColumns = 16
+	// 相机右边界前方提前加载的距离（像素）
+	chunkLoadAhead = chunkColumns * mapItemWidth
+	// 相机左边界后方保留的距离（像素），更远的障碍物会被释放
+	chunkReleaseBehind = chunkColumns * mapItemWidth
+)
+
+// chunkLayout 创建障碍物需要的布局信息（在 initObstacles 中计算一次）
+type chunkLayout struct {
+	grassDef       *ObstacleDef
+	obstacleDef    *ObstacleDef
+	toolDef        *ObstacleDef
+	grassWidth     float64
+	grassHeight    float64
+	grassY         float64                      // 道路顶部的 Y 坐标
+	platformY      float64                      // 高处路线平台顶部的 Y 坐标
+	platformImages [roadTileCount]*ebiten.Image // 各种类的平台图片（道路图块的顶部）
+	coins          [][]CoinSpot                 // 每个区块内的金币位置
+	platforms      [][]platformColumn           // 每个区块内的纵向滚动段平台（每列一块）
+}
+
+// platformColumn 纵向滚动段平台中的一列
+type platformColumn struct {
+	Column int
+	Height float64  // 平台顶部高出道路顶部的距离
+	Tile   RoadTile // 在平台中的位置（两端使用端点图块）
+}
+
+// ChunkStreamer 按区块流式创建和释放障碍物
+// 很长的地图如果一次性创建所有 Obstacle 会占用大量内存，因此只在相机接近时创建区块内的障碍物，
+// 并释放相机后方已经看不到的障碍物。区块总是按从左到右的顺序各创建一次，
+// 各随机数流的使用顺序与一次性创建时相同，同一个种子生成的关卡不受流式加载影响
+type ChunkStreamer struct {
+	layout    chunkLayout
+	loaded    int           // 已创建的区块数量（区块 [0, loaded) 已创建）
+	obstacles [][]*Obstacle // 每个已创建区块的障碍物（用于回溯时恢复快照之后才创建的区块），释放后为 nil
+}
+
+// chunkCount 地图的区块数量
+func (g *Game) chunkCount() int {
+	return (len(g.MapItems) + chunkColumns - 1) / chunkColumns
+}
+
+// initObstacles 根据 MapItems 准备障碍物的布局，并创建相机附近的区块
+func (g *Game) initObstacles() {
+	// 图片和碰撞盒来自障碍物目录，预先计算图片尺寸，避免在循环中重复计算
+	grassDef := g.obstacleCatalog.Get(obstacleDefGrass)
+	grassWidth, grassHeight := grassDef.Size()
+
+	// 道路块在地图最下面的位置
+	grassY := float64(windowHeight) - grassHeight
+	g.groundY = grassY
+	// 重新创建所有障碍物时机关回到初始状态（编辑器修改地图或结束试玩时）
+	g.devices.reset()
+
+	// 高处路线的平台使用道路图片的顶部
+	platformY := grassY - highRouteHeight
+
+	layout := chunkLayout{
+		grassDef:    grassDef,
+		obstacleDef: g.obstacleCatalog.Get(obstacleDefObstacle),
+		toolDef:     g.obstacleCatalog.Get(obstacleDefTool),
+		grassWidth:  grassWidth,
+		grassHeight: grassHeight,
+		grassY:      grassY,
+		platformY:   platformY,
+		coins:       make([][]CoinSpot, g.chunkCount()),
+		platforms:   make([][]platformColumn, g.chunkCount()),
+	}
+	for tile := range layout.platformImages {
+		layout.platformImages[tile] = grassDef.Tile(RoadTile(tile)).SubImage(image.Rect(0, 0, int(grassWidth), int(platformThickness))).(*ebiten.Image)
+	}
+
+	// 沿跳跃轨迹和高处路线的金币位置需要看整张地图才能计算，先算好再按区块分组
+	coins := GenCoinArcs(g.MapItems, grassY, g.options.Mutators.PlayerPhysics(), g.rng.Stream(rngStreamCoins))
+	coins = append(coins, GenHighRouteCoins(g.MapItems, platformY)...)
+	for _, spot := range coins {
+		chunk := int(spot.X / (chunkColumns * grassWidth))
+		if chunk >= 0 && chunk < len(layout.coins) {
+			layout.coins[chunk] = append(layout.coins[chunk], spot)
+		}
+	}
+
+	// 纵向滚动段的平台拆成每列一块后按区块分组（拆开前记下每列在平台中的位置）
+	for _, segment := range g.vertical.segments {
+		for _, spot := range segment.Platforms {
+			for col := spot.Column; col < spot.Column+spot.Columns; col++ {
+				if chunk := col / chunkColumns; col >= 0 && chunk < len(layout.platforms) {
+					tile := roadTileFor(col > spot.Column, col < spot.Column+spot.Columns-1)
+					layout.platforms[chunk] = append(layout.platforms[chunk], platformColumn{Column: col, Height: spot.Height, Tile: tile})
+				}
+			}
+		}
+	}
+
+	g.chunks = &ChunkStreamer{
+		layout:    layout,
+		obstacles: make([][]*Obstacle, g.chunkCount()),
+	}
+	g.Obstacles = g.Obstacles[:0]
+	g.Decorations = g.Decorations[:0]
+	g.updateChunks()
+}
+
+// updateChunks 创建相机前方需要的区块，释放相机后方的障碍物
+func (g *Game) updateChunks() {
+	c := g.chunks
+	chunkWidth := chunkColumns * c.layout.grassWidth
+
+	// 创建进入加载范围的区块
+	// 只有向右滚动的关卡才流式加载；向左和往返滚动的关卡以及编辑器中相机会回到已经经过的位置，一开始就创建所有区块且不释放
+	streamable := g.scroll.Streamable() && g.editor == nil
+	needed := int((g.Camera.X+g.Camera.Width+chunkLoadAhead)/chunkWidth) + 1
+	if needed > g.chunkCount() || !streamable {
+		needed = g.chunkCount()
+	}
+	for c.loaded < needed {
+		g.buildChunk(c.loaded)
+		c.loaded++
+	}
+
+	// 释放相机后方太远的障碍物和装饰物（按位置判断，怪物可能已经离开出生的区块）
+	releaseX := g.Camera.X - chunkReleaseBehind
+	if releaseX <= 0 || !streamable {
+		return
+	}
+	g.Obstacles = releaseBehind(g.Obstacles, releaseX)
+	g.Decorations = releaseBehind(g.Decorations, releaseX)
+	for i := 0; i < c.loaded && float64(i+1)*chunkWidth < releaseX; i++ {
+		c.obstacles[i] = nil
+	}
+}
+
+// releaseBehind 原地移除右边界在 releaseX 左侧的障碍物
+func releaseBehind(obstacles []*Obstacle, releaseX float64) []*Obstacle {
+	kept := obstacles[:0]
+	for _, obstacle := range obstacles {
+		if obstacle.Dx+obstacle.Width >= releaseX || obstacle.X+obstacle.Width >= releaseX {
+			kept = append(kept, obstacle)
+		}
+	}
+	// 清空尾部的引用，让被释放的障碍物可以被回收
+	for i := len(kept); i < len(obstacles); i++ {
+		obstacles[i] = nil
+	}
+	return kept
+}
+
+// restoreChunks 回溯恢复快照后调用：快照之后才创建的区块不在快照的障碍物列表中，需要重新加入
+// loaded: 快照时已创建的区块数量
+func (g *Game) restoreChunks(loaded int) {
+	for i := loaded; i < g.chunks.loaded; i++ {
+		g.Obstacles = append(g.Obstacles, g.chunks.obstacles[i]...)
+	}
+}
+
+// buildChunk 创建区块内所有列的障碍物和装饰物
+func (g *Game) buildChunk(index int) {
+	layout := &g.chunks.layout
+	start := index * chunkColumns
+	end := start + chunkColumns
+	if end > len(g.MapItems) {
+		end = len(g.MapItems)
+	}
+	first := len(g.Obstacles)
+
+	// 外观变体的随机数流（与其他生成步骤独立，新增变体不会改变地图布局）
+	variants := g.rng.Stream(rngStreamVariants)
+	decorations := g.rng.Stream(rngStreamDecorations)
+	powerUps := g.rng.Stream(rngStreamPowerUps)
+	boulders := g.rng.Stream(rngStreamBoulders)
+	swings := g.rng.Stream(rngStreamSwings)
+	grassWidth := layout.grassWidth
+	grassY := layout.grassY
+
+	// 遍历区块内的 MapItem，创建对应的 Obstacle 对象
+	for _, item := range g.MapItems[start:end] {
+		// 计算道路块的绝对坐标
+		grassX := float64(item.Index) * grassWidth
+
+		// 如果有道路，创建 grass Obstacle
+		if item.HasRoad {
+			// 道路变体只改变外观（颜色、图片），尺寸必须与原始道路一致；两端按相邻的道路换成端点图块
+			variant := layout.grassDef.PickVariant(variants)
+			grass := layout.grassDef.PlaceVariant(variant, grassX+grassWidth/2.0, grassY+layout.grassHeight, ObstacleTypeGrass)
+			layout.grassDef.ApplyTile(grass, variant, g.roadTile(item.Index))
+			g.Obstacles = append(g.Obstacles, grass)
+
+			// 如果有障碍，创建 obstacle Obstacle
+			if item.HasObstacle {
+				// 比地图块宽的变体会伸进左右两列，只有两侧都是没有障碍物和怪物的道路时才使用
+				variant := layout.obstacleDef.PickVariant(variants)
+				if width, _ := layout.obstacleDef.VariantSize(variant); width > grassWidth && !(g.isClearRoad(item.Index-1) && g.isClearRoad(item.Index+1)) {
+					variant = nil
+				}
+				obstacle := layout.obstacleDef.PlaceVariant(variant, grassX+grassWidth/2.0, grassY, ObstacleTypeObstacle)
+				// 随机生成的地图上一部分障碍物换成可以推动的巨石（在选择变体之后抽取，不改变变体的随机数流；关卡文件保持作者的设计）
+				if g.options.LevelPath == "" && boulders.Float64() < boulderChance {
+					obstacle = NewBoulder(grassX+grassWidth/2.0, grassY)
+				}
+				g.Obstacles = append(g.Obstacles, obstacle)
+			}
+
+			// 如果有怪物，按怪物目录的权重随机选择种类，放在道路块上面
+			if item.HasMonster {
+				monster := NewMonster(g.monsterCatalog.Pick(g.rng.Stream(rngStreamMonsters)), grassX, grassY)
+				g.Obstacles = append(g.Obstacles, monster)
+			}
+
+			// 如果有道具，创建 tool Obstacle
+			if item.HasTool {
+				toolY := 120.0 // 道具 Y 坐标固定为 120
+				tool := layout.toolDef.NewObstacle(grassX, toolY, ObstacleTypeTool)
+				// 一部分道具是子弹时间、护盾或 1UP 道具（单独的随机数流，不改变其他生成结果），换成不同的颜色以示区别
+				// 关闭回溯时没有 1UP 道具（落在 1UP 区间的道具仍然是飞行道具）
+				switch roll := powerUps.Float64(); {
+				case roll < bulletTimeChance:
+					tool.PowerUp = PowerUpBulletTime
+					tool.Tint.Scale(bulletTimeTint[0], bulletTimeTint[1], bulletTimeTint[2], 1)
+				case roll < bulletTimeChance+shieldChance:
+					tool.PowerUp = PowerUpShield
+					tool.Tint.Scale(shieldTint[0], shieldTint[1], shieldTint[2], 1)
+				case roll < bulletTimeChance+shieldChance+extraLifeChance && g.settings.RewindCharges > 0:
+					tool.PowerUp = PowerUpExtraLife
+					tool.Tint.Scale(extraLifeTint[0], extraLifeTint[1], extraLifeTint[2], 1)
+				}
+				g.Obstacles = append(g.Obstacles, tool)
+			}
+
+			// 装饰物：放在没有障碍物的道路块上，只加入 Decorations，不参与碰撞
+			if !item.HasObstacle {
+				for _, def := range g.obstacleCatalog.Decorations {
+					if decorations.Float64() >= def.Chance {
+						continue
+					}
+					// 在地图块内随机水平位置，底部贴着道路顶部
+					centerX := (float64(item.Index) + 0.2 + decorations.Float64()*0.6) * grassWidth
+					decoration := def.PlaceVariant(def.PickVariant(decorations), centerX, grassY, ObstacleTypeDecoration)
+					g.Decorations = append(g.Decorations, decoration)
+				}
+			}
+		}
+
+		// 缺口的第一列：一部分缺口（上方没有高处路线时）在缺口中间的上方放置秋千点
+		if !item.HasRoad && item.Index > 0 && g.MapItems[item.Index-1].HasRoad && swings.Float64() < swingPointChance && !item.HasPlatform {
+			gapColumns := 1
+			if next := item.Index + 1; next < len(g.MapItems) && !g.MapItems[next].HasRoad {
+				gapColumns = 2
+			}
+			g.Obstacles = append(g.Obstacles, NewSwingPoint(grassX+float64(gapColumns)*grassWidth/2.0, grassY-swingPointHeight))
+		}
+
+		// 机关：开关、门和钥匙（门和开关所在的列是没有障碍物的道路，钥匙在高处路线的平台上方）
+		if device := g.devices.newObstacle(item, grassX+grassWidth/2.0, grassY, layout.platformY); device != nil {
+			g.Obstacles = append(g.Obstacles, device)
+		}
+
+		// 高处路线：单向平台和平台上的哨兵
+		if item.HasPlatform {
+			platformImage := layout.platformImages[g.platformTile(item.Index)]
+			platform := NewObstacle(grassX, layout.platformY, grassX, layout.platformY, grassWidth, platformThickness, platformImage, ObstacleTypePlatform)
+			g.Obstacles = append(g.Obstacles, platform)
+
+			if item.HasPlatformHazard {
+				if def := g.monsterCatalog.Get(highRouteHazardMonster); def != nil {
+					g.Obstacles = append(g.Obstacles, NewMonster(def, grassX, layout.platformY))
+				}
+			}
+		}
+	}
+
+	// 区块内纵向滚动段的平台
+	for _, spot := range layout.platforms[index] {
+		x := float64(spot.Column) * grassWidth
+		y := grassY - spot.Height
+		g.Obstacles = append(g.Obstacles, NewObstacle(x, y, x, y, grassWidth, platformThickness, layout.platformImages[spot.Tile], ObstacleTypePlatform))
+	}
+
+	// 区块内的金币
+	for _, spot := range layout.coins[index] {
+		g.Obstacles = append(g.Obstacles, NewCoin(spot.X, spot.Y))
+	}
+
+	g.chunks.obstacles[index] = append([]*Obstacle(nil), g.Obstacles[first:]...)
+}
+
+// ChunkSystem 区块系统：相机移动后创建前方的区块并释放后方的障碍物
+type ChunkSystem struct{}
+
+// Update 更新区块
+func (s *ChunkSystem) Update(g *Game) {
+	if g.isPaused || g.isRewinding {
+		return
+	}
+	g.updateChunks()
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 金币尺寸（像素）
+	coinSize = 32.0
+	// 金币轨迹上相邻两枚金币之间的帧数间隔
+	coinArcSpacingFrames = 8
+	// 单列缺口放置金币轨迹的概率（两列缺口总是放置）
+	coinArcSingleGapChance = 0.5
+	// 金币数变化时显示的数字滚动到新值所用的帧数
+	coinCounterFrames = 24
+)
+
+// CoinCounter 界面上的金币数：金币数变化时数字在 coinCounterFrames 帧内滚动到新的值
+type CoinCounter struct {
+	target int
+	shown  float64
+	tweens engine.Tweener
+}
+
+// Update 跟随玩家的金币数（回溯后减少时同样滚动回去），每帧调用一次
+func (c *CoinCounter) Update(coins int) {
+	if coins != c.target {
+		c.target = coins
+		c.tweens.Clear()
+		c.tweens.Start(engine.NewTween(coinCounterFrames, engine.EaseOutQuad).Float(&c.shown, float64(coins)))
+	}
+	c.tweens.Update(1)
+}
+
+// Value 返回当前显示的金币数
+func (c *CoinCounter) Value() int {
+	return int(math.Round(c.shown))
+}
+
+// CoinSpot 金币位置（中心点，世界坐标）
+type CoinSpot struct {
+	X, Y float64
+}
+
+// jumpArc 模拟一次从地面起跳并保持向右移动的完整跳跃，返回每帧玩家原点（底部中心）相对起跳点的偏移
+// 与 Player.Update 使用相同的积分顺序（先加重力再移动），保证轨迹与实际跳跃一致
+// physics: 玩家的移动参数（突变会改变速度和重力）
+func jumpArc(physics PlayerPhysics) []CoinSpot {
+	return jumpArcUntil(physics, 0)
+}
+
+// jumpArcUntil 与 jumpArc 相同，但一直模拟到玩家落到起跳点以下 drop 像素（编辑器用它检查能否跳到更低的地面）
+func jumpArcUntil(physics PlayerPhysics, drop float64) []CoinSpot {
+	var arc []CoinSpot
+	x, y, velocityY := 0.0, 0.0, jumpSpeed
+	for {
+		velocityY += physics.Gravity
+		x += physics.Speed
+		y += velocityY
+		if y >= drop {
+			return arc
+		}
+		arc = append(arc, CoinSpot{X: x, Y: y})
+	}
+}
+
+// GenCoinArcs 生成沿跳跃轨迹摆放的金币，引导玩家跳过缺口
+// 轨迹由 jumpSpeed 和玩家的移动参数计算，以缺口中心为轨迹中心；
+// 只有起跳点和落点都在可行走的道路上（轨迹可行）时才放置
+// groundY: 道路顶部的 Y 坐标
+// physics: 玩家的移动参数（与本局突变一致）
+func GenCoinArcs(items []*MapItem, groundY float64, physics PlayerPhysics, random *rand.Rand) []CoinSpot {
+	arc := jumpArc(physics)
+	if len(arc) == 0 {
+		return nil
+	}
+	jumpDistance := arc[len(arc)-1].X + physics.Speed
+
+	walkable := func(x float64) bool {
+		col := int(x / mapItemWidth)
+		return col >= 0 && col < len(items) && items[col].HasRoad && !items[col].HasObstacle
+	}
+
+	var spots []CoinSpot
+	for start := 0; start < len(items); start++ {
+		if items[start].HasRoad {
+			continue
+		}
+
+		// 找到连续没有道路的缺口 [start, end)
+		end := start
+		for end < len(items) && !items[end].HasRoad {
+			end++
+		}
+		gap := end - start
+		if gap == 1 && random.Float64() >= coinArcSingleGapChance {
+			start = end
+			continue
+		}
+
+		// 以缺口中心为轨迹中心，计算起跳点
+		centerX := float64(start+end) / 2.0 * mapItemWidth
+		takeoffX := centerX - jumpDistance/2.0
+		if walkable(takeoffX) && walkable(takeoffX+jumpDistance) {
+			for i := coinArcSpacingFrames / 2; i < len(arc); i += coinArcSpacingFrames {
+				spots = append(spots, CoinSpot{
+					X: takeoffX + arc[i].X,
+					Y: groundY + arc[i].Y - playerCollisionHeight/2.0, // 金币放在玩家碰撞盒中间的高度
+				})
+			}
+		}
+		start = end
+	}
+	return spots
+}
+
+// GenHighRouteCoins 在高处路线的平台上方摆放金币（每列两枚，有危险的列不放）
+// platformY: 平台顶部的 Y 坐标
+func GenHighRouteCoins(items []*MapItem, platformY float64) []CoinSpot {
+	var spots []CoinSpot
+	for _, item := range items {
+		if !item.HasPlatform || item.HasPlatformHazard {
+			continue
+		}
+		left := float64(item.Index) * mapItemWidth
+		y := platformY - playerCollisionHeight/2.0
+		spots = append(spots, CoinSpot{X: left + mapItemWidth*0.25, Y: y}, CoinSpot{X: left + mapItemWidth*0.75, Y: y})
+	}
+	return spots
+}
+
+// coinImage 金币图片（首次使用时生成）
+var coinImage *ebiten.Image
+
+// NewCoin 创建金币障碍物
+// x, y: 金币中心坐标
+func NewCoin(x, y float64) *Obstacle {
+	if coinImage == nil {
+		coinImage = ebiten.NewImage(int(coinSize), int(coinSize))
+		half := float32(coinSize / 2.0)
+		vector.FillCircle(coinImage, half, half, half, color.RGBA{R: 0xf0, G: 0xc0, B: 0x30, A: 0xff}, true)
+		vector.FillCircle(coinImage, half, half, half*0.6, color.RGBA{R: 0xff, G: 0xe0, B: 0x70, A: 0xff}, true)
+	}
+
+	left := x - coinSize/2.0
+	top := y - coinSize/2.0
+	return NewObstacle(left, top, left, top, coinSize, coinSize, coinImage, ObstacleTypeCoin)
+}
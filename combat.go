@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 伤害数字显示的帧数（真实时间，顿帧期间也会上浮）、上浮的距离（像素）和放大的倍数
+	damageNumberFrames = 45
+	damageNumberRise   = 60.0
+	damageNumberScale  = 2.0
+)
+
+// AttackDef 攻击：对怪物造成的伤害和命中时的顿帧，每种攻击单独调整手感
+// 怪物目前没有生命值，命中即击败；伤害只用于显示伤害数字
+type AttackDef struct {
+	Name          string
+	Damage        int        // 伤害（伤害数字显示的值）
+	HitStopFrames int        // 命中时游戏停顿的帧数（真实时间，2～4 帧）
+	NumberColor   color.RGBA // 伤害数字的颜色
+}
+
+// attackShieldBash 护盾撞击：带着护盾碰到怪物时护盾破裂并击败怪物（目前唯一的攻击）
+var attackShieldBash = &AttackDef{
+	Name:          "shield_bash",
+	Damage:        1,
+	HitStopFrames: 3,
+	NumberColor:   color.RGBA{R: 0xff, G: 0xe0, B: 0x60, A: 0xff},
+}
+
+// damageNumber 一个正在显示的伤害数字
+type damageNumber struct {
+	image *ebiten.Image // 数字（按调试字体的大小绘制，显示时放大）
+	x, y  float64       // 出现的位置（世界坐标，数字的中心）
+	timer engine.Timer
+	color color.RGBA
+}
+
+// DamageNumbers 怪物被命中时在命中位置显示伤害数字，数字向上浮起并在后半段淡出
+type DamageNumbers struct {
+	numbers []*damageNumber
+}
+
+// NewDamageNumbers 创建伤害数字显示，订阅怪物受击事件
+func NewDamageNumbers(events *EventBus) *DamageNumbers {
+	d := &DamageNumbers{}
+	events.Subscribe(EventMonsterHit, func(e Event) {
+		if e.Attack != nil {
+			d.Show(e.X, e.Y, e.Value, e.Attack.NumberColor)
+		}
+	})
+	return d
+}
+
+// Show 在世界坐标 (x, y) 显示一个伤害数字
+func (d *DamageNumbers) Show(x, y float64, damage int, clr color.RGBA) {
+	text := fmt.Sprint(damage)
+	image := NewTextImage(text)
+	number := &damageNumber{image: image, x: x, y: y, color: clr}
+	number.timer.Start(damageNumberFrames)
+	d.numbers = append(d.numbers, number)
+}
+
+// Update 推进所有数字，移除显示完的数字
+// dt: 经过的帧数
+func (d *DamageNumbers) Update(dt float64) {
+	active := d.numbers[:0]
+	for _, number := range d.numbers {
+		if number.timer.Tick(dt) {
+			number.image.Deallocate()
+			continue
+		}
+		active = append(active, number)
+	}
+	d.numbers = active
+}
+
+// Draw 绘制所有数字
+func (d *DamageNumbers) Draw(screen *ebiten.Image, camera *engine.Camera) {
+	for _, number := range d.numbers {
+		t := number.timer.Progress()
+		w, h := number.image.Bounds().Dx(), number.image.Bounds().Dy()
+		screenX, screenY := camera.WorldToScreen(number.x, number.y-damageNumberRise*engine.EaseOutCubic(t))
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(-float64(w)/2, -float64(h)/2)
+		op.GeoM.Scale(damageNumberScale, damageNumberScale)
+		op.GeoM.Translate(screenX, screenY)
+		op.ColorScale.ScaleWithColor(number.color)
+		if t > 0.5 {
+			op.ColorScale.ScaleAlpha(float32(2 - 2*t))
+		}
+		screen.DrawImage(number.image, op)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// 连击窗口：两次得分事件间隔超过这么多帧，连击中断
+	comboWindowFrames = 120
+	// 播报文字显示的帧数
+	announceDisplayFrames = 60
+	// 播报语音的冷却帧数（同一条语音）
+	announceCooldownFrames = 180
+	// 播报语音目录
+	announcerAudioDir = "res/audio/announcer/"
+)
+
+// comboEvents 计入连击的事件
+var comboEvents = []EventType{
+	EventToolCollected,
+	EventMonsterKilled,
+	EventCoinCollected,
+}
+
+// ComboSystem 连击系统：短时间内连续触发得分事件时累加连击数，超时中断
+type ComboSystem struct {
+	events *EventBus
+	count  int // 当前连击数
+	timer  int // 距离连击中断剩余的帧数
+}
+
+// NewComboSystem 创建连击系统并订阅得分事件
+func NewComboSystem(bus *EventBus) *ComboSystem {
+	s := &ComboSystem{events: bus}
+	for _, eventType := range comboEvents {
+		bus.Subscribe(eventType, s.onScore)
+	}
+	return s
+}
+
+// onScore 得分事件：连击数加一并发布连击事件
+func (s *ComboSystem) onScore(e Event) {
+	s.count++
+	s.timer = comboWindowFrames
+	s.events.Publish(Event{Type: EventComboChanged, X: e.X, Y: e.Y, Value: s.count})
+}
+
+// Update 推进连击窗口计时
+func (s *ComboSystem) Update(g *Game) {
+	if g.isPaused || g.isRewinding || s.count == 0 {
+		return
+	}
+	s.timer--
+	if s.timer <= 0 || (g.Player != nil && g.Player.IsDead) {
+		s.count = 0
+	}
+}
+
+// Count 返回当前连击数
+func (s *ComboSystem) Count() int {
+	return s.count
+}
+
+// announcement 连击播报档位
+type announcement struct {
+	combo int    // 达到的连击数
+	clip  string // 语音名称（res/audio/announcer/<clip>.wav）
+	text  string // 屏幕上显示的文字
+}
+
+// announcements 连击播报档位（按连击数从小到大）
+var announcements = []announcement{
+	{combo: 3, clip: "great", text: "GREAT!"},
+	{combo: 5, clip: "amazing", text: "AMAZING!"},
+	{combo: 10, clip: "incredible", text: "INCREDIBLE!"},
+}
+
+// Announcer 连击播报：连击数达到档位时通过音效池播放语音并显示文字
+// 语音文件是可选的，缺少时只显示文字；Settings.Announcer 关闭时不播报
+type Announcer struct {
+	sfx     *SFXPool
+	text    string // 当前显示的文字
+	display int    // 文字剩余显示帧数
+}
+
+// NewAnnouncer 创建连击播报，加载语音并订阅连击事件
+func NewAnnouncer(bus *EventBus, sfx *SFXPool, enabled bool) *Announcer {
+	a := &Announcer{sfx: sfx}
+	if !enabled {
+		return a
+	}
+
+	for _, ann := range announcements {
+		sfx.Load("announcer_"+ann.clip, announcerAudioDir+ann.clip+".wav", soundVolume, 1, announceCooldownFrames)
+	}
+	bus.Subscribe(EventComboChanged, a.onCombo)
+	return a
+}
+
+// onCombo 连击数正好达到某个档位时播报
+func (a *Announcer) onCombo(e Event) {
+	for _, ann := range announcements {
+		if e.Value == ann.combo {
+			a.sfx.Play("announcer_" + ann.clip)
+			a.text = ann.text
+			a.display = announceDisplayFrames
+			return
+		}
+	}
+}
+
+// Update 推进文字显示计时
+func (a *Announcer) Update(g *Game) {
+	if a.display > 0 {
+		a.display--
+	}
+}
+
+// Draw 在屏幕上方显示播报文字
+func (a *Announcer) Draw(screen *ebiten.Image) {
+	if a.display <= 0 {
+		return
+	}
+	DrawTextCentered(screen, a.text, windowWidth/2, 120)
+}
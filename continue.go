@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	// 接关花费的金币数
+	continueCost = 10
+	// 倒数的起始数字（10…0）和每个数字显示的帧数
+	continueCountdownFrom = 10
+	continueSecondFrames  = 60
+	// 最后一次死亡后等待死亡动画播放完再开始倒数的帧数
+	continueDelayFrames = 60
+	// 接关复活后的无敌帧数
+	continueGraceFrames = 120
+)
+
+// continueState 接关系统的状态
+type continueState int
+
+const (
+	continueIdle     continueState = iota // 玩家存活，或者还可以回溯
+	continueWaiting                       // 最后一次死亡，等待死亡动画
+	continueCounting                      // 显示接关倒数
+	continueFinished                      // 倒数结束，显示结算界面
+	continueClosed                        // 结算界面之后进入了游戏结束场景（只绘制结算界面，不再更新）
+)
+
+// ContinueSystem 接关系统：最后一次死亡（没有剩余回溯次数）后显示街机风格的 10…0 倒数，
+// 倒数期间按确认键（Enter 或手柄下方按钮）花费 continueCost 枚金币从死亡的位置继续（复活后进入飞行状态并短暂无敌），按跳过键（空格或手柄上方按钮）跳过一秒；
+// 倒数结束或金币不够时进入结算界面；碰到终点通关时直接进入通关的结算界面
+// 倒数按真实帧计算，暂停时停止
+type ContinueSystem struct {
+	state     continueState
+	frames    int // 当前状态经过的帧数
+	count     int // 倒数当前显示的数字
+	continues int // 本局接关的次数
+	deaths    int // 本局死亡的次数
+	saves     int // 本局护盾抵挡触碰的次数（避免的死亡）
+	results   *RunResults
+	startX    float64 // 玩家的起点（计算距离）
+	started   bool
+}
+
+// NewContinueSystem 创建接关系统，订阅死亡和护盾破裂事件（结算评级使用）
+func NewContinueSystem(events *EventBus) *ContinueSystem {
+	s := &ContinueSystem{}
+	events.Subscribe(EventPlayerDied, func(e Event) {
+		s.deaths++
+	})
+	events.Subscribe(EventShieldPopped, func(e Event) {
+		s.saves++
+	})
+	return s
+}
+
+// Update 检查最后一次死亡，推进倒数并处理接关输入
+func (s *ContinueSystem) Update(g *Game) {
+	if g.Player == nil {
+		return
+	}
+	if !s.started {
+		s.startX = g.Player.X
+		s.started = true
+	}
+	if g.isPaused {
+		return
+	}
+
+	switch s.state {
+	case continueIdle:
+		if g.cleared {
+			s.finish(g)
+			return
+		}
+		if g.Player.IsDead && !g.isRewinding && g.rewind.Charges() == 0 {
+			s.enter(continueWaiting)
+		}
+	case continueWaiting:
+		if s.frames++; s.frames >= continueDelayFrames {
+			s.enter(continueCounting)
+			s.count = continueCountdownFrom
+		}
+	case continueCounting:
+		if g.Input.Continue && s.CanContinue(g) {
+			s.accept(g)
+			return
+		}
+		s.frames++
+		if g.Input.Skip {
+			s.frames = continueSecondFrames
+		}
+		if s.frames < continueSecondFrames {
+			return
+		}
+		s.frames = 0
+		if s.count--; s.count < 0 {
+			s.finish(g)
+		}
+	case continueFinished:
+		s.results.Update()
+		// 从标题开始的游戏按确认键进入游戏结束场景（保存最后的画面）并保存存档，切换时场景管理器释放这一局的资源
+		if scenes := g.options.scenes; scenes != nil && ActionJustPressed(ActionConfirm) {
+			s.enter(continueClosed)
+			scenes.Switch(NewGameOverScene(g))
+			g.profile.Close()
+		}
+	}
+}
+
+// enter 切换状态并清零帧数
+func (s *ContinueSystem) enter(state continueState) {
+	s.state = state
+	s.frames = 0
+}
+
+// CanContinue 金币是否足够接关
+func (s *ContinueSystem) CanContinue(g *Game) bool {
+	return g.Player.Coins >= continueCost
+}
+
+// accept 花费金币接关：玩家在死亡的位置复活并进入飞行状态，离开缺口或怪物
+func (s *ContinueSystem) accept(g *Game) {
+	g.Player.Coins -= continueCost
+	g.Player.revive(continueGraceFrames)
+	g.startFlight()
+	s.continues++
+	s.enter(continueIdle)
+	g.events.Publish(Event{Type: EventPlayerContinued, X: g.Player.X, Y: g.Player.Y, Value: continueCost})
+}
+
+// finish 倒数结束或通关，记录本局结果和评级（使用存档时按地图保存最好的评级）并显示结算界面
+func (s *ContinueSystem) finish(g *Game) {
+	s.results = NewRunResults(g, s.startX, s.continues, s.deaths, s.saves)
+	// 由玩家操作的一局才签名（演示和测试的结算界面不显示签名，渲染测试的画面不随版本变化）
+	if !g.options.autoplay && !g.options.golden {
+		s.results.Signature = RunSignature(g.options, g.inputHash, s.results)
+		log.Printf("结果签名: %s（输入摘要 %s，%d 步）", s.results.Signature, g.inputHash.Sum(), g.inputHash.steps)
+	}
+	g.recorder.Save(s.results.Signature)
+	s.results.NewBest = g.profile.RecordGrade(analyticsMapKey(g.options), s.results)
+	s.enter(continueFinished)
+}
+
+// Results 返回本局的结果（还没有结束时为 nil）
+func (s *ContinueSystem) Results() *RunResults {
+	return s.results
+}
+
+// Draw 绘制接关倒数或结算界面
+func (s *ContinueSystem) Draw(screen *ebiten.Image, g *Game) {
+	switch s.state {
+	case continueCounting:
+		vector.FillRect(screen, 0, 0, windowWidth, windowHeight, resultsOverlayColor, false)
+		DrawTextCentered(screen, "CONTINUE?", windowWidth/2, windowHeight/2-40)
+		count := fmt.Sprintf("%d", s.count)
+		DrawTextCentered(screen, count, windowWidth/2, windowHeight/2-16)
+		prompt := fmt.Sprintf("{confirm} CONTINUE (%d COINS)  {skip} FASTER", continueCost)
+		if !s.CanContinue(g) {
+			prompt = fmt.Sprintf("NEED %d COINS TO CONTINUE  {skip} FASTER", continueCost)
+		}
+		DrawPromptCentered(screen, prompt, windowWidth/2, windowHeight/2+8)
+	case continueFinished, continueClosed:
+		s.results.Draw(screen)
+		if s.state == continueFinished && g.options.scenes != nil {
+			DrawPromptCentered(screen, "{confirm} NEXT", windowWidth/2, windowHeight-60)
+		}
+	}
+}
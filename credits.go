@@ -0,0 +1,121 @@
+package main
+
+import (
+	_ "embed"
+	"log"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 制作人员名单的背景音乐（可选，不存在时使用默认背景音乐）
+	creditsMusicPath = "res/audio/credits.mp3"
+	// 每帧向上滚动的像素数（按浮点数平移，低于一像素也是平滑的）
+	creditsScrollSpeed = 0.6
+	// 普通行和标题行（以 "# " 开头）的行高，标题放大的倍数
+	creditsLineHeight    = 20
+	creditsHeadingHeight = 44
+	creditsHeadingScale  = 2
+)
+
+// creditsText 制作人员名单（编译时嵌入，"# " 开头的行是标题）
+//
+//go:embed res/data/credits.txt
+var creditsText string
+
+// CreditsScene 制作人员名单：从标题菜单进入，背景音乐播放期间名单从屏幕下方平滑向上滚动，
+// 滚动完或按任意键（或点击鼠标）时结束
+type CreditsScene struct {
+	text   *ebiten.Image // 预先绘制好的整份名单（每帧只按偏移绘制一次）
+	offset float64       // 名单顶部的屏幕 Y 坐标
+	audio  *engine.AudioManager
+	done   bool
+}
+
+// NewCreditsScene 创建制作人员名单场景并开始播放背景音乐
+// sampleRate: 音频输出采样率；mute: 是否静音；musicVolume: 玩家设置的背景音乐音量
+func NewCreditsScene(sampleRate int, mute bool, musicVolume float64) *CreditsScene {
+	manager := engine.NewAudioManager(sampleRate, duckVolumeRatio)
+	manager.SetMuted(mute)
+	manager.SetMusicVolume(musicVolume)
+	if err := manager.PlayBGM(creditsMusicPath, bgmVolume); err != nil {
+		log.Printf("警告: 无法加载制作人员名单的背景音乐，使用默认背景音乐: %v", err)
+		if err := manager.PlayBGM(bgmPath, bgmVolume); err != nil {
+			log.Printf("警告: 无法加载背景音乐: %v", err)
+		}
+	}
+	return &CreditsScene{text: renderCredits(creditsText), offset: windowHeight, audio: manager}
+}
+
+// renderCredits 把名单绘制成一张图片，每行水平居中（使用界面字体，名单中可以有中文和日文）
+func renderCredits(text string) *ebiten.Image {
+	font := UIFont()
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	height := 0
+	for _, line := range lines {
+		height += creditsLineAdvance(line)
+	}
+
+	image := ebiten.NewImage(windowWidth, max(height, 1))
+	y := 0
+	for _, line := range lines {
+		if heading, ok := strings.CutPrefix(line, "# "); ok {
+			// 标题先按界面字体的大小绘制，再放大绘制到名单上
+			width := font.Measure(heading)
+			label := ebiten.NewImage(max(width, 1), font.LineHeight())
+			font.Draw(label, heading, 0, 0)
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(creditsHeadingScale, creditsHeadingScale)
+			op.GeoM.Translate(float64(windowWidth-width*creditsHeadingScale)/2, float64(y))
+			image.DrawImage(label, op)
+			label.Deallocate()
+		} else {
+			font.Draw(image, line, (windowWidth-font.Measure(line))/2, y)
+		}
+		y += creditsLineAdvance(line)
+	}
+	return image
+}
+
+// creditsLineAdvance 返回一行占用的高度
+func creditsLineAdvance(line string) int {
+	if strings.HasPrefix(line, "# ") {
+		return creditsHeadingHeight
+	}
+	return creditsLineHeight
+}
+
+// Update 滚动名单，滚动完或按任意键时结束并关闭背景音乐
+func (c *CreditsScene) Update() {
+	if c.done {
+		return
+	}
+	c.offset -= creditsScrollSpeed
+	skipped := len(inpututil.AppendJustPressedKeys(nil)) > 0 || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
+	if skipped || c.offset < -float64(c.text.Bounds().Dy()) {
+		c.done = true
+		c.audio.Close()
+	}
+}
+
+// Close 关闭背景音乐（名单结束时已经关闭，可以重复调用）并释放预先绘制的名单
+func (c *CreditsScene) Close() {
+	c.audio.Close()
+	c.text.Deallocate()
+}
+
+// Done 名单是否已经结束
+func (c *CreditsScene) Done() bool {
+	return c.done
+}
+
+// Draw 绘制名单
+func (c *CreditsScene) Draw(screen *ebiten.Image) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(0, c.offset)
+	screen.DrawImage(c.text, op)
+}
@@ -0,0 +1,448 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 开关打开门的默认时间（帧，关卡中的机关可以用 duration 修改）
+	defaultGateOpenFrames = 300.0
+	// 空旷的道路列放置门的概率，以及相邻两扇门之间至少间隔的列数
+	gateChance     = 0.04
+	gateMinSpacing = 12
+	// 随机地图中开关在门之前（滚动方向的反方向）的列数
+	gateSwitchColumns = 4
+	// 门的宽度和高度（像素）：门从道路顶部一直挡到屏幕上方，跳不过去
+	gateWidth  = 40.0
+	gateHeight = 560.0
+	// 门打开时的不透明度
+	gateOpenAlpha = 0.25
+	// 开关踏板的宽度和高度（像素）
+	switchWidth  = 80.0
+	switchHeight = 12.0
+	// 门上方倒计时进度条的宽度和高度（像素）
+	gateBarWidth  = 60.0
+	gateBarHeight = 6.0
+)
+
+// switchActiveTint 开关打开的门还没有关上时踏板的颜色倍数（偏绿）
+var switchActiveTint = [3]float32{0.5, 1.5, 0.5}
+
+// gateBarColor 门的倒计时进度条颜色
+var gateBarColor = color.RGBA{R: 0x60, G: 0xd0, B: 0x60, A: 0xff}
+
+// 机关图片（第一次创建机关时绘制）
+var (
+	gateImage   *ebiten.Image
+	switchImage *ebiten.Image
+)
+
+// EntityKind 关卡机关的种类
+type EntityKind int
+
+const (
+	EntitySwitch EntityKind = iota // 开关：玩家碰到（走过或踩下）时打开链接的门
+	EntityGate                     // 门：平时挡住道路，被开关打开后一段时间内可以通过
+	EntityKey                      // 钥匙：从高处拾取，打开链接的上锁的门
+	EntityDoor                     // 上锁的门：平时挡住道路，玩家带着链接它的钥匙走到门前时永久打开
+)
+
+// entityKindNames 机关种类名称（关卡文件使用）
+var entityKindNames = map[EntityKind]string{
+	EntitySwitch: "switch",
+	EntityGate:   "gate",
+	EntityKey:    "key",
+	EntityDoor:   "door",
+}
+
+// String 返回机关种类名称
+func (k EntityKind) String() string {
+	return entityKindNames[k]
+}
+
+// ParseEntityKind 根据名称解析机关种类
+func ParseEntityKind(name string) (EntityKind, error) {
+	for kind, kindName := range entityKindNames {
+		if strings.EqualFold(name, kindName) {
+			return kind, nil
+		}
+	}
+	return EntitySwitch, fmt.Errorf("未知的机关种类: %s", name)
+}
+
+// MarshalText 关卡文件中以名称保存机关种类
+func (k EntityKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText 从关卡文件中的名称解析机关种类
+func (k *EntityKind) UnmarshalText(text []byte) error {
+	kind, err := ParseEntityKind(string(text))
+	if err != nil {
+		return err
+	}
+	*k = kind
+	return nil
+}
+
+// LevelEntity 关卡中的机关，机关之间用 ID 互相引用（开关和钥匙通过 Links 引用它们打开的门）
+type LevelEntity struct {
+	ID       string     `json:"id"`
+	Kind     EntityKind `json:"kind"`
+	Column   int        `json:"column"`             // 所在的列（每列最多一个机关；除钥匙外必须是没有障碍物的道路）
+	Links    []string   `json:"links,omitempty"`    // 开关：打开的门的 ID；钥匙：打开的上锁的门的 ID
+	Duration float64    `json:"duration,omitempty"` // 开关：门打开的时间（帧），0 表示使用默认值
+}
+
+// openFrames 返回开关打开门的时间（帧）
+func (e *LevelEntity) openFrames() float64 {
+	if e.Duration > 0 {
+		return e.Duration
+	}
+	return defaultGateOpenFrames
+}
+
+// entityLinkKinds 各种机关可以链接的机关种类（不在表中的机关不能链接其他机关）
+var entityLinkKinds = map[EntityKind]EntityKind{
+	EntitySwitch: EntityGate,
+	EntityKey:    EntityDoor,
+}
+
+// ValidateEntities 检查关卡中的机关：ID 不能重复，每列最多一个机关，除钥匙外所在的列必须是没有障碍物的道路；
+// 开关至少链接一扇门并且只能链接到门，钥匙至少链接一扇上锁的门并且只能链接到上锁的门；
+// 每扇上锁的门至少有一把钥匙，并且钥匙按滚动方向在门之前（往返滚动的关卡按第一段向右滚动判断）
+func ValidateEntities(entities []*LevelEntity, items []*MapItem, mode ScrollMode) error {
+	byID := make(map[string]*LevelEntity, len(entities))
+	columns := make(map[int]string, len(entities))
+	for i, entity := range entities {
+		switch {
+		case entity == nil:
+			return fmt.Errorf("第 %d 个机关为空", i)
+		case entity.ID == "":
+			return fmt.Errorf("第 %d 个机关没有 ID", i)
+		case byID[entity.ID] != nil:
+			return fmt.Errorf("机关 ID %q 重复", entity.ID)
+		case entity.Column < 0 || entity.Column >= len(items):
+			return fmt.Errorf("机关 %q 所在的第 %d 列超出地图", entity.ID, entity.Column)
+		case entity.Kind != EntityKey && (!items[entity.Column].HasRoad || items[entity.Column].HasObstacle):
+			return fmt.Errorf("机关 %q 所在的第 %d 列不是没有障碍物的道路", entity.ID, entity.Column)
+		case columns[entity.Column] != "":
+			return fmt.Errorf("机关 %q 和 %q 在同一列", entity.ID, columns[entity.Column])
+		}
+		byID[entity.ID] = entity
+		columns[entity.Column] = entity.ID
+	}
+
+	locked := make(map[string]bool) // 有钥匙的上锁的门
+	for _, entity := range entities {
+		linkKind, canLink := entityLinkKinds[entity.Kind]
+		if !canLink {
+			if len(entity.Links) > 0 {
+				return fmt.Errorf("%s %q 不能链接其他机关", entity.Kind, entity.ID)
+			}
+			continue
+		}
+		if len(entity.Links) == 0 {
+			return fmt.Errorf("%s %q 没有链接任何 %s", entity.Kind, entity.ID, linkKind)
+		}
+		for _, id := range entity.Links {
+			target := byID[id]
+			if target == nil {
+				return fmt.Errorf("%s %q 链接的机关 %q 不存在", entity.Kind, entity.ID, id)
+			}
+			if target.Kind != linkKind {
+				return fmt.Errorf("%s %q 链接的机关 %q 不是 %s", entity.Kind, entity.ID, id, linkKind)
+			}
+			if entity.Kind == EntityKey {
+				if (mode == ScrollModeLeft) != (entity.Column > target.Column) {
+					return fmt.Errorf("钥匙 %q 不在它打开的门 %q 之前", entity.ID, id)
+				}
+				locked[id] = true
+			}
+		}
+	}
+
+	for _, entity := range entities {
+		if entity.Kind == EntityDoor && !locked[entity.ID] {
+			return fmt.Errorf("上锁的门 %q 没有钥匙", entity.ID)
+		}
+	}
+	return nil
+}
+
+// GenDeviceEntities 在空旷的道路列（没有障碍物、怪物和高处路线）上随机放置门，
+// 并在门之前的空旷道路上放置打开它的开关；往返滚动时门的两侧各放一个开关
+// items: 地图；mode: 滚动方式；random: 随机数流（来自 RNG 服务）
+func GenDeviceEntities(items []*MapItem, mode ScrollMode, random *rand.Rand) []*LevelEntity {
+	var offsets []int
+	switch mode {
+	case ScrollModeLeft:
+		offsets = []int{gateSwitchColumns}
+	case ScrollModeAlternating:
+		offsets = []int{-gateSwitchColumns, gateSwitchColumns}
+	default:
+		offsets = []int{-gateSwitchColumns}
+	}
+	isClear := func(col int) bool {
+		if col < 0 || col >= len(items) {
+			return false
+		}
+		item := items[col]
+		return item.HasRoad && !item.HasObstacle && !item.HasMonster && !item.HasPlatform
+	}
+
+	var entities []*LevelEntity
+	last := -gateMinSpacing
+	// 地图两端留出起步和结束的距离
+	for col := gateMinSpacing; col < len(items)-gateMinSpacing; col++ {
+		if !isClear(col) || col-last < gateMinSpacing || random.Float64() >= gateChance {
+			continue
+		}
+		placeable := true
+		for _, offset := range offsets {
+			placeable = placeable && isClear(col+offset)
+		}
+		if !placeable {
+			continue
+		}
+
+		gate := &LevelEntity{ID: fmt.Sprintf("gate-%d", col), Kind: EntityGate, Column: col}
+		entities = append(entities, gate)
+		for _, offset := range offsets {
+			entities = append(entities, &LevelEntity{
+				ID:     fmt.Sprintf("switch-%d", col+offset),
+				Kind:   EntitySwitch,
+				Column: col + offset,
+				Links:  []string{gate.ID},
+			})
+		}
+		last = col
+	}
+	return entities
+}
+
+// deviceState 机关会变化的状态（回溯快照按值复制）
+type deviceState struct {
+	open      engine.Timer // 门：打开的剩余时间（游戏时间）
+	pressed   bool         // 开关：玩家是否正踩在上面（只在踩上去的那一帧触发）
+	collected bool         // 钥匙：是否已经拾取
+	used      bool         // 钥匙：是否已经用来打开了门
+	unlocked  bool         // 上锁的门：是否已经打开
+}
+
+// Device 机关的运行时状态（所有机关在开局时创建，区块创建障碍物时挂上对应的障碍物）
+type Device struct {
+	Entity   *LevelEntity
+	links    []*Device // 开关和钥匙：链接的门
+	obstacle *Obstacle // 区块创建的障碍物（还没有创建时为 nil）
+	deviceState
+}
+
+// Open 门是否打开着（限时的门正在计时，或上锁的门已经打开）
+func (d *Device) Open() bool {
+	return d.open.Running() || d.unlocked
+}
+
+// DeviceSystem 机关系统：玩家碰到开关时打开链接的门（已经打开时重新计时），门打开期间不阻挡移动，到时后关上；
+// 玩家拾取钥匙后走到链接的上锁的门前时用掉钥匙，门永久打开
+type DeviceSystem struct {
+	entities []*LevelEntity
+	devices  []*Device
+	byColumn map[int]*Device
+}
+
+// NewDeviceSystem 创建没有机关的机关系统
+func NewDeviceSystem() *DeviceSystem {
+	return &DeviceSystem{byColumn: make(map[int]*Device)}
+}
+
+// SetEntities 设置本局的机关（地图生成或关卡加载之后调用，机关已经通过 ValidateEntities 检查）
+func (s *DeviceSystem) SetEntities(entities []*LevelEntity) {
+	s.entities = entities
+	s.devices = s.devices[:0]
+	clear(s.byColumn)
+	byID := make(map[string]*Device, len(entities))
+	for _, entity := range entities {
+		device := &Device{Entity: entity}
+		s.devices = append(s.devices, device)
+		s.byColumn[entity.Column] = device
+		byID[entity.ID] = device
+	}
+	for _, device := range s.devices {
+		for _, id := range device.Entity.Links {
+			device.links = append(device.links, byID[id])
+		}
+	}
+}
+
+// reset 把所有机关恢复到初始状态（重新创建所有障碍物时调用）
+func (s *DeviceSystem) reset() {
+	for _, device := range s.devices {
+		device.deviceState = deviceState{}
+		device.obstacle = nil
+	}
+}
+
+// Entities 返回本局的机关（编辑器保存关卡时使用）
+func (s *DeviceSystem) Entities() []*LevelEntity {
+	return s.entities
+}
+
+// newObstacle 为 item 所在列上的机关创建障碍物，没有机关（或钥匙已经拾取）时返回 nil
+// centerX: 列中心的 X 坐标；groundY: 道路顶部的 Y 坐标；platformY: 高处路线平台顶部的 Y 坐标（钥匙放在平台上方）
+func (s *DeviceSystem) newObstacle(item *MapItem, centerX, groundY, platformY float64) *Obstacle {
+	device := s.byColumn[item.Index]
+	if device == nil || device.collected {
+		return nil
+	}
+
+	var obstacle *Obstacle
+	switch device.Entity.Kind {
+	case EntityKey:
+		surfaceY := groundY
+		if item.HasPlatform {
+			surfaceY = platformY
+		}
+		obstacle = NewKey(centerX, surfaceY)
+	case EntityDoor:
+		left, top := centerX-gateWidth/2.0, groundY-gateHeight
+		obstacle = NewObstacle(left, top, left, top, gateWidth, gateHeight, lockedDoorImage(), ObstacleTypeObstacle)
+	case EntityGate:
+		if gateImage == nil {
+			gateImage = ebiten.NewImage(int(gateWidth), int(gateHeight))
+			gateImage.Fill(color.RGBA{R: 0x50, G: 0x50, B: 0x60, A: 0xff})
+			// 横向的栅栏条
+			for y := float32(20); y < gateHeight; y += 60 {
+				vector.FillRect(gateImage, 0, y, gateWidth, 8, color.RGBA{R: 0x80, G: 0x80, B: 0x90, A: 0xff}, false)
+			}
+		}
+		left, top := centerX-gateWidth/2.0, groundY-gateHeight
+		obstacle = NewObstacle(left, top, left, top, gateWidth, gateHeight, gateImage, ObstacleTypeObstacle)
+	case EntitySwitch:
+		if switchImage == nil {
+			switchImage = ebiten.NewImage(int(switchWidth), int(switchHeight))
+			switchImage.Fill(color.RGBA{R: 0x90, G: 0x90, B: 0x90, A: 0xff})
+			vector.FillRect(switchImage, switchWidth/4, 0, switchWidth/2, switchHeight/2, color.RGBA{R: 0xd0, G: 0x40, B: 0x40, A: 0xff}, false)
+		}
+		left, top := centerX-switchWidth/2.0, groundY-switchHeight
+		obstacle = NewObstacle(left, top, left, top, switchWidth, switchHeight, switchImage, ObstacleTypeSwitch)
+	}
+	obstacle.Device = device
+	device.obstacle = obstacle
+	device.apply()
+	return obstacle
+}
+
+// apply 按机关的状态更新障碍物：打开的门不阻挡移动并变成半透明，开关在它打开的门关上之前变成绿色
+func (d *Device) apply() {
+	o := d.obstacle
+	if o == nil {
+		return
+	}
+	o.Tint = ebiten.ColorScale{}
+	switch d.Entity.Kind {
+	case EntityGate, EntityDoor:
+		o.Solidity = defaultSolidity(ObstacleTypeObstacle)
+		if d.Open() {
+			o.Solidity = 0
+			o.Tint.ScaleAlpha(gateOpenAlpha)
+		}
+	case EntitySwitch:
+		for _, gate := range d.links {
+			if gate.Open() {
+				o.Tint.Scale(switchActiveTint[0], switchActiveTint[1], switchActiveTint[2], 1)
+				break
+			}
+		}
+	}
+}
+
+// Update 检查玩家是否踩上开关、拾取钥匙或带着钥匙走到上锁的门前，推进门的计时（在模拟步中调用，按游戏时间推进）
+func (s *DeviceSystem) Update(g *Game) {
+	if g.isPaused || g.isRewinding || g.Player == nil {
+		return
+	}
+
+	for _, device := range s.devices {
+		if device.Entity.Kind == EntityGate {
+			device.open.Tick(1)
+		}
+	}
+
+	p := g.Player
+	active := !p.IsDead && !p.IsFlying
+	for _, device := range s.devices {
+		o := device.obstacle
+		switch device.Entity.Kind {
+		case EntitySwitch:
+			touching := o != nil && active && engine.CheckCollision(p, o)
+			if touching && !device.pressed {
+				for _, gate := range device.links {
+					gate.open.Start(device.Entity.openFrames())
+				}
+			}
+			device.pressed = touching
+		case EntityKey:
+			if o != nil && active && !device.collected && canTakeKey(p, o) {
+				device.collected = true
+				g.Obstacles = slices.DeleteFunc(g.Obstacles, func(other *Obstacle) bool { return other == o })
+			}
+		case EntityDoor:
+			if o != nil && active && !device.unlocked && atDoor(p, o) {
+				if key := s.heldKey(device); key != nil {
+					key.used = true
+					device.unlocked = true
+				}
+			}
+		}
+	}
+
+	for _, device := range s.devices {
+		device.apply()
+	}
+}
+
+// Draw 在限时打开的门上方绘制剩余时间（秒）和倒计时进度条
+func (s *DeviceSystem) Draw(screen *ebiten.Image, camera *engine.Camera) {
+	for _, device := range s.devices {
+		o := device.obstacle
+		if o == nil || !device.open.Running() || !o.onScreen(camera) {
+			continue
+		}
+		remaining := 1 - device.open.Progress()
+		x, y := camera.WorldToScreen(o.X+o.Width/2.0, o.Y)
+		barX, barY := float32(x-gateBarWidth/2.0), float32(y-gateBarHeight-4)
+		vector.FillRect(screen, barX, barY, gateBarWidth, gateBarHeight, color.RGBA{A: 0xa0}, false)
+		vector.FillRect(screen, barX, barY, gateBarWidth*float32(remaining), gateBarHeight, gateBarColor, false)
+		seconds := fmt.Sprintf("%.1f", device.open.Remaining()/float64(ebiten.DefaultTPS))
+		DrawTextCentered(screen, seconds, int(x), int(barY)-18)
+	}
+}
+
+// snapshot 返回所有机关的状态（回溯快照使用，复用 into 的切片）
+func (s *DeviceSystem) snapshot(into []deviceState) []deviceState {
+	into = into[:0]
+	for _, device := range s.devices {
+		into = append(into, device.deviceState)
+	}
+	return into
+}
+
+// restore 恢复快照时所有机关的状态
+func (s *DeviceSystem) restore(states []deviceState) {
+	for i, state := range states {
+		s.devices[i].deviceState = state
+	}
+	for _, device := range s.devices {
+		device.apply()
+	}
+}
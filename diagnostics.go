@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 输入时间线显示的帧数
+	diagHistoryFrames = 120
+	// 显示的最近起跳记录数
+	diagJumpHistory = 8
+	// 时间线每帧的宽度和每行的高度（像素）
+	diagCellWidth  = 3
+	diagCellHeight = 8
+)
+
+// DiagnosticsSystem 输入诊断：记录每帧输入和每次起跳使用的跳跃缓冲/土狼时间，用于调整跳跃宽容参数
+// F3 切换显示，-debug 启动时默认显示
+type DiagnosticsSystem struct {
+	visible bool
+
+	history   [diagHistoryFrames]PlayerInput // 最近的输入（环形缓冲）
+	historyAt int                            // 下一次写入的位置
+
+	seenJumps int        // 已记录的起跳次数（与 Player.JumpCount 比较发现新的起跳）
+	jumps     []JumpInfo // 最近的起跳记录（最新的在最后）
+
+	totalJumps    int // 总起跳次数
+	bufferedJumps int // 依靠跳跃缓冲的起跳次数
+	coyoteJumps   int // 依靠土狼时间的起跳次数
+	bufferedSum   int // 跳跃缓冲帧数之和（用于计算平均输入延迟）
+
+	animation string // 玩家当前的动画状态、帧和播放进度
+	monsters  string // 怪物数量、更新范围外（休眠）的数量和时间倍数
+
+	deaths       *Heatmap // 这张地图记录的死亡热力图（每次打开诊断界面时重新读取）
+	deathsLoaded bool     // 本次打开后是否已经读取过
+}
+
+// NewDiagnosticsSystem 创建诊断系统
+func NewDiagnosticsSystem(visible bool) *DiagnosticsSystem {
+	return &DiagnosticsSystem{visible: visible}
+}
+
+// Update 记录本帧输入和新的起跳
+func (s *DiagnosticsSystem) Update(g *Game) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+		s.visible = !s.visible
+		s.deathsLoaded = false
+	}
+	if s.visible && !s.deathsLoaded {
+		s.loadDeaths(g)
+	}
+	if g.isPaused || g.isRewinding || g.Player == nil {
+		return
+	}
+
+	s.history[s.historyAt] = g.Input
+	s.historyAt = (s.historyAt + 1) % diagHistoryFrames
+
+	anim := g.Player.Animation
+	s.animation = fmt.Sprintf("ANIM %s  FRAME %d/%d  %3.0f%%", anim.GetState(),
+		anim.GetCurrentFrameIndex()+1, anim.GetFrameCount(), anim.GetProgress()*100)
+
+	monsters, asleep := 0, 0
+	for _, obstacle := range g.Obstacles {
+		if obstacle.Monster == nil {
+			continue
+		}
+		monsters++
+		if obstacle.Monster.Asleep {
+			asleep++
+		}
+	}
+	s.monsters = fmt.Sprintf("MONSTERS %d  ASLEEP %d  TIME SCALE %.2f", monsters, asleep, g.TimeScale())
+
+	if g.Player.JumpCount == s.seenJumps {
+		return
+	}
+	s.seenJumps = g.Player.JumpCount
+
+	jump := g.Player.LastJump
+	s.totalJumps++
+	s.bufferedSum += jump.BufferedFrames
+	if jump.UsedBuffer {
+		s.bufferedJumps++
+	}
+	if jump.UsedCoyote {
+		s.coyoteJumps++
+	}
+	s.jumps = append(s.jumps, jump)
+	if len(s.jumps) > diagJumpHistory {
+		s.jumps = s.jumps[1:]
+	}
+}
+
+// mebibytes 把字节数换算成 MiB
+func mebibytes(bytes int64) float64 {
+	return float64(bytes) / (1 << 20)
+}
+
+// loadDeaths 读取这张地图的死亡统计（没有记录或读取失败时不显示热力图）
+func (s *DiagnosticsSystem) loadDeaths(g *Game) {
+	s.deathsLoaded = true
+	s.deaths = nil
+	heatmap, err := LoadHeatmap(analyticsPath(g.options), len(g.MapItems), HeatmapDeaths)
+	if err != nil {
+		log.Printf("警告: 读取统计记录失败: %v", err)
+		return
+	}
+	s.deaths = heatmap
+}
+
+// DrawWorld 在游戏世界中绘制死亡热力图（跟随调色和镜像）
+func (s *DiagnosticsSystem) DrawWorld(screen *ebiten.Image, camera *engine.Camera) {
+	if s.visible && s.deaths != nil {
+		s.deaths.Draw(screen, camera)
+	}
+}
+
+// Draw 绘制输入时间线和起跳统计
+func (s *DiagnosticsSystem) Draw(screen *ebiten.Image) {
+	if !s.visible {
+		return
+	}
+
+	const x0, y0 = 10, 70
+	width := float32(diagHistoryFrames * diagCellWidth)
+	vector.FillRect(screen, x0-4, y0-4, width+120, 250, color.RGBA{A: 160}, false)
+
+	// 输入时间线：每行一个按键，从左（最早）到右（最新）
+	rows := []struct {
+		label string
+		held  func(PlayerInput) bool
+	}{
+		{"L", func(in PlayerInput) bool { return in.Left }},
+		{"R", func(in PlayerInput) bool { return in.Right }},
+		{"J", func(in PlayerInput) bool { return in.Jump }},
+	}
+	for row, r := range rows {
+		y := float32(y0 + row*(diagCellHeight+4))
+		ebitenutil.DebugPrintAt(screen, r.label, x0, int(y)-4)
+		for i := 0; i < diagHistoryFrames; i++ {
+			in := s.history[(s.historyAt+i)%diagHistoryFrames]
+			if r.held(in) {
+				x := float32(x0+12) + float32(i*diagCellWidth)
+				vector.FillRect(screen, x, y, diagCellWidth, diagCellHeight, color.RGBA{G: 220, B: 120, A: 255}, false)
+			}
+		}
+	}
+
+	// 统计和最近的起跳记录
+	y := y0 + len(rows)*(diagCellHeight+4) + 8
+	avgLatency := 0.0
+	if s.totalJumps > 0 {
+		avgLatency = float64(s.bufferedSum) / float64(s.totalJumps)
+	}
+	summary := fmt.Sprintf("JUMPS %d  BUFFER HITS %d  COYOTE %d  AVG LATENCY %.1ff",
+		s.totalJumps, s.bufferedJumps, s.coyoteJumps, avgLatency)
+	ebitenutil.DebugPrintAt(screen, summary, x0, y)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("WINDOWS: BUFFER %df  COYOTE %df", jumpBufferFrames, coyoteFrames), x0, y+16)
+
+	for i := len(s.jumps) - 1; i >= 0; i-- {
+		jump := s.jumps[i]
+		line := fmt.Sprintf("#%d buffered %df coyote %df", s.totalJumps-(len(s.jumps)-1-i), jump.BufferedFrames, jump.CoyoteFrames)
+		if jump.UsedBuffer {
+			line += " [BUF]"
+		}
+		if jump.UsedCoyote {
+			line += " [COY]"
+		}
+		y += 16
+		ebitenutil.DebugPrintAt(screen, line, x0, y+16)
+	}
+
+	// 死亡热力图（画在游戏世界中）的摘要
+	if s.deaths != nil && s.deaths.Max > 0 {
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("DEATH HEATMAP: %d RUNS  WORST COL %d", s.deaths.Runs, s.deaths.Peak()), x0, y+32)
+	}
+
+	// 玩家当前的动画、纹理预算和怪物更新范围
+	ebitenutil.DebugPrintAt(screen, s.animation, x0, y+48)
+	textures := engine.Textures.Stats()
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("TEXTURES %.1f/%.0f MiB (PEAK %.1f)  LOADED %d/%d  LOADS %d  UNLOADS %d",
+		mebibytes(textures.Resident), mebibytes(textures.Budget), mebibytes(textures.Peak),
+		textures.Loaded, textures.Animations, textures.Loads, textures.Unloads), x0, y+64)
+	ebitenutil.DebugPrintAt(screen, s.monsters, x0, y+80)
+}
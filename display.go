@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// WindowMode 窗口模式
+type WindowMode int
+
+const (
+	WindowModeWindowed   WindowMode = iota // 窗口模式
+	WindowModeBorderless                   // 无边框窗口，铺满所选显示器
+	WindowModeFullscreen                   // 独占全屏
+)
+
+// windowModeNames 窗口模式名称（命令行参数使用）
+var windowModeNames = map[WindowMode]string{
+	WindowModeWindowed:   "windowed",
+	WindowModeBorderless: "borderless",
+	WindowModeFullscreen: "fullscreen",
+}
+
+// String 返回窗口模式名称
+func (m WindowMode) String() string {
+	return windowModeNames[m]
+}
+
+// ParseWindowMode 根据名称解析窗口模式
+func ParseWindowMode(name string) (WindowMode, error) {
+	for mode, modeName := range windowModeNames {
+		if strings.EqualFold(name, modeName) {
+			return mode, nil
+		}
+	}
+	return WindowModeWindowed, fmt.Errorf("未知的窗口模式: %s", name)
+}
+
+// Resolution 窗口分辨率
+type Resolution struct {
+	Width, Height int
+}
+
+// String 返回 "宽x高" 形式的分辨率
+func (r Resolution) String() string {
+	return fmt.Sprintf("%dx%d", r.Width, r.Height)
+}
+
+// resolutionPresets 窗口模式下可选的分辨率预设（都是 16:9，与逻辑画面比例一致）
+var resolutionPresets = []Resolution{
+	{960, 540},
+	{1280, 720},
+	{1600, 900},
+	{1920, 1080},
+	{2560, 1440},
+}
+
+// ParseResolution 解析 "宽x高" 形式的分辨率
+func ParseResolution(text string) (Resolution, error) {
+	width, height, ok := strings.Cut(strings.ToLower(text), "x")
+	if !ok {
+		return Resolution{}, fmt.Errorf("分辨率格式应为 宽x高: %s", text)
+	}
+	w, err := strconv.Atoi(width)
+	if err != nil || w <= 0 {
+		return Resolution{}, fmt.Errorf("无效的分辨率宽度: %s", text)
+	}
+	h, err := strconv.Atoi(height)
+	if err != nil || h <= 0 {
+		return Resolution{}, fmt.Errorf("无效的分辨率高度: %s", text)
+	}
+	return Resolution{Width: w, Height: h}, nil
+}
+
+// DisplaySettings 显示设置
+// 逻辑画面固定为 windowWidth × windowHeight，窗口或显示器比例不同时由 Ebiten 自动加黑边（letterbox）
+type DisplaySettings struct {
+	Mode       WindowMode // 窗口模式
+	Resolution Resolution // 窗口模式下的窗口尺寸
+	Monitor    int        // 显示器序号（0 为主显示器）
+}
+
+// ApplyDisplaySettings 应用显示设置，可以在游戏运行前或运行中调用
+func ApplyDisplaySettings(d DisplaySettings) {
+	// 选择显示器，序号超出范围时保持当前显示器
+	monitor := ebiten.Monitor()
+	monitors := ebiten.AppendMonitors(nil)
+	if d.Monitor >= 0 && d.Monitor < len(monitors) {
+		monitor = monitors[d.Monitor]
+		ebiten.SetMonitor(monitor)
+	} else {
+		log.Printf("警告: 显示器 %d 不存在（共 %d 个），使用当前显示器", d.Monitor, len(monitors))
+	}
+
+	// 显示器尺寸未知时按窗口尺寸处理
+	monitorWidth, monitorHeight := d.Resolution.Width, d.Resolution.Height
+	if monitor != nil {
+		monitorWidth, monitorHeight = monitor.Size()
+	}
+
+	switch d.Mode {
+	case WindowModeFullscreen:
+		ebiten.SetFullscreen(true)
+	case WindowModeBorderless:
+		// 无边框窗口：去掉标题栏，窗口尺寸与显示器相同并放在显示器左上角
+		ebiten.SetFullscreen(false)
+		ebiten.SetWindowDecorated(false)
+		ebiten.SetWindowSize(monitorWidth, monitorHeight)
+		ebiten.SetWindowPosition(0, 0)
+	default:
+		// 窗口模式：按分辨率设置窗口尺寸，并放在显示器中央
+		ebiten.SetFullscreen(false)
+		ebiten.SetWindowDecorated(true)
+		ebiten.SetWindowSize(d.Resolution.Width, d.Resolution.Height)
+		ebiten.SetWindowPosition((monitorWidth-d.Resolution.Width)/2, (monitorHeight-d.Resolution.Height)/2)
+	}
+}
+
+// NextWindowMode 返回切换后的窗口模式（窗口 → 无边框 → 全屏 → 窗口）
+func NextWindowMode(mode WindowMode) WindowMode {
+	return (mode + 1) % (WindowModeFullscreen + 1)
+}
+
+// NextResolution 返回下一个分辨率预设，当前分辨率不是预设时返回第一个预设
+func NextResolution(r Resolution) Resolution {
+	for i, preset := range resolutionPresets {
+		if preset == r {
+			return resolutionPresets[(i+1)%len(resolutionPresets)]
+		}
+	}
+	return resolutionPresets[0]
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 编辑器平移相机的速度（像素/帧），按住 Shift 时乘以 editorFastPanScale
+	editorPanSpeed     = 12.0
+	editorFastPanScale = 4.0
+	// 没有指定关卡文件时保存的路径
+	editorDefaultLevelPath = "level.json"
+	// 跳跃轨迹每隔几帧画一个点
+	editorArcDotFrames = 2
+	// 保存提示显示的帧数
+	editorMessageFrames = 120
+	// 结束试玩后相机回到原位置所用的帧数
+	editorCameraReturnFrames = 30
+)
+
+var (
+	editorCursorColor   = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x60}
+	editorSelectedColor = color.RGBA{R: 0x40, G: 0xc0, B: 0xff, A: 0xff}
+	editorArcColor      = color.RGBA{R: 0x40, G: 0xc0, B: 0xff, A: 0xc0}
+	editorReachColor    = color.RGBA{R: 0xff, G: 0xe0, B: 0x40, A: 0xff}
+)
+
+// EditorSystem 关卡编辑器（-editor 启动）
+// 平移相机浏览地图，在光标所在列切换道路、障碍物、怪物和平台，Ctrl+S 保存为关卡文件；
+// 点击一列的地面选中它，显示从该地面向两侧起跳的最大跳跃轨迹，检查缺口和平台是否可达；
+// F5 在光标处放下玩家就地试玩，再按 F5 回到编辑状态，相机回到试玩前的位置
+type EditorSystem struct {
+	level *Level   // 正在编辑的关卡（Items 与 Game.MapItems 是同一份数据）
+	path  string   // 保存路径
+	play  []System // 试玩时在编辑器之后依次更新的游戏系统
+
+	playing       bool           // 是否正在试玩
+	savedCamera   engine.Camera  // 开始试玩时的相机
+	savedScroll   CameraScroll   // 开始试玩时的滚动状态
+	savedVertical VerticalScroll // 开始试玩时的纵向滚动状态
+
+	hoverCol  int     // 光标所在的列
+	selected  int     // 选中的列（-1 表示没有选中）
+	selectedY float64 // 选中时光标的世界 Y 坐标（从这里向下找该列的地面）
+
+	message       string // 保存结果提示
+	messageFrames int    // 提示剩余显示的帧数
+
+	heatmap *Heatmap // 统计热力图（H 依次切换所有事件、只看死亡和关闭，为 nil 时不显示）
+
+	tweens engine.Tweener // 编辑界面的补间（结束试玩后相机回到原位置）
+}
+
+// NewEditorSystem 创建编辑器
+// play: 试玩时需要更新的游戏系统（物理、拾取、相机等，不包括输入和区块系统）
+func NewEditorSystem(level *Level, path string, play []System) *EditorSystem {
+	if path == "" {
+		path = editorDefaultLevelPath
+	}
+	return &EditorSystem{level: level, path: path, play: play, selected: -1}
+}
+
+// cursorWorld 返回鼠标光标的世界坐标（镜像模式下画面水平翻转，先翻转回来）
+func (s *EditorSystem) cursorWorld(g *Game) (x, y float64) {
+	cx, cy := ebiten.CursorPosition()
+	screenX := float64(cx)
+	if g.options.Mutators.Has(MutatorMirror) {
+		screenX = windowWidth - screenX
+	}
+	return screenX + g.Camera.X, float64(cy) + g.Camera.Y
+}
+
+// Update 处理相机平移、选中和编辑
+func (s *EditorSystem) Update(g *Game) {
+	if s.messageFrames > 0 {
+		s.messageFrames--
+	}
+
+	// F5 切换就地试玩
+	if inpututil.IsKeyJustPressed(ebiten.KeyF5) {
+		if s.playing {
+			s.stopPlaytest(g)
+		} else {
+			s.startPlaytest(g)
+		}
+	}
+	if s.playing {
+		for _, system := range s.play {
+			system.Update(g)
+		}
+		return
+	}
+
+	// 平移相机（使用 InputSystem 读取的左右输入，镜像模式下已经互换）
+	speed := editorPanSpeed
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		speed *= editorFastPanScale
+	}
+	if g.Input.Left || g.Input.Right {
+		s.tweens.Clear()
+	}
+	s.tweens.Update(1)
+	if g.Input.Left {
+		g.Camera.ScrollX(-speed)
+	}
+	if g.Input.Right {
+		g.Camera.ScrollX(speed)
+	}
+
+	x, y := s.cursorWorld(g)
+	s.hoverCol = min(max(int(x/mapItemWidth), 0), len(g.MapItems)-1)
+
+	// 左键选中光标下方的地面，右键取消选中
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		s.selected, s.selectedY = s.hoverCol, y
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		s.selected = -1
+	}
+
+	// 数字键切换光标所在列的内容
+	edited := false
+	item := g.MapItems[s.hoverCol]
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyDigit1):
+		item.HasRoad = !item.HasRoad
+		if !item.HasRoad {
+			item.HasObstacle, item.HasMonster, item.HasTool = false, false, false
+		}
+		edited = true
+	case inpututil.IsKeyJustPressed(ebiten.KeyDigit2) && item.HasRoad:
+		item.HasObstacle = !item.HasObstacle
+		item.HasMonster = false
+		edited = true
+	case inpututil.IsKeyJustPressed(ebiten.KeyDigit3) && item.HasRoad && !item.HasObstacle:
+		item.HasMonster = !item.HasMonster
+		edited = true
+	case inpututil.IsKeyJustPressed(ebiten.KeyDigit4):
+		item.HasPlatform = !item.HasPlatform
+		item.HasPlatformHazard = false
+		edited = true
+	}
+	if edited {
+		g.initObstacles()
+		g.navMap = BuildNavMap(g.MapItems)
+	}
+
+	// Ctrl+S 保存
+	if ebiten.IsKeyPressed(ebiten.KeyControl) && inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		s.save()
+	}
+
+	// H 切换统计热力图（每次切换时重新读取记录）
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		s.cycleHeatmap(g)
+	}
+}
+
+// cycleHeatmap 依次切换热力图：关闭 → 所有事件 → 只看死亡 → 关闭，打开时读取这张地图的统计记录
+func (s *EditorSystem) cycleHeatmap(g *Game) {
+	mode := HeatmapEvents
+	if s.heatmap != nil {
+		if s.heatmap.Mode == HeatmapDeaths {
+			s.heatmap = nil
+			return
+		}
+		mode = HeatmapDeaths
+	}
+	heatmap, err := LoadHeatmap(analyticsPath(g.options), len(g.MapItems), mode)
+	if err != nil {
+		log.Printf("警告: 读取统计记录失败: %v", err)
+		s.message = "HEATMAP FAILED"
+		s.messageFrames = editorMessageFrames
+		return
+	}
+	s.heatmap = heatmap
+}
+
+// startPlaytest 在光标下方的地面上放下玩家开始试玩（光标下方没有地面时从光标处落下）
+// 记录相机和滚动状态，结束试玩时恢复
+func (s *EditorSystem) startPlaytest(g *Game) {
+	s.savedCamera = *g.Camera
+	s.savedScroll = g.scroll
+	s.savedVertical = g.vertical
+
+	x, y := s.cursorWorld(g)
+	if top, ok := surfaceBelow(x, y, g.Obstacles); ok {
+		y = top
+	}
+	killPlaneDepth := defaultKillPlaneDepth
+	if s.level.KillPlane > 0 {
+		killPlaneDepth = s.level.KillPlane
+	}
+	player, err := NewPlayer(x, y, g.events, g.clock)
+	if err != nil {
+		log.Printf("警告: 无法开始试玩: %v", err)
+		return
+	}
+	g.Player = player
+	g.Player.FacingLeft = g.scroll.Direction < 0
+	g.Player.KillPlaneY = g.groundY + killPlaneDepth
+	g.Player.Physics = g.options.Mutators.PlayerPhysics()
+	if g.options.Skin != "" {
+		if err := g.Player.SetSkin(g.options.Skin); err != nil {
+			log.Printf("警告: 加载皮肤 %s 失败，使用原色: %v", g.options.Skin, err)
+		}
+	}
+	if g.options.Skeleton != "" {
+		g.Player.SetSkeleton(g.options.Skeleton)
+	}
+	s.playing = true
+}
+
+// stopPlaytest 结束试玩：移除玩家，恢复相机和滚动状态，重新创建障碍物（恢复试玩中拾取的金币和移动过的怪物）
+func (s *EditorSystem) stopPlaytest(g *Game) {
+	// 相机平滑回到试玩前的位置（界面补间，平移相机时停止）
+	from := *g.Camera
+	*g.Camera = s.savedCamera
+	g.Camera.X, g.Camera.Y = from.X, from.Y
+	s.tweens.Clear()
+	s.tweens.Start(engine.NewTween(editorCameraReturnFrames, engine.EaseInOutQuad).Vec(&g.Camera.X, &g.Camera.Y, s.savedCamera.X, s.savedCamera.Y))
+
+	g.scroll = s.savedScroll
+	g.vertical = s.savedVertical
+	g.tweens.Clear()
+	g.Player = nil
+	g.initObstacles()
+	s.playing = false
+}
+
+// save 把关卡保存到文件，并在界面上提示结果
+func (s *EditorSystem) save() {
+	if err := SaveLevel(s.path, s.level); err != nil {
+		log.Printf("警告: 保存关卡失败: %v", err)
+		s.message = "SAVE FAILED"
+	} else {
+		s.message = "SAVED " + s.path
+	}
+	s.messageFrames = editorMessageFrames
+}
+
+// DrawWorld 绘制光标所在列和选中地面的跳跃轨迹（绘制到游戏世界，跟随调色和镜像）
+func (s *EditorSystem) DrawWorld(screen *ebiten.Image, g *Game) {
+	if s.playing {
+		return
+	}
+	if s.heatmap != nil {
+		s.heatmap.Draw(screen, g.Camera)
+	}
+	hoverX, _ := g.Camera.WorldToScreen(float64(s.hoverCol)*mapItemWidth, 0)
+	vector.FillRect(screen, float32(hoverX), 0, mapItemWidth, windowHeight, editorCursorColor, false)
+
+	if s.selected < 0 {
+		return
+	}
+	left := float64(s.selected) * mapItemWidth
+	right := left + mapItemWidth
+	top, ok := surfaceBelow(left+mapItemWidth/2, s.selectedY, g.Obstacles)
+	if !ok {
+		return
+	}
+	x, y := g.Camera.WorldToScreen(left, top)
+	vector.StrokeLine(screen, float32(x), float32(y), float32(x+mapItemWidth), float32(y), 3, editorSelectedColor, false)
+
+	// 从地面两侧边缘分别向外起跳（按住跳跃键、保持移动的最大跳跃），轨迹一直画到屏幕底部
+	physics := g.options.Mutators.PlayerPhysics()
+	arc := jumpArcUntil(physics, g.Camera.Y+windowHeight-top)
+	s.drawArc(screen, g, arc, right, top, 1)
+	s.drawArc(screen, g, arc, left, top, -1)
+}
+
+// drawArc 从 (startX, startY) 按方向 direction 绘制跳跃轨迹，并标出回到起跳高度时的最远距离
+func (s *EditorSystem) drawArc(screen *ebiten.Image, g *Game, arc []CoinSpot, startX, startY, direction float64) {
+	for i, spot := range arc {
+		x, y := g.Camera.WorldToScreen(startX+spot.X*direction, startY+spot.Y)
+		if i%editorArcDotFrames == 0 {
+			vector.FillCircle(screen, float32(x), float32(y), 2, editorArcColor, true)
+		}
+		// 轨迹回到起跳高度的位置：同一高度上能跳过的最大距离
+		if i+1 < len(arc) && spot.Y < 0 && arc[i+1].Y >= 0 {
+			vector.StrokeLine(screen, float32(x), float32(y-12), float32(x), float32(y+12), 2, editorReachColor, false)
+		}
+	}
+}
+
+// DrawHUD 绘制编辑器的状态和按键说明（不参与调色和镜像）
+func (s *EditorSystem) DrawHUD(screen *ebiten.Image, g *Game) {
+	if s.playing {
+		ebitenutil.DebugPrintAt(screen, "PLAYTEST  F5 BACK TO EDITOR", 10, 58)
+		return
+	}
+	item := g.MapItems[s.hoverCol]
+	status := fmt.Sprintf("EDITOR  COL %d  ROAD:%v OBSTACLE:%v MONSTER:%v PLATFORM:%v",
+		s.hoverCol, item.HasRoad, item.HasObstacle, item.HasMonster, item.HasPlatform)
+	ebitenutil.DebugPrintAt(screen, status, 10, 26)
+	ebitenutil.DebugPrintAt(screen, "A/D PAN (SHIFT FAST)  1 ROAD  2 OBSTACLE  3 MONSTER  4 PLATFORM", 10, 42)
+	ebitenutil.DebugPrintAt(screen, "LMB SELECT GROUND (JUMP ARC)  RMB CLEAR  F5 PLAYTEST  CTRL+S SAVE  H HEATMAP", 10, 58)
+	if s.heatmap != nil {
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("HEATMAP  RUNS %d  COL %d: %d %s",
+			s.heatmap.Runs, s.hoverCol, s.heatmap.Counts[s.hoverCol], strings.ToUpper(s.heatmap.Mode.String())), 10, 90)
+	}
+	if s.messageFrames > 0 {
+		ebitenutil.DebugPrintAt(screen, s.message, 10, 74)
+	}
+}
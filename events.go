@@ -0,0 +1,57 @@
+package main
+
+// EventType 游戏事件类型
+type EventType int
+
+const (
+	EventPlayerJumped      EventType = iota // 玩家起跳
+	EventPlayerDied                         // 玩家死亡
+	EventToolCollected                      // 拾取道具
+	EventMonsterKilled                      // 击败怪物
+	EventCheckpointReached                  // 到达检查点
+	EventPlayerRewound                      // 玩家死亡后回溯复活
+	EventComboChanged                       // 连击数增加（Value 为当前连击数）
+	EventCoinCollected                      // 拾取金币（Value 为本局金币总数）
+	EventShieldPopped                       // 护盾抵挡致命触碰后破裂（Obstacle 为碰到的怪物或子弹）
+	EventPlayerContinued                    // 最后一次死亡后花费金币接关（Value 为花费的金币数）
+	EventMonsterHit                         // 怪物被攻击命中（Obstacle 为怪物，Attack 为攻击，Value 为伤害，位置为怪物碰撞盒顶部中心）
+	EventGoalReached                        // 玩家碰到终点通关
+)
+
+// Event 游戏事件
+type Event struct {
+	Type     EventType
+	X, Y     float64    // 事件发生的位置（世界坐标）
+	Obstacle *Obstacle  // 相关对象（道具、怪物等），可能为空
+	Value    int        // 事件附带的数值（例如连击数）
+	Attack   *AttackDef // 造成伤害的攻击（怪物受击事件），可能为空
+}
+
+// EventHandler 事件处理函数
+type EventHandler func(e Event)
+
+// EventBus 事件总线（发布/订阅）
+// HUD、音频、成就、统计等子系统通过订阅事件响应游戏中发生的事情，而不是由 Game.Update 逐个调用
+// 事件在 Publish 时同步分发
+type EventBus struct {
+	handlers map[EventType][]EventHandler
+}
+
+// NewEventBus 创建事件总线
+func NewEventBus() *EventBus {
+	return &EventBus{
+		handlers: make(map[EventType][]EventHandler),
+	}
+}
+
+// Subscribe 订阅事件
+func (b *EventBus) Subscribe(eventType EventType, handler EventHandler) {
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Publish 发布事件，按订阅顺序依次调用处理函数
+func (b *EventBus) Publish(e Event) {
+	for _, handler := range b.handlers[e.Type] {
+		handler(e)
+	}
+}
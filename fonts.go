@@ -0,0 +1,238 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	// 界面文字的字号（像素）：拉丁字体每个字符宽 6 像素，与调试字体相同，按字符数排版的菜单和列表位置不变
+	uiFontSize = 10.0
+	// 缓存的字形数量上限（超过时释放最久没有使用的字形）
+	glyphCacheLimit = 512
+	// 调试字体每个字符的宽度和行高（没有可用字体时使用）
+	debugGlyphWidth  = 6
+	debugLineHeight  = 16
+	missingGlyphRune = '?'
+)
+
+// uiFontPaths 界面字体文件，按顺序回退：拉丁字母、简体中文、日文（不存在时跳过）
+// 拉丁字体放在最前面，英文和数字不会使用中文字体中宽度不同的字形；
+// 游戏自带 latin.ttf（Go Mono，等宽，按列对齐的列表仍然对齐）和 jp.ttf（M+ 1p，包括常用汉字），sc.ttf 可以另外放入简体中文字体
+var uiFontPaths = []string{
+	"res/fonts/latin.ttf",
+	"res/fonts/sc.ttf",
+	"res/fonts/jp.ttf",
+}
+
+// GlyphSource 一个字体文件的字形来源（由字体后端创建）
+type GlyphSource interface {
+	// HasGlyph 字体中是否有这个字符的字形
+	HasGlyph(r rune) bool
+	// Glyph 把字形栅格化成图片（空白字符为 nil），返回图片左上角相对基线起点的偏移和前进宽度
+	Glyph(r rune) (image *ebiten.Image, offsetX, offsetY, advance float64)
+	// Metrics 返回基线以上的高度和行高
+	Metrics() (ascent, lineHeight float64)
+}
+
+// cachedGlyph 缓存的字形
+type cachedGlyph struct {
+	image            *ebiten.Image // 空白字符为 nil
+	offsetX, offsetY float64
+	advance          float64
+	used             int // 最近一次使用时 FontStack.tick 的值
+}
+
+// FontStack 多语言字体：按顺序在各个字体中查找字形（使用第一个有这个字形的字体，都没有时显示 ?），
+// 字形第一次绘制时才栅格化并缓存（只为用到的字符生成字形，中日文字体不会整个栅格化），
+// 缓存超过 glyphCacheLimit 个字形时释放最久没有使用的一半
+// 没有可用的字体文件时用调试字体绘制，只能显示 ASCII，其他字符显示为 ?
+type FontStack struct {
+	sources    []GlyphSource
+	ascent     float64 // 第一个字体的基线高度（所有字体对齐到同一条基线）
+	lineHeight float64
+	glyphs     map[rune]*cachedGlyph
+	tick       int
+}
+
+// LoadFontStack 按顺序加载字体文件，无法加载的字体给出警告并跳过
+func LoadFontStack(paths []string, size float64) *FontStack {
+	f := &FontStack{lineHeight: debugLineHeight, glyphs: make(map[rune]*cachedGlyph)}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		var source GlyphSource
+		if err == nil {
+			source, err = newOpenTypeSource(data, size)
+		}
+		if err != nil {
+			log.Printf("警告: 无法加载字体 %s: %v", path, err)
+			continue
+		}
+		if len(f.sources) == 0 {
+			f.ascent, f.lineHeight = source.Metrics()
+		}
+		f.sources = append(f.sources, source)
+	}
+	return f
+}
+
+// LineHeight 返回行高
+func (f *FontStack) LineHeight() int {
+	return int(f.lineHeight + 0.5)
+}
+
+// glyph 返回字符的字形（没有缓存时栅格化），没有任何字体有这个字形时使用 ?
+func (f *FontStack) glyph(r rune) *cachedGlyph {
+	f.tick++
+	if g, ok := f.glyphs[r]; ok {
+		g.used = f.tick
+		return g
+	}
+	for _, source := range f.sources {
+		if !source.HasGlyph(r) {
+			continue
+		}
+		f.evict()
+		g := &cachedGlyph{used: f.tick}
+		g.image, g.offsetX, g.offsetY, g.advance = source.Glyph(r)
+		f.glyphs[r] = g
+		return g
+	}
+	if r == missingGlyphRune {
+		return &cachedGlyph{advance: debugGlyphWidth}
+	}
+	return f.glyph(missingGlyphRune)
+}
+
+// evict 缓存已满时释放最久没有使用的一半字形
+func (f *FontStack) evict() {
+	if len(f.glyphs) < glyphCacheLimit {
+		return
+	}
+	threshold := f.tick - (f.tick-f.oldestUse())/2
+	for r, g := range f.glyphs {
+		if g.used < threshold {
+			if g.image != nil {
+				g.image.Deallocate()
+			}
+			delete(f.glyphs, r)
+		}
+	}
+}
+
+// oldestUse 返回缓存中最久没有使用的字形的使用时间
+func (f *FontStack) oldestUse() int {
+	oldest := f.tick
+	for _, g := range f.glyphs {
+		oldest = min(oldest, g.used)
+	}
+	return oldest
+}
+
+// Draw 在 (x, y) 绘制文字（y 为第一行的顶部，换行符另起一行，与 ebitenutil.DebugPrintAt 相同）
+func (f *FontStack) Draw(screen *ebiten.Image, text string, x, y int) {
+	if len(f.sources) == 0 {
+		ebitenutil.DebugPrintAt(screen, asciiOnly(text), x, y)
+		return
+	}
+	dotX, baseline := float64(x), float64(y)+f.ascent
+	for _, r := range text {
+		if r == '\n' {
+			dotX, baseline = float64(x), baseline+f.lineHeight
+			continue
+		}
+		g := f.glyph(r)
+		if g.image != nil {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(dotX+g.offsetX, baseline+g.offsetY)
+			screen.DrawImage(g.image, op)
+		}
+		dotX += g.advance
+	}
+}
+
+// Measure 返回文字的宽度（像素，有换行符时为最长一行的宽度）
+func (f *FontStack) Measure(text string) int {
+	widest := 0.0
+	for line := range strings.SplitSeq(text, "\n") {
+		if len(f.sources) == 0 {
+			widest = max(widest, float64(len([]rune(line))*debugGlyphWidth))
+			continue
+		}
+		width := 0.0
+		for _, r := range line {
+			width += f.glyph(r).advance
+		}
+		widest = max(widest, width)
+	}
+	return int(widest + 0.5)
+}
+
+// Close 释放缓存的字形
+func (f *FontStack) Close() {
+	for _, g := range f.glyphs {
+		if g.image != nil {
+			g.image.Deallocate()
+		}
+	}
+	clear(f.glyphs)
+}
+
+// asciiOnly 把调试字体无法显示的字符换成 ?
+func asciiOnly(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r > 0x7e {
+			return missingGlyphRune
+		}
+		return r
+	}, text)
+}
+
+// uiFont 界面字体（第一次使用时加载）
+var uiFont *FontStack
+
+// UIFont 返回界面字体，第一次调用时加载 uiFontPaths
+func UIFont() *FontStack {
+	if uiFont == nil {
+		uiFont = LoadFontStack(uiFontPaths, uiFontSize)
+	}
+	return uiFont
+}
+
+// DrawText 用界面字体在 (x, y) 绘制文字（代替 ebitenutil.DebugPrintAt：标题、菜单、提示和 HUD 都使用，可以显示中日文）
+func DrawText(screen *ebiten.Image, text string, x, y int) {
+	UIFont().Draw(screen, text, x, y)
+}
+
+// DrawTextCentered 以 centerX 为中心用界面字体绘制一行文字
+func DrawTextCentered(screen *ebiten.Image, text string, centerX, y int) {
+	DrawText(screen, text, centerX-TextWidth(text)/2, y)
+}
+
+// TextWidth 返回文字用界面字体绘制的宽度（像素）
+func TextWidth(text string) int {
+	return UIFont().Measure(text)
+}
+
+// NewTextImage 把一行文字用界面字体绘制成一张图片（放大绘制的标题标志、评级和伤害数字使用）
+func NewTextImage(text string) *ebiten.Image {
+	image := ebiten.NewImage(max(TextWidth(text), 1), UIFont().LineHeight())
+	DrawText(image, text, 0, 0)
+	return image
+}
+
+// DisposeFonts 释放界面字体缓存的字形（退出时调用；之后再使用时重新加载）
+func DisposeFonts() {
+	if uiFont != nil {
+		uiFont.Close()
+		uiFont = nil
+	}
+}
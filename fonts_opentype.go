@@ -0,0 +1,61 @@
+package main
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// openTypeSource 基于 golang.org/x/image/font/opentype 的字形来源（TrueType 和 OpenType 字体）
+// 只在游戏线程中使用（字体的 Face 不能并发使用）
+type openTypeSource struct {
+	font *opentype.Font
+	face font.Face
+	buf  sfnt.Buffer
+}
+
+// newOpenTypeSource 解析字体文件，按 size（像素）创建字形来源
+func newOpenTypeSource(data []byte, size float64) (GlyphSource, error) {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	return &openTypeSource{font: f, face: face}, nil
+}
+
+// HasGlyph 字体的字符映射表中是否有这个字符（0 号字形是缺字符号）
+func (s *openTypeSource) HasGlyph(r rune) bool {
+	index, err := s.font.GlyphIndex(&s.buf, r)
+	return err == nil && index != 0
+}
+
+// Glyph 以基线起点为原点栅格化字形
+func (s *openTypeSource) Glyph(r rune) (*ebiten.Image, float64, float64, float64) {
+	bounds, mask, maskPoint, advance, ok := s.face.Glyph(fixed.Point26_6{}, r)
+	if !ok || bounds.Empty() {
+		return nil, 0, 0, fixedToFloat(advance)
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.DrawMask(rgba, rgba.Bounds(), image.White, image.Point{}, mask, maskPoint, draw.Over)
+	return ebiten.NewImageFromImage(rgba), float64(bounds.Min.X), float64(bounds.Min.Y), fixedToFloat(advance)
+}
+
+// Metrics 返回基线以上的高度和行高
+func (s *openTypeSource) Metrics() (float64, float64) {
+	metrics := s.face.Metrics()
+	return fixedToFloat(metrics.Ascent), fixedToFloat(metrics.Height)
+}
+
+// fixedToFloat 把 26.6 定点数转换为浮点数
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 帧数据记录的帧数（最近 10 秒）
+	frameDumpFrames = 10 * int(engine.GameFPS)
+	// 帧数据文件保存的目录
+	frameDumpDir = "debug"
+	// 碰撞盒之间的距离不超过多少像素时算作接触（记录贴着站立、贴墙等情况）
+	frameDumpTouchDistance = 1.0
+	// 导出提示显示的帧数
+	frameDumpMessageFrames = 180
+)
+
+// frameContact 某一帧与玩家接触的障碍物
+type frameContact struct {
+	kind                ObstacleType
+	x, y, width, height float64
+	overlap             bool // 碰撞盒是否重叠（不只是贴着）
+}
+
+// frameSample 某一帧的玩家物理状态
+type frameSample struct {
+	frame      int
+	x, y       float64
+	velocityX  float64 // 本帧的水平位移
+	velocityY  float64
+	onGround   bool
+	flying     bool
+	dead       bool
+	input      PlayerInput
+	contacts   []frameContact
+	cameraX    float64
+	cameraY    float64
+	rewinding  bool
+	obstacleAt int // 玩家所在的列
+}
+
+// FrameDumpSystem 帧数据记录：每帧记录玩家的位置、速度、是否在地面和接触的障碍物（环形缓冲，最近 10 秒），
+// 按 F6 把记录导出为 CSV（debug/frames-<时间>.csv），用于排查“穿过了平台”之类的物理问题
+type FrameDumpSystem struct {
+	samples [frameDumpFrames]frameSample
+	at      int // 下一次写入的位置
+	count   int // 有效记录数量
+	frame   int // 本局经过的帧数
+	prevX   float64
+
+	message       string // 导出结果提示
+	messageFrames int    // 提示剩余显示的帧数
+}
+
+// NewFrameDumpSystem 创建帧数据记录
+func NewFrameDumpSystem() *FrameDumpSystem {
+	return &FrameDumpSystem{}
+}
+
+// Update 记录本帧的玩家状态，按 F6 导出
+func (s *FrameDumpSystem) Update(g *Game) {
+	if s.messageFrames > 0 {
+		s.messageFrames--
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF6) {
+		s.export()
+	}
+	if g.isPaused || g.Player == nil {
+		return
+	}
+	p := g.Player
+	if s.frame == 0 {
+		s.prevX = p.X
+	}
+	s.frame++
+
+	sample := &s.samples[s.at]
+	s.at = (s.at + 1) % frameDumpFrames
+	s.count = min(s.count+1, frameDumpFrames)
+	contacts := sample.contacts[:0]
+	*sample = frameSample{
+		frame:      s.frame,
+		x:          p.X,
+		y:          p.Y,
+		velocityX:  p.X - s.prevX,
+		velocityY:  p.VelocityY,
+		onGround:   p.IsOnGround,
+		flying:     p.IsFlying,
+		dead:       p.IsDead,
+		input:      g.Input,
+		cameraX:    g.Camera.X,
+		cameraY:    g.Camera.Y,
+		rewinding:  g.isRewinding,
+		obstacleAt: int(p.X / mapItemWidth),
+	}
+	s.prevX = p.X
+
+	// 与玩家碰撞盒重叠或相距不超过 frameDumpTouchDistance 的障碍物
+	left, right, top, bottom := p.GetCollisionBox()
+	for _, obstacle := range g.Obstacles {
+		oLeft, oRight, oTop, oBottom := obstacle.GetCollisionBox()
+		if oLeft > right+frameDumpTouchDistance || oRight < left-frameDumpTouchDistance ||
+			oTop > bottom+frameDumpTouchDistance || oBottom < top-frameDumpTouchDistance {
+			continue
+		}
+		contacts = append(contacts, frameContact{
+			kind:    obstacle.Type,
+			x:       obstacle.X,
+			y:       obstacle.Y,
+			width:   obstacle.Width,
+			height:  obstacle.Height,
+			overlap: engine.CheckCollision(p, obstacle),
+		})
+	}
+	sample.contacts = contacts
+}
+
+// export 把记录导出为 CSV 文件，结果显示在屏幕上
+func (s *FrameDumpSystem) export() {
+	path := filepath.Join(frameDumpDir, "frames-"+time.Now().Format("20060102-150405")+".csv")
+	if err := s.Dump(path); err != nil {
+		s.message = "FRAME DUMP FAILED: " + err.Error()
+	} else {
+		s.message = fmt.Sprintf("FRAME DUMP: %s (%d FRAMES)", path, s.count)
+	}
+	s.messageFrames = frameDumpMessageFrames
+}
+
+// Dump 按时间顺序把记录写入 CSV 文件
+// contacts 列是分号分隔的接触障碍物：类型@x,y,宽x高，碰撞盒重叠时末尾加 !
+func (s *FrameDumpSystem) Dump(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"frame", "x", "y", "vx", "vy", "on_ground", "flying", "dead", "rewinding",
+		"left", "right", "jump", "column", "camera_x", "camera_y", "contacts"})
+	float := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+	for i := 0; i < s.count; i++ {
+		sample := &s.samples[(s.at-s.count+i+frameDumpFrames)%frameDumpFrames]
+		contacts := make([]string, 0, len(sample.contacts))
+		for _, c := range sample.contacts {
+			contact := fmt.Sprintf("%s@%s,%s,%sx%s", c.kind, float(c.x), float(c.y), float(c.width), float(c.height))
+			if c.overlap {
+				contact += "!"
+			}
+			contacts = append(contacts, contact)
+		}
+		w.Write([]string{
+			strconv.Itoa(sample.frame), float(sample.x), float(sample.y), float(sample.velocityX), float(sample.velocityY),
+			strconv.FormatBool(sample.onGround), strconv.FormatBool(sample.flying), strconv.FormatBool(sample.dead),
+			strconv.FormatBool(sample.rewinding), strconv.FormatBool(sample.input.Left), strconv.FormatBool(sample.input.Right),
+			strconv.FormatBool(sample.input.Jump), strconv.Itoa(sample.obstacleAt), float(sample.cameraX), float(sample.cameraY),
+			strings.Join(contacts, ";"),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// Draw 显示导出结果（编辑器模式下没有帧数据记录，s 为 nil）
+func (s *FrameDumpSystem) Draw(screen *ebiten.Image) {
+	if s != nil && s.messageFrames > 0 {
+		ebitenutil.DebugPrintAt(screen, s.message, 10, windowHeight-42)
+	}
+}
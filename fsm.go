@@ -0,0 +1,92 @@
+package main
+
+// AIState AI 状态
+type AIState int
+
+const (
+	AIStateIdle   AIState = iota // 待机
+	AIStatePatrol                // 巡逻
+	AIStateAlert                 // 警觉（发现目标后的反应时间）
+	AIStateAttack                // 攻击（追击、射击等）
+	AIStateDead                  // 死亡
+)
+
+// FSMState 状态机中的单个状态
+// C 为每帧传入的上下文类型（例如怪物使用 *MonsterContext）
+// 所有回调都可以为 nil
+type FSMState[C any] struct {
+	Enter  func(ctx C)         // 进入状态时调用
+	Update func(ctx C) AIState // 每帧调用，返回下一个状态（返回当前状态表示保持不变）
+	Exit   func(ctx C)         // 离开状态时调用
+}
+
+// StateMachine 有限状态机
+// 怪物和 Boss 的 AI 都由若干 FSMState 组合而成，避免每种怪物各写一套 if/else
+type StateMachine[C any] struct {
+	current     AIState
+	states      map[AIState]*FSMState[C]
+	stateFrames int  // 当前状态已持续的帧数
+	entered     bool // 初始状态是否已调用过 Enter
+}
+
+// NewStateMachine 创建状态机
+// initial: 初始状态（第一次 Update 时调用其 Enter）
+func NewStateMachine[C any](initial AIState) *StateMachine[C] {
+	return &StateMachine[C]{
+		current: initial,
+		states:  make(map[AIState]*FSMState[C]),
+	}
+}
+
+// AddState 注册状态，返回状态机本身以便链式调用
+func (sm *StateMachine[C]) AddState(state AIState, handler *FSMState[C]) *StateMachine[C] {
+	sm.states[state] = handler
+	return sm
+}
+
+// State 获取当前状态
+func (sm *StateMachine[C]) State() AIState {
+	return sm.current
+}
+
+// StateFrames 获取当前状态已持续的帧数
+func (sm *StateMachine[C]) StateFrames() int {
+	return sm.stateFrames
+}
+
+// Update 执行当前状态的 Update，并根据返回值切换状态
+func (sm *StateMachine[C]) Update(ctx C) {
+	if !sm.entered {
+		sm.entered = true
+		if handler := sm.states[sm.current]; handler != nil && handler.Enter != nil {
+			handler.Enter(ctx)
+		}
+	}
+
+	handler := sm.states[sm.current]
+	if handler == nil || handler.Update == nil {
+		sm.stateFrames++
+		return
+	}
+
+	next := handler.Update(ctx)
+	sm.stateFrames++
+	if next != sm.current {
+		sm.ChangeState(next, ctx)
+	}
+}
+
+// ChangeState 立即切换到指定状态（依次调用旧状态的 Exit 和新状态的 Enter）
+func (sm *StateMachine[C]) ChangeState(next AIState, ctx C) {
+	if handler := sm.states[sm.current]; handler != nil && handler.Exit != nil {
+		handler.Exit(ctx)
+	}
+
+	sm.current = next
+	sm.stateFrames = 0
+	sm.entered = true
+
+	if handler := sm.states[next]; handler != nil && handler.Enter != nil {
+		handler.Enter(ctx)
+	}
+}
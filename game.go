@@ -2,10 +2,15 @@ package main
 
 import (
 	"fmt"
+	"image/color"
 	"log"
+	"slices"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
 )
 
 const (
@@ -16,276 +21,742 @@ const (
 	mapItemWidth = 120.0
 	// 相机移动速度（像素/帧）
 	cameraSpeed = 5.0
+	// 默认背景图片路径（关卡可以自带背景）
+	defaultBackgroundPath = "res/image/bg.png"
 )
 
 // Game 实现 ebiten.Game 接口
 type Game struct {
 	MapItems  []*MapItem
-	Obstacles []*Obstacle // 所有障碍物对象（包括 grass 和 obstacle）
-	Player    *Player     // 玩家
-	CameraX   float64     // 相机位置（用于滚屏）
+	Obstacles []*Obstacle // 所有参与碰撞的障碍物对象（包括 grass 和 obstacle）
+	// 装饰物（只绘制，不参与碰撞检测，避免地图越来越丰富时碰撞查询变慢）
+	Decorations []*Obstacle
+	Player      *Player            // 玩家
+	Camera      *engine.Camera     // 滚屏相机
+	scroll      CameraScroll       // 相机自动滚动的方向和转向触发点
+	vertical    VerticalScroll     // 纵向滚动段的相机状态
+	speedScales []float64          // 每列的相机目标速度倍数（由速度区域展开）
+	Input       PlayerInput        // 本帧玩家输入（由 InputSystem 写入）
+	systems     []System           // 按顺序每帧更新的系统
+	chunks      *ChunkStreamer     // 按区块流式创建障碍物
+	rewind      *RewindSystem      // 回溯系统（HUD 需要读取剩余次数）
+	continues   *ContinueSystem    // 接关倒数和结算界面
+	hazards     *HazardScheduler   // 从屏幕上方落下的危险物
+	devices     *DeviceSystem      // 开关和限时打开的门
+	goal        *GoalSystem        // 地图尽头的终点
+	inputHash   *InputHashSystem   // 这一局输入的摘要（结果签名使用）
+	recorder    *ReplayRecorder    // 回放录制（只在从标题开始的游戏中录制）
+	diag        *DiagnosticsSystem // 输入诊断（绘制诊断界面）
+	frameDump   *FrameDumpSystem   // 帧数据记录（F6 导出，绘制导出提示）
+	scrubber    *ScrubberSystem    // 存档点回放（只在 -debug 时创建）
+	pause       *PauseMenu         // 暂停菜单（只在从标题开始的游戏中创建）
+	quit        QuitDialog         // 一局进行中关闭窗口时的退出确认对话框
+	idle        *IdleSystem        // 离开检测（只在由玩家操作的游戏中创建）
+	combo       *ComboSystem       // 连击系统
+	timeScale   *TimeScaleSystem   // 时间倍数（慢动作死亡、子弹时间和调试慢动作）
+	announcer   *Announcer         // 连击播报
+	nowPlaying  NowPlayingToast    // 切换背景音乐曲目时的“正在播放”提示
+	coinCounter CoinCounter        // 界面上滚动显示的金币数
+	captions    *CaptionHUD        // 声音提示的字幕（辅助功能）
+	editor      *EditorSystem      // 关卡编辑器（只在编辑器模式下创建）
+	mods        *ModHooks          // 启用的模组注册的钩子
+	profile     *ProfileSystem     // 存档系统（没有使用存档时为 nil）
 
 	// 图片资源
-	bgImage       *ebiten.Image
-	grassImage    *ebiten.Image
-	obstacleImage *ebiten.Image
-	monsterImage  *ebiten.Image
-	toolImage     *ebiten.Image
+	background      *engine.ScrollingLayer // 背景层（预先拼接的滚动缓冲图）
+	obstacleCatalog *ObstacleCatalog       // 障碍物目录（道路、障碍物、道具、装饰物的图片和碰撞盒）
+
+	// 主题调色（为 nil 时不调色）
+	grading     *engine.ColorGrading
+	sceneBuffer *ebiten.Image // 调色和镜像之前的游戏世界画面（不需要时为 nil，直接绘制到屏幕）
+
+	// 玩家脚下的阴影
+	shadow *Shadow
+	// 玩家的护盾气泡和破裂动画
+	shieldBubble *ShieldBubble
+	// 怪物受击时的伤害数字
+	damageNumbers *DamageNumbers
+
+	// 地图元素的渲染队列（按层和图片合批绘制）
+	renderQueue *engine.RenderQueue
+
+	// 怪物
+	monsterCatalog *MonsterCatalog // 怪物目录
+	navMap         *NavMap         // 地面怪物使用的导航数据
+	groundY        float64         // 道路顶部的 Y 坐标
+
+	// 随机数服务（所有随机数都从这里按名称取流）
+	rng *RNG
 
 	// 音频资源
-	audioManager  *AudioManager // 音频管理器
-	hasStoppedBGM bool          // 是否已停止背景音乐
+	audioManager *engine.AudioManager // 音频管理器
+	sfx          *SFXPool             // 按名称管理的音效池
+
+	// 这一局拥有的图片（Close 时释放），以及 Close 时依次调用的其他释放函数（统计文件、脚本运行时、在线状态的后台协程）
+	resources *engine.ResourceManager
+	releases  []func()
+
+	// 事件总线
+	events *EventBus
+
+	// 游戏时钟（暂停和回溯时不前进，玩家、怪物和动画按游戏时间推进）
+	clock *engine.Clock
+	// 按游戏时间推进的补间（例如进入飞行时玩家移到飞行高度），回溯时全部停止
+	tweens *engine.Tweener
+
+	// 设置与窗口焦点状态
+	options   GameOptions // 启动选项
+	settings  Settings    // 游戏设置
+	isFocused bool        // 窗口是否拥有焦点（未最小化）
+	isPaused  bool        // 游戏是否暂停（暂停时不更新玩家和相机）
+
+	isRewinding bool // 是否正在回溯（回溯时不更新物理、拾取和相机）
+	cleared     bool // 是否已经碰到终点通关（之后游戏时钟停止，只显示结算界面）
 }
 
-func NewGame(count int) *Game {
+// NewGame 根据启动选项创建游戏，必需的资源加载失败时 log.Fatalf 退出（编辑器、回放观看、演示和测试模式使用）
+func NewGame(opts GameOptions) *Game {
+	game, err := LoadGame(opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return game
+}
+
+// LoadGame 根据启动选项创建游戏
+// 指定了关卡文件时从文件加载地图，否则按随机种子生成
+// 必需的资源（关卡文件、背景图片、障碍物和怪物目录）加载失败时释放已经创建的部分并返回错误（加载界面显示错误后回到标题）
+func LoadGame(opts GameOptions) (*Game, error) {
+	// 从加载界面创建时报告加载进度（不留在游戏的启动选项中，重新开始时由新的加载界面设置）
+	progress := opts.loading
+	opts.loading = nil
+
+	// 使用存档时，存档记住的设置作为启动选项的默认值（编辑器模式下不使用存档）
+	// 开启同步时先同步存档（远程的更新时替换本地存档）
+	var profile *Profile
+	var sync SaveSyncBackend
+	progress.Step("SAVE DATA")
+	if opts.Profile != "" && !opts.Editor {
+		var err error
+		if opts.SyncURL != "" {
+			if sync, err = NewSaveSyncBackend(opts.SyncURL); err == nil {
+				err = SyncProfile(sync, opts.Profile)
+			}
+			if err != nil {
+				log.Printf("警告: 同步存档失败，使用本地存档: %v", err)
+			}
+		}
+		if profile, err = OpenProfile(opts.Profile); err != nil {
+			log.Printf("警告: 无法读取存档，本局不记录: %v", err)
+		}
+		opts = profile.ApplyTo(opts)
+	}
+
 	game := &Game{
-		MapItems:  GenMap(count),
 		Obstacles: make([]*Obstacle, 0),
-		CameraX:   0,
+		Camera:    engine.NewCamera(windowWidth, windowHeight),
+		isFocused: true,
+		options:   opts,
+		rng:       NewRNG(opts.Seed),
+		events:    NewEventBus(),
+		clock:     engine.NewClock(),
+		tweens:    engine.NewTweener(),
+		resources: engine.NewResourceManager(),
 	}
 
-	// 初始化音频管理器（会自动加载并播放背景音乐）
-	game.audioManager = NewAudioManager()
+	// 安装启用的模组（地图钩子在生成地图后调用，必须先安装）
+	game.mods = InstallMods(opts.Mods)
+
+	// 默认设置，显示设置由启动选项决定
+	game.settings = DefaultSettings()
+	game.settings.Display = opts.Display
+	game.settings.Quality = opts.Quality
+	game.settings.Accessibility.LandingPredictor = opts.LandingAssist
+	game.settings.Accessibility.Captions = opts.Captions
+	game.settings.Announcer = opts.Announcer
+	game.settings.FocusLossAudio = opts.FocusAudio
+	game.settings.PauseOnFocusLoss = opts.AutoPause
+	game.settings.Analytics = opts.Analytics
+	game.settings.RichPresence = opts.Presence
+	game.settings.MusicVolume = opts.MusicVolume
+	game.settings.SFXVolume = opts.SFXVolume
+	game.settings.ShowHitboxes = opts.Hitboxes || opts.Debug
+	game.settings.SampleRate = opts.SampleRate
+
+	progress.Step("AUDIO")
+	// 初始化音频管理器（会自动加载并播放背景音乐），加载音效注册表并订阅需要播放音效的事件
+	game.audioManager = NewAudioManager(game.settings.SampleRate)
+	game.audioManager.SetMuted(opts.Mute)
+	game.audioManager.SetMusicVolume(game.settings.MusicVolume)
+	game.audioManager.SetSFXVolume(game.settings.SFXVolume)
+	game.captions = NewCaptionHUD(game.settings.Accessibility.Captions)
+	game.sfx = NewSFXPool(game.audioManager)
+	game.sfx.Captions = game.captions
+	SubscribeAudioEvents(game.audioManager, game.sfx, game.events)
+
+	// 注册系统（顺序即每帧的更新顺序）
+	// 回溯系统放在相机之后，记录的是一帧更新完成后的状态
+	game.rewind = NewRewindSystem(game.settings.RewindCharges, game.events)
+	game.continues = NewContinueSystem(game.events)
+	game.diag = NewDiagnosticsSystem(opts.Debug)
+	game.frameDump = NewFrameDumpSystem()
+	game.combo = NewComboSystem(game.events)
+	game.timeScale = NewTimeScaleSystem(opts.TimeScale, game.events)
+	game.announcer = NewAnnouncer(game.events, game.sfx, game.settings.Announcer)
+	game.hazards = &HazardScheduler{}
+	game.devices = NewDeviceSystem()
+	game.goal = NewGoalSystem()
+	game.inputHash = NewInputHashSystem()
+	input := &InputSystem{}
+	if opts.autoplay && opts.replay == nil {
+		input.bot = NewBot()
+	}
+	if opts.golden {
+		input.script = NewInputScript(goldenScript)
+	}
+	input.replay = opts.replay
+	game.systems = []System{
+		input,
+		game.timeScale,
+		&ClockSystem{},
+		SteppedSystems{
+			game.inputHash,
+			&PhysicsSystem{},
+			game.goal,
+			&PickupSystem{},
+			game.devices,
+			game.hazards,
+			&CameraSystem{},
+			&ChunkSystem{},
+			game.combo,
+		},
+		&AnimationSystem{},
+		game.announcer,
+		game.rewind,
+		game.continues,
+		game.diag,
+		game.frameDump,
+		&AudioSystem{wasFocused: true},
+	}
+	// 从标题开始的游戏可以打开暂停菜单（输入系统之后、时钟之前，本帧暂停）
+	if opts.scenes != nil {
+		game.pause = &PauseMenu{}
+		game.systems = slices.Insert(game.systems, 1, System(game.pause))
+	}
+	// 由玩家操作时长时间没有输入自动暂停（暂停菜单之后、时钟之前）
+	if !opts.autoplay && !opts.golden && opts.replay == nil && game.settings.IdlePauseMinutes > 0 {
+		game.idle = NewIdleSystem(game.settings.IdlePauseMinutes)
+		game.systems = slices.Insert(game.systems, slices.Index(game.systems, System(game.timeScale)), System(game.idle))
+	}
+	// 从标题开始的游戏录制回放（暂停菜单和离开检测之后、时间倍数之前，本帧的暂停状态已经确定）
+	if opts.scenes != nil && !opts.Debug {
+		game.recorder = NewReplayRecorder(opts)
+		game.systems = slices.Insert(game.systems, slices.Index(game.systems, System(game.timeScale)), System(game.recorder))
+	}
+	// 调试模式下每秒记录存档点，可以用 [ ] 回放
+	if opts.Debug {
+		game.scrubber = NewScrubberSystem()
+		game.systems = append(game.systems, game.scrubber)
+	}
 
-	// 加载图片资源
+	// 加载关卡文件，未指定时随机生成地图
+	progress.Step("MAP")
+	// 关卡文件自带滚动方式，随机生成的地图使用启动选项中的滚动方式
 	var err error
-	game.bgImage, _, err = ebitenutil.NewImageFromFile("res/image/bg.png")
-	if err != nil {
-		log.Fatalf("加载背景图片失败: %v", err)
+	var level *Level
+	var verticalSegments []*VerticalSegment
+	var speedZones []SpeedZone
+	var entities []*LevelEntity
+	killPlaneDepth := defaultKillPlaneDepth
+	backgroundPath := defaultBackgroundPath
+	scrollMode := opts.Scroll
+	if opts.LevelPath != "" {
+		level, err = LoadLevel(opts.LevelPath)
+		if err != nil {
+			game.Close()
+			return nil, fmt.Errorf("加载关卡失败: %w", err)
+		}
+		game.MapItems = level.Items
+		scrollMode = level.Scroll
+		verticalSegments = level.Vertical
+		speedZones = level.SpeedZones
+		entities = level.Entities
+		if level.KillPlane > 0 {
+			killPlaneDepth = level.KillPlane
+		}
+		// 关卡自带的背景和背景音乐（可选，加载失败时使用游戏自带的资源）
+		if level.Background != "" {
+			backgroundPath = level.AssetPath(level.Background)
+		}
+		if level.Music != "" {
+			if err := game.audioManager.PlayBGM(level.AssetPath(level.Music), bgmVolume); err != nil {
+				log.Printf("警告: 无法加载关卡的背景音乐: %v", err)
+			}
+		}
+	} else {
+		game.MapItems = GenMap(opts.MapLength, game.rng.Stream(rngStreamMap))
+		GenHighRoutes(game.MapItems, game.rng.Stream(rngStreamRoutes))
+		PrepareScrollMap(game.MapItems, scrollMode, game.rng.Stream(rngStreamScroll))
+		if opts.Vertical && scrollMode == ScrollModeRight {
+			verticalSegments = GenVerticalSegments(game.MapItems, game.rng.Stream(rngStreamVertical))
+		}
+		speedZones = GenSpeedZones(game.MapItems)
+	}
+	if scrollMode != ScrollModeRight {
+		// 纵向滚动段要求相机从左向右经过攀爬区域
+		verticalSegments = nil
+	}
+	game.vertical = NewVerticalScroll(verticalSegments)
+	game.speedScales = BuildSpeedScales(len(game.MapItems), speedZones)
+	opts.Mutators.ApplyToMap(game.MapItems)
+	game.mods.MapGenerated(game.MapItems)
+	// 落物点、开关和门、钥匙只放在随机生成的地图上（关卡文件保持作者的设计）
+	if level == nil {
+		game.hazards.SetTriggers(GenHazardTriggers(game.MapItems, game.rng.Stream(rngStreamHazards)))
+		entities = GenDeviceEntities(game.MapItems, scrollMode, game.rng.Stream(rngStreamGates))
+		entities = append(entities, GenKeyEntities(game.MapItems, scrollMode, entities, game.rng.Stream(rngStreamKeys))...)
 	}
+	game.devices.SetEntities(entities)
+	game.goal.SetMap(game.MapItems, scrollMode)
 
-	game.grassImage, _, err = ebitenutil.NewImageFromFile("res/image/grass.png")
+	// 加载图片资源
+	progress.Step("IMAGES")
+	bgImage, _, err := ebitenutil.NewImageFromFile(backgroundPath)
+	if err != nil && backgroundPath != defaultBackgroundPath {
+		log.Printf("警告: 无法加载关卡的背景图片，使用默认背景: %v", err)
+		bgImage, _, err = ebitenutil.NewImageFromFile(defaultBackgroundPath)
+	}
 	if err != nil {
-		log.Fatalf("加载道路图片失败: %v", err)
+		game.Close()
+		return nil, fmt.Errorf("加载背景图片失败: %w", err)
+	}
+	game.background = engine.NewScrollingLayer(game.resources.Track(bgImage), windowWidth)
+	game.shadow = NewShadow()
+	game.resources.Track(game.shadow.texture)
+	game.shieldBubble = NewShieldBubble(game.events)
+	game.resources.Track(game.shieldBubble.texture)
+	game.damageNumbers = NewDamageNumbers(game.events)
+	game.renderQueue = engine.NewRenderQueue()
+	theme := loadTheme(opts.Theme)
+	game.initColorGrading(theme)
+	// 关卡自带背景音乐时不使用主题的播放列表
+	if level == nil || level.Music == "" {
+		game.initThemeMusic(theme)
 	}
 
-	game.obstacleImage, _, err = ebitenutil.NewImageFromFile("res/image/obstacle.png")
-	if err != nil {
-		log.Fatalf("加载障碍图片失败: %v", err)
+	// 主题调色和镜像模式需要先把游戏世界绘制到缓冲图
+	if game.grading != nil || opts.Mutators.Has(MutatorMirror) {
+		game.sceneBuffer = game.resources.NewImage(windowWidth, windowHeight)
 	}
 
-	game.monsterImage, _, err = ebitenutil.NewImageFromFile("res/image/most_pix.png")
+	progress.Step("CATALOGS")
+	game.obstacleCatalog, err = LoadObstacleCatalog(obstacleCatalogPath)
 	if err != nil {
-		log.Fatalf("加载怪物图片失败: %v", err)
+		game.Close()
+		return nil, fmt.Errorf("加载障碍物目录失败: %w", err)
 	}
 
-	game.toolImage, _, err = ebitenutil.NewImageFromFile("res/image/tool.png")
+	game.monsterCatalog, err = LoadMonsterCatalog(monsterCatalogPath)
 	if err != nil {
-		log.Fatalf("加载道具图片失败: %v", err)
+		game.Close()
+		return nil, fmt.Errorf("加载怪物目录失败: %w", err)
 	}
 
-	// 根据 MapItems 创建 Obstacle 对象
+	// 关卡引用的脚本（怪物行为替换为脚本，必须在创建障碍物之前）
+	if level != nil {
+		game.initScripts(level)
+	}
+
+	// 相机按滚动方式放到起始位置（区块按相机位置创建，必须在 initObstacles 之前）
+	game.Camera.SetWorldWidth(float64(len(game.MapItems)) * mapItemWidth)
+	game.scroll = NewCameraScroll(scrollMode, game.MapItems, game.Camera)
+
+	// 根据 MapItems 创建 Obstacle 对象和导航数据
+	progress.Step("OBSTACLES")
 	game.initObstacles()
+	game.navMap = BuildNavMap(game.MapItems)
 
-	// 初始化玩家，位置在屏幕中心
+	// 初始化玩家，位置在屏幕中心，面向滚动方向
+	progress.Step("PLAYER")
 	// 玩家原点在底部中心，所以 X 在屏幕中心，Y 在窗口底部
-	playerX := float64(windowWidth) / 2.0
+	playerX := game.Camera.X + float64(windowWidth)/2.0
 	playerY := float64(windowHeight) / 2.0
-	game.Player = NewPlayer(playerX, playerY, game.audioManager)
+	game.Player, err = NewPlayer(playerX, playerY, game.events, game.clock)
+	if err != nil {
+		game.Close()
+		return nil, fmt.Errorf("创建玩家失败: %w", err)
+	}
+	game.Player.FacingLeft = game.scroll.Direction < 0
+	game.Player.KillPlaneY = game.groundY + killPlaneDepth
+	game.Player.Physics = opts.Mutators.PlayerPhysics()
+	if opts.Skin != "" {
+		if err := game.Player.SetSkin(opts.Skin); err != nil {
+			game.Close()
+			return nil, fmt.Errorf("加载皮肤 %s 失败: %w", opts.Skin, err)
+		}
+	}
+	if opts.Skeleton != "" {
+		game.Player.SetSkeleton(opts.Skeleton)
+	}
 
-	return game
-}
+	// 开启统计时记录本局（编辑器模式下不记录）
+	if game.settings.Analytics && !opts.Editor {
+		if analytics, err := NewAnalyticsSystem(analyticsPath(opts)); err != nil {
+			log.Printf("警告: 无法打开统计记录文件，本局不记录: %v", err)
+		} else {
+			game.systems = append(game.systems, analytics)
+			game.releases = append(game.releases, analytics.Close)
+		}
+	}
 
-// initObstacles 根据 MapItems 初始化所有障碍物对象
-func (g *Game) initObstacles() {
-	// 预先计算所有图片尺寸，避免在循环中重复计算
-	grassBounds := g.grassImage.Bounds()
-	grassWidth := float64(grassBounds.Dx())
-	grassHeight := float64(grassBounds.Dy())
-
-	obstacleBounds := g.obstacleImage.Bounds()
-	obstacleWidth := float64(obstacleBounds.Dx())
-	obstacleHeight := float64(obstacleBounds.Dy())
-
-	monsterBounds := g.monsterImage.Bounds()
-	monsterHeight := float64(monsterBounds.Dy())
-
-	toolBounds := g.toolImage.Bounds()
-	toolWidth := float64(toolBounds.Dx())
-	toolHeight := 120.0 // 道具高度固定为 120
-
-	// 道路块在地图最下面的位置
-	grassY := float64(windowHeight) - grassHeight
-
-	// 预先分配容量，减少内存重新分配
-	estimatedCount := len(g.MapItems) * 2 // 估算：每个 MapItem 平均 2 个障碍物（道路 + 其他）
-	g.Obstacles = make([]*Obstacle, 0, estimatedCount)
-
-	// 遍历所有 MapItem，创建对应的 Obstacle 对象
-	for _, item := range g.MapItems {
-		// 计算道路块的绝对坐标
-		grassX := float64(item.Index) * grassWidth
-
-		// 如果有道路，创建 grass Obstacle
-		if item.HasRoad {
-			grass := NewObstacle(grassX, grassY, grassX, grassY, grassWidth, grassHeight, g.grassImage, ObstacleTypeGrass)
-			g.Obstacles = append(g.Obstacles, grass)
-
-			// 如果有障碍，创建 obstacle Obstacle
-			if item.HasObstacle {
-				obstacleY := grassY - obstacleHeight
-				obstacle := NewObstacle(grassX, obstacleY, grassX, obstacleY, obstacleWidth, obstacleHeight, g.obstacleImage, ObstacleTypeObstacle)
-				g.Obstacles = append(g.Obstacles, obstacle)
-			}
+	// 开启在线状态时报告本局（编辑器模式下不报告）
+	if game.settings.RichPresence && !opts.Editor {
+		if presence, err := NewPresenceSystem(opts); err != nil {
+			log.Printf("警告: 无法显示在线状态: %v", err)
+		} else {
+			game.systems = append(game.systems, presence)
+			game.releases = append(game.releases, presence.Close)
+		}
+	}
 
-			// 如果有怪物，创建 monster Obstacle
-			if item.HasMonster {
-				// 怪物放在道路块上面，怪物的碰撞盒与绘制相比略小
-				monsterDrawY := grassY - monsterHeight
-				monsterCollisionX := grassX + 25
-				monsterCollisionY := monsterDrawY + 12
-				monster := NewObstacle(grassX, monsterDrawY, monsterCollisionX, monsterCollisionY, 70, 145, g.monsterImage, ObstacleTypeMonster)
-				g.Obstacles = append(g.Obstacles, monster)
-			}
+	// 使用存档时累计本局的统计，关闭窗口时先保存存档再退出（一局进行中时先确认，见 RequestQuit）
+	if profile != nil {
+		game.profile = NewProfileSystem(profile, opts, game.events)
+		game.profile.sync = sync
+		game.systems = append(game.systems, game.profile)
+		ebiten.SetWindowClosingHandled(true)
+	}
 
-			// 如果有道具，创建 tool Obstacle
-			if item.HasTool {
-				toolY := 120.0 // 道具 Y 坐标固定为 120
-				tool := NewObstacle(grassX, toolY, grassX, toolY, toolWidth, toolHeight, g.toolImage, ObstacleTypeTool)
-				g.Obstacles = append(g.Obstacles, tool)
-			}
+	// 编辑器模式：不创建玩家，只平移相机浏览和编辑地图（一开始创建所有区块，不释放）
+	// 就地试玩时由编辑器创建玩家并更新物理、拾取、相机和连击系统
+	if opts.Editor {
+		level := &Level{Scroll: scrollMode, Items: game.MapItems, Vertical: verticalSegments, SpeedZones: speedZones, Entities: entities}
+		if killPlaneDepth != defaultKillPlaneDepth {
+			level.KillPlane = killPlaneDepth
+		}
+		game.Player = nil
+		game.editor = NewEditorSystem(level, opts.LevelPath, []System{
+			game.timeScale,
+			&ClockSystem{},
+			SteppedSystems{
+				&PhysicsSystem{},
+				&PickupSystem{},
+				game.devices,
+				&CameraSystem{},
+				game.combo,
+			},
+			&AnimationSystem{},
+			game.announcer,
+		})
+		game.systems = []System{
+			&InputSystem{},
+			game.editor,
+			&ChunkSystem{},
+			&AudioSystem{wasFocused: true},
 		}
 	}
+
+	return game, nil
+}
+
+// isClearRoad 判断给定列是否是没有障碍物和怪物的道路
+func (g *Game) isClearRoad(col int) bool {
+	if col < 0 || col >= len(g.MapItems) {
+		return false
+	}
+	item := g.MapItems[col]
+	return item.HasRoad && !item.HasObstacle && !item.HasMonster
 }
 
-// Update 每帧更新游戏逻辑
+// Update 每帧更新游戏逻辑，按注册顺序依次更新各系统
 func (g *Game) Update() error {
-	// 更新玩家状态（传入障碍物列表和地图宽度用于碰撞检测和边界限制，以及相机位置用于死亡检测）
+	// 不是从标题开始、使用存档时关闭窗口由游戏处理（从标题开始时由场景管理器调用 RequestQuit）
+	if g.options.scenes == nil && ebiten.IsWindowClosingHandled() && ebiten.IsWindowBeingClosed() && g.RequestQuit() {
+		return ebiten.Termination
+	}
+	// 退出确认对话框打开时游戏冻结，只处理对话框
+	if g.quit.Open() {
+		return g.quit.Update(g)
+	}
+	for _, system := range g.systems {
+		system.Update(g)
+	}
 	if g.Player != nil {
-		mapWidth := float64(len(g.MapItems)) * mapItemWidth
-		g.Player.Update(g.Obstacles, mapWidth, g.CameraX)
-
-		// 检查玩家是否死亡
-		if g.Player.IsDead {
-			// 玩家死亡后，停止背景音乐（只停止一次）
-			if !g.hasStoppedBGM {
-				g.audioManager.PauseBGM()
-				g.hasStoppedBGM = true
-			}
-			// 玩家死亡后，相机不再移动
-			return nil
-		}
+		g.coinCounter.Update(g.Player.Coins)
+	}
+	// 卸载长时间没有使用的延迟加载动画（超过纹理预算时）
+	engine.Textures.Update()
+	return nil
+}
 
-		// 检查玩家与道具的碰撞，移除被触碰的道具
-		g.removeTouchedTools()
+// RequestQuit 处理关闭窗口的请求，返回是否马上退出：一局进行中时打开退出确认对话框（选择退出后 Update 返回 ebiten.Termination）；
+// 对话框已经打开（再次关闭窗口）或者没有进行中的一局（编辑器、结算界面之后、演示和测试）时保存（和上传）存档后马上退出
+func (g *Game) RequestQuit() bool {
+	running := g.Player != nil && g.editor == nil && g.continues.Results() == nil && !g.options.autoplay && !g.options.golden
+	if running && !g.quit.Open() {
+		g.quit.show(g)
+		return false
 	}
+	g.profile.Close()
+	return true
+}
 
-	// 更新相机位置，自动向右移动（只有在玩家未死亡时才移动）
-	g.updateCamera()
+// SetMusicVolume 设置背景音乐音量（0 到 1），立即生效
+func (g *Game) SetMusicVolume(volume float64) {
+	g.settings.MusicVolume = volume
+	g.audioManager.SetMusicVolume(volume)
+}
 
-	return nil
+// SetSFXVolume 设置音效音量（0 到 1），立即生效
+func (g *Game) SetSFXVolume(volume float64) {
+	g.settings.SFXVolume = volume
+	g.audioManager.SetSFXVolume(volume)
 }
 
-// removeTouchedTools 移除玩家触碰到的道具，并触发飞行状态
-func (g *Game) removeTouchedTools() {
-	if g.Player == nil {
-		return
+// SetFullscreen 切换全屏（关闭时回到窗口模式）并应用显示设置
+func (g *Game) SetFullscreen(fullscreen bool) {
+	g.settings.Display.Mode = WindowModeWindowed
+	if fullscreen {
+		g.settings.Display.Mode = WindowModeFullscreen
 	}
+	ApplyDisplaySettings(g.settings.Display)
+}
 
-	// 从后往前遍历，避免删除时索引错乱
-	for i := len(g.Obstacles) - 1; i >= 0; i-- {
-		obstacle := g.Obstacles[i]
-		// 如果是道具且与玩家发生碰撞
-		if obstacle.Type == ObstacleTypeTool && CheckCollision(g.Player, obstacle) {
-			// 触发飞行状态
-			if !g.Player.IsFlying {
-				g.Player.IsFlying = true
-				g.Player.Y = 240
-				g.Player.X = g.CameraX + float64(windowWidth)/2.0
-				g.Player.Animation.SetState(StateFly)
-			}
-			g.Player.flyFrameCount = 0
-			// 从切片中移除该元素
-			g.Obstacles = append(g.Obstacles[:i], g.Obstacles[i+1:]...)
-		}
+// SetShowHitboxes 设置是否绘制碰撞盒
+func (g *Game) SetShowHitboxes(show bool) {
+	g.settings.ShowHitboxes = show
+}
+
+// Close 释放游戏拥有的资源：音频播放器、登记的图片和背景的拼接缓冲图、统计文件、脚本运行时和在线状态的后台协程
+// 同一进程中先后创建多个游戏时使用（例如重新开始、回到标题、结束吸引模式的演示），退出时也调用；可以重复调用
+// 全局缓存的动画和图片目录不释放（下一局继续使用，退出时由 DisposeArtAnimations 释放）
+func (g *Game) Close() {
+	g.audioManager.Close()
+	if g.background != nil {
+		g.background.Dispose()
 	}
+	g.resources.Dispose()
+	for _, release := range g.releases {
+		release()
+	}
+	g.releases = nil
 }
 
-// updateCamera 更新相机位置，自动向右移动
-// 相机每帧向右移动，速度根据玩家飞行状态调整
-// 范围：0 ～ 生成地图块数量 * 120 - 屏幕宽度
-// 如果玩家死亡，相机停止移动
-func (g *Game) updateCamera() {
-	// 如果玩家死亡，相机停止移动
-	if g.Player != nil && g.Player.IsDead {
+// initColorGrading 按主题创建调色效果
+// 主题是可选的画面效果：低配设置、没有主题或查找表加载失败时不调色
+func (g *Game) initColorGrading(theme *ThemeDef) {
+	if g.settings.Quality == GraphicsQualityLow || theme == nil {
 		return
 	}
-
-	// 计算相机的最大移动范围
-	// 地图总宽度 = 地图块数量 * 120
-	// 最大相机位置 = 地图总宽度 - 屏幕宽度
-	maxCameraX := float64(len(g.MapItems))*mapItemWidth - float64(windowWidth)
-	if maxCameraX < 0 {
-		maxCameraX = 0
+	var err error
+	g.grading, err = theme.NewColorGrading()
+	if err != nil {
+		log.Printf("警告: %v", err)
 	}
+}
 
-	// 根据玩家飞行状态调整相机移动速度
-	var currentSpeed float64
-	if g.Player != nil && g.Player.IsFlying {
-		currentSpeed = 15.0 // 飞行状态下每帧 15 像素（与玩家飞行速度同步）
-	} else {
-		currentSpeed = cameraSpeed // 正常状态下每帧 5 像素
+// initThemeMusic 播放主题的背景音乐播放列表（主题没有播放列表时保持默认背景音乐）
+func (g *Game) initThemeMusic(theme *ThemeDef) {
+	if theme == nil || len(theme.Music) == 0 {
+		return
 	}
-
-	// 如果相机还未到达边界，继续向右移动
-	if g.CameraX < maxCameraX {
-		g.CameraX += currentSpeed
-		// 确保不超过边界
-		if g.CameraX > maxCameraX {
-			g.CameraX = maxCameraX
-		}
+	if err := g.audioManager.PlayPlaylist(theme.Music, theme.order, bgmVolume); err != nil {
+		log.Printf("警告: 无法播放主题 %s 的背景音乐: %v", theme.Name, err)
 	}
-	// 如果已经到达边界，相机停止移动（保持在 maxCameraX）
 }
 
 // Draw 每帧绘制游戏画面
 func (g *Game) Draw(screen *ebiten.Image) {
+	// 有主题调色或镜像模式时先把游戏世界绘制到缓冲图，调色、翻转后再绘制到屏幕（HUD 不参与调色和翻转）
+	world := screen
+	if g.sceneBuffer != nil {
+		g.sceneBuffer.Clear()
+		world = g.sceneBuffer
+	}
+
 	// 绘制背景（无限滚动）
-	g.drawBackground(screen)
+	g.drawBackground(world)
 
 	// 绘制道路和障碍
-	g.drawMap(screen)
+	g.drawMap(world)
+
+	// 绘制玩家阴影（在道路和障碍之上、玩家之下）
+	g.drawShadow(world)
+	// 落物的预警阴影
+	g.hazards.Draw(world, g.Camera, g.shadow, g.groundY)
+
+	// 落点预测标记（辅助功能）
+	if g.settings.Accessibility.LandingPredictor && g.Player != nil {
+		drawLandingMarker(world, g.Camera, g.Player, g.Input, g.Obstacles)
+	}
 
 	// 绘制玩家碰撞盒（半透明绿色）
-	g.drawPlayer(screen)
+	g.drawPlayer(world)
+	if g.Player != nil {
+		g.shieldBubble.Draw(world, g.Camera, g.Player)
+	}
+	g.damageNumbers.Draw(world, g.Camera)
+	// 打开的门的倒计时
+	g.devices.Draw(world, g.Camera)
+	// 终点旗（编辑器中同样显示）
+	g.goal.Draw(world, g.Camera, g.groundY)
+
+	// 诊断界面的死亡热力图
+	g.diag.DrawWorld(world, g.Camera)
+
+	// 编辑器的光标和跳跃轨迹
+	if g.editor != nil {
+		g.editor.DrawWorld(world, g)
+	}
+
+	if g.sceneBuffer != nil {
+		g.drawWorldBuffer(screen)
+	}
+
+	// 模组的绘制钩子
+	g.mods.Draw(screen, g)
+
+	// 调试模式或设置开启时绘制碰撞盒
+	if g.settings.ShowHitboxes {
+		g.drawCollisionBoxes(screen)
+	}
+
+	// 在左上角显示帧率（渲染测试时不显示，保证画面确定）
+	if !g.options.golden {
+		fps := fmt.Sprintf("FPS: %.0f", ebiten.ActualFPS())
+		DrawText(screen, fps, 10, 10)
+	}
+
+	// 金币数
+	if g.Player != nil {
+		DrawText(screen, fmt.Sprintf("COINS: %d", g.coinCounter.Value()), 10, 42)
+		// 拿着的钥匙
+		g.devices.DrawHUD(screen, 10, 60)
+	}
 
-	// 在左上角显示帧率
-	fps := fmt.Sprintf("FPS: %.0f", ebiten.ActualFPS())
-	ebitenutil.DebugPrintAt(screen, fps, 10, 10)
+	// 连击播报、正在播放提示、字幕和回溯提示
+	g.announcer.Draw(screen)
+	g.nowPlaying.Draw(screen)
+	g.captions.Draw(screen)
+	if g.editor != nil {
+		g.editor.DrawHUD(screen, g)
+	} else {
+		g.drawRewindHUD(screen)
+		g.continues.Draw(screen, g)
+	}
+
+	// 输入诊断和帧数据导出提示
+	g.diag.Draw(screen)
+	g.frameDump.Draw(screen)
+	g.scrubber.Draw(screen)
+
+	// 暂停时绘制半透明遮罩和提示（存档点回放时不遮挡画面）
+	if g.isPaused && !g.scrubber.Active() {
+		g.drawPauseOverlay(screen)
+	}
 }
 
-// drawBackground 绘制背景图片（上下铺满，左右无限生成）
-func (g *Game) drawBackground(screen *ebiten.Image) {
-	bgBounds := g.bgImage.Bounds()
-	bgWidth := float64(bgBounds.Dx())
+// drawRewindHUD 绘制剩余回溯次数，玩家死亡且可以回溯时提示按住 R 键
+func (g *Game) drawRewindHUD(screen *ebiten.Image) {
+	if g.settings.RewindCharges <= 0 {
+		return
+	}
+	DrawText(screen, fmt.Sprintf("REWIND: %d", g.rewind.Charges()), 10, 26)
 
-	// 计算需要绘制的背景图片数量（左右各多绘制一张以确保无缝滚动）
-	startX := int(g.CameraX/bgWidth) - 1
-	endX := int((g.CameraX+float64(windowWidth))/bgWidth) + 1
+	switch {
+	case g.isRewinding:
+		DrawTextCentered(screen, "<< REWINDING", windowWidth/2, windowHeight/2-8)
+	case g.Player != nil && g.Player.IsDead && g.rewind.Charges() > 0:
+		DrawPromptCentered(screen, "HOLD {rewind} TO REWIND", windowWidth/2, windowHeight/2-8)
+	}
+}
 
-	// 复用 DrawImageOptions 对象，减少内存分配
-	op := &ebiten.DrawImageOptions{}
+// drawPauseOverlay 绘制暂停遮罩
+func (g *Game) drawPauseOverlay(screen *ebiten.Image) {
+	vector.FillRect(screen, 0, 0, windowWidth, windowHeight, color.RGBA{A: 128}, false)
+	if g.idle.Idle() {
+		g.idle.Draw(screen)
+	}
+	DrawTextCentered(screen, "PAUSED", windowWidth/2, windowHeight/2-8)
 
-	for i := startX; i <= endX; i++ {
-		x := float64(i)*bgWidth - g.CameraX
-		op.GeoM.Reset()
-		op.GeoM.Translate(x, 0)
-		screen.DrawImage(g.bgImage, op)
+	// 种子码（用于分享，在相同的种子和突变下比较成绩）
+	code := "CODE: " + SeedCode(g.options.Seed, g.options.Mutators)
+	DrawTextCentered(screen, code, windowWidth/2, windowHeight/2+12)
+	if g.quit.Open() {
+		g.quit.Draw(screen, g)
+	} else {
+		g.pause.Draw(screen)
 	}
 }
 
-// drawMap 绘制地图（道路和障碍）
+// drawCollisionBoxes 绘制所有碰撞盒（调试用）
+// 玩家为绿色，怪物为红色，其余障碍物为黄色
+func (g *Game) drawCollisionBoxes(screen *ebiten.Image) {
+	strokeBox := func(box engine.CollisionBox, clr color.Color) {
+		left, right, top, bottom := box.GetCollisionBox()
+		x, y := g.Camera.WorldToScreen(left, top)
+		if g.options.Mutators.Has(MutatorMirror) {
+			x = windowWidth - (x + right - left)
+		}
+		vector.StrokeRect(screen, float32(x), float32(y), float32(right-left), float32(bottom-top), 1, clr, false)
+	}
+
+	for _, obstacle := range g.Obstacles {
+		if obstacle.Type == ObstacleTypeMonster {
+			strokeBox(obstacle, color.RGBA{R: 255, A: 255})
+		} else {
+			strokeBox(obstacle, color.RGBA{R: 255, G: 255, A: 255})
+		}
+	}
+	if g.Player != nil {
+		strokeBox(g.Player, color.RGBA{G: 255, A: 255})
+	}
+}
+
+// drawWorldBuffer 把游戏世界缓冲图调色、按镜像模式翻转后绘制到屏幕
+func (g *Game) drawWorldBuffer(screen *ebiten.Image) {
+	mirror := g.options.Mutators.Has(MutatorMirror)
+	if g.grading != nil {
+		g.grading.Apply(screen, g.sceneBuffer, mirror)
+		return
+	}
+	op := &ebiten.DrawImageOptions{}
+	if mirror {
+		op.GeoM.Scale(-1, 1)
+		op.GeoM.Translate(windowWidth, 0)
+	}
+	screen.DrawImage(g.sceneBuffer, op)
+}
+
+// drawBackground 绘制背景（左右无限滚动，每层一次绘制调用）
+func (g *Game) drawBackground(screen *ebiten.Image) {
+	g.background.Draw(screen, g.Camera.X)
+}
+
+// drawMap 绘制地图（装饰物、道路和障碍）
+// 所有地图元素先加入渲染队列，按绘制层（装饰物在最后面）和图片排序后合批绘制
 func (g *Game) drawMap(screen *ebiten.Image) {
-	// 遍历所有障碍物，调用其 Draw 方法
+	for _, decoration := range g.Decorations {
+		decoration.Enqueue(g.renderQueue, g.Camera)
+	}
 	for _, obstacle := range g.Obstacles {
-		obstacle.Draw(screen, g.CameraX)
+		obstacle.Enqueue(g.renderQueue, g.Camera)
+	}
+	g.renderQueue.Flush(screen)
+}
+
+// drawShadow 绘制玩家投射到正下方地面上的阴影
+func (g *Game) drawShadow(screen *ebiten.Image) {
+	if g.Player != nil {
+		g.shadow.Draw(screen, g.Camera, g.Player, g.Obstacles)
 	}
 }
 
 // drawPlayer 绘制玩家
 func (g *Game) drawPlayer(screen *ebiten.Image) {
 	if g.Player != nil {
-		g.Player.Draw(screen, g.CameraX)
+		g.Player.drawRope(screen, g.Camera)
+		g.Player.Draw(screen, g.Camera)
 	}
 }
 
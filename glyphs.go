@@ -0,0 +1,408 @@
+package main
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	// 摇杆超过这个幅度才算操作（移动和切换输入设备）
+	gamepadStickDeadZone = 0.5
+	// 按键图标的高度和按钮图标的直径（像素）
+	glyphHeight   = 16
+	glyphDiameter = 16
+)
+
+// InputDevice 玩家最近使用的输入设备（决定提示中显示的按键图标）
+type InputDevice int
+
+const (
+	InputDeviceKeyboard    InputDevice = iota // 键盘（和鼠标）
+	InputDeviceXbox                           // Xbox 手柄（A/B/X/Y）
+	InputDevicePlayStation                    // PlayStation 手柄（叉/圆/方/三角）
+	InputDeviceNintendo                       // Nintendo 手柄（A 和 B、X 和 Y 的位置与 Xbox 相反）
+	InputDeviceGamepad                        // 其他手柄（按位置编号 1～4）
+)
+
+// inputDeviceNames 输入设备名称
+var inputDeviceNames = map[InputDevice]string{
+	InputDeviceKeyboard:    "keyboard",
+	InputDeviceXbox:        "xbox",
+	InputDevicePlayStation: "playstation",
+	InputDeviceNintendo:    "nintendo",
+	InputDeviceGamepad:     "gamepad",
+}
+
+// String 返回输入设备名称
+func (d InputDevice) String() string {
+	return inputDeviceNames[d]
+}
+
+// gamepadDeviceNames 按手柄名称（小写）中的关键字判断手柄类型，按顺序匹配
+var gamepadDeviceNames = []struct {
+	keyword string
+	device  InputDevice
+}{
+	{"xbox", InputDeviceXbox},
+	{"xinput", InputDeviceXbox},
+	{"playstation", InputDevicePlayStation},
+	{"dualshock", InputDevicePlayStation},
+	{"dualsense", InputDevicePlayStation},
+	{"ps4", InputDevicePlayStation},
+	{"ps5", InputDevicePlayStation},
+	{"wireless controller", InputDevicePlayStation}, // DualShock 4 在部分系统上报告的名称
+	{"nintendo", InputDeviceNintendo},
+	{"switch", InputDeviceNintendo},
+	{"pro controller", InputDeviceNintendo},
+	{"joy-con", InputDeviceNintendo},
+}
+
+// classifyGamepad 按手柄名称判断手柄类型，无法判断时返回 InputDeviceGamepad
+func classifyGamepad(name string) InputDevice {
+	name = strings.ToLower(name)
+	for _, entry := range gamepadDeviceNames {
+		if strings.Contains(name, entry.keyword) {
+			return entry.device
+		}
+	}
+	return InputDeviceGamepad
+}
+
+// InputDeviceTracker 记录最近使用的输入设备：按下键盘按键或点击鼠标时切换到键盘，
+// 按下手柄按钮或推动摇杆时切换到这个手柄的类型
+// 每帧可以调用多次 Update（同一帧结果相同），所有场景共用 lastInput
+type InputDeviceTracker struct {
+	device InputDevice
+}
+
+// lastInput 最近使用的输入设备（标题、菜单和游戏共用）
+var lastInput InputDeviceTracker
+
+// Update 检查本帧的输入，切换最近使用的输入设备
+func (t *InputDeviceTracker) Update() {
+	if len(inpututil.AppendJustPressedKeys(nil)) > 0 || inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		t.device = InputDeviceKeyboard
+		return
+	}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if len(inpututil.AppendJustPressedStandardGamepadButtons(id, nil)) > 0 || gamepadStickX(id) != 0 {
+			t.device = classifyGamepad(ebiten.GamepadName(id))
+			return
+		}
+	}
+}
+
+// Device 返回最近使用的输入设备
+func (t *InputDeviceTracker) Device() InputDevice {
+	return t.device
+}
+
+// InputAction 可以用键盘或手柄触发的操作（提示中用 {名称} 引用，例如 "HOLD {rewind} TO REWIND"）
+type InputAction int
+
+const (
+	ActionJump     InputAction = iota // 跳跃
+	ActionConfirm                     // 菜单确认、接关
+	ActionBack                        // 菜单返回
+	ActionRewind                      // 回溯（按住）
+	ActionSkip                        // 跳过（接关倒数跳过一秒）
+	ActionNavigate                    // 菜单上下选择（方向键、十字键）
+	ActionGrab                        // 抓住秋千点（按住）
+)
+
+// inputActionNames 操作名称（提示文本中的 {名称}）
+var inputActionNames = map[InputAction]string{
+	ActionJump:     "jump",
+	ActionConfirm:  "confirm",
+	ActionBack:     "back",
+	ActionRewind:   "rewind",
+	ActionSkip:     "skip",
+	ActionNavigate: "navigate",
+	ActionGrab:     "grab",
+}
+
+// String 返回操作名称
+func (a InputAction) String() string {
+	return inputActionNames[a]
+}
+
+// ParseInputAction 解析操作名称
+func ParseInputAction(name string) (InputAction, bool) {
+	for action, actionName := range inputActionNames {
+		if actionName == name {
+			return action, true
+		}
+	}
+	return 0, false
+}
+
+// actionBinding 操作对应的键盘按键和标准布局的手柄按钮，以及键盘图标上的文字
+type actionBinding struct {
+	key      ebiten.Key
+	keyLabel string
+	button   ebiten.StandardGamepadButton
+}
+
+// actionBindings 操作的按键绑定（ActionNavigate 只用于提示，由菜单自己读取方向键和十字键）
+var actionBindings = map[InputAction]actionBinding{
+	ActionJump:     {key: ebiten.KeySpace, keyLabel: "SPACE", button: ebiten.StandardGamepadButtonRightBottom},
+	ActionConfirm:  {key: ebiten.KeyEnter, keyLabel: "ENTER", button: ebiten.StandardGamepadButtonRightBottom},
+	ActionBack:     {key: ebiten.KeyEscape, keyLabel: "ESC", button: ebiten.StandardGamepadButtonRightRight},
+	ActionRewind:   {key: ebiten.KeyR, keyLabel: "R", button: ebiten.StandardGamepadButtonRightLeft},
+	ActionSkip:     {key: ebiten.KeySpace, keyLabel: "SPACE", button: ebiten.StandardGamepadButtonRightTop},
+	ActionNavigate: {keyLabel: "UP/DOWN", button: ebiten.StandardGamepadButtonLeftTop},
+	ActionGrab:     {key: ebiten.KeyE, keyLabel: "E", button: ebiten.StandardGamepadButtonRightTop},
+}
+
+// ActionPressed 操作的按键或任意手柄上的按钮是否按住
+func ActionPressed(action InputAction) bool {
+	binding := actionBindings[action]
+	if ebiten.IsKeyPressed(binding.key) {
+		return true
+	}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if ebiten.IsStandardGamepadButtonPressed(id, binding.button) {
+			return true
+		}
+	}
+	return false
+}
+
+// ActionJustPressed 操作的按键或任意手柄上的按钮是否在本帧按下
+func ActionJustPressed(action InputAction) bool {
+	binding := actionBindings[action]
+	if inpututil.IsKeyJustPressed(binding.key) {
+		return true
+	}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if inpututil.IsStandardGamepadButtonJustPressed(id, binding.button) {
+			return true
+		}
+	}
+	return false
+}
+
+// GamepadJustPressed 任意手柄的标准布局按钮是否在本帧按下（菜单的十字键）
+func GamepadJustPressed(button ebiten.StandardGamepadButton) bool {
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if inpututil.IsStandardGamepadButtonJustPressed(id, button) {
+			return true
+		}
+	}
+	return false
+}
+
+// AnyInputPressed 是否按住了任意键盘按键、鼠标按键、手柄按钮或推动了左摇杆（检测玩家是否离开）
+func AnyInputPressed() bool {
+	if len(inpututil.AppendPressedKeys(nil)) > 0 || ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) || ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) {
+		return true
+	}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if len(inpututil.AppendPressedStandardGamepadButtons(id, nil)) > 0 || gamepadStickX(id) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// GamepadDirection 所有手柄十字键和左摇杆的水平方向（-1 左，1 右，0 没有操作）
+func GamepadDirection() int {
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		switch {
+		case ebiten.IsStandardGamepadButtonPressed(id, ebiten.StandardGamepadButtonLeftLeft):
+			return -1
+		case ebiten.IsStandardGamepadButtonPressed(id, ebiten.StandardGamepadButtonLeftRight):
+			return 1
+		}
+		if x := gamepadStickX(id); x != 0 {
+			return x
+		}
+	}
+	return 0
+}
+
+// gamepadStickX 手柄左摇杆的水平方向（超过死区时为 -1 或 1，否则为 0）
+func gamepadStickX(id ebiten.GamepadID) int {
+	if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+		return 0
+	}
+	x := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+	switch {
+	case x <= -gamepadStickDeadZone:
+		return -1
+	case x >= gamepadStickDeadZone:
+		return 1
+	}
+	return 0
+}
+
+// 按键图标的颜色
+var (
+	glyphKeyColor     = color.RGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff}
+	glyphButtonColor  = color.RGBA{R: 0x40, G: 0x40, B: 0x48, A: 0xff}
+	glyphOutlineColor = color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xff}
+)
+
+// glyphXboxColors Xbox 手柄各位置按钮的颜色
+var glyphXboxColors = map[ebiten.StandardGamepadButton]color.RGBA{
+	ebiten.StandardGamepadButtonRightBottom: {R: 0x50, G: 0xb0, B: 0x40, A: 0xff},
+	ebiten.StandardGamepadButtonRightRight:  {R: 0xd0, G: 0x40, B: 0x40, A: 0xff},
+	ebiten.StandardGamepadButtonRightLeft:   {R: 0x40, G: 0x70, B: 0xd0, A: 0xff},
+	ebiten.StandardGamepadButtonRightTop:    {R: 0xe0, G: 0xb0, B: 0x20, A: 0xff},
+}
+
+// glyphPSColors PlayStation 手柄各位置按钮上形状的颜色
+var glyphPSColors = map[ebiten.StandardGamepadButton]color.RGBA{
+	ebiten.StandardGamepadButtonRightBottom: {R: 0x80, G: 0xa0, B: 0xff, A: 0xff},
+	ebiten.StandardGamepadButtonRightRight:  {R: 0xff, G: 0x60, B: 0x70, A: 0xff},
+	ebiten.StandardGamepadButtonRightLeft:   {R: 0xe0, G: 0x80, B: 0xd0, A: 0xff},
+	ebiten.StandardGamepadButtonRightTop:    {R: 0x50, G: 0xd0, B: 0xb0, A: 0xff},
+}
+
+// glyphFaceLabels 各类手柄各位置按钮上的文字（标准布局按位置编号，Nintendo 手柄的 A/B、X/Y 与 Xbox 位置相反）
+var glyphFaceLabels = map[InputDevice]map[ebiten.StandardGamepadButton]string{
+	InputDeviceXbox: {
+		ebiten.StandardGamepadButtonRightBottom: "A",
+		ebiten.StandardGamepadButtonRightRight:  "B",
+		ebiten.StandardGamepadButtonRightLeft:   "X",
+		ebiten.StandardGamepadButtonRightTop:    "Y",
+	},
+	InputDeviceNintendo: {
+		ebiten.StandardGamepadButtonRightBottom: "B",
+		ebiten.StandardGamepadButtonRightRight:  "A",
+		ebiten.StandardGamepadButtonRightLeft:   "Y",
+		ebiten.StandardGamepadButtonRightTop:    "X",
+	},
+	InputDeviceGamepad: {
+		ebiten.StandardGamepadButtonRightBottom: "1",
+		ebiten.StandardGamepadButtonRightRight:  "2",
+		ebiten.StandardGamepadButtonRightLeft:   "3",
+		ebiten.StandardGamepadButtonRightTop:    "4",
+	},
+}
+
+// glyphWidth 返回操作在当前输入设备下的图标宽度（像素）
+func glyphWidth(action InputAction, device InputDevice) int {
+	if device == InputDeviceKeyboard {
+		return TextWidth(actionBindings[action].keyLabel) + 8
+	}
+	if action == ActionNavigate {
+		return TextWidth("D-PAD") + 8
+	}
+	return glyphDiameter
+}
+
+// drawGlyph 在 (x, y)（左上角）绘制操作在当前输入设备下的图标：键盘为带文字的键帽，手柄为对应位置的按钮
+func drawGlyph(screen *ebiten.Image, action InputAction, device InputDevice, x, y int) {
+	width := glyphWidth(action, device)
+	binding := actionBindings[action]
+	if device == InputDeviceKeyboard || action == ActionNavigate {
+		label := binding.keyLabel
+		if device != InputDeviceKeyboard {
+			label = "D-PAD"
+		}
+		vector.StrokeRect(screen, float32(x)+0.5, float32(y)+0.5, float32(width-1), glyphHeight-1, 1, glyphKeyColor, false)
+		DrawText(screen, label, x+4, y)
+		return
+	}
+
+	r := float32(glyphDiameter) / 2
+	cx, cy := float32(x)+r, float32(y)+r
+	if device == InputDevicePlayStation {
+		// PlayStation 手柄画深色按钮和彩色的形状
+		vector.FillCircle(screen, cx, cy, r, glyphButtonColor, true)
+		drawPlayStationShape(screen, binding.button, cx, cy, r*0.55)
+		return
+	}
+	fill := glyphButtonColor
+	if device == InputDeviceXbox {
+		fill = glyphXboxColors[binding.button]
+	}
+	vector.FillCircle(screen, cx, cy, r, fill, true)
+	vector.StrokeCircle(screen, cx, cy, r-0.5, 1, glyphOutlineColor, true)
+	DrawText(screen, glyphFaceLabels[device][binding.button], x+5, y)
+}
+
+// drawPlayStationShape 绘制 PlayStation 手柄按钮上的形状（叉、圆、方、三角）
+func drawPlayStationShape(screen *ebiten.Image, button ebiten.StandardGamepadButton, cx, cy, size float32) {
+	clr := glyphPSColors[button]
+	switch button {
+	case ebiten.StandardGamepadButtonRightBottom:
+		vector.StrokeLine(screen, cx-size, cy-size, cx+size, cy+size, 2, clr, true)
+		vector.StrokeLine(screen, cx-size, cy+size, cx+size, cy-size, 2, clr, true)
+	case ebiten.StandardGamepadButtonRightRight:
+		vector.StrokeCircle(screen, cx, cy, size, 2, clr, true)
+	case ebiten.StandardGamepadButtonRightLeft:
+		vector.StrokeRect(screen, cx-size, cy-size, size*2, size*2, 2, clr, true)
+	case ebiten.StandardGamepadButtonRightTop:
+		vector.StrokeLine(screen, cx, cy-size, cx+size, cy+size*0.8, 2, clr, true)
+		vector.StrokeLine(screen, cx+size, cy+size*0.8, cx-size, cy+size*0.8, 2, clr, true)
+		vector.StrokeLine(screen, cx-size, cy+size*0.8, cx, cy-size, 2, clr, true)
+	}
+}
+
+// promptSegment 提示文本的一段：普通文字或操作图标
+type promptSegment struct {
+	text   string
+	action InputAction
+	glyph  bool
+}
+
+// parsePrompt 把提示文本按 {操作名称} 拆分成文字和图标，无法识别的 {…} 按文字处理
+func parsePrompt(text string) []promptSegment {
+	var segments []promptSegment
+	for text != "" {
+		start := strings.IndexByte(text, '{')
+		end := strings.IndexByte(text, '}')
+		if start < 0 || end < start {
+			segments = append(segments, promptSegment{text: text})
+			break
+		}
+		if action, ok := ParseInputAction(text[start+1 : end]); ok {
+			if start > 0 {
+				segments = append(segments, promptSegment{text: text[:start]})
+			}
+			segments = append(segments, promptSegment{action: action, glyph: true})
+		} else {
+			segments = append(segments, promptSegment{text: text[:end+1]})
+		}
+		text = text[end+1:]
+	}
+	return segments
+}
+
+// PromptWidth 返回提示在当前输入设备下的宽度（像素，用于居中）
+func PromptWidth(text string) int {
+	width := 0
+	for _, segment := range parsePrompt(text) {
+		if segment.glyph {
+			width += glyphWidth(segment.action, lastInput.Device()) + 2
+		} else {
+			width += TextWidth(segment.text)
+		}
+	}
+	return width
+}
+
+// DrawPrompt 在 (x, y) 绘制提示：文字用界面字体，{操作名称} 换成当前输入设备对应的按键图标
+func DrawPrompt(screen *ebiten.Image, text string, x, y int) {
+	device := lastInput.Device()
+	for _, segment := range parsePrompt(text) {
+		if segment.glyph {
+			drawGlyph(screen, segment.action, device, x+1, y)
+			x += glyphWidth(segment.action, device) + 2
+			continue
+		}
+		DrawText(screen, segment.text, x, y)
+		x += TextWidth(segment.text)
+	}
+}
+
+// DrawPromptCentered 以 centerX 为中心绘制提示
+func DrawPromptCentered(screen *ebiten.Image, text string, centerX, y int) {
+	DrawPrompt(screen, text, centerX-PromptWidth(text)/2, y)
+}
@@ -4,7 +4,11 @@ go 1.24.0
 
 toolchain go1.24.5
 
-require github.com/hajimehoshi/ebiten/v2 v2.9.4
+require (
+	github.com/hajimehoshi/ebiten/v2 v2.9.4
+	github.com/yuin/gopher-lua v1.1.2
+	golang.org/x/image v0.31.0
+)
 
 require (
 	github.com/ebitengine/gomobile v0.0.0-20250923094054-ea854a63cce1 // indirect
@@ -15,4 +19,5 @@ require (
 	github.com/jezek/xgb v1.1.1 // indirect
 	golang.org/x/sync v0.17.0 // indirect
 	golang.org/x/sys v0.36.0 // indirect
+	golang.org/x/text v0.29.0 // indirect
 )
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 终点旗杆的宽度和高度（像素，旗杆底部在道路顶部）
+	goalPoleWidth  = 6.0
+	goalPoleHeight = 180.0
+	// 旗帜的宽度和高度（像素，挂在旗杆顶部）
+	goalFlagWidth  = 48.0
+	goalFlagHeight = 32.0
+)
+
+// goalImage 终点旗的图片（第一次绘制时生成）
+var goalImage *ebiten.Image
+
+// goalFlagImage 返回终点旗的图片：灰色旗杆和黑白格子的旗帜
+func goalFlagImage() *ebiten.Image {
+	if goalImage != nil {
+		return goalImage
+	}
+	goalImage = ebiten.NewImage(int(goalPoleWidth+goalFlagWidth), int(goalPoleHeight))
+	vector.FillRect(goalImage, 0, 0, goalPoleWidth, goalPoleHeight, color.RGBA{R: 0xc0, G: 0xc0, B: 0xc8, A: 0xff}, false)
+	const cell = goalFlagHeight / 4
+	for y := float32(0); y < goalFlagHeight; y += cell {
+		for x := float32(0); x < goalFlagWidth; x += cell {
+			clr := color.RGBA{R: 0xf0, G: 0xf0, B: 0xf0, A: 0xff}
+			if int((x+y)/cell)%2 == 1 {
+				clr = color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xff}
+			}
+			vector.FillRect(goalImage, goalPoleWidth+x, y, cell, cell, clr, false)
+		}
+	}
+	return goalImage
+}
+
+// GoalSystem 终点：在滚动方向上地图的最后一列（向左滚动的地图为第一列）立一面终点旗，
+// 玩家碰到终点所在的列时通关（旗杆所在的列从地面一直到天空都算，跳过去也会碰到）：设置 Game.cleared，游戏时钟停止，
+// 发布 EventGoalReached，接关系统随后显示通关的结算界面；放在模拟步中物理系统之后
+type GoalSystem struct {
+	column int // 终点所在的列（没有地图时为 -1）
+}
+
+// NewGoalSystem 创建终点系统（地图生成后由 SetMap 设置终点）
+func NewGoalSystem() *GoalSystem {
+	return &GoalSystem{column: -1}
+}
+
+// SetMap 按滚动方式把终点放在地图的最后一列（向左滚动时为第一列）
+func (s *GoalSystem) SetMap(items []*MapItem, mode ScrollMode) {
+	s.column = len(items) - 1
+	if mode == ScrollModeLeft && len(items) > 0 {
+		s.column = 0
+	}
+}
+
+// Update 检查玩家是否碰到终点所在的列
+func (s *GoalSystem) Update(g *Game) {
+	p := g.Player
+	if s.column < 0 || g.cleared || p == nil || p.IsDead {
+		return
+	}
+	left, right, _, _ := p.GetCollisionBox()
+	columnLeft := float64(s.column) * mapItemWidth
+	if right < columnLeft || left > columnLeft+mapItemWidth {
+		return
+	}
+	g.cleared = true
+	g.events.Publish(Event{Type: EventGoalReached, X: p.X, Y: p.Y})
+}
+
+// Draw 在终点所在列的中间绘制终点旗（旗杆底部在道路顶部）
+func (s *GoalSystem) Draw(screen *ebiten.Image, camera *engine.Camera, groundY float64) {
+	if s.column < 0 {
+		return
+	}
+	centerX := (float64(s.column) + 0.5) * mapItemWidth
+	if !camera.IsVisible(centerX-goalPoleWidth/2.0, centerX+goalPoleWidth/2.0+goalFlagWidth) {
+		return
+	}
+	x, y := camera.WorldToScreen(centerX-goalPoleWidth/2.0, groundY-goalPoleHeight)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(x, y)
+	screen.DrawImage(goalFlagImage(), op)
+}
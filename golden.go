@@ -0,0 +1,41 @@
+package main
+
+// scriptStep 输入脚本的一项：从第 Frame 帧开始使用 Input，直到下一项
+type scriptStep struct {
+	Frame int
+	Input PlayerInput
+}
+
+// goldenScript 渲染测试（golden_test.go）的输入：一直向右跑，中间跳两次，最后停下
+var goldenScript = []scriptStep{
+	{Frame: 0, Input: PlayerInput{Right: true}},
+	{Frame: 55, Input: PlayerInput{Right: true, Jump: true}},
+	{Frame: 70, Input: PlayerInput{Right: true}},
+	{Frame: 200, Input: PlayerInput{Right: true, Jump: true}},
+	{Frame: 215, Input: PlayerInput{Right: true}},
+	{Frame: 450},
+}
+
+// InputScript 按帧编排的玩家输入（渲染测试代替键盘和手柄），每次读取前进一帧
+type InputScript struct {
+	steps []scriptStep // 按 Frame 排序
+	frame int
+}
+
+// NewInputScript 创建从第 0 帧开始的输入脚本
+func NewInputScript(steps []scriptStep) *InputScript {
+	return &InputScript{steps: steps}
+}
+
+// Input 返回本帧的输入并前进一帧
+func (s *InputScript) Input() PlayerInput {
+	var input PlayerInput
+	for _, step := range s.steps {
+		if step.Frame > s.frame {
+			break
+		}
+		input = step.Input
+	}
+	s.frame++
+	return input
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// 渲染测试使用的种子（固定）
+	goldenSeed = 20240101
+	// 渲染测试运行的帧数
+	goldenTicks = 600
+	// 基准图片所在的目录，以及比较失败时保存实际画面的目录
+	goldenDir       = "testdata/golden"
+	goldenActualDir = "debug/golden"
+	// 像素的任一通道相差超过这个值时算作不同
+	goldenPixelTolerance = 16
+	// 不同的像素占比超过这个值时比较失败（容忍缩放、抗锯齿在不同显卡上的细微差别）
+	goldenMaxDiffRatio = 0.002
+)
+
+// updateGolden 渲染测试是否写入基准图片而不是比较（go test -run TestGolden -update）
+var updateGolden = flag.Bool("update", false, "渲染测试：把关键帧的画面写入 testdata/golden 作为新的基准图片")
+
+// goldenFrames 保存和比较画面的帧（运行第几帧之后）：开局、起跳、空中、跑出第一屏和结束
+var goldenFrames = []int{1, 60, 75, 300, goldenTicks}
+
+// goldenOptions 渲染测试的启动选项：固定种子、默认设置、静音、不使用存档和统计，不显示帧率
+func goldenOptions() GameOptions {
+	return GameOptions{
+		Seed:        goldenSeed,
+		MapLength:   defaultMapLength,
+		Scroll:      ScrollModeRight,
+		Theme:       defaultThemeName,
+		Quality:     GraphicsQualityHigh,
+		TimeScale:   1,
+		SampleRate:  audioSampleRate,
+		Mute:        true,
+		Announcer:   true,
+		MusicVolume: 1,
+		SFXVolume:   1,
+		golden:      true,
+	}
+}
+
+// TestGolden 用固定的种子和输入脚本运行 goldenTicks 帧，在 goldenFrames 把游戏画面绘制到缓冲图，
+// 与 testdata/golden 中的基准图片比较（-update 时改为写入基准图片）；比较失败时把实际画面保存到 debug/golden
+// 画面在测试的游戏循环中绘制和读取，没有图形上下文时跳过
+func TestGolden(t *testing.T) {
+	frames := make(map[int]*image.RGBA)
+	err := runOnGameLoop(t, func() error {
+		game := NewGame(goldenOptions())
+		defer game.Close()
+		frame := ebiten.NewImage(windowWidth, windowHeight)
+		defer frame.Deallocate()
+		for tick := 1; tick <= goldenTicks; tick++ {
+			if err := game.Update(); err != nil {
+				return err
+			}
+			if slices.Contains(goldenFrames, tick) {
+				frame.Clear()
+				game.Draw(frame)
+				pixels := image.NewRGBA(image.Rect(0, 0, windowWidth, windowHeight))
+				frame.ReadPixels(pixels.Pix)
+				frames[tick] = pixels
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tick := range goldenFrames {
+		name := fmt.Sprintf("frame-%04d.png", tick)
+		actual := frames[tick]
+		if *updateGolden {
+			if err := writePNG(filepath.Join(goldenDir, name), actual); err != nil {
+				t.Fatalf("写入基准图片失败: %v", err)
+			}
+			t.Logf("%s  updated", name)
+			continue
+		}
+
+		expected, err := readPNG(filepath.Join(goldenDir, name))
+		var ratio float64
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			err = fmt.Errorf("没有基准图片（使用 go test -run TestGolden -update 生成）")
+		case err == nil:
+			ratio, err = compareImages(expected, actual)
+		}
+		if err == nil {
+			t.Logf("%s  ok    %.4f%% different", name, ratio*100)
+			continue
+		}
+
+		actualPath := filepath.Join(goldenActualDir, name)
+		if writeErr := writePNG(actualPath, actual); writeErr != nil {
+			t.Logf("无法保存实际画面: %v", writeErr)
+		}
+		t.Errorf("%s  %v（实际画面: %s）", name, err, actualPath)
+	}
+}
+
+// compareImages 返回两张图片中不同像素的占比，尺寸不同或占比超过 goldenMaxDiffRatio 时返回错误
+func compareImages(expected image.Image, actual *image.RGBA) (float64, error) {
+	bounds := actual.Bounds()
+	if expected.Bounds() != bounds {
+		return 1, fmt.Errorf("尺寸不同: 基准 %v，实际 %v", expected.Bounds().Size(), bounds.Size())
+	}
+	diff := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			er, eg, eb, ea := expected.At(x, y).RGBA()
+			ar, ag, ab, aa := actual.At(x, y).RGBA()
+			for _, d := range [4][2]uint32{{er, ar}, {eg, ag}, {eb, ab}, {ea, aa}} {
+				if absDiff(d[0]>>8, d[1]>>8) > goldenPixelTolerance {
+					diff++
+					break
+				}
+			}
+		}
+	}
+	ratio := float64(diff) / float64(bounds.Dx()*bounds.Dy())
+	if ratio > goldenMaxDiffRatio {
+		return ratio, fmt.Errorf("%.4f%% 的像素不同（允许 %.4f%%）", ratio*100, goldenMaxDiffRatio*100)
+	}
+	return ratio, nil
+}
+
+// absDiff 返回两个无符号数之差的绝对值
+func absDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// readPNG 读取 PNG 图片
+func readPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return png.Decode(file)
+}
+
+// writePNG 把图片写入 PNG 文件（自动创建目录）
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
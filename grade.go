@@ -0,0 +1,128 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 评分：每项的分数
+	gradeDistancePoints = 10  // 每列距离
+	gradeCoinPoints     = 20  // 每枚剩余的金币
+	gradeSavePoints     = 100 // 每次避免的死亡（护盾抵挡的触碰）
+	gradeSecondPoints   = 2   // 每秒游戏时间
+	gradeDeathPenalty   = 150 // 最后一次之前的每次死亡（回溯或接关之前的死亡）扣分，通关时每次死亡都扣分
+	gradeClearPoints    = 500 // 碰到终点通关
+	// 评级的最低分数
+	gradeSScore = 3000
+	gradeAScore = 1800
+	gradeBScore = 800
+	// 评级印章：结算界面出现后等待的帧数、盖章动画的帧数、印章放大的倍数和盖章开始时额外的倍数
+	gradeStampDelayFrames = 30
+	gradeStampFrames      = 18
+	gradeStampScale       = 8.0
+	gradeStampDropScale   = 3.0
+)
+
+// Grade 一局的评级
+type Grade int
+
+const (
+	GradeC Grade = iota
+	GradeB
+	GradeA
+	GradeS
+)
+
+// gradeNames 评级名称
+var gradeNames = map[Grade]string{
+	GradeC: "C",
+	GradeB: "B",
+	GradeA: "A",
+	GradeS: "S",
+}
+
+// gradeColors 评级印章的颜色
+var gradeColors = map[Grade]color.RGBA{
+	GradeC: {R: 0xb0, G: 0xb0, B: 0xb0, A: 0xff},
+	GradeB: {R: 0x60, G: 0xa0, B: 0xff, A: 0xff},
+	GradeA: {R: 0x60, G: 0xe0, B: 0x70, A: 0xff},
+	GradeS: {R: 0xff, G: 0xd0, B: 0x40, A: 0xff},
+}
+
+// String 返回评级名称
+func (g Grade) String() string {
+	return gradeNames[g]
+}
+
+// RunScore 按距离、金币、避免的死亡、游戏时间和是否通关计算一局的分数（不小于 0）
+func RunScore(r *RunResults) int {
+	penalized := max(r.Deaths-1, 0)
+	if r.Cleared {
+		penalized = r.Deaths
+	}
+	score := r.Distance*gradeDistancePoints +
+		r.Coins*gradeCoinPoints +
+		r.Saves*gradeSavePoints +
+		int(r.Frames/60)*gradeSecondPoints -
+		penalized*gradeDeathPenalty
+	if r.Cleared {
+		score += gradeClearPoints
+	}
+	return max(score, 0)
+}
+
+// GradeForScore 返回分数对应的评级
+func GradeForScore(score int) Grade {
+	switch {
+	case score >= gradeSScore:
+		return GradeS
+	case score >= gradeAScore:
+		return GradeA
+	case score >= gradeBScore:
+		return GradeB
+	}
+	return GradeC
+}
+
+// GradeStamp 结算界面上的评级印章：等待一会儿后从大到小“盖”到界面上
+type GradeStamp struct {
+	grade  Grade
+	letter *ebiten.Image // 评级字母（按界面字体的大小绘制，显示时放大）
+	scale  float64       // 额外的放大倍数（盖章动画从 gradeStampDropScale 变到 1）
+	alpha  float64
+	tweens engine.Tweener
+}
+
+// NewGradeStamp 创建评级印章并开始盖章动画
+func NewGradeStamp(grade Grade) *GradeStamp {
+	letter := NewTextImage(grade.String())
+	s := &GradeStamp{grade: grade, letter: letter, scale: gradeStampDropScale}
+	s.tweens.Start(engine.NewTween(gradeStampFrames, engine.EaseInQuad).Float(&s.scale, 1).Float(&s.alpha, 1).Delay(gradeStampDelayFrames))
+	return s
+}
+
+// Update 推进盖章动画（界面动画，每帧推进一帧）
+func (s *GradeStamp) Update() {
+	s.tweens.Update(1)
+}
+
+// Draw 以屏幕坐标 (x, y) 为中心绘制印章
+func (s *GradeStamp) Draw(screen *ebiten.Image, x, y float64) {
+	if s.alpha <= 0 {
+		return
+	}
+	w, h := s.letter.Bounds().Dx(), s.letter.Bounds().Dy()
+	scale := gradeStampScale * s.scale
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-float64(w)/2, -float64(h)/2)
+	op.GeoM.Rotate(-0.2) // 稍微倾斜，像盖上去的印章
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(x, y)
+	op.ColorScale.ScaleWithColor(gradeColors[s.grade])
+	op.ColorScale.ScaleAlpha(float32(s.alpha))
+	screen.DrawImage(s.letter, op)
+}
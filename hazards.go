@@ -0,0 +1,243 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 空旷的道路列成为落物点的概率，以及相邻两个落物点之间至少间隔的列数
+	hazardChance      = 0.06
+	hazardMinSpacing  = 6
+	hazardIcicleShare = 0.5 // 落物中冰锥的比例（其余是落石）
+	// 触发区域向落物点两侧延伸的列数：玩家从任一侧走进这个范围时开始预警
+	hazardTriggerColumns = 2
+	// 预警阴影最大时的宽度和高度（像素）和不透明度
+	hazardShadowWidth  = 110.0
+	hazardShadowHeight = 22.0
+	hazardShadowAlpha  = 0.6
+)
+
+// HazardKind 从屏幕上方落下的危险物种类
+type HazardKind int
+
+const (
+	HazardIcicle HazardKind = iota // 冰锥：细长，预警短，落得快
+	HazardRock                     // 落石：宽大，预警长，落得慢
+)
+
+// hazardKindNames 危险物种类名称
+var hazardKindNames = map[HazardKind]string{
+	HazardIcicle: "icicle",
+	HazardRock:   "rock",
+}
+
+// String 返回危险物种类名称
+func (k HazardKind) String() string {
+	return hazardKindNames[k]
+}
+
+// hazardKindDef 危险物种类的参数
+type hazardKindDef struct {
+	width, height float64
+	warningFrames float64 // 预警阴影显示多久后开始落下（游戏时间）
+	gravity       float64 // 下落的加速度（像素/帧²）
+	color         color.RGBA
+	monster       *MonsterDef
+	image         *ebiten.Image // 第一次生成时绘制
+}
+
+// hazardKinds 各种危险物的参数
+var hazardKinds = map[HazardKind]*hazardKindDef{
+	HazardIcicle: {width: 24, height: 64, warningFrames: 40, gravity: 0.9, color: color.RGBA{R: 0xb0, G: 0xe0, B: 0xff, A: 0xff},
+		monster: &MonsterDef{Name: "icicle", Behavior: "falling", missile: true}},
+	HazardRock: {width: 72, height: 64, warningFrames: 60, gravity: 0.6, color: color.RGBA{R: 0x80, G: 0x78, B: 0x70, A: 0xff},
+		monster: &MonsterDef{Name: "rock", Behavior: "falling", missile: true}},
+}
+
+// hazardImage 返回危险物的图片（冰锥为尖端朝下的三角形，落石为圆形）
+func (d *hazardKindDef) hazardImage() *ebiten.Image {
+	if d.image != nil {
+		return d.image
+	}
+	d.image = ebiten.NewImage(int(d.width), int(d.height))
+	w, h := float32(d.width), float32(d.height)
+	if d.monster.Name == HazardIcicle.String() {
+		var path vector.Path
+		path.MoveTo(0, 0)
+		path.LineTo(w, 0)
+		path.LineTo(w/2, h)
+		path.Close()
+		vector.FillPath(d.image, &path, &vector.FillOptions{}, &vector.DrawPathOptions{AntiAlias: true, ColorScale: colorScaleOf(d.color)})
+	} else {
+		vector.FillCircle(d.image, w/2, h/2, min(w, h)/2, d.color, true)
+	}
+	return d.image
+}
+
+// colorScaleOf 把颜色转换成绘制路径使用的颜色倍数
+func colorScaleOf(clr color.RGBA) ebiten.ColorScale {
+	var scale ebiten.ColorScale
+	scale.ScaleWithColor(clr)
+	return scale
+}
+
+// HazardTrigger 落物点和它的触发区域
+type HazardTrigger struct {
+	Kind        HazardKind
+	Column      int // 落物点所在的列（落在这一列中间）
+	Left, Right int // 触发区域的列范围 [Left, Right]
+}
+
+// GenHazardTriggers 在空旷的道路列（没有障碍物、怪物和高处路线）上随机选择落物点
+// items: 地图；random: 随机数流（来自 RNG 服务）
+func GenHazardTriggers(items []*MapItem, random *rand.Rand) []HazardTrigger {
+	var triggers []HazardTrigger
+	last := -hazardMinSpacing
+	// 地图开头留出玩家起步的距离
+	for col := hazardMinSpacing; col < len(items); col++ {
+		item := items[col]
+		clear := item.HasRoad && !item.HasObstacle && !item.HasMonster && !item.HasPlatform
+		if !clear || col-last < hazardMinSpacing || random.Float64() >= hazardChance {
+			continue
+		}
+		kind := HazardRock
+		if random.Float64() < hazardIcicleShare {
+			kind = HazardIcicle
+		}
+		triggers = append(triggers, HazardTrigger{
+			Kind:   kind,
+			Column: col,
+			Left:   col - hazardTriggerColumns,
+			Right:  col + hazardTriggerColumns,
+		})
+		last = col
+	}
+	return triggers
+}
+
+// hazardDrop 一次已触发的落物：先显示预警阴影，到时后从屏幕上方生成危险物，危险物消失后结束
+type hazardDrop struct {
+	kind     HazardKind
+	x        float64      // 落物点中心的 X 坐标
+	warning  engine.Timer // 预警计时（游戏时间）
+	obstacle *Obstacle    // 生成的危险物（还在预警时为 nil）
+}
+
+// HazardScheduler 落物调度：玩家走进触发区域时安排一次落物（每个落物点只触发一次），
+// 预警结束后在相机顶部生成危险物，危险物落到道路上碎掉，和怪物一样触碰即死（护盾可以抵挡）
+type HazardScheduler struct {
+	triggers []HazardTrigger
+	fired    []bool // 每个落物点是否已经触发
+	drops    []hazardDrop
+}
+
+// SetTriggers 设置本局的落物点（地图生成之后调用）
+func (s *HazardScheduler) SetTriggers(triggers []HazardTrigger) {
+	s.triggers = triggers
+	s.fired = make([]bool, len(triggers))
+	s.drops = s.drops[:0]
+}
+
+// Update 检查触发区域，推进预警并生成危险物（在模拟步中调用，按游戏时间推进）
+func (s *HazardScheduler) Update(g *Game) {
+	if g.isPaused || g.isRewinding || g.Player == nil {
+		return
+	}
+
+	p := g.Player
+	if !p.IsDead && !p.IsFlying {
+		col := int(p.X / mapItemWidth)
+		for i, trigger := range s.triggers {
+			if s.fired[i] || col < trigger.Left || col > trigger.Right {
+				continue
+			}
+			s.fired[i] = true
+			drop := hazardDrop{kind: trigger.Kind, x: (float64(trigger.Column) + 0.5) * mapItemWidth}
+			drop.warning.Start(hazardKinds[trigger.Kind].warningFrames)
+			s.drops = append(s.drops, drop)
+		}
+	}
+
+	active := s.drops[:0]
+	for _, drop := range s.drops {
+		if drop.obstacle == nil {
+			if drop.warning.Tick(1) {
+				drop.obstacle = newFallingHazard(drop.kind, drop.x, g.Camera.Y, g.groundY)
+				g.Obstacles = append(g.Obstacles, drop.obstacle)
+			}
+		} else if drop.obstacle.Monster.IsDead {
+			continue
+		}
+		active = append(active, drop)
+	}
+	s.drops = active
+}
+
+// Draw 在落物点的道路上绘制预警阴影：预警期间逐渐变大变深，危险物落下时随高度继续变大
+func (s *HazardScheduler) Draw(screen *ebiten.Image, camera *engine.Camera, shadow *Shadow, groundY float64) {
+	for _, drop := range s.drops {
+		scale := drop.warning.Progress()
+		if drop.obstacle != nil {
+			_, _, _, bottom := drop.obstacle.GetCollisionBox()
+			scale = 1 + 0.3*max(0, 1-(groundY-bottom)/float64(windowHeight))
+		}
+		width := hazardShadowWidth * max(0.2, scale)
+		height := hazardShadowHeight * max(0.2, scale)
+		shadow.drawEllipse(screen, camera, drop.x, groundY, width, height, hazardShadowAlpha*min(1, scale))
+	}
+}
+
+// snapshot 返回调度状态的副本（回溯快照使用，复用 into 的切片）
+func (s *HazardScheduler) snapshot(into HazardScheduler) HazardScheduler {
+	into.triggers = s.triggers
+	into.fired = append(into.fired[:0], s.fired...)
+	into.drops = append(into.drops[:0], s.drops...)
+	return into
+}
+
+// restore 恢复快照时的调度状态
+func (s *HazardScheduler) restore(from *HazardScheduler) {
+	s.fired = append(s.fired[:0], from.fired...)
+	s.drops = append(s.drops[:0], from.drops...)
+}
+
+// newFallingHazard 在相机顶部上方生成危险物（和子弹一样是不计入击败的怪物）
+// x: 落物点中心；cameraY: 相机顶部；groundY: 道路顶部（落到这里时碎掉）
+func newFallingHazard(kind HazardKind, x, cameraY, groundY float64) *Obstacle {
+	def := hazardKinds[kind]
+	left := x - def.width/2.0
+	top := cameraY - def.height
+	obstacle := NewObstacle(left, top, left, top, def.width, def.height, def.hazardImage(), ObstacleTypeMonster)
+	obstacle.Monster = &Monster{
+		Def:       def.monster,
+		HomeX:     left,
+		Direction: -1,
+	}
+	obstacle.Monster.Brain = behaviorFalling(obstacle, def.gravity, groundY)
+	return obstacle
+}
+
+// behaviorFalling 落物：加速下落，底部碰到道路顶部时碎掉
+func behaviorFalling(o *Obstacle, fallGravity, groundY float64) *monsterMachine {
+	m := o.Monster
+
+	sm := NewStateMachine[*MonsterContext](AIStatePatrol)
+	sm.AddState(AIStatePatrol, &monsterState{
+		Update: func(ctx *MonsterContext) AIState {
+			m.VelocityY += fallGravity
+			o.Move(0, m.VelocityY)
+			if o.Y+o.Height >= groundY {
+				return AIStateDead
+			}
+			return AIStatePatrol
+		},
+	})
+	sm.AddState(AIStateDead, deadState(o))
+	return sm
+}
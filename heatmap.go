@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"image/color"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 热力图柱的最大高度（像素）
+	heatmapBarHeight = 160.0
+)
+
+// HeatmapMode 热力图统计的事件
+type HeatmapMode int
+
+const (
+	HeatmapEvents HeatmapMode = iota // 死亡、检查点和路线事件
+	HeatmapDeaths                    // 只统计死亡（玩家最常死在哪里）
+)
+
+// heatmapModeNames 热力图模式名称
+var heatmapModeNames = map[HeatmapMode]string{
+	HeatmapEvents: "events",
+	HeatmapDeaths: "deaths",
+}
+
+// String 返回热力图模式名称
+func (m HeatmapMode) String() string {
+	return heatmapModeNames[m]
+}
+
+// filter 返回该模式统计的记录（nil 表示统计开始以外的所有事件）
+func (m HeatmapMode) filter() func(r AnalyticsRecord) bool {
+	if m == HeatmapDeaths {
+		return func(r AnalyticsRecord) bool { return r.Event == analyticsEventDeath }
+	}
+	return nil
+}
+
+// Heatmap 按列统计的事件次数（由统计记录生成，用于平衡地图）
+type Heatmap struct {
+	Counts []int // 每列的事件次数
+	Max    int   // 单列的最大次数
+	Runs   int   // 记录中的局数
+	Mode   HeatmapMode
+}
+
+// LoadHeatmap 读取统计记录文件，按列统计该模式的事件
+// 文件不存在时返回空热力图
+func LoadHeatmap(path string, columns int, mode HeatmapMode) (*Heatmap, error) {
+	h := &Heatmap{Counts: make([]int, columns), Mode: mode}
+	filter := mode.filter()
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var r AnalyticsRecord
+		if json.Unmarshal(scanner.Bytes(), &r) != nil {
+			continue
+		}
+		if r.Event == analyticsEventStart {
+			h.Runs++
+			continue
+		}
+		if (filter != nil && !filter(r)) || r.Column < 0 || r.Column >= columns {
+			continue
+		}
+		h.Counts[r.Column]++
+		h.Max = max(h.Max, h.Counts[r.Column])
+	}
+	return h, scanner.Err()
+}
+
+// Peak 返回次数最多的列（没有记录时为 -1）
+func (h *Heatmap) Peak() int {
+	peak := -1
+	for col, count := range h.Counts {
+		if count > 0 && (peak < 0 || count > h.Counts[peak]) {
+			peak = col
+		}
+	}
+	return peak
+}
+
+// Draw 在每列底部画一根柱子，高度和颜色随该列的事件次数增加（黄 → 红）
+func (h *Heatmap) Draw(screen *ebiten.Image, camera *engine.Camera) {
+	if h.Max == 0 {
+		return
+	}
+	first := max(int(camera.X/mapItemWidth), 0)
+	last := min(int((camera.X+camera.Width)/mapItemWidth)+1, len(h.Counts))
+	for col := first; col < last; col++ {
+		if h.Counts[col] == 0 {
+			continue
+		}
+		t := float64(h.Counts[col]) / float64(h.Max)
+		height := heatmapBarHeight * t
+		x, y := camera.WorldToScreen(float64(col)*mapItemWidth, camera.Y+camera.Height-height)
+		clr := color.RGBA{R: 0xff, G: uint8(0xe0 * (1 - t)), B: 0x20, A: 0xa0}
+		vector.FillRect(screen, float32(x), float32(y), mapItemWidth, float32(height), clr, false)
+	}
+}
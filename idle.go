@@ -0,0 +1,69 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// idleDimColor 离开暂停时压暗画面的遮罩（叠加在暂停遮罩之上，比普通暂停更暗）
+var idleDimColor = color.RGBA{A: 176}
+
+// IdleSystem 离开检测：游戏中连续 Settings.IdlePauseMinutes 分钟没有任何输入（键盘、鼠标按键、手柄）时自动暂停，
+// 压暗画面并压低音频（与失去焦点时的压低相同），避免自动滚动的相机杀死离开的玩家；任意输入后继续
+// 放在暂停菜单之后、时钟之前：离开时按返回键或 P 打开暂停菜单并保持暂停，其他输入在同一帧继续游戏
+// 只在由玩家操作的游戏中创建（吸引模式的演示、测试模式和编辑器没有）
+type IdleSystem struct {
+	limit  int  // 自动暂停前没有输入的帧数
+	frames int  // 连续没有输入的帧数
+	idle   bool // 是否因为没有输入而暂停
+}
+
+// NewIdleSystem 创建离开检测，minutes 分钟没有输入后暂停
+func NewIdleSystem(minutes int) *IdleSystem {
+	return &IdleSystem{limit: minutes * 60 * ebiten.DefaultTPS}
+}
+
+// Idle 是否因为没有输入而暂停（s 为 nil 时没有离开检测）
+func (s *IdleSystem) Idle() bool {
+	return s != nil && s.idle
+}
+
+// Update 累计没有输入的帧数，超过限制时暂停，离开暂停中有输入时继续
+// 已经暂停（暂停菜单、失去焦点）、回溯、死亡和通关之后不计时
+func (s *IdleSystem) Update(g *Game) {
+	if AnyInputPressed() {
+		s.frames = 0
+		if s.idle {
+			s.wake(g)
+		}
+		return
+	}
+	if s.idle || g.isPaused || g.isRewinding || g.cleared || g.Player == nil || g.Player.IsDead {
+		return
+	}
+	if s.frames++; s.frames >= s.limit {
+		s.idle = true
+		g.isPaused = true
+		g.audioManager.SetDucked(true)
+	}
+}
+
+// wake 结束离开暂停，恢复音量；本帧打开了暂停菜单时保持暂停
+func (s *IdleSystem) wake(g *Game) {
+	s.idle = false
+	g.audioManager.SetDucked(false)
+	if !g.pause.Open() {
+		g.isPaused = false
+	}
+}
+
+// Draw 在暂停遮罩上压暗画面并提示按任意键继续
+func (s *IdleSystem) Draw(screen *ebiten.Image) {
+	vector.FillRect(screen, 0, 0, windowWidth, windowHeight, idleDimColor, false)
+	const line = "ARE YOU STILL THERE?"
+	DrawTextCentered(screen, line, windowWidth/2, windowHeight/2+36)
+	const prompt = "PRESS ANY KEY TO CONTINUE"
+	DrawTextCentered(screen, prompt, windowWidth/2, windowHeight/2+56)
+}
@@ -0,0 +1,504 @@
+package engine
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"log"
+	"math"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	// GameFPS 游戏帧率（假设60fps）
+	GameFPS = 60.0
+)
+
+// Animation 动画结构体
+type Animation struct {
+	Image         *ebiten.Image // 动画图片（精灵表）
+	FrameCount    int           // 总帧数
+	FrameWidth    int           // 每帧宽度
+	FrameHeight   int           // 每帧高度
+	Loop          bool          // 是否循环播放
+	PingPong      bool          // 是否往返循环播放（播放到最后一帧后倒着播放回第一帧，再正着播放，不会结束）
+	FPS           float64       // 动画播放速度（帧/秒）
+	OriginOffsetY float64       // 动画原点Y偏移（相对于帧底部，正数向上偏移）
+	Scale         float64       // 美术缩放（加载时已经缩小，帧尺寸和原点偏移都是缩小后的值）
+
+	frames   []*ebiten.Image // 加载时预先切好的每帧子图片（延迟加载的动画卸载后为 nil）
+	path     string          // 精灵表路径（延迟加载和卸载后重新加载时使用）
+	palette  []PaletteSwap   // 换色表
+	lazy     bool            // 是否延迟加载（可以被纹理预算卸载）
+	failed   bool            // 延迟加载失败（不再重试，绘制时跳过）
+	bytes    int64           // 精灵表占用的显存（字节，RGBA 每像素 4 字节）
+	lastUsed int             // 最后一次使用时纹理预算的帧计数
+}
+
+// NewAnimation 创建新动画（按原始尺寸绘制）
+// imagePath: 图片路径
+// frameCount: 帧数
+// loop: 是否循环播放
+// fps: 动画播放速度（帧/秒）
+// originOffsetY: 动画原点Y偏移（相对于帧底部，正数向上偏移）
+func NewAnimation(imagePath string, frameCount int, loop bool, fps float64, originOffsetY float64) (*Animation, error) {
+	return NewArtAnimation(imagePath, frameCount, loop, fps, originOffsetY, AnimationArt{})
+}
+
+// AnimationArt 加载动画时对精灵表做的预处理，零值表示按原图绘制
+type AnimationArt struct {
+	Scale   float64       // 美术缩放（绘制尺寸 / 原图尺寸），0 表示不缩放
+	Palette []PaletteSwap // 换色表（为空时不换色）
+}
+
+// NewArtAnimation 创建经过美术预处理的动画
+// 先按换色表换色（见 Recolor），再在缩放小于 1 时逐帧用 mipmap 缩小（见 Downscale）；
+// 帧尺寸和原点Y偏移都是缩小后的值，绘制时不需要再缩放
+func NewArtAnimation(imagePath string, frameCount int, loop bool, fps float64, originOffsetY float64, art AnimationArt) (*Animation, error) {
+	a := newArtAnimation(imagePath, frameCount, loop, fps, originOffsetY, art)
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// NewLazyArtAnimation 创建延迟加载的动画：只读取图片尺寸，第一次绘制时才解码和预处理
+// 长时间没有使用时可以被纹理预算卸载（见 TextureBudget），再次使用时重新加载
+func NewLazyArtAnimation(imagePath string, frameCount int, loop bool, fps float64, originOffsetY float64, art AnimationArt) (*Animation, error) {
+	a := newArtAnimation(imagePath, frameCount, loop, fps, originOffsetY, art)
+	a.lazy = true
+
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return nil, fmt.Errorf("加载动画图片失败 %s: %w", imagePath, err)
+	}
+	defer file.Close()
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取动画图片尺寸失败 %s: %w", imagePath, err)
+	}
+
+	// 帧尺寸与 load 中逐帧缩小的结果一致
+	a.FrameWidth, a.FrameHeight = config.Width/frameCount, config.Height
+	if a.Scale < 1 {
+		a.FrameWidth = max(int(math.Round(float64(a.FrameWidth)*a.Scale)), 1)
+		a.FrameHeight = max(int(math.Round(float64(a.FrameHeight)*a.Scale)), 1)
+		a.OriginOffsetY *= a.Scale
+	}
+	Textures.register(a)
+	return a, nil
+}
+
+// newArtAnimation 创建还没有加载图片的动画
+func newArtAnimation(imagePath string, frameCount int, loop bool, fps float64, originOffsetY float64, art AnimationArt) *Animation {
+	scale := art.Scale
+	if scale <= 0 || scale >= 1 {
+		scale = 1
+	}
+	return &Animation{
+		FrameCount:    frameCount,
+		Loop:          loop,
+		FPS:           fps,
+		OriginOffsetY: originOffsetY,
+		Scale:         scale,
+		path:          imagePath,
+		palette:       art.Palette,
+	}
+}
+
+// load 解码精灵表、换色、缩小并切出每帧的子图片
+func (a *Animation) load() error {
+	img, src, err := ebitenutil.NewImageFromFile(a.path)
+	if err != nil {
+		return fmt.Errorf("加载动画图片失败 %s: %w", a.path, err)
+	}
+	if len(a.palette) > 0 {
+		recolored := Recolor(src, a.palette)
+		src = recolored
+		img = ebiten.NewImageFromImage(recolored)
+	}
+
+	bounds := img.Bounds()
+	width := bounds.Dx()
+	height := bounds.Dy()
+
+	// 计算每帧宽度（水平均等拆分）
+	frameCount := a.FrameCount
+	frameWidth := width / frameCount
+
+	// 缩小时逐帧缩小（整张精灵表一起缩小会让相邻帧的像素混在一起），再拼回一张精灵表
+	// 延迟加载的动画在创建时已经按缩放计算过原点偏移
+	if a.Scale < 1 {
+		var frames []*image.RGBA
+		for i := 0; i < frameCount; i++ {
+			frameRect := image.Rect(i*frameWidth, 0, (i+1)*frameWidth, height).Add(src.Bounds().Min)
+			frames = append(frames, Downscale(subImage(src, frameRect), a.Scale))
+		}
+		frameWidth, height = frames[0].Rect.Dx(), frames[0].Rect.Dy()
+		sheet := image.NewRGBA(image.Rect(0, 0, frameWidth*frameCount, height))
+		for i, frame := range frames {
+			draw.Draw(sheet, frame.Rect.Add(image.Pt(i*frameWidth, 0)), frame, image.Point{}, draw.Src)
+		}
+		img = ebiten.NewImageFromImage(sheet)
+		if !a.lazy {
+			a.OriginOffsetY *= a.Scale
+		}
+	}
+
+	// 加载时一次性切出所有帧，GetFrame 每帧查询时不再调用 SubImage
+	frames := make([]*ebiten.Image, frameCount)
+	for i := range frames {
+		frameRect := image.Rect(i*frameWidth, 0, (i+1)*frameWidth, height)
+		frames[i] = img.SubImage(frameRect).(*ebiten.Image)
+	}
+
+	a.Image = img
+	a.FrameWidth = frameWidth
+	a.FrameHeight = height
+	a.frames = frames
+	a.bytes = int64(img.Bounds().Dx()) * int64(img.Bounds().Dy()) * 4
+	if a.lazy {
+		Textures.loaded(a)
+	} else {
+		Textures.register(a)
+	}
+	return nil
+}
+
+// unload 释放精灵表的显存（只用于延迟加载的动画，再次使用时重新加载）
+func (a *Animation) unload() {
+	if !a.lazy || a.Image == nil {
+		return
+	}
+	a.Image.Deallocate()
+	a.Image = nil
+	a.frames = nil
+}
+
+// Dispose 释放精灵表的显存并从纹理预算中移除（动画不再使用时调用，之后不能再绘制），可以重复调用
+func (a *Animation) Dispose() {
+	Textures.unregister(a)
+	if a.Image != nil {
+		a.Image.Deallocate()
+	}
+	a.Image = nil
+	a.frames = nil
+}
+
+// IsLoaded 精灵表是否已经加载（常驻显存）
+func (a *Animation) IsLoaded() bool {
+	return a.Image != nil
+}
+
+// subImage 截取图片的一部分（解码得到的图片类型都支持 SubImage）
+func subImage(img image.Image, rect image.Rectangle) image.Image {
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(dst, dst.Rect, img, rect.Min, draw.Src)
+	return dst
+}
+
+// GetFrame 获取指定帧的图片（加载时缓存的子图片，不分配内存）
+// 延迟加载的动画在这里第一次加载或卸载后重新加载，加载失败时给出警告并不再绘制
+func (a *Animation) GetFrame(frameIndex int) *ebiten.Image {
+	a.lastUsed = Textures.frame
+	if a.frames == nil && a.lazy && !a.failed {
+		if err := a.load(); err != nil {
+			log.Printf("警告: %v", err)
+			a.failed = true
+		}
+	}
+	if frameIndex < 0 || frameIndex >= len(a.frames) {
+		return nil
+	}
+	return a.frames[frameIndex]
+}
+
+// AnimationController 动画控制器
+// 只负责更新当前动画的下一帧和判断是否动画结束，状态切换由使用方控制
+// 支持暂停、倒放和整体的播放速度倍数（慢动作、过场动画使用），对所有动画生效
+// S 为动画状态类型（通常是使用方定义的枚举）
+type AnimationController[S comparable] struct {
+	currentState S
+	currentFrame float64 // 当前帧（浮点数，用于平滑播放）
+	animations   map[S]*Animation
+
+	paused     bool    // 是否暂停（暂停时 Update 不推进帧）
+	reverse    bool    // 是否倒放（从最后一帧向第一帧播放）
+	speedScale float64 // 播放速度倍数（1 为正常速度）
+	backward   bool    // 往返循环的动画当前是否在往回播放
+}
+
+// NewAnimationController 创建动画控制器
+// initial: 初始动画状态
+func NewAnimationController[S comparable](initial S) *AnimationController[S] {
+	return &AnimationController[S]{
+		currentState: initial,
+		currentFrame: 0,
+		animations:   make(map[S]*Animation),
+		speedScale:   1,
+	}
+}
+
+// AddAnimation 注册指定状态的动画
+func (ac *AnimationController[S]) AddAnimation(state S, anim *Animation) {
+	ac.animations[state] = anim
+}
+
+// Animation 返回指定状态的动画（没有注册时为 nil）
+func (ac *AnimationController[S]) Animation(state S) *Animation {
+	return ac.animations[state]
+}
+
+// AnimationSnapshot 动画控制器的播放进度快照（用于回溯等需要恢复状态的功能）
+type AnimationSnapshot[S comparable] struct {
+	State    S
+	Frame    float64
+	Backward bool // 往返循环的动画是否在往回播放
+}
+
+// Snapshot 保存当前动画状态和播放进度
+func (ac *AnimationController[S]) Snapshot() AnimationSnapshot[S] {
+	return AnimationSnapshot[S]{State: ac.currentState, Frame: ac.currentFrame, Backward: ac.backward}
+}
+
+// Restore 恢复到快照时的动画状态和播放进度
+func (ac *AnimationController[S]) Restore(snapshot AnimationSnapshot[S]) {
+	ac.currentState = snapshot.State
+	ac.currentFrame = snapshot.Frame
+	ac.backward = snapshot.Backward
+}
+
+// SetState 设置动画状态（从头播放，倒放时从最后一帧开始）
+func (ac *AnimationController[S]) SetState(state S) {
+	if ac.currentState != state {
+		ac.currentState = state
+		ac.rewindToStart()
+	}
+}
+
+// rewindToStart 回到当前动画的开头（倒放时为最后一帧）
+func (ac *AnimationController[S]) rewindToStart() {
+	ac.currentFrame = 0
+	ac.backward = false
+	if anim := ac.animations[ac.currentState]; ac.reverse && anim != nil {
+		ac.currentFrame = float64(anim.FrameCount) - 1
+	}
+}
+
+// Pause 暂停播放（停在当前帧）
+func (ac *AnimationController[S]) Pause() {
+	ac.paused = true
+}
+
+// Resume 继续播放
+func (ac *AnimationController[S]) Resume() {
+	ac.paused = false
+}
+
+// IsPaused 是否暂停
+func (ac *AnimationController[S]) IsPaused() bool {
+	return ac.paused
+}
+
+// SetReverse 设置是否倒放（从当前帧开始改变播放方向）
+func (ac *AnimationController[S]) SetReverse(reverse bool) {
+	ac.reverse = reverse
+}
+
+// IsReverse 是否倒放
+func (ac *AnimationController[S]) IsReverse() bool {
+	return ac.reverse
+}
+
+// SetSpeedScale 设置播放速度倍数（对所有动画生效，1 为正常速度，小于 0 时按 0 处理）
+func (ac *AnimationController[S]) SetSpeedScale(scale float64) {
+	ac.speedScale = max(scale, 0)
+}
+
+// GetSpeedScale 获取播放速度倍数
+func (ac *AnimationController[S]) GetSpeedScale() float64 {
+	return ac.speedScale
+}
+
+// GetState 获取当前动画状态
+func (ac *AnimationController[S]) GetState() S {
+	return ac.currentState
+}
+
+// Update 更新动画帧（前进一帧的时间，暂停时不更新）
+func (ac *AnimationController[S]) Update() {
+	ac.Advance(1)
+}
+
+// Advance 让动画前进 dt 帧的时间（通常为 Clock.Delta()，游戏暂停时为 0，慢动作时小于 1）
+func (ac *AnimationController[S]) Advance(dt float64) {
+	anim := ac.animations[ac.currentState]
+	if anim == nil || ac.paused || dt <= 0 {
+		return
+	}
+
+	// 使用当前动画的FPS、播放速度倍数和经过的时间计算帧步进，倒放和往回播放时方向相反
+	frameStep := anim.FPS / GameFPS * ac.speedScale * dt
+	if ac.reverse != ac.backward {
+		frameStep = -frameStep
+	}
+	ac.currentFrame += frameStep
+	count := float64(anim.FrameCount)
+
+	// 往返循环：越过第一帧或最后一帧时折返
+	if anim.PingPong {
+		last := count - 1
+		switch {
+		case last <= 0:
+			ac.currentFrame = 0
+		case ac.currentFrame > last:
+			ac.currentFrame = max(2*last-ac.currentFrame, 0)
+			ac.backward = !ac.backward
+		case ac.currentFrame < 0:
+			ac.currentFrame = min(-ac.currentFrame, last)
+			ac.backward = !ac.backward
+		}
+		return
+	}
+
+	// 处理帧数溢出
+	switch {
+	case ac.currentFrame >= count:
+		if anim.Loop {
+			// 循环播放
+			ac.currentFrame = ac.currentFrame - count
+		} else {
+			// 非循环动画，保持在最后一帧
+			ac.currentFrame = count - 1
+		}
+	case ac.currentFrame < 0:
+		if anim.Loop {
+			ac.currentFrame += count
+		} else {
+			// 倒放的非循环动画，保持在第一帧
+			ac.currentFrame = 0
+		}
+	}
+}
+
+// IsFinished 判断当前动画是否播放完毕（仅对非循环动画有效，倒放时播放到第一帧算作完毕）
+func (ac *AnimationController[S]) IsFinished() bool {
+	anim := ac.animations[ac.currentState]
+	if anim == nil || anim.Loop || anim.PingPong {
+		return false
+	}
+	if ac.reverse {
+		return ac.currentFrame <= 0.1
+	}
+	return ac.currentFrame >= float64(anim.FrameCount)-0.1 // 允许小的浮点误差
+}
+
+// GetCurrentFrame 获取当前帧图片
+func (ac *AnimationController[S]) GetCurrentFrame() *ebiten.Image {
+	anim := ac.animations[ac.currentState]
+	if anim == nil {
+		return nil
+	}
+
+	frameIndex := int(ac.currentFrame)
+	return anim.GetFrame(frameIndex)
+}
+
+// GetCurrentFrameIndex 获取当前播放到第几帧（从 0 开始，没有当前动画时返回 0）
+func (ac *AnimationController[S]) GetCurrentFrameIndex() int {
+	anim := ac.animations[ac.currentState]
+	if anim == nil {
+		return 0
+	}
+	return min(int(ac.currentFrame), anim.FrameCount-1)
+}
+
+// GetFrameCount 获取当前动画的总帧数（没有当前动画时返回 0）
+func (ac *AnimationController[S]) GetFrameCount() int {
+	anim := ac.animations[ac.currentState]
+	if anim == nil {
+		return 0
+	}
+	return anim.FrameCount
+}
+
+// GetProgress 获取当前动画的播放进度（0 到 1，非循环动画播放完毕时为 1，没有当前动画时返回 0）
+// 倒放时按倒放的方向计算（从最后一帧开始为 0）
+func (ac *AnimationController[S]) GetProgress() float64 {
+	anim := ac.animations[ac.currentState]
+	if anim == nil || anim.FrameCount <= 1 {
+		return 0
+	}
+	if ac.IsFinished() {
+		return 1
+	}
+	var progress float64
+	if anim.Loop && !anim.PingPong {
+		progress = ac.currentFrame / float64(anim.FrameCount)
+	} else {
+		progress = min(ac.currentFrame/float64(anim.FrameCount-1), 1)
+	}
+	if ac.reverse {
+		return 1 - progress
+	}
+	return progress
+}
+
+// GetFrameSize 获取当前动画帧的尺寸
+func (ac *AnimationController[S]) GetFrameSize() (width, height int) {
+	anim := ac.animations[ac.currentState]
+	if anim == nil {
+		return 0, 0
+	}
+	return anim.FrameWidth, anim.FrameHeight
+}
+
+// GetCurrentFPS 获取当前动画的播放速度（帧/秒）
+func (ac *AnimationController[S]) GetCurrentFPS() float64 {
+	anim := ac.animations[ac.currentState]
+	if anim == nil {
+		return 0
+	}
+	return anim.FPS
+}
+
+// GetCurrentOriginOffsetY 获取当前动画的原点Y偏移
+func (ac *AnimationController[S]) GetCurrentOriginOffsetY() float64 {
+	anim := ac.animations[ac.currentState]
+	if anim == nil {
+		return 0
+	}
+	return anim.OriginOffsetY
+}
+
+// SetAnimationFPS 设置指定动画的播放速度
+func (ac *AnimationController[S]) SetAnimationFPS(state S, fps float64) {
+	anim := ac.animations[state]
+	if anim != nil {
+		anim.FPS = fps
+	}
+}
+
+// SetAnimationPingPong 设置指定动画是否往返循环播放
+func (ac *AnimationController[S]) SetAnimationPingPong(state S, pingPong bool) {
+	anim := ac.animations[state]
+	if anim != nil {
+		anim.PingPong = pingPong
+	}
+}
+
+// SetAnimationOriginOffsetY 设置指定动画的原点Y偏移
+func (ac *AnimationController[S]) SetAnimationOriginOffsetY(state S, offsetY float64) {
+	anim := ac.animations[state]
+	if anim != nil {
+		anim.OriginOffsetY = offsetY
+	}
+}
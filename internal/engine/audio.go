@@ -0,0 +1,374 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/mp3"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+)
+
+// Sound 可重复播放的音效
+// 所有方法都允许在 nil 上调用，加载失败的音效可以直接当作静音处理
+type Sound struct {
+	player *audio.Player
+	volume float64 // 音量（未压低时）
+}
+
+// Play 从头播放音效
+func (s *Sound) Play() {
+	if s == nil {
+		return
+	}
+	// 重置到开头并播放
+	s.player.Rewind()
+	s.player.Play()
+}
+
+// SoundPool 同一音效的多个播放器（声部），允许同时播放多次，并支持播放冷却
+// 所有方法都允许在 nil 上调用
+type SoundPool struct {
+	voices         []*Sound
+	next           int // 下一次播放使用的声部
+	cooldownFrames int // 两次播放之间至少间隔的帧数
+	cooldown       int // 剩余冷却帧数
+}
+
+// Play 使用下一个声部播放音效，冷却中时忽略并返回 false
+func (p *SoundPool) Play() bool {
+	if p == nil || p.cooldown > 0 {
+		return false
+	}
+	p.voices[p.next].Play()
+	p.next = (p.next + 1) % len(p.voices)
+	p.cooldown = p.cooldownFrames
+	return true
+}
+
+// Update 每帧调用，推进冷却计时
+func (p *SoundPool) Update() {
+	if p != nil && p.cooldown > 0 {
+		p.cooldown--
+	}
+}
+
+// AudioManager 音频管理器：背景音乐、音效、统一压低音量和暂停/恢复
+type AudioManager struct {
+	context        *audio.Context  // 音频上下文
+	sampleRate     int             // 输出采样率（所有音频在加载时转换到这个采样率）
+	bgmPlayer      *audio.Player   // 背景音乐播放器
+	playlist       *playlistStream // 背景音乐播放列表（播放单首循环的背景音乐时为 nil）
+	bgmVolumeLevel float64         // 背景音乐音量（未压低时）
+	musicVolume    float64         // 玩家设置的背景音乐音量（0 到 1，乘在背景音乐音量上）
+	sfxVolume      float64         // 玩家设置的音效音量（0 到 1，乘在每个音效的音量上）
+	sounds         []*Sound        // 所有音效（用于统一压低音量或暂停）
+	duckRatio      float64         // 压低音量时的音量比例
+	isDucked       bool            // 是否处于压低音量状态
+	bgmDuckFrames  int             // 只压低背景音乐的剩余帧数（DuckBGM，例如播放短旋律时）
+	isMuted        bool            // 是否静音
+	pausedPlayers  []*audio.Player // 被 PauseAll 暂停的播放器，ResumeAll 时恢复
+}
+
+// NewAudioManager 创建音频管理器
+// 音频上下文每个进程只能创建一次，已经创建过时复用（使用已有上下文的采样率）
+// sampleRate: 输出采样率，采样率不同的音频文件在加载时自动重采样
+// duckRatio: 压低音量时的音量比例
+func NewAudioManager(sampleRate int, duckRatio float64) *AudioManager {
+	context := audio.CurrentContext()
+	if context == nil {
+		context = audio.NewContext(sampleRate)
+	}
+	return &AudioManager{
+		context:     context,
+		sampleRate:  context.SampleRate(),
+		duckRatio:   duckRatio,
+		musicVolume: 1,
+		sfxVolume:   1,
+	}
+}
+
+// GetContext 获取音频上下文
+func (am *AudioManager) GetContext() *audio.Context {
+	return am.context
+}
+
+// decodeFile 读取并解码音频文件，按扩展名选择解码器（支持 .mp3 和 .wav）
+// 文件的采样率与 sampleRate 不同时解码器自动重采样，返回的长度是重采样后的字节数
+func decodeFile(path string, sampleRate int) (io.ReadSeeker, int64, error) {
+	// 读取整个文件到内存，避免播放期间持有文件句柄
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	reader := bytes.NewReader(data)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp3":
+		stream, err := mp3.DecodeWithSampleRate(sampleRate, reader)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stream, stream.Length(), nil
+	case ".wav":
+		stream, err := wav.DecodeWithSampleRate(sampleRate, reader)
+		if err != nil {
+			return nil, 0, err
+		}
+		return stream, stream.Length(), nil
+	default:
+		return nil, 0, fmt.Errorf("不支持的音频格式: %s", path)
+	}
+}
+
+// PlayBGM 加载并循环播放背景音乐，替换当前正在播放的背景音乐
+func (am *AudioManager) PlayBGM(path string, volume float64) error {
+	stream, length, err := decodeFile(path, am.sampleRate)
+	if err != nil {
+		return err
+	}
+
+	// 创建循环播放器（使用 InfiniteLoop 实现循环）
+	player, err := am.context.NewPlayer(audio.NewInfiniteLoop(stream, length))
+	if err != nil {
+		return err
+	}
+
+	am.playlist = nil
+	am.startBGM(player, volume)
+	return nil
+}
+
+// PlayPlaylist 按播放顺序无缝播放多首背景音乐，替换当前正在播放的背景音乐
+// 每首曲目播放完后直接接上下一首（需要每帧调用 Update 提前准备下一首）；解码失败的曲目跳过
+func (am *AudioManager) PlayPlaylist(tracks []string, mode PlaylistMode, volume float64) error {
+	stream, err := newPlaylistStream(tracks, mode, am.sampleRate)
+	if err != nil {
+		return err
+	}
+	player, err := am.context.NewPlayer(stream)
+	if err != nil {
+		return err
+	}
+	am.playlist = stream
+	am.startBGM(player, volume)
+	return nil
+}
+
+// startBGM 替换背景音乐播放器并开始播放
+func (am *AudioManager) startBGM(player *audio.Player, volume float64) {
+	liveResources.AudioPlayers++
+	if am.bgmPlayer != nil {
+		am.bgmPlayer.Close()
+		liveResources.AudioPlayers--
+	}
+	am.bgmPlayer = player
+	am.bgmVolumeLevel = volume
+	player.SetVolume(am.bgmScaledVolume()) // 设置音量（0.0 到 1.0）
+	player.Play()                          // 开始播放
+}
+
+// Update 每帧调用：提前准备播放列表的下一首
+// 返回新开始播放的曲目路径（没有切换曲目或没有播放列表时返回空字符串）
+func (am *AudioManager) Update() string {
+	if am.bgmDuckFrames > 0 {
+		am.bgmDuckFrames--
+		if am.bgmDuckFrames == 0 {
+			am.applyVolumes()
+		}
+	}
+	if am.playlist == nil {
+		return ""
+	}
+	return am.playlist.update()
+}
+
+// LoadSound 加载音效
+// 音效很短，加载时一次性解码（和重采样）到内存，播放时不再解码
+func (am *AudioManager) LoadSound(path string, volume float64) (*Sound, error) {
+	stream, _, err := decodeFile(path, am.sampleRate)
+	if err != nil {
+		return nil, err
+	}
+	pcm, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	player, err := am.context.NewPlayer(bytes.NewReader(pcm))
+	if err != nil {
+		return nil, err
+	}
+	liveResources.AudioPlayers++
+
+	sound := &Sound{player: player, volume: volume}
+	player.SetVolume(am.soundScaledVolume(sound))
+	am.sounds = append(am.sounds, sound)
+	return sound, nil
+}
+
+// LoadSoundPool 加载音效池
+// voices: 声部数量（同时播放的最大次数）
+// cooldownFrames: 两次播放之间至少间隔的帧数
+func (am *AudioManager) LoadSoundPool(path string, volume float64, voices, cooldownFrames int) (*SoundPool, error) {
+	if voices < 1 {
+		voices = 1
+	}
+	pool := &SoundPool{cooldownFrames: cooldownFrames}
+	for i := 0; i < voices; i++ {
+		sound, err := am.LoadSound(path, volume)
+		if err != nil {
+			return nil, err
+		}
+		pool.voices = append(pool.voices, sound)
+	}
+	return pool, nil
+}
+
+// SetBGMVolume 设置背景音乐音量
+func (am *AudioManager) SetBGMVolume(volume float64) {
+	am.bgmVolumeLevel = volume
+	if am.bgmPlayer != nil {
+		am.bgmPlayer.SetVolume(am.bgmScaledVolume())
+	}
+}
+
+// SetMusicVolume 设置玩家的背景音乐音量（0 到 1，超出范围时限制在范围内），立即生效
+func (am *AudioManager) SetMusicVolume(volume float64) {
+	am.musicVolume = min(max(volume, 0), 1)
+	am.applyVolumes()
+}
+
+// SetSFXVolume 设置玩家的音效音量（0 到 1，超出范围时限制在范围内），立即生效
+func (am *AudioManager) SetSFXVolume(volume float64) {
+	am.sfxVolume = min(max(volume, 0), 1)
+	am.applyVolumes()
+}
+
+// scaledVolume 根据静音和压低状态计算实际音量
+func (am *AudioManager) scaledVolume(volume float64) float64 {
+	if am.isMuted {
+		return 0
+	}
+	if am.isDucked {
+		return volume * am.duckRatio
+	}
+	return volume
+}
+
+// soundScaledVolume 计算音效的实际音量（乘上玩家的音效音量）
+func (am *AudioManager) soundScaledVolume(sound *Sound) float64 {
+	return am.scaledVolume(sound.volume * am.sfxVolume)
+}
+
+// bgmScaledVolume 计算背景音乐的实际音量（乘上玩家的背景音乐音量，DuckBGM 期间额外压低）
+func (am *AudioManager) bgmScaledVolume() float64 {
+	volume := am.scaledVolume(am.bgmVolumeLevel * am.musicVolume)
+	if am.bgmDuckFrames > 0 && !am.isDucked {
+		volume *= am.duckRatio
+	}
+	return volume
+}
+
+// DuckBGM 在接下来的 frames 帧内只压低背景音乐的音量（音效不受影响），正在压低时按较长的剩余时间计算
+// 与 SetDucked 相互独立：两者同时生效时背景音乐不会被压低两次
+func (am *AudioManager) DuckBGM(frames int) {
+	if frames <= am.bgmDuckFrames {
+		return
+	}
+	am.bgmDuckFrames = frames
+	am.applyVolumes()
+}
+
+// SetDucked 设置是否压低所有音频的音量
+func (am *AudioManager) SetDucked(ducked bool) {
+	if am.isDucked == ducked {
+		return
+	}
+	am.isDucked = ducked
+	am.applyVolumes()
+}
+
+// SetMuted 设置是否静音
+func (am *AudioManager) SetMuted(muted bool) {
+	if am.isMuted == muted {
+		return
+	}
+	am.isMuted = muted
+	am.applyVolumes()
+}
+
+// applyVolumes 按当前静音和压低状态重新设置所有播放器的音量
+func (am *AudioManager) applyVolumes() {
+	if am.bgmPlayer != nil {
+		am.bgmPlayer.SetVolume(am.bgmScaledVolume())
+	}
+	for _, sound := range am.sounds {
+		sound.player.SetVolume(am.soundScaledVolume(sound))
+	}
+}
+
+// Close 关闭背景音乐和所有音效的播放器（音频管理器不再使用时调用），可以重复调用
+func (am *AudioManager) Close() {
+	if am.bgmPlayer != nil {
+		am.bgmPlayer.Close()
+		am.bgmPlayer = nil
+		liveResources.AudioPlayers--
+	}
+	am.playlist = nil
+	for _, sound := range am.sounds {
+		sound.player.Close()
+	}
+	liveResources.AudioPlayers -= len(am.sounds)
+	am.sounds = nil
+	am.pausedPlayers = nil
+}
+
+// PauseAll 暂停所有正在播放的音频，并记录下来以便 ResumeAll 恢复
+func (am *AudioManager) PauseAll() {
+	players := make([]*audio.Player, 0, len(am.sounds)+1)
+	if am.bgmPlayer != nil {
+		players = append(players, am.bgmPlayer)
+	}
+	for _, sound := range am.sounds {
+		players = append(players, sound.player)
+	}
+
+	for _, player := range players {
+		if player.IsPlaying() {
+			player.Pause()
+			am.pausedPlayers = append(am.pausedPlayers, player)
+		}
+	}
+}
+
+// ResumeAll 恢复被 PauseAll 暂停的音频
+func (am *AudioManager) ResumeAll() {
+	for _, player := range am.pausedPlayers {
+		player.Play()
+	}
+	am.pausedPlayers = am.pausedPlayers[:0]
+}
+
+// PauseBGM 暂停背景音乐
+func (am *AudioManager) PauseBGM() {
+	if am.bgmPlayer != nil && am.bgmPlayer.IsPlaying() {
+		am.bgmPlayer.Pause()
+	}
+}
+
+// IsBGMPlaying 背景音乐是否正在播放
+func (am *AudioManager) IsBGMPlaying() bool {
+	return am.bgmPlayer != nil && am.bgmPlayer.IsPlaying()
+}
+
+// ResumeBGM 恢复背景音乐
+func (am *AudioManager) ResumeBGM() {
+	if am.bgmPlayer != nil && !am.bgmPlayer.IsPlaying() {
+		am.bgmPlayer.Play()
+	}
+}
@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ScrollingLayer 横向循环滚动的背景层
+// 第一次绘制时把图块横向拼接成一张比视口宽一个图块的缓冲图，之后每帧只需绘制一次缓冲图，
+// 绘制次数与视口宽度无关
+type ScrollingLayer struct {
+	tile      *ebiten.Image
+	strip     *ebiten.Image // 预先拼接的缓冲图（延迟到第一次绘制时创建）
+	tileWidth float64
+	viewWidth int
+	Parallax  float64 // 视差系数：1 表示与相机同速滚动，越小越慢
+}
+
+// NewScrollingLayer 创建背景层
+// tile: 可左右无缝拼接的背景图块
+// viewWidth: 视口宽度
+func NewScrollingLayer(tile *ebiten.Image, viewWidth int) *ScrollingLayer {
+	return &ScrollingLayer{
+		tile:      tile,
+		tileWidth: float64(tile.Bounds().Dx()),
+		viewWidth: viewWidth,
+		Parallax:  1,
+	}
+}
+
+// buildStrip 把图块拼接成至少覆盖视口宽度加一个图块的缓冲图
+func (l *ScrollingLayer) buildStrip() {
+	bounds := l.tile.Bounds()
+	count := int(math.Ceil(float64(l.viewWidth)/l.tileWidth)) + 1
+	l.strip = ebiten.NewImage(count*bounds.Dx(), bounds.Dy())
+
+	op := &ebiten.DrawImageOptions{}
+	for i := 0; i < count; i++ {
+		op.GeoM.Reset()
+		op.GeoM.Translate(float64(i)*l.tileWidth, 0)
+		l.strip.DrawImage(l.tile, op)
+	}
+}
+
+// Dispose 释放拼接的缓冲图（图块由调用方释放），之后再绘制时重新拼接
+func (l *ScrollingLayer) Dispose() {
+	if l.strip != nil {
+		l.strip.Deallocate()
+		l.strip = nil
+	}
+}
+
+// Draw 按相机位置绘制背景层（一次绘制调用）
+func (l *ScrollingLayer) Draw(screen *ebiten.Image, cameraX float64) {
+	if l.strip == nil {
+		l.buildStrip()
+	}
+
+	// 缓冲图比视口宽一个图块，只需按图块宽度取模平移
+	offset := math.Mod(cameraX*l.Parallax, l.tileWidth)
+	if offset < 0 {
+		offset += l.tileWidth
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-offset, 0)
+	screen.DrawImage(l.strip, op)
+}
@@ -0,0 +1,60 @@
+package engine
+
+// Camera 横向滚屏相机
+// X/Y 为视口左上角的世界坐标，MinX/MaxX 为 X 的可移动范围
+type Camera struct {
+	X, Y          float64
+	Width, Height float64 // 视口尺寸
+	MinX, MaxX    float64
+	SpeedScale    float64 // 自动滚动速度倍数（由 ApproachSpeedScale 平滑调整）
+}
+
+// NewCamera 创建相机
+func NewCamera(width, height float64) *Camera {
+	return &Camera{Width: width, Height: height, SpeedScale: 1}
+}
+
+// ApproachSpeedScale 让滚动速度倍数向目标值平滑靠近，每次最多变化 step
+// 进入不同速度的区域时速度逐渐变化，而不是突然加速或减速
+func (c *Camera) ApproachSpeedScale(target, step float64) {
+	if c.SpeedScale < target {
+		c.SpeedScale = min(c.SpeedScale+step, target)
+	} else if c.SpeedScale > target {
+		c.SpeedScale = max(c.SpeedScale-step, target)
+	}
+}
+
+// SetWorldWidth 根据世界宽度设置相机的横向移动范围（0 ～ 世界宽度 - 视口宽度）
+func (c *Camera) SetWorldWidth(worldWidth float64) {
+	c.MinX = 0
+	c.MaxX = worldWidth - c.Width
+	if c.MaxX < 0 {
+		c.MaxX = 0
+	}
+}
+
+// ScrollX 横向移动相机并限制在可移动范围内
+func (c *Camera) ScrollX(dx float64) {
+	c.X += dx
+	if c.X > c.MaxX {
+		c.X = c.MaxX
+	}
+	if c.X < c.MinX {
+		c.X = c.MinX
+	}
+}
+
+// AtEnd 相机是否已经到达右侧边界
+func (c *Camera) AtEnd() bool {
+	return c.X >= c.MaxX
+}
+
+// WorldToScreen 将世界坐标转换为屏幕坐标
+func (c *Camera) WorldToScreen(x, y float64) (float64, float64) {
+	return x - c.X, y - c.Y
+}
+
+// IsVisible 判断横向区间 [left, right] 是否在视口内
+func (c *Camera) IsVisible(left, right float64) bool {
+	return right >= c.X && left <= c.X+c.Width
+}
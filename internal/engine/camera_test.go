@@ -0,0 +1,75 @@
+package engine
+
+import "testing"
+
+// TestCameraScrollClamp 相机移动限制在世界范围内，世界比视口窄时不能移动
+func TestCameraScrollClamp(t *testing.T) {
+	camera := NewCamera(100, 50)
+	camera.SetWorldWidth(250)
+	if camera.MinX != 0 || camera.MaxX != 150 {
+		t.Fatalf("移动范围为 [%g, %g]，应为 [0, 150]", camera.MinX, camera.MaxX)
+	}
+
+	camera.ScrollX(40)
+	if camera.X != 40 || camera.AtEnd() {
+		t.Errorf("移动 40 后 X = %g，到达右边界 %t", camera.X, camera.AtEnd())
+	}
+	camera.ScrollX(1000)
+	if camera.X != 150 || !camera.AtEnd() {
+		t.Errorf("越过右边界后 X = %g，到达右边界 %t", camera.X, camera.AtEnd())
+	}
+	camera.ScrollX(-1000)
+	if camera.X != 0 {
+		t.Errorf("越过左边界后 X = %g", camera.X)
+	}
+
+	camera.SetWorldWidth(60)
+	camera.ScrollX(10)
+	if camera.MaxX != 0 || camera.X != 0 || !camera.AtEnd() {
+		t.Errorf("世界比视口窄时 MaxX = %g，X = %g", camera.MaxX, camera.X)
+	}
+}
+
+// TestCameraApproachSpeedScale 滚动速度倍数每次最多变化 step，到达目标后不越过
+func TestCameraApproachSpeedScale(t *testing.T) {
+	camera := NewCamera(100, 50)
+	if camera.SpeedScale != 1 {
+		t.Fatalf("初始速度倍数为 %g", camera.SpeedScale)
+	}
+	for _, want := range []float64{1.25, 1.5, 1.6, 1.6} {
+		camera.ApproachSpeedScale(1.6, 0.25)
+		if camera.SpeedScale != want {
+			t.Fatalf("加速时速度倍数为 %g，应为 %g", camera.SpeedScale, want)
+		}
+	}
+	for _, want := range []float64{1.1, 0.8, 0.8} {
+		camera.ApproachSpeedScale(0.8, 0.5)
+		if camera.SpeedScale != want {
+			t.Fatalf("减速时速度倍数为 %g，应为 %g", camera.SpeedScale, want)
+		}
+	}
+}
+
+// TestCameraVisibility 坐标转换和可见性判断跟随相机位置
+func TestCameraVisibility(t *testing.T) {
+	camera := NewCamera(100, 50)
+	camera.X, camera.Y = 200, 30
+	if x, y := camera.WorldToScreen(250, 40); x != 50 || y != 10 {
+		t.Errorf("WorldToScreen(250, 40) = (%g, %g)，应为 (50, 10)", x, y)
+	}
+	tests := []struct {
+		left, right float64
+		want        bool
+	}{
+		{150, 199, false},
+		{150, 200, true},
+		{250, 260, true},
+		{300, 400, true},
+		{301, 400, false},
+	}
+	for _, tt := range tests {
+		if got := camera.IsVisible(tt.left, tt.right); got != tt.want {
+			t.Errorf("IsVisible(%g, %g) = %t，应为 %t", tt.left, tt.right, got, tt.want)
+		}
+	}
+}
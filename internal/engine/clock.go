@@ -0,0 +1,162 @@
+package engine
+
+// Clock 游戏时间：以帧为单位，暂停时不前进，按时间倍数缩放（慢动作时每帧前进不到一帧）
+// 游戏每帧调用一次 Tick；动画和补间按 Delta 平滑推进，物理等按固定步长模拟的逻辑每帧执行 Steps 步，界面不受影响
+type Clock struct {
+	paused bool
+	scale  float64
+	delta  float64 // 本帧前进的游戏时间（帧）
+	now    float64 // 累计的游戏时间（帧）
+	carry  float64 // 还不够一个模拟步的游戏时间（帧）
+	steps  int     // 本帧的模拟步数
+}
+
+// NewClock 创建游戏时钟（时间倍数为 1）
+func NewClock() *Clock {
+	return &Clock{scale: 1}
+}
+
+// Tick 推进一帧：暂停时本帧不前进，否则前进时间倍数对应的游戏时间
+func (c *Clock) Tick() {
+	if c.paused {
+		c.delta = 0
+		c.steps = 0
+		return
+	}
+	c.delta = c.scale
+	c.now += c.delta
+	c.carry += c.delta
+	c.steps = int(c.carry + 1e-9) // 容许累加慢动作倍数（例如 0.1）时的浮点误差
+	c.carry -= float64(c.steps)
+}
+
+// SetPaused 设置是否暂停（下一次 Tick 生效）
+func (c *Clock) SetPaused(paused bool) {
+	c.paused = paused
+}
+
+// IsPaused 是否暂停
+func (c *Clock) IsPaused() bool {
+	return c.paused
+}
+
+// SetScale 设置时间倍数（1 为正常速度，小于 1 为慢动作，负数按 0 处理）
+func (c *Clock) SetScale(scale float64) {
+	c.scale = max(scale, 0)
+}
+
+// Scale 返回时间倍数
+func (c *Clock) Scale() float64 {
+	return c.scale
+}
+
+// Delta 返回本帧前进的游戏时间（帧），暂停时为 0
+func (c *Clock) Delta() float64 {
+	return c.delta
+}
+
+// Steps 返回本帧要执行的模拟步数（每步为一帧的游戏时间）：正常速度为 1，慢动作时部分帧为 0，加速时大于 1，暂停时为 0
+// 物理、碰撞和相机按整步模拟，时间倍数改变时积分方式和碰撞结果都不变
+func (c *Clock) Steps() int {
+	return c.steps
+}
+
+// Now 返回累计的游戏时间（帧）
+func (c *Clock) Now() float64 {
+	return c.now
+}
+
+// Timer 计时器：按游戏时间倒数，零值为未启动
+// 计时器是值类型，可以直接放在需要快照的结构体中（回溯时随结构体一起复制）
+type Timer struct {
+	duration float64
+	elapsed  float64
+	running  bool
+}
+
+// Start 开始计时（duration 帧后到时），正在计时时重新开始
+func (t *Timer) Start(duration float64) {
+	t.duration = duration
+	t.elapsed = 0
+	t.running = true
+}
+
+// Stop 停止计时（不算作到时）
+func (t *Timer) Stop() {
+	t.running = false
+	t.elapsed = 0
+}
+
+// Tick 按 dt 帧推进计时，到时的那一次返回 true 并停止计时
+func (t *Timer) Tick(dt float64) bool {
+	if !t.running {
+		return false
+	}
+	t.elapsed += dt
+	if t.elapsed < t.duration {
+		return false
+	}
+	t.running = false
+	return true
+}
+
+// Running 是否正在计时
+func (t *Timer) Running() bool {
+	return t.running
+}
+
+// Elapsed 返回已经经过的时间（帧）
+func (t *Timer) Elapsed() float64 {
+	return t.elapsed
+}
+
+// Remaining 返回到时前剩余的时间（帧），未启动时为 0
+func (t *Timer) Remaining() float64 {
+	if !t.running {
+		return 0
+	}
+	return max(t.duration-t.elapsed, 0)
+}
+
+// Progress 返回计时进度（0 到 1），未启动时为 0
+func (t *Timer) Progress() float64 {
+	if t.duration <= 0 {
+		return 0
+	}
+	return min(t.elapsed/t.duration, 1)
+}
+
+// Cooldown 冷却：触发后要经过 Duration 帧的游戏时间才能再次触发，零值随时可以触发
+type Cooldown struct {
+	Duration  float64 // 冷却时间（帧）
+	remaining float64
+}
+
+// NewCooldown 创建冷却时间为 duration 帧的冷却
+func NewCooldown(duration float64) Cooldown {
+	return Cooldown{Duration: duration}
+}
+
+// Tick 按 dt 帧推进冷却
+func (c *Cooldown) Tick(dt float64) {
+	c.remaining = max(c.remaining-dt, 0)
+}
+
+// Ready 是否可以触发
+func (c *Cooldown) Ready() bool {
+	return c.remaining <= 0
+}
+
+// Trigger 可以触发时开始冷却并返回 true，冷却中返回 false
+func (c *Cooldown) Trigger() bool {
+	if !c.Ready() {
+		return false
+	}
+	c.remaining = c.Duration
+	return true
+}
+
+// Reset 结束冷却
+func (c *Cooldown) Reset() {
+	c.remaining = 0
+}
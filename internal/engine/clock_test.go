@@ -0,0 +1,94 @@
+package engine
+
+import "testing"
+
+// TestClockSteps 正常速度每帧一步，慢动作时累计够一帧才走一步，加速时一帧多步，暂停时不前进
+func TestClockSteps(t *testing.T) {
+	clock := NewClock()
+	clock.Tick()
+	if clock.Steps() != 1 || clock.Delta() != 1 || clock.Now() != 1 {
+		t.Fatalf("正常速度: 步数 %d，Delta %g，Now %g", clock.Steps(), clock.Delta(), clock.Now())
+	}
+
+	clock.SetScale(0.1)
+	steps := 0
+	for i := 0; i < 10; i++ {
+		clock.Tick()
+		steps += clock.Steps()
+	}
+	if steps != 1 {
+		t.Errorf("0.1 倍速 10 帧走了 %d 步，应为 1", steps)
+	}
+
+	clock.SetScale(2)
+	clock.Tick()
+	if clock.Steps() != 2 {
+		t.Errorf("2 倍速一帧走了 %d 步", clock.Steps())
+	}
+
+	clock.SetPaused(true)
+	now := clock.Now()
+	clock.Tick()
+	if clock.Steps() != 0 || clock.Delta() != 0 || clock.Now() != now || !clock.IsPaused() {
+		t.Errorf("暂停时: 步数 %d，Delta %g，Now %g", clock.Steps(), clock.Delta(), clock.Now())
+	}
+
+	clock.SetScale(-1)
+	if clock.Scale() != 0 {
+		t.Errorf("负数倍数为 %g，应按 0 处理", clock.Scale())
+	}
+}
+
+// TestTimer 计时器到时的那一次返回 true 并停止，停止后不再到时
+func TestTimer(t *testing.T) {
+	var timer Timer
+	if timer.Running() || timer.Tick(1) || timer.Remaining() != 0 || timer.Progress() != 0 {
+		t.Fatal("零值计时器不应在计时")
+	}
+
+	timer.Start(3)
+	for i := 0; i < 2; i++ {
+		if timer.Tick(1) {
+			t.Fatalf("第 %d 帧提前到时", i+1)
+		}
+	}
+	if timer.Remaining() != 1 || timer.Elapsed() != 2 {
+		t.Errorf("剩余 %g，已经过 %g", timer.Remaining(), timer.Elapsed())
+	}
+	if !timer.Tick(1) {
+		t.Fatal("第 3 帧没有到时")
+	}
+	if timer.Running() || timer.Tick(1) || timer.Progress() != 1 {
+		t.Errorf("到时后仍在计时: 进度 %g", timer.Progress())
+	}
+
+	timer.Start(2)
+	timer.Tick(0.5)
+	timer.Stop()
+	if timer.Running() || timer.Tick(5) {
+		t.Error("停止后仍然到时")
+	}
+}
+
+// TestCooldown 冷却中不能触发，经过冷却时间或 Reset 后可以再次触发
+func TestCooldown(t *testing.T) {
+	cooldown := NewCooldown(2)
+	if !cooldown.Trigger() {
+		t.Fatal("新的冷却不能触发")
+	}
+	if cooldown.Trigger() {
+		t.Fatal("冷却中触发了")
+	}
+	cooldown.Tick(1)
+	if cooldown.Ready() {
+		t.Fatal("只过了一半冷却时间")
+	}
+	cooldown.Tick(1)
+	if !cooldown.Trigger() {
+		t.Fatal("冷却结束后不能触发")
+	}
+	cooldown.Reset()
+	if !cooldown.Ready() {
+		t.Error("Reset 后仍在冷却")
+	}
+}
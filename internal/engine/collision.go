@@ -0,0 +1,58 @@
+// Package engine 包含与具体游戏无关的可复用模块：碰撞检测、帧动画、音频管理和相机
+package engine
+
+// CollisionBox 碰撞盒接口
+type CollisionBox interface {
+	GetCollisionBox() (left, right, top, bottom float64)
+}
+
+// CheckCollision 检查两个碰撞盒是否发生碰撞（矩形碰撞）
+// 返回 true 表示发生碰撞
+func CheckCollision(a, b CollisionBox) bool {
+	aLeft, aRight, aTop, aBottom := a.GetCollisionBox()
+	bLeft, bRight, bTop, bBottom := b.GetCollisionBox()
+
+	// AABB 碰撞检测：检查两个矩形是否重叠
+	// 如果两个矩形在 X 轴和 Y 轴上都重叠，则发生碰撞
+	return aLeft < bRight && aRight > bLeft && aTop < bBottom && aBottom > bTop
+}
+
+// SegmentIntersectsBox 检查线段 (x0, y0)-(x1, y1) 是否穿过碰撞盒（Slab 算法）
+// 用于视线遮挡等射线检测
+func SegmentIntersectsBox(x0, y0, x1, y1 float64, box CollisionBox) bool {
+	left, right, top, bottom := box.GetCollisionBox()
+	dx := x1 - x0
+	dy := y1 - y0
+
+	// tMin/tMax 为线段参数区间 [0, 1] 与盒子在两个轴上的重叠部分
+	tMin, tMax := 0.0, 1.0
+	for _, axis := range [2][4]float64{
+		{x0, dx, left, right},
+		{y0, dy, top, bottom},
+	} {
+		origin, delta, minEdge, maxEdge := axis[0], axis[1], axis[2], axis[3]
+		if delta == 0 {
+			// 线段与该轴平行，起点必须在盒子范围内
+			if origin <= minEdge || origin >= maxEdge {
+				return false
+			}
+			continue
+		}
+
+		t1 := (minEdge - origin) / delta
+		t2 := (maxEdge - origin) / delta
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		if t1 > tMin {
+			tMin = t1
+		}
+		if t2 < tMax {
+			tMax = t2
+		}
+		if tMin > tMax {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+const (
+	// 随机碰撞盒的坐标范围和尺寸范围（像素，与游戏中的物体相当）
+	testBoxRange   = 2000.0
+	testBoxMinSize = 0.5
+	testBoxMaxSize = 600.0
+	// 分开检查使用的最小间隔（像素）
+	testBoxEpsilon = 1e-6
+	// 性质测试的随机次数
+	collisionPropertyCases = 10000
+)
+
+// testBox 测试用的碰撞盒
+type testBox struct {
+	left, right, top, bottom float64
+}
+
+// GetCollisionBox 返回碰撞盒
+func (b testBox) GetCollisionBox() (left, right, top, bottom float64) {
+	return b.left, b.right, b.top, b.bottom
+}
+
+// String 返回重现用的坐标
+func (b testBox) String() string {
+	return fmt.Sprintf("[%g, %g]x[%g, %g]", b.left, b.right, b.top, b.bottom)
+}
+
+// randomTestBox 随机生成一个碰撞盒，四分之一的坐标和尺寸取整（容易出现边缘重合）
+func randomTestBox(random *rand.Rand) testBox {
+	value := func(lo, hi float64) float64 {
+		v := lo + random.Float64()*(hi-lo)
+		if random.Intn(4) == 0 {
+			v = math.Round(v)
+		}
+		return v
+	}
+	left, top := value(-testBoxRange, testBoxRange), value(-testBoxRange, testBoxRange)
+	width := max(value(testBoxMinSize, testBoxMaxSize), testBoxMinSize)
+	height := max(value(testBoxMinSize, testBoxMaxSize), testBoxMinSize)
+	return testBox{left: left, right: left + width, top: top, bottom: top + height}
+}
+
+// TestCheckCollision 重叠、边缘接触、分开和包含的情况
+func TestCheckCollision(t *testing.T) {
+	a := testBox{left: 0, right: 10, top: 0, bottom: 10}
+	tests := []struct {
+		name string
+		b    testBox
+		want bool
+	}{
+		{"重叠", testBox{left: 5, right: 15, top: 5, bottom: 15}, true},
+		{"包含", testBox{left: 2, right: 8, top: 2, bottom: 8}, true},
+		{"被包含", testBox{left: -5, right: 15, top: -5, bottom: 15}, true},
+		{"右边缘接触", testBox{left: 10, right: 20, top: 0, bottom: 10}, false},
+		{"下边缘接触", testBox{left: 0, right: 10, top: 10, bottom: 20}, false},
+		{"水平分开", testBox{left: 11, right: 20, top: 0, bottom: 10}, false},
+		{"只有水平重叠", testBox{left: 0, right: 10, top: 20, bottom: 30}, false},
+	}
+	for _, tt := range tests {
+		if got := CheckCollision(a, tt.b); got != tt.want {
+			t.Errorf("%s: CheckCollision(%s, %s) = %t，应为 %t", tt.name, a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// TestSegmentIntersectsBox 穿过、停在盒子前、平行于边和起点在盒子里的线段
+func TestSegmentIntersectsBox(t *testing.T) {
+	box := testBox{left: 10, right: 20, top: 10, bottom: 20}
+	tests := []struct {
+		name           string
+		x0, y0, x1, y1 float64
+		want           bool
+	}{
+		{"水平穿过", 0, 15, 30, 15, true},
+		{"斜着穿过", 0, 0, 30, 30, true},
+		{"反方向穿过", 30, 15, 0, 15, true},
+		{"停在盒子前", 0, 15, 9, 15, false},
+		{"从盒子上方经过", 0, 5, 30, 5, false},
+		{"沿着边", 0, 10, 30, 10, false},
+		{"起点在盒子里", 15, 15, 40, 40, true},
+		{"一个点在盒子里", 15, 15, 15, 15, true},
+		{"斜着错过角", 0, 15, 15, 0, false},
+	}
+	for _, tt := range tests {
+		if got := SegmentIntersectsBox(tt.x0, tt.y0, tt.x1, tt.y1, box); got != tt.want {
+			t.Errorf("%s: (%g, %g)-(%g, %g) = %t，应为 %t", tt.name, tt.x0, tt.y0, tt.x1, tt.y1, got, tt.want)
+		}
+	}
+}
+
+// TestCheckCollisionProperties 用随机的碰撞盒检查 CheckCollision 的性质，改写碰撞检测（例如扫掠 AABB）时锁定现有的行为
+//   - 对称：CheckCollision(a, b) == CheckCollision(b, a)
+//   - 分开：把 b 移到 a 的任意一边，只隔 epsilon 或边缘重合时都不碰撞
+//   - 包含：b 缩小后完全放在 a 里面时碰撞（两个方向都是）
+func TestCheckCollisionProperties(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < collisionPropertyCases; i++ {
+		a, b := randomTestBox(random), randomTestBox(random)
+		if CheckCollision(a, b) != CheckCollision(b, a) {
+			t.Fatalf("不对称: a %s  b %s", a, b)
+		}
+
+		width, height := b.right-b.left, b.bottom-b.top
+		for _, gap := range []float64{0, testBoxEpsilon} {
+			sides := []testBox{
+				{left: a.right + gap, right: a.right + gap + width, top: b.top, bottom: b.bottom},
+				{left: a.left - gap - width, right: a.left - gap, top: b.top, bottom: b.bottom},
+				{left: b.left, right: b.right, top: a.bottom + gap, bottom: a.bottom + gap + height},
+				{left: b.left, right: b.right, top: a.top - gap - height, bottom: a.top - gap},
+			}
+			for _, side := range sides {
+				if CheckCollision(a, side) || CheckCollision(side, a) {
+					t.Fatalf("间隔 %g 时碰撞: a %s  b %s", gap, a, side)
+				}
+			}
+		}
+
+		// b 缩小到 a 的尺寸以内（至少留出 epsilon），放在 a 里面的随机位置
+		innerWidth := (a.right - a.left) * (0.01 + random.Float64()*0.98)
+		innerHeight := (a.bottom - a.top) * (0.01 + random.Float64()*0.98)
+		innerLeft := a.left + (a.right-a.left-innerWidth)*random.Float64()
+		innerTop := a.top + (a.bottom-a.top-innerHeight)*random.Float64()
+		inner := testBox{left: innerLeft, right: innerLeft + innerWidth, top: innerTop, bottom: innerTop + innerHeight}
+		if !CheckCollision(a, inner) || !CheckCollision(inner, a) {
+			t.Fatalf("包含时没有碰撞: a %s  b %s", a, inner)
+		}
+	}
+}
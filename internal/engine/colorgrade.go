@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// LUTSize 颜色查找表每个通道的级数
+// 查找表图片为 LUTSize 个 LUTSize×LUTSize 的切片横向排列（宽 LUTSize*LUTSize，高 LUTSize），
+// 切片序号为蓝色，切片内 X 为红色、Y 为绿色
+const LUTSize = 32
+
+// colorGradeShaderSource 按查找表调色的 Kage 着色器（像素单位）
+// 红色和绿色取最近的格子，蓝色在相邻两个切片之间插值
+const colorGradeShaderSource = `//kage:unit pixels
+
+package main
+
+var Strength float
+var Size float
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	c := imageSrc0At(srcPos)
+	if c.a == 0 {
+		return c
+	}
+	rgb := clamp(c.rgb/c.a, 0, 1)
+	maxIndex := Size - 1
+	b := rgb.b * maxIndex
+	b0 := floor(b)
+	b1 := min(b0+1, maxIndex)
+	rg := floor(rgb.rg*maxIndex+0.5) + 0.5
+	origin := imageSrc1Origin()
+	c0 := imageSrc1At(origin + vec2(b0*Size+rg.x, rg.y)).rgb
+	c1 := imageSrc1At(origin + vec2(b1*Size+rg.x, rg.y)).rgb
+	graded := mix(c0, c1, b-b0)
+	return vec4(mix(rgb, graded, Strength)*c.a, c.a)
+}
+`
+
+// colorGradeShader 编译后的调色着色器（第一次创建 ColorGrading 时编译）
+var colorGradeShader *ebiten.Shader
+
+// ColorGrade 生成查找表的调色参数，零值表示不改变颜色
+type ColorGrade struct {
+	Temperature float64 `json:"temperature"` // 色温：正数偏暖（加红减蓝），负数偏冷
+	Saturation  float64 `json:"saturation"`  // 饱和度调整：-1 为灰度，正数更鲜艳
+	Contrast    float64 `json:"contrast"`    // 对比度调整：正数增强，负数减弱
+	Brightness  float64 `json:"brightness"`  // 亮度偏移
+}
+
+// apply 对一个颜色（0～1）应用调色参数
+func (g ColorGrade) apply(r, gr, b float64) (float64, float64, float64) {
+	r += g.Temperature * 0.1
+	b -= g.Temperature * 0.1
+
+	luma := 0.299*r + 0.587*gr + 0.114*b
+	saturation := 1 + g.Saturation
+	r = luma + (r-luma)*saturation
+	gr = luma + (gr-luma)*saturation
+	b = luma + (b-luma)*saturation
+
+	contrast := 1 + g.Contrast
+	r = (r-0.5)*contrast + 0.5 + g.Brightness
+	gr = (gr-0.5)*contrast + 0.5 + g.Brightness
+	b = (b-0.5)*contrast + 0.5 + g.Brightness
+	return r, gr, b
+}
+
+// BakeLUT 按调色参数生成查找表图片
+func BakeLUT(grade ColorGrade) *ebiten.Image {
+	img := image.NewRGBA(image.Rect(0, 0, LUTSize*LUTSize, LUTSize))
+	toByte := func(v float64) uint8 {
+		return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+	}
+	for bi := 0; bi < LUTSize; bi++ {
+		for gi := 0; gi < LUTSize; gi++ {
+			for ri := 0; ri < LUTSize; ri++ {
+				r, g, b := grade.apply(float64(ri)/(LUTSize-1), float64(gi)/(LUTSize-1), float64(bi)/(LUTSize-1))
+				img.SetRGBA(bi*LUTSize+ri, gi, color.RGBA{R: toByte(r), G: toByte(g), B: toByte(b), A: 255})
+			}
+		}
+	}
+	return ebiten.NewImageFromImage(img)
+}
+
+// LoadLUT 从图片文件加载查找表（布局见 LUTSize）
+func LoadLUT(path string) (*ebiten.Image, error) {
+	img, _, err := ebitenutil.NewImageFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if size := img.Bounds().Size(); size != image.Pt(LUTSize*LUTSize, LUTSize) {
+		return nil, fmt.Errorf("查找表 %s 尺寸为 %dx%d，应为 %dx%d", path, size.X, size.Y, LUTSize*LUTSize, LUTSize)
+	}
+	return img, nil
+}
+
+// ColorGrading 基于查找表的调色后期效果
+type ColorGrading struct {
+	lut      *ebiten.Image
+	Strength float32 // 调色强度：0 为原色，1 为完全使用查找表的颜色
+}
+
+// NewColorGrading 创建调色效果
+func NewColorGrading(lut *ebiten.Image, strength float32) (*ColorGrading, error) {
+	if colorGradeShader == nil {
+		shader, err := ebiten.NewShader([]byte(colorGradeShaderSource))
+		if err != nil {
+			return nil, fmt.Errorf("编译调色着色器失败: %w", err)
+		}
+		colorGradeShader = shader
+	}
+	return &ColorGrading{lut: lut, Strength: strength}, nil
+}
+
+// Apply 把 src 调色后绘制到 dst 的左上角
+// flipX: 是否水平翻转
+func (c *ColorGrading) Apply(dst, src *ebiten.Image, flipX bool) {
+	bounds := src.Bounds()
+	w, h := float32(bounds.Dx()), float32(bounds.Dy())
+	left, right := float32(0), w
+	if flipX {
+		left, right = right, left
+	}
+	vertices := []ebiten.Vertex{
+		{DstX: left, DstY: 0, SrcX: 0, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: right, DstY: 0, SrcX: w, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: left, DstY: h, SrcX: 0, SrcY: h, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: right, DstY: h, SrcX: w, SrcY: h, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+	}
+	indices := []uint16{0, 1, 2, 1, 2, 3}
+
+	op := &ebiten.DrawTrianglesShaderOptions{}
+	op.Images[0] = src
+	op.Images[1] = c.lut
+	op.Uniforms = map[string]any{
+		"Strength": c.Strength,
+		"Size":     float32(LUTSize),
+	}
+	dst.DrawTrianglesShader(vertices, indices, colorGradeShader, op)
+}
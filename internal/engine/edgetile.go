@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// EdgeTile 由中间图块生成端点图块：在露出的一侧把顶角切成圆角，并把该侧边缘逐渐加深
+// left/right: 左侧/右侧是否露出（没有相邻的图块）
+// radius: 圆角半径（像素），加深的宽度与圆角半径相同
+// shade: 边缘最暗处的亮度倍数（0～1）
+func EdgeTile(src image.Image, left, right bool, radius int, shade float64) *image.NRGBA {
+	bounds := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Rect, src, bounds.Min, draw.Src)
+	if radius <= 0 {
+		return dst
+	}
+
+	width := dst.Rect.Dx()
+	r := float64(radius)
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		for x := 0; x < width; x++ {
+			// 到露出一侧的距离（两侧都露出时取较近的一侧）
+			d := math.Inf(1)
+			if left {
+				d = float64(x)
+			}
+			if right {
+				d = min(d, float64(width-1-x))
+			}
+			if d >= r {
+				continue
+			}
+
+			i := dst.PixOffset(x, y)
+			alpha := 1.0
+			if float64(y) < r {
+				// 圆角：到圆心的距离超过半径的部分透明，边缘 1 像素抗锯齿
+				dist := math.Hypot(r-d-0.5, r-float64(y)-0.5)
+				alpha = min(max(r-dist, 0), 1)
+			}
+			factor := shade + (1-shade)*d/r
+			dst.Pix[i] = uint8(float64(dst.Pix[i]) * factor)
+			dst.Pix[i+1] = uint8(float64(dst.Pix[i+1]) * factor)
+			dst.Pix[i+2] = uint8(float64(dst.Pix[i+2]) * factor)
+			dst.Pix[i+3] = uint8(float64(dst.Pix[i+3]) * alpha)
+		}
+	}
+	return dst
+}
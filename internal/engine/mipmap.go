@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// Downscale 在加载时把图片缩小到 scale 倍（0 < scale < 1），避免运行时双线性缩小造成的锯齿和模糊
+// 先逐级减半生成 mipmap（每级取 2×2 像素的平均值），直到再减半就会小于目标尺寸，
+// 再从这一级双线性采样到目标尺寸；像素按预乘透明度计算，透明边缘不会发黑
+func Downscale(src image.Image, scale float64) *image.RGBA {
+	bounds := src.Bounds()
+	level := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(level, level.Bounds(), src, bounds.Min, draw.Src)
+
+	width := max(int(math.Round(float64(bounds.Dx())*scale)), 1)
+	height := max(int(math.Round(float64(bounds.Dy())*scale)), 1)
+	for level.Rect.Dx()/2 >= width && level.Rect.Dy()/2 >= height {
+		level = halve(level)
+	}
+	if level.Rect.Dx() == width && level.Rect.Dy() == height {
+		return level
+	}
+	return resample(level, width, height)
+}
+
+// halve 把图片缩小一半，每个像素取对应 2×2 像素的平均值（奇数尺寸时丢弃最后一行/列）
+func halve(src *image.RGBA) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, src.Rect.Dx()/2, src.Rect.Dy()/2))
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			i0 := src.PixOffset(x*2, y*2)
+			i1 := i0 + src.Stride
+			o := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				sum := int(src.Pix[i0+c]) + int(src.Pix[i0+4+c]) + int(src.Pix[i1+c]) + int(src.Pix[i1+4+c])
+				dst.Pix[o+c] = uint8((sum + 2) / 4)
+			}
+		}
+	}
+	return dst
+}
+
+// resample 双线性采样到指定尺寸（只用于缩小不到一半的最后一步）
+func resample(src *image.RGBA, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	sx := float64(src.Rect.Dx()) / float64(width)
+	sy := float64(src.Rect.Dy()) / float64(height)
+	maxX, maxY := src.Rect.Dx()-1, src.Rect.Dy()-1
+	for y := 0; y < height; y++ {
+		fy := min(max((float64(y)+0.5)*sy-0.5, 0), float64(maxY))
+		y0 := int(fy)
+		y1 := min(y0+1, maxY)
+		ty := fy - float64(y0)
+		for x := 0; x < width; x++ {
+			fx := min(max((float64(x)+0.5)*sx-0.5, 0), float64(maxX))
+			x0 := int(fx)
+			x1 := min(x0+1, maxX)
+			tx := fx - float64(x0)
+
+			p00, p10 := src.PixOffset(x0, y0), src.PixOffset(x1, y0)
+			p01, p11 := src.PixOffset(x0, y1), src.PixOffset(x1, y1)
+			o := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				top := float64(src.Pix[p00+c])*(1-tx) + float64(src.Pix[p10+c])*tx
+				bottom := float64(src.Pix[p01+c])*(1-tx) + float64(src.Pix[p11+c])*tx
+				dst.Pix[o+c] = uint8(top*(1-ty) + bottom*ty + 0.5)
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// PaletteSwap 一组换色：与 From 相近的颜色换成 To
+// 相近的颜色保持与 From 的差值（例如同一色相的阴影和高光一起换色），不会变成一块平涂色
+type PaletteSwap struct {
+	From      color.NRGBA // 原色
+	To        color.NRGBA // 目标色
+	Tolerance int         // 容差：每个通道与原色的差都不超过容差时换色
+}
+
+// Recolor 按换色表为图片换色（在加载时对整张精灵表执行一次），透明度不变
+// 一个像素匹配多组换色时使用第一组
+func Recolor(src image.Image, swaps []PaletteSwap) *image.NRGBA {
+	bounds := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Rect, src, bounds.Min, draw.Src)
+
+	for i := 0; i < len(dst.Pix); i += 4 {
+		if dst.Pix[i+3] == 0 {
+			continue
+		}
+		r, g, b := int(dst.Pix[i]), int(dst.Pix[i+1]), int(dst.Pix[i+2])
+		for _, swap := range swaps {
+			dr, dg, db := r-int(swap.From.R), g-int(swap.From.G), b-int(swap.From.B)
+			if abs(dr) > swap.Tolerance || abs(dg) > swap.Tolerance || abs(db) > swap.Tolerance {
+				continue
+			}
+			dst.Pix[i] = clampByte(int(swap.To.R) + dr)
+			dst.Pix[i+1] = clampByte(int(swap.To.G) + dg)
+			dst.Pix[i+2] = clampByte(int(swap.To.B) + db)
+			break
+		}
+	}
+	return dst
+}
+
+// abs 整数绝对值
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// clampByte 把整数限制在 0～255
+func clampByte(n int) uint8 {
+	return uint8(min(max(n, 0), 255))
+}
@@ -0,0 +1,161 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"sync"
+)
+
+// PlaylistMode 背景音乐播放列表的播放顺序
+type PlaylistMode int
+
+const (
+	PlaylistSequential PlaylistMode = iota // 按列表顺序循环播放
+	PlaylistShuffle                        // 每轮打乱顺序播放（新一轮的第一首不会与上一首相同）
+)
+
+// playlistModeNames 播放顺序名称（数据文件中使用）
+var playlistModeNames = map[PlaylistMode]string{
+	PlaylistSequential: "sequential",
+	PlaylistShuffle:    "shuffle",
+}
+
+// String 返回播放顺序名称
+func (m PlaylistMode) String() string {
+	return playlistModeNames[m]
+}
+
+// ParsePlaylistMode 根据名称解析播放顺序，空字符串为按顺序播放
+func ParsePlaylistMode(name string) (PlaylistMode, error) {
+	if name == "" {
+		return PlaylistSequential, nil
+	}
+	for mode, modeName := range playlistModeNames {
+		if strings.EqualFold(name, modeName) {
+			return mode, nil
+		}
+	}
+	return PlaylistSequential, fmt.Errorf("未知的播放顺序: %s", name)
+}
+
+// playlistStream 把播放列表中的曲目首尾相接成一条音频流，由同一个播放器播放，曲目之间没有间隙
+// 音频线程调用 Read；下一首由游戏线程在 update 中提前解码好，Read 读完当前曲目后直接接上
+type playlistStream struct {
+	// 以下字段只在游戏线程中使用
+	tracks     []string
+	mode       PlaylistMode
+	sampleRate int    // 输出采样率（曲目解码时重采样到这个采样率，首尾相接时不会变调）
+	order      []int  // 本轮的播放顺序（tracks 的下标）
+	pos        int    // 下一首要准备的曲目在 order 中的位置
+	last       string // 最近准备的曲目（新一轮打乱顺序时避免重复）
+
+	mu          sync.Mutex // 保护以下字段（音频线程和游戏线程共用）
+	current     io.Reader  // 正在播放的曲目
+	currentPath string
+	next        io.Reader // 提前解码好的下一首（为 nil 时还没有准备好）
+	nextPath    string
+	started     bool // 是否切换到了新的曲目（游戏线程取走后清除）
+}
+
+// newPlaylistStream 创建播放列表音频流，解码第一首曲目
+// 解码失败的曲目从列表中移除，所有曲目都失败时返回错误
+func newPlaylistStream(tracks []string, mode PlaylistMode, sampleRate int) (*playlistStream, error) {
+	s := &playlistStream{tracks: append([]string(nil), tracks...), mode: mode, sampleRate: sampleRate}
+	stream, path := s.prepare()
+	if stream == nil {
+		return nil, fmt.Errorf("播放列表中没有可以播放的曲目")
+	}
+	s.current, s.currentPath, s.started = stream, path, true
+	return s, nil
+}
+
+// newOrder 生成一轮的播放顺序；随机顺序时避免第一首与上一轮的最后一首相同
+func (s *playlistStream) newOrder() []int {
+	order := make([]int, len(s.tracks))
+	for i := range order {
+		order[i] = i
+	}
+	if s.mode == PlaylistShuffle {
+		rand.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		if len(order) > 1 && s.tracks[order[0]] == s.last {
+			order[0], order[1] = order[1], order[0]
+		}
+	}
+	return order
+}
+
+// prepare 按播放顺序解码下一首曲目（在游戏线程中调用）
+// 解码失败的曲目从列表中移除，列表为空时返回 nil
+func (s *playlistStream) prepare() (io.Reader, string) {
+	for len(s.tracks) > 0 {
+		if s.pos >= len(s.order) {
+			s.order = s.newOrder()
+			s.pos = 0
+		}
+		index := s.order[s.pos]
+		path := s.tracks[index]
+		stream, _, err := decodeFile(path, s.sampleRate)
+		if err == nil {
+			s.pos++
+			s.last = path
+			return stream, path
+		}
+		log.Printf("警告: 无法加载背景音乐 %s，从播放列表中移除: %v", path, err)
+		s.tracks = append(s.tracks[:index], s.tracks[index+1:]...)
+		s.order, s.pos = nil, 0
+	}
+	return nil, ""
+}
+
+// Read 读取当前曲目，读完后接上下一首；下一首还没准备好时输出静音，不让播放器停止
+func (s *playlistStream) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		n, err := s.current.Read(p)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if err == nil {
+			continue
+		}
+		if s.next == nil {
+			// 16 位立体声，每个采样 4 字节
+			n = len(p) / 4 * 4
+			clear(p[:n])
+			return n, nil
+		}
+		s.current, s.currentPath = s.next, s.nextPath
+		s.next = nil
+		s.started = true
+	}
+}
+
+// update 在游戏线程中提前解码下一首，返回新开始播放的曲目路径（没有切换时返回空字符串）
+func (s *playlistStream) update() string {
+	s.mu.Lock()
+	started := ""
+	if s.started {
+		s.started = false
+		started = s.currentPath
+	}
+	needNext := s.next == nil
+	s.mu.Unlock()
+
+	// 下一首在锁外解码（读文件较慢，不阻塞音频线程）
+	if needNext {
+		if stream, path := s.prepare(); stream != nil {
+			s.mu.Lock()
+			s.next, s.nextPath = stream, path
+			s.mu.Unlock()
+		}
+	}
+	return started
+}
@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 一次 DrawTriangles 调用最多提交的四边形数（16 位下标最多引用 65536 个顶点）
+const maxBatchQuads = (1 << 16) / 4
+
+// renderItem 渲染队列中的一个绘制项
+type renderItem struct {
+	layer      int
+	order      int // 加入队列的顺序（同一层、同一张图片内保持加入顺序）
+	image      *ebiten.Image
+	geom       ebiten.GeoM
+	colorScale ebiten.ColorScale
+}
+
+// RenderQueue 渲染队列：收集一帧中要绘制的图片，按层、再按图片排序后合批提交
+// 同一层中使用同一张图片的绘制项合并成一次 DrawTriangles 调用（共享绘制选项，顶点中带各自的变换和颜色），
+// 实体数量增加时减少每个实体单独 DrawImage 的开销；层之间保持先后顺序，层内不同图片的先后顺序不保证
+type RenderQueue struct {
+	items    []renderItem
+	vertices []ebiten.Vertex
+	indices  []uint16
+}
+
+// NewRenderQueue 创建渲染队列
+func NewRenderQueue() *RenderQueue {
+	return &RenderQueue{}
+}
+
+// Add 加入一个绘制项
+// layer: 绘制层（小的先画）
+// image: 图片（可以是子图片，按图片指针合批）
+// geom: 图片左上角到目标图片的变换（与 DrawImageOptions.GeoM 相同）
+// colorScale: 颜色调整（与 DrawImageOptions.ColorScale 相同，零值为不调整）
+func (q *RenderQueue) Add(layer int, image *ebiten.Image, geom ebiten.GeoM, colorScale ebiten.ColorScale) {
+	q.items = append(q.items, renderItem{
+		layer:      layer,
+		order:      len(q.items),
+		image:      image,
+		geom:       geom,
+		colorScale: colorScale,
+	})
+}
+
+// Len 返回队列中的绘制项数量
+func (q *RenderQueue) Len() int {
+	return len(q.items)
+}
+
+// Flush 把队列中的绘制项排序、合批绘制到 dst 并清空队列，返回 DrawTriangles 的调用次数
+func (q *RenderQueue) Flush(dst *ebiten.Image) int {
+	if len(q.items) == 0 {
+		return 0
+	}
+
+	// 图片按第一次出现的顺序编号，同一层内按编号排序，相同图片的绘制项排在一起
+	imageOrder := make(map[*ebiten.Image]int)
+	for _, item := range q.items {
+		if _, ok := imageOrder[item.image]; !ok {
+			imageOrder[item.image] = len(imageOrder)
+		}
+	}
+	sort.Slice(q.items, func(i, j int) bool {
+		a, b := &q.items[i], &q.items[j]
+		if a.layer != b.layer {
+			return a.layer < b.layer
+		}
+		if a.image != b.image {
+			return imageOrder[a.image] < imageOrder[b.image]
+		}
+		return a.order < b.order
+	})
+
+	batches := 0
+	op := &ebiten.DrawTrianglesOptions{ColorScaleMode: ebiten.ColorScaleModePremultipliedAlpha}
+	for start := 0; start < len(q.items); {
+		image := q.items[start].image
+		layer := q.items[start].layer
+		end := start + 1
+		for end < len(q.items) && end-start < maxBatchQuads && q.items[end].image == image && q.items[end].layer == layer {
+			end++
+		}
+
+		q.vertices = q.vertices[:0]
+		q.indices = q.indices[:0]
+		for _, item := range q.items[start:end] {
+			q.appendQuad(&item)
+		}
+		dst.DrawTriangles(q.vertices, q.indices, image, op)
+		batches++
+		start = end
+	}
+	q.items = q.items[:0]
+	return batches
+}
+
+// appendQuad 把一个绘制项的四个顶点和两个三角形加入当前批次
+func (q *RenderQueue) appendQuad(item *renderItem) {
+	bounds := item.image.Bounds()
+	base := uint16(len(q.vertices))
+	r, g, b, a := item.colorScale.R(), item.colorScale.G(), item.colorScale.B(), item.colorScale.A()
+	corners := [4][2]int{
+		{bounds.Min.X, bounds.Min.Y},
+		{bounds.Max.X, bounds.Min.Y},
+		{bounds.Min.X, bounds.Max.Y},
+		{bounds.Max.X, bounds.Max.Y},
+	}
+	for _, c := range corners {
+		// GeoM 以图片左上角为原点，子图片的源坐标需要减去左上角
+		x, y := item.geom.Apply(float64(c[0]-bounds.Min.X), float64(c[1]-bounds.Min.Y))
+		q.vertices = append(q.vertices, ebiten.Vertex{
+			DstX:   float32(x),
+			DstY:   float32(y),
+			SrcX:   float32(c[0]),
+			SrcY:   float32(c[1]),
+			ColorR: r,
+			ColorG: g,
+			ColorB: b,
+			ColorA: a,
+		})
+	}
+	q.indices = append(q.indices, base, base+1, base+2, base+1, base+3, base+2)
+}
@@ -0,0 +1,51 @@
+package engine
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// ResourceCounts 需要显式释放的资源数量：音频管理器创建的播放器、资源管理器登记的图片
+type ResourceCounts struct {
+	AudioPlayers int
+	Images       int
+}
+
+// liveResources 当前没有释放的资源数量
+var liveResources ResourceCounts
+
+// LiveResources 返回当前没有释放的资源数量（检查重新开始、场景切换之后是否泄漏）
+func LiveResources() ResourceCounts {
+	return liveResources
+}
+
+// ResourceManager 一个场景（例如一局游戏）拥有的图片：登记后在 Dispose 时统一释放显存
+// 图片本来也会被垃圾回收释放，显式释放让重新开始时的显存占用不依赖 GC 的时机，并且可以统计是否有遗漏
+type ResourceManager struct {
+	images []*ebiten.Image
+}
+
+// NewResourceManager 创建资源管理器
+func NewResourceManager() *ResourceManager {
+	return &ResourceManager{}
+}
+
+// Track 登记图片（nil 时忽略），返回同一张图片
+func (m *ResourceManager) Track(img *ebiten.Image) *ebiten.Image {
+	if img != nil {
+		m.images = append(m.images, img)
+		liveResources.Images++
+	}
+	return img
+}
+
+// NewImage 创建并登记图片
+func (m *ResourceManager) NewImage(width, height int) *ebiten.Image {
+	return m.Track(ebiten.NewImage(width, height))
+}
+
+// Dispose 释放所有登记的图片，可以重复调用
+func (m *ResourceManager) Dispose() {
+	for _, img := range m.images {
+		img.Deallocate()
+	}
+	liveResources.Images -= len(m.images)
+	m.images = nil
+}
@@ -0,0 +1,240 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// BoneDef 骨骼定义：设置姿势下相对父骨骼的位移、旋转和缩放
+// 骨骼按父骨骼在前的顺序排列，第一个骨骼通常是位于角色原点（底部中心）的根骨骼
+type BoneDef struct {
+	Name     string  `json:"name"`
+	Parent   string  `json:"parent"`   // 父骨骼名称，为空时相对角色原点
+	X        float64 `json:"x"`        // 相对父骨骼的位移（像素）
+	Y        float64 `json:"y"`        // 相对父骨骼的位移（像素，向下为正）
+	Rotation float64 `json:"rotation"` // 相对父骨骼的旋转（角度，顺时针为正）
+	ScaleX   float64 `json:"scaleX"`   // 水平缩放，0 表示不缩放
+	ScaleY   float64 `json:"scaleY"`   // 垂直缩放，0 表示不缩放
+}
+
+// SlotDef 挂在骨骼上的图片（按数组顺序绘制，后面的画在上面）
+type SlotDef struct {
+	Name      string  `json:"name"`
+	Bone      string  `json:"bone"`     // 所在骨骼
+	ImagePath string  `json:"image"`    // 图片路径
+	X         float64 `json:"x"`        // 图片轴心在骨骼坐标系中的位置
+	Y         float64 `json:"y"`        // 图片轴心在骨骼坐标系中的位置
+	Rotation  float64 `json:"rotation"` // 图片相对骨骼的旋转（角度）
+	PivotX    float64 `json:"pivotX"`   // 轴心在图片中的位置（像素）
+	PivotY    float64 `json:"pivotY"`   // 轴心在图片中的位置（像素）
+
+	image *ebiten.Image
+}
+
+// BoneKey 骨骼关键帧：在设置姿势的基础上叠加的位移和旋转、乘上的缩放
+type BoneKey struct {
+	Time     float64 `json:"time"` // 时间（秒）
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Rotation float64 `json:"rotation"`
+	ScaleX   float64 `json:"scaleX"` // 0 表示不缩放
+	ScaleY   float64 `json:"scaleY"` // 0 表示不缩放
+}
+
+// SkeletonClip 骨骼动画片段：每个骨骼一条关键帧时间线，关键帧之间线性插值
+type SkeletonClip struct {
+	Duration float64              `json:"duration"` // 时长（秒）
+	Bones    map[string][]BoneKey `json:"bones"`    // 骨骼名称 → 关键帧（按时间排序）
+
+	timelines [][]BoneKey // 按骨骼下标排列的关键帧（没有时间线的骨骼为空）
+}
+
+// SkeletonData 骨骼动画数据（从 JSON 文件加载，多个 Skeleton 实例共享）
+// 只支持骨骼的位移、旋转、缩放和挂在骨骼上的图片，相比逐帧精灵表只需要保存各个部件的图片
+type SkeletonData struct {
+	Bones []*BoneDef               `json:"bones"`
+	Slots []*SlotDef               `json:"slots"`
+	Clips map[string]*SkeletonClip `json:"animations"` // 片段名称 → 片段
+
+	parents []int // 每个骨骼的父骨骼下标（根骨骼为 -1）
+	slots   []int // 每个图片所在骨骼的下标
+}
+
+// LoadSkeleton 从 JSON 文件加载骨骼动画数据，并加载所有部件图片
+func LoadSkeleton(path string) (*SkeletonData, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var data SkeletonData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("解析骨骼动画失败: %w", err)
+	}
+	if len(data.Bones) == 0 {
+		return nil, fmt.Errorf("骨骼动画没有骨骼: %s", path)
+	}
+
+	// 骨骼名称 → 下标，父骨骼必须排在前面（计算世界变换时按顺序一次完成）
+	index := make(map[string]int, len(data.Bones))
+	for i, bone := range data.Bones {
+		if _, ok := index[bone.Name]; ok {
+			return nil, fmt.Errorf("骨骼名称重复: %s", bone.Name)
+		}
+		parent := -1
+		if bone.Parent != "" {
+			p, ok := index[bone.Parent]
+			if !ok {
+				return nil, fmt.Errorf("骨骼 %s 的父骨骼 %s 不存在或排在后面", bone.Name, bone.Parent)
+			}
+			parent = p
+		}
+		index[bone.Name] = i
+		data.parents = append(data.parents, parent)
+	}
+
+	for _, slot := range data.Slots {
+		bone, ok := index[slot.Bone]
+		if !ok {
+			return nil, fmt.Errorf("图片 %s 所在的骨骼 %s 不存在", slot.Name, slot.Bone)
+		}
+		data.slots = append(data.slots, bone)
+		slot.image, _, err = ebitenutil.NewImageFromFile(slot.ImagePath)
+		if err != nil {
+			return nil, fmt.Errorf("加载骨骼动画图片 %s 失败: %w", slot.Name, err)
+		}
+	}
+
+	for name, clip := range data.Clips {
+		if clip.Duration <= 0 {
+			return nil, fmt.Errorf("骨骼动画片段 %s 的时长必须大于 0", name)
+		}
+		clip.timelines = make([][]BoneKey, len(data.Bones))
+		for boneName, keys := range clip.Bones {
+			bone, ok := index[boneName]
+			if !ok {
+				return nil, fmt.Errorf("骨骼动画片段 %s 中的骨骼 %s 不存在", name, boneName)
+			}
+			sort.Slice(keys, func(i, j int) bool { return keys[i].Time < keys[j].Time })
+			clip.timelines[bone] = keys
+		}
+	}
+	return &data, nil
+}
+
+// Skeleton 骨骼动画实例：按片段和播放进度计算骨骼姿势并绘制
+type Skeleton struct {
+	data  *SkeletonData
+	world []ebiten.GeoM // 每个骨骼的世界变换（相对角色原点，Pose 时重新计算）
+}
+
+// NewSkeleton 创建骨骼动画实例（设置姿势）
+func NewSkeleton(data *SkeletonData) *Skeleton {
+	s := &Skeleton{data: data, world: make([]ebiten.GeoM, len(data.Bones))}
+	s.pose(nil, 0)
+	return s
+}
+
+// HasClip 是否有指定名称的片段
+func (s *Skeleton) HasClip(name string) bool {
+	return s.data.Clips[name] != nil
+}
+
+// Pose 把骨骼摆成片段在播放进度 progress（0 到 1）时的姿势，片段不存在时返回 false（姿势不变）
+// 播放进度由使用方的动画控制器提供，骨骼动画的循环、倒放和速度与精灵表动画保持一致
+func (s *Skeleton) Pose(name string, progress float64) bool {
+	clip := s.data.Clips[name]
+	if clip == nil {
+		return false
+	}
+	s.pose(clip, min(max(progress, 0), 1)*clip.Duration)
+	return true
+}
+
+// pose 计算 time 秒时每个骨骼的世界变换（clip 为 nil 时使用设置姿势）
+func (s *Skeleton) pose(clip *SkeletonClip, time float64) {
+	for i, bone := range s.data.Bones {
+		x, y, rotation := bone.X, bone.Y, bone.Rotation
+		scaleX, scaleY := orOne(bone.ScaleX), orOne(bone.ScaleY)
+		if clip != nil {
+			if key, ok := sampleBoneKeys(clip.timelines[i], time); ok {
+				x += key.X
+				y += key.Y
+				rotation += key.Rotation
+				scaleX *= orOne(key.ScaleX)
+				scaleY *= orOne(key.ScaleY)
+			}
+		}
+
+		// 局部变换：先缩放、再旋转、最后移动到父骨骼坐标系中的位置，再接上父骨骼的世界变换
+		var m ebiten.GeoM
+		m.Scale(scaleX, scaleY)
+		m.Rotate(rotation * math.Pi / 180)
+		m.Translate(x, y)
+		if parent := s.data.parents[i]; parent >= 0 {
+			m.Concat(s.world[parent])
+		}
+		s.world[i] = m
+	}
+}
+
+// sampleBoneKeys 在关键帧之间线性插值，时间在第一个关键帧之前或最后一个之后时使用端点的值
+func sampleBoneKeys(keys []BoneKey, time float64) (BoneKey, bool) {
+	if len(keys) == 0 {
+		return BoneKey{}, false
+	}
+	if time <= keys[0].Time {
+		return keys[0], true
+	}
+	for i := 1; i < len(keys); i++ {
+		next := keys[i]
+		if time > next.Time {
+			continue
+		}
+		prev := keys[i-1]
+		t := 0.0
+		if next.Time > prev.Time {
+			t = (time - prev.Time) / (next.Time - prev.Time)
+		}
+		lerp := func(a, b float64) float64 { return a + (b-a)*t }
+		return BoneKey{
+			Time:     time,
+			X:        lerp(prev.X, next.X),
+			Y:        lerp(prev.Y, next.Y),
+			Rotation: lerp(prev.Rotation, next.Rotation),
+			ScaleX:   lerp(orOne(prev.ScaleX), orOne(next.ScaleX)),
+			ScaleY:   lerp(orOne(prev.ScaleY), orOne(next.ScaleY)),
+		}, true
+	}
+	return keys[len(keys)-1], true
+}
+
+// orOne 未设置（为 0）的缩放按 1 处理
+func orOne(scale float64) float64 {
+	if scale == 0 {
+		return 1
+	}
+	return scale
+}
+
+// Draw 按当前姿势绘制所有图片
+// geom: 角色原点（根骨骼坐标系原点）到屏幕的变换（包括水平翻转和屏幕位置）
+// colorScale: 所有图片共用的颜色调整（例如淡出）
+func (s *Skeleton) Draw(screen *ebiten.Image, geom ebiten.GeoM, colorScale ebiten.ColorScale) {
+	op := &ebiten.DrawImageOptions{ColorScale: colorScale}
+	op.Filter = ebiten.FilterLinear
+	for i, slot := range s.data.Slots {
+		op.GeoM.Reset()
+		op.GeoM.Translate(-slot.PivotX, -slot.PivotY)
+		op.GeoM.Rotate(slot.Rotation * math.Pi / 180)
+		op.GeoM.Translate(slot.X, slot.Y)
+		op.GeoM.Concat(s.world[s.data.slots[i]])
+		op.GeoM.Concat(geom)
+		screen.DrawImage(slot.image, op)
+	}
+}
@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"slices"
+	"sort"
+)
+
+const (
+	// 默认的纹理预算（字节）：常驻显存的精灵表总量超过预算时卸载最久没有使用的延迟加载动画
+	// 玩家常用的动画（闲置、移动、跳跃）约 24 MiB，死亡和飞行动画只在用到时常驻
+	defaultTextureBudget = 32 << 20
+	// 延迟加载的动画至少多少帧没有使用才会被卸载（避免刚用过又马上要用的动画来回加载）
+	textureIdleFrames = 10 * 60
+)
+
+// TextureStats 纹理预算的统计（调试界面显示）
+type TextureStats struct {
+	Resident   int64 // 常驻显存的精灵表占用的字节数
+	Peak       int64 // 常驻字节数的峰值
+	Budget     int64 // 预算（字节）
+	Animations int   // 动画总数
+	Loaded     int   // 已加载的动画数
+	Lazy       int   // 延迟加载的动画数
+	Loads      int   // 延迟加载的动画累计加载次数（包括卸载后重新加载）
+	Unloads    int   // 累计卸载次数
+}
+
+// TextureBudget 纹理预算：记录所有动画精灵表占用的显存，
+// 延迟加载的动画常驻总量超过预算时，卸载超过 textureIdleFrames 帧没有使用的动画（最久没用的先卸载）
+// 游戏每帧调用一次 Update
+type TextureBudget struct {
+	Budget int64 // 预算（字节）
+
+	frame      int
+	animations []*Animation
+	stats      TextureStats
+}
+
+// Textures 全局的纹理预算（所有动画共用）
+var Textures = &TextureBudget{Budget: defaultTextureBudget}
+
+// register 记录新创建的动画（已加载的动画同时计入常驻字节数）
+func (b *TextureBudget) register(a *Animation) {
+	b.animations = append(b.animations, a)
+	if a.lazy {
+		return
+	}
+	b.addResident(a.bytes)
+}
+
+// unregister 移除释放的动画（已加载时从常驻字节数中减去），动画不在预算中时忽略
+func (b *TextureBudget) unregister(a *Animation) {
+	i := slices.Index(b.animations, a)
+	if i < 0 {
+		return
+	}
+	b.animations = slices.Delete(b.animations, i, i+1)
+	if a.IsLoaded() {
+		b.stats.Resident -= a.bytes
+	}
+}
+
+// loaded 延迟加载的动画加载完成
+func (b *TextureBudget) loaded(a *Animation) {
+	b.stats.Loads++
+	b.addResident(a.bytes)
+}
+
+// addResident 增加常驻字节数并更新峰值
+func (b *TextureBudget) addResident(bytes int64) {
+	b.stats.Resident += bytes
+	b.stats.Peak = max(b.stats.Peak, b.stats.Resident)
+}
+
+// Update 推进帧计数，超过预算时卸载空闲的延迟加载动画
+func (b *TextureBudget) Update() {
+	b.frame++
+	if b.Budget <= 0 || b.stats.Resident <= b.Budget {
+		return
+	}
+
+	var idle []*Animation
+	for _, a := range b.animations {
+		if a.lazy && a.IsLoaded() && b.frame-a.lastUsed >= textureIdleFrames {
+			idle = append(idle, a)
+		}
+	}
+	sort.Slice(idle, func(i, j int) bool { return idle[i].lastUsed < idle[j].lastUsed })
+	for _, a := range idle {
+		if b.stats.Resident <= b.Budget {
+			break
+		}
+		a.unload()
+		b.stats.Resident -= a.bytes
+		b.stats.Unloads++
+	}
+}
+
+// Stats 返回当前的统计
+func (b *TextureBudget) Stats() TextureStats {
+	stats := b.stats
+	stats.Budget = b.Budget
+	stats.Animations = len(b.animations)
+	for _, a := range b.animations {
+		if a.IsLoaded() {
+			stats.Loaded++
+		}
+		if a.lazy {
+			stats.Lazy++
+		}
+	}
+	return stats
+}
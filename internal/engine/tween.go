@@ -0,0 +1,216 @@
+package engine
+
+import "math"
+
+// Easing 缓动曲线：把线性进度 t（0 到 1）映射为插值进度（起点为 0，终点为 1，中间可以超出）
+type Easing func(t float64) float64
+
+// Linear 匀速
+func Linear(t float64) float64 {
+	return t
+}
+
+// EaseInQuad 由慢到快
+func EaseInQuad(t float64) float64 {
+	return t * t
+}
+
+// EaseOutQuad 由快到慢
+func EaseOutQuad(t float64) float64 {
+	return t * (2 - t)
+}
+
+// EaseInOutQuad 两头慢中间快
+func EaseInOutQuad(t float64) float64 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+// EaseOutCubic 由快到慢（比 EaseOutQuad 减速更明显，适合滑入的界面）
+func EaseOutCubic(t float64) float64 {
+	t--
+	return t*t*t + 1
+}
+
+// EaseOutBack 越过终点一点再回来（适合弹出的效果）
+func EaseOutBack(t float64) float64 {
+	const c1 = 1.70158
+	const c3 = c1 + 1
+	t--
+	return 1 + c3*t*t*t + c1*t*t
+}
+
+// EaseInOutSine 正弦曲线的两头慢中间快
+func EaseInOutSine(t float64) float64 {
+	return -(math.Cos(math.Pi*t) - 1) / 2
+}
+
+// Tween 补间：在 duration 帧内把一个或多个浮点数从开始时的值变化到目标值
+// 起点在补间开始（延迟结束）时读取，所以接在其他补间后面的补间从前一个补间的终点开始
+type Tween struct {
+	targets []*float64
+	from    []float64
+	to      []float64
+
+	duration float64
+	delay    float64
+	elapsed  float64
+	ease     Easing
+	started  bool
+
+	onComplete func()
+	next       *Tween
+}
+
+// NewTween 创建补间
+// duration: 时长（帧）
+// ease: 缓动曲线，为 nil 时匀速
+func NewTween(duration float64, ease Easing) *Tween {
+	if ease == nil {
+		ease = Linear
+	}
+	return &Tween{duration: duration, ease: ease}
+}
+
+// Float 加入一个浮点数目标
+func (t *Tween) Float(target *float64, to float64) *Tween {
+	t.targets = append(t.targets, target)
+	t.to = append(t.to, to)
+	return t
+}
+
+// Vec 加入一个二维坐标目标
+func (t *Tween) Vec(x, y *float64, toX, toY float64) *Tween {
+	return t.Float(x, toX).Float(y, toY)
+}
+
+// Delay 设置开始前等待的帧数（没有目标的补间加上延迟可以用作等待）
+func (t *Tween) Delay(frames float64) *Tween {
+	t.delay = frames
+	return t
+}
+
+// OnComplete 设置补间结束时的回调（在接着的补间开始之前调用）
+func (t *Tween) OnComplete(fn func()) *Tween {
+	t.onComplete = fn
+	return t
+}
+
+// Then 设置这个补间结束后接着开始的补间，返回 next，可以连续调用：a.Then(b).Then(c)
+func (t *Tween) Then(next *Tween) *Tween {
+	t.next = next
+	return next
+}
+
+// advance 推进 dt 帧，返回补间是否结束
+func (t *Tween) advance(dt float64) bool {
+	if t.delay > 0 {
+		t.delay -= dt
+		if t.delay > 0 {
+			return false
+		}
+		dt = -t.delay
+	}
+	if !t.started {
+		t.started = true
+		t.from = t.from[:0]
+		for _, target := range t.targets {
+			t.from = append(t.from, *target)
+		}
+	}
+
+	t.elapsed += dt
+	progress := 1.0
+	if t.duration > 0 {
+		progress = min(t.elapsed/t.duration, 1)
+	}
+	k := t.ease(progress)
+	if progress >= 1 {
+		k = 1 // 结束时准确落在目标值上
+	}
+	for i, target := range t.targets {
+		*target = t.from[i] + (t.to[i]-t.from[i])*k
+	}
+	return progress >= 1
+}
+
+// has 判断补间是否修改 target
+func (t *Tween) has(target *float64) bool {
+	for _, p := range t.targets {
+		if p == target {
+			return true
+		}
+	}
+	return false
+}
+
+// Tweener 补间管理器：每帧推进所有正在进行的补间，补间结束后调用回调并开始接着的补间
+type Tweener struct {
+	tweens []*Tween
+}
+
+// NewTweener 创建补间管理器
+func NewTweener() *Tweener {
+	return &Tweener{}
+}
+
+// Start 开始一个补间（以及用 Then 接在它后面的补间），返回这个补间
+func (m *Tweener) Start(t *Tween) *Tween {
+	m.tweens = append(m.tweens, t)
+	return t
+}
+
+// Update 推进 dt 帧（界面使用 1，跟随游戏时间的补间使用 Clock.Delta()）
+func (m *Tweener) Update(dt float64) {
+	if dt <= 0 {
+		return
+	}
+	// 回调中可能开始新的补间，先取出当前的列表
+	tweens := m.tweens
+	m.tweens = nil
+	for _, t := range tweens {
+		if !t.advance(dt) {
+			m.tweens = append(m.tweens, t)
+			continue
+		}
+		if t.onComplete != nil {
+			t.onComplete()
+		}
+		if t.next != nil {
+			m.tweens = append(m.tweens, t.next)
+		}
+	}
+}
+
+// Stop 停止正在进行的补间 t（不调用回调，接在它后面的补间也不会开始），目标保持当前值
+func (m *Tweener) Stop(t *Tween) {
+	for i, active := range m.tweens {
+		if active == t {
+			m.tweens = append(m.tweens[:i], m.tweens[i+1:]...)
+			return
+		}
+	}
+}
+
+// StopTarget 停止所有正在修改 target 的补间（重新开始同一个目标的补间之前调用，避免两个补间争抢）
+func (m *Tweener) StopTarget(target *float64) {
+	active := m.tweens[:0]
+	for _, t := range m.tweens {
+		if !t.has(target) {
+			active = append(active, t)
+		}
+	}
+	m.tweens = active
+}
+
+// Clear 停止所有补间
+func (m *Tweener) Clear() {
+	m.tweens = nil
+}
+
+// Len 返回正在进行的补间数量
+func (m *Tweener) Len() int {
+	return len(m.tweens)
+}
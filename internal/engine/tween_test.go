@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+// TestEasingEndpoints 所有缓动曲线从 0 开始、到 1 结束
+func TestEasingEndpoints(t *testing.T) {
+	easings := map[string]Easing{
+		"Linear":        Linear,
+		"EaseInQuad":    EaseInQuad,
+		"EaseOutQuad":   EaseOutQuad,
+		"EaseInOutQuad": EaseInOutQuad,
+		"EaseOutCubic":  EaseOutCubic,
+		"EaseOutBack":   EaseOutBack,
+		"EaseInOutSine": EaseInOutSine,
+	}
+	for name, ease := range easings {
+		if got := ease(0); math.Abs(got) > 1e-9 {
+			t.Errorf("%s(0) = %g", name, got)
+		}
+		if got := ease(1); math.Abs(got-1) > 1e-9 {
+			t.Errorf("%s(1) = %g", name, got)
+		}
+	}
+	if got := EaseInOutQuad(0.5); got != 0.5 {
+		t.Errorf("EaseInOutQuad(0.5) = %g", got)
+	}
+	if EaseInQuad(0.5) >= 0.5 || EaseOutQuad(0.5) <= 0.5 {
+		t.Errorf("EaseInQuad(0.5) = %g，EaseOutQuad(0.5) = %g", EaseInQuad(0.5), EaseOutQuad(0.5))
+	}
+	overshoot := false
+	for i := 1; i < 100; i++ {
+		if EaseOutBack(float64(i)/100) > 1 {
+			overshoot = true
+		}
+	}
+	if !overshoot {
+		t.Error("EaseOutBack 没有越过终点")
+	}
+}
+
+// TestTweenSequence 补间按延迟开始、结束时准确落在目标值，接着的补间从前一个补间的终点开始
+func TestTweenSequence(t *testing.T) {
+	tweener := NewTweener()
+	x, y := 0.0, 10.0
+	completed := 0
+	first := NewTween(4, EaseInQuad).Float(&x, 100).Delay(2).OnComplete(func() { completed++ })
+	first.Then(NewTween(2, nil).Float(&x, 0)).Then(NewTween(1, nil).Float(&y, 20))
+	tweener.Start(first)
+
+	tweener.Update(2)
+	if x != 0 {
+		t.Fatalf("延迟中 x = %g", x)
+	}
+	tweener.Update(2)
+	if x != 25 {
+		t.Errorf("过了一半时 x = %g，应为 25（EaseInQuad）", x)
+	}
+	tweener.Update(2)
+	if x != 100 || completed != 1 {
+		t.Fatalf("结束时 x = %g，回调 %d 次", x, completed)
+	}
+	tweener.Update(1)
+	if x != 50 {
+		t.Errorf("第二个补间过了一半时 x = %g，应为 50", x)
+	}
+	tweener.Update(1)
+	tweener.Update(1)
+	if x != 0 || y != 20 || tweener.Len() != 0 {
+		t.Errorf("全部结束后 x = %g，y = %g，剩余 %d 个补间", x, y, tweener.Len())
+	}
+}
+
+// TestTweenerStop 停止的补间保持当前值，不调用回调也不开始接着的补间
+func TestTweenerStop(t *testing.T) {
+	tweener := NewTweener()
+	x, y := 0.0, 0.0
+	called := false
+	tween := tweener.Start(NewTween(4, nil).Float(&x, 8).OnComplete(func() { called = true }))
+	tween.Then(NewTween(1, nil).Float(&x, 100))
+	tweener.Start(NewTween(4, nil).Float(&y, 8))
+
+	tweener.Update(1)
+	tweener.StopTarget(&x)
+	tweener.Update(10)
+	if x != 2 || called {
+		t.Errorf("停止后 x = %g，回调 %t", x, called)
+	}
+	if y != 8 || tweener.Len() != 0 {
+		t.Errorf("其他补间没有继续: y = %g，剩余 %d 个补间", y, tweener.Len())
+	}
+
+	tweener.Start(NewTween(2, nil).Float(&x, 0))
+	tweener.Update(0)
+	tweener.Clear()
+	if tweener.Len() != 0 {
+		t.Error("Clear 后仍有补间")
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 高处路线放置钥匙（并在路线之后放置上锁的门）的概率
+	keyChance = 0.5
+	// 上锁的门离高处路线最远的列数（从汇合点之后开始找空旷的道路）
+	keyDoorMaxDistance = 6
+	// 钥匙图片的宽度和高度（像素）
+	keyWidth  = 40.0
+	keyHeight = 20.0
+	// 钥匙底部在平台（或道路）顶部以上的高度（像素），站在平台上的玩家伸手可及
+	keyHoverHeight = 150.0
+	// 玩家走到门前多近时用钥匙开门（碰撞盒之间的水平距离，像素）
+	doorReach = 4.0
+	// HUD 上钥匙图标的缩放和间距（像素）
+	keyIconScale   = 0.6
+	keyIconSpacing = 28.0
+)
+
+// keyColor 钥匙和门锁的颜色
+var keyColor = color.RGBA{R: 0xf0, G: 0xc8, B: 0x30, A: 0xff}
+
+// 钥匙和上锁的门的图片（第一次使用时绘制）
+var (
+	keyImage  *ebiten.Image
+	doorImage *ebiten.Image
+)
+
+// NewKey 创建钥匙，底部中心在 (centerX, surfaceY - keyHoverHeight)
+// surfaceY: 钥匙下方的平台或道路顶部
+func NewKey(centerX, surfaceY float64) *Obstacle {
+	if keyImage == nil {
+		keyImage = ebiten.NewImage(int(keyWidth), int(keyHeight))
+		// 左边是圆环，右边是钥匙杆和两个齿
+		ring := float32(keyHeight / 2.0)
+		vector.StrokeCircle(keyImage, ring, ring, ring-3, 4, keyColor, true)
+		vector.FillRect(keyImage, ring*2-3, ring-2, keyWidth-ring*2+3, 4, keyColor, false)
+		vector.FillRect(keyImage, keyWidth-10, ring, 4, 8, keyColor, false)
+		vector.FillRect(keyImage, keyWidth-4, ring, 4, 6, keyColor, false)
+	}
+
+	left := centerX - keyWidth/2.0
+	top := surfaceY - keyHoverHeight - keyHeight
+	return NewObstacle(left, top, left, top, keyWidth, keyHeight, keyImage, ObstacleTypeKey)
+}
+
+// lockedDoorImage 返回上锁的门的图片（木门，中间有一把锁）
+func lockedDoorImage() *ebiten.Image {
+	if doorImage != nil {
+		return doorImage
+	}
+	doorImage = ebiten.NewImage(int(gateWidth), int(gateHeight))
+	doorImage.Fill(color.RGBA{R: 0x70, G: 0x48, B: 0x28, A: 0xff})
+	// 竖向的木板缝
+	for x := float32(gateWidth / 3); x < gateWidth; x += gateWidth / 3 {
+		vector.FillRect(doorImage, x-1, 0, 2, gateHeight, color.RGBA{R: 0x50, G: 0x30, B: 0x18, A: 0xff}, false)
+	}
+	// 门锁在玩家腰部的高度
+	lockY := float32(gateHeight - playerCollisionHeight/2.0)
+	vector.FillRect(doorImage, 8, lockY, gateWidth-16, 20, keyColor, false)
+	vector.FillCircle(doorImage, gateWidth/2, lockY+8, 3, color.Black, false)
+	return doorImage
+}
+
+// canTakeKey 玩家是否可以拾取钥匙：碰到钥匙，并且脚不低于钥匙下方的平台
+// （钥匙在站在道路上的玩家的碰撞盒高度以内，只有上到高处路线才能拿到）
+func canTakeKey(p *Player, key *Obstacle) bool {
+	_, _, _, bottom := key.GetCollisionBox()
+	return p.Y <= bottom+keyHoverHeight && engine.CheckCollision(p, key)
+}
+
+// atDoor 玩家是否走到了门前（碰撞盒贴着门，并且在门的高度范围内）
+func atDoor(p *Player, door *Obstacle) bool {
+	left, right, top, bottom := door.GetCollisionBox()
+	gap := math.Max(left-(p.X+playerCollisionWidth/2.0), (p.X-playerCollisionWidth/2.0)-right)
+	return gap <= doorReach && p.Y > top && p.Y-playerCollisionHeight < bottom
+}
+
+// heldKey 返回玩家拿着的、能打开 door 的钥匙，没有时返回 nil
+func (s *DeviceSystem) heldKey(door *Device) *Device {
+	for _, device := range s.devices {
+		if device.Entity.Kind != EntityKey || !device.collected || device.used {
+			continue
+		}
+		for _, link := range device.links {
+			if link == door {
+				return device
+			}
+		}
+	}
+	return nil
+}
+
+// DrawHUD 在金币数下方为玩家拿着的每把钥匙绘制一个图标
+func (s *DeviceSystem) DrawHUD(screen *ebiten.Image, x, y float64) {
+	for _, device := range s.devices {
+		if device.Entity.Kind != EntityKey || !device.collected || device.used {
+			continue
+		}
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(keyIconScale, keyIconScale)
+		op.GeoM.Translate(x, y)
+		screen.DrawImage(keyImage, op)
+		x += keyIconSpacing
+	}
+}
+
+// GenKeyEntities 为一部分高处路线生成钥匙和上锁的门：钥匙放在路线中间（没有哨兵的）平台上方，
+// 门放在路线之后（按滚动方向，往返滚动按第一段向右滚动）不远处的空旷道路上，保证玩家先经过钥匙再遇到门
+// items: 地图；mode: 滚动方式；taken: 已经放置的机关（不和它们放在同一列）；random: 随机数流（来自 RNG 服务）
+func GenKeyEntities(items []*MapItem, mode ScrollMode, taken []*LevelEntity, random *rand.Rand) []*LevelEntity {
+	occupied := make(map[int]bool, len(taken))
+	for _, entity := range taken {
+		occupied[entity.Column] = true
+	}
+	isFree := func(col int) bool {
+		if col < 0 || col >= len(items) || occupied[col] {
+			return false
+		}
+		item := items[col]
+		return item.HasRoad && !item.HasObstacle && !item.HasMonster && !item.HasPlatform
+	}
+
+	var entities []*LevelEntity
+	for start := 0; start < len(items); start++ {
+		if !items[start].HasPlatform {
+			continue
+		}
+		end := start // 路线后的第一列
+		for end < len(items) && items[end].HasPlatform {
+			end++
+		}
+		route := start
+		start = end
+		if random.Float64() >= keyChance {
+			continue
+		}
+
+		// 钥匙：从路线中间向两边找没有哨兵的平台
+		keyCol := -1
+		middle := (route + end - 1) / 2
+		for offset := 0; offset < end-route && keyCol < 0; offset++ {
+			for _, col := range []int{middle - offset, middle + offset} {
+				if col >= route && col < end && !items[col].HasPlatformHazard && !occupied[col] {
+					keyCol = col
+					break
+				}
+			}
+		}
+
+		// 门：按滚动方向在路线之后找空旷的道路
+		doorCol := -1
+		for distance := 1; distance <= keyDoorMaxDistance && doorCol < 0; distance++ {
+			col := end + distance
+			if mode == ScrollModeLeft {
+				col = route - 1 - distance
+			}
+			if isFree(col) {
+				doorCol = col
+			}
+		}
+		if keyCol < 0 || doorCol < 0 {
+			continue
+		}
+
+		door := &LevelEntity{ID: fmt.Sprintf("door-%d", doorCol), Kind: EntityDoor, Column: doorCol}
+		key := &LevelEntity{ID: fmt.Sprintf("key-%d", keyCol), Kind: EntityKey, Column: keyCol, Links: []string{door.ID}}
+		entities = append(entities, key, door)
+		occupied[keyCol], occupied[doorCol] = true, true
+	}
+	return entities
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// 回到标题或泄漏测试结束时，允许比基线多出的协程数量（运行时和音频驱动偶尔启动的后台协程）
+const leakCheckGoroutineSlack = 2
+
+// leakSample 垃圾回收之后的协程数量和堆内存
+type leakSample struct {
+	goroutines int
+	heap       uint64
+}
+
+// takeLeakSample 垃圾回收后记录协程数量和堆内存
+func takeLeakSample() leakSample {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return leakSample{goroutines: runtime.NumGoroutine(), heap: stats.HeapAlloc}
+}
+
+// String 返回协程数量和堆内存（MiB）
+func (s leakSample) String() string {
+	return fmt.Sprintf("goroutines %d  heap %.1f MiB", s.goroutines, float64(s.heap)/(1<<20))
+}
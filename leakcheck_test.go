@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 泄漏测试的局数
+	leakCheckCycles = 5
+	// 泄漏测试中每局运行的帧数
+	leakCheckFrames = 300
+	// 最后一局之后允许比第一局之后多出的堆内存（字节）
+	leakCheckHeapGrowth = 16 << 20
+)
+
+// TestNoLeaksAcrossRestarts 由机器人连续玩 leakCheckCycles 局（每局 leakCheckFrames 帧，种子依次加一），
+// 每局结束后关闭游戏，检查音频播放器和登记的图片全部释放；最后比较第一局和最后一局之后的协程数量和堆内存
+// 第一局之后才记录基线（目录、动画等缓存在第一局加载）
+func TestNoLeaksAcrossRestarts(t *testing.T) {
+	opts, err := ParseOptions([]string{"-seed", "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts = autoplayOptions(opts)
+	baseSeed := opts.Seed
+
+	var baseline leakSample
+	for i := 0; i < leakCheckCycles; i++ {
+		opts.Seed = baseSeed + int64(i)
+		var before engine.ResourceCounts
+		err := runOnGameLoop(t, func() error {
+			before = engine.LiveResources()
+			game := NewGame(opts)
+			defer game.Close()
+			for frame := 0; frame < leakCheckFrames; frame++ {
+				if err := game.Update(); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("第 %d 局（种子码 %s）出错: %v", i, SeedCode(opts.Seed, opts.Mutators), err)
+		}
+		if after := engine.LiveResources(); after != before {
+			t.Fatalf("第 %d 局（种子码 %s）有 %d 个音频播放器和 %d 张图片没有释放",
+				i, SeedCode(opts.Seed, opts.Mutators), after.AudioPlayers-before.AudioPlayers, after.Images-before.Images)
+		}
+		if i == 0 {
+			baseline = takeLeakSample()
+		}
+	}
+
+	final := takeLeakSample()
+	t.Logf("第一局之后 %s，最后一局之后 %s", baseline, final)
+	if final.goroutines > baseline.goroutines+leakCheckGoroutineSlack {
+		t.Errorf("泄漏了 %d 个协程", final.goroutines-baseline.goroutines)
+	}
+	if final.heap > baseline.heap+leakCheckHeapGrowth {
+		t.Errorf("堆内存增长了 %.1f MiB", float64(final.heap-baseline.heap)/(1<<20))
+	}
+}
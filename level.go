@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	// levelFormat 关卡文件的格式标识（文件头），用来区分关卡文件和其他 JSON 文件
+	levelFormat = "my_ai_game/level"
+	// LevelVersion 当前的关卡文件版本，关卡格式变化时加一，并在 levelMigrations 末尾加上旧版本的迁移函数
+	LevelVersion = 2
+)
+
+// Level 关卡文件的内容
+type Level struct {
+	Format  string `json:"format"`  // 格式标识，固定为 levelFormat
+	Version int    `json:"version"` // 关卡文件版本
+
+	Name       string `json:"name"`       // 关卡名称（关卡浏览中显示，为空时使用文件名）
+	Author     string `json:"author"`     // 作者
+	Difficulty string `json:"difficulty"` // 难度（为空时由 EstimateDifficulty 估计）
+
+	// 关卡自带的资源，路径相对于关卡文件所在的目录，为空时使用游戏自带的资源
+	Background string `json:"background,omitempty"` // 背景图片
+	Music      string `json:"music,omitempty"`      // 背景音乐
+
+	Scroll ScrollMode `json:"scroll"` // 相机滚动方式（省略时向右）
+	Items  []*MapItem `json:"items"`  // 地图数据
+
+	Vertical   []*VerticalSegment `json:"vertical"`   // 纵向滚动段（只在向右滚动的关卡中生效）
+	SpeedZones []SpeedZone        `json:"speedZones"` // 相机速度区域
+	KillPlane  float64            `json:"killPlane"`  // 死亡平面在道路顶部以下的距离（像素），0 表示使用默认值
+
+	Entities []*LevelEntity `json:"entities,omitempty"` // 开关、门和钥匙等机关（机关之间用 ID 链接）
+
+	// 脚本（路径相对于关卡文件所在的目录，需要使用 -tags lua 编译）
+	Behaviors map[string]string `json:"behaviors,omitempty"` // 怪物名称 -> 行为脚本，本关卡中该怪物的行为由脚本驱动
+	Triggers  []ScriptTrigger   `json:"triggers,omitempty"`  // 脚本触发点
+
+	dir string // 关卡文件所在的目录（加载时设置，用于解析资源路径）
+}
+
+// AssetPath 返回关卡资源的实际路径（相对于关卡文件所在的目录），name 为空时返回空字符串
+func (l *Level) AssetPath(name string) string {
+	if name == "" {
+		return ""
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(name))
+}
+
+// Assets 返回关卡自带的所有资源（关卡文件中的相对路径，不重复）
+func (l *Level) Assets() []string {
+	names := []string{l.Background, l.Music}
+	for _, script := range l.Behaviors {
+		names = append(names, script)
+	}
+	for _, trigger := range l.Triggers {
+		names = append(names, trigger.Script)
+	}
+
+	var assets []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			assets = append(assets, name)
+		}
+	}
+	sort.Strings(assets)
+	return assets
+}
+
+// levelHeader 关卡文件头（加载时先只解析文件头，确定版本后再迁移）
+type levelHeader struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+}
+
+// levelMigration 把关卡文件从一个版本迁移到下一个版本
+// 迁移在解析为 Level 之前对原始 JSON 进行，字段改名、拆分等结构变化都可以在这里处理
+type levelMigration func(data []byte) ([]byte, error)
+
+// levelMigrations 第 i 项把版本 i 的关卡文件迁移到版本 i+1，长度必须等于 LevelVersion
+//   - 版本 0：只有 MapItem 的 JSON 数组（相机向右滚动）
+//   - 版本 1：没有文件头的 Level 对象
+//   - 版本 2：带格式标识和版本号的 Level 对象
+var levelMigrations = []levelMigration{
+	migrateLevelV0,
+	migrateLevelV1,
+}
+
+// migrateLevelV0 把 MapItem 数组包装成 Level 对象
+func migrateLevelV0(data []byte) ([]byte, error) {
+	return json.Marshal(map[string]json.RawMessage{"items": data})
+}
+
+// migrateLevelV1 版本 2 只增加了文件头，内容不变（文件头在加载完成后统一设置）
+func migrateLevelV1(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+// levelFileVersion 判断关卡文件的版本
+// 没有文件头的旧文件：JSON 数组为版本 0，对象为版本 1
+func levelFileVersion(data []byte) (int, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		return 0, nil
+	}
+	var header levelHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, err
+	}
+	if header.Format == "" {
+		return 1, nil
+	}
+	if header.Format != levelFormat {
+		return 0, fmt.Errorf("不是关卡文件（格式标识为 %q）", header.Format)
+	}
+	if header.Version < 2 || header.Version > LevelVersion {
+		return 0, fmt.Errorf("不支持的关卡文件版本 %d（当前版本 %d）", header.Version, LevelVersion)
+	}
+	return header.Version, nil
+}
+
+// LoadLevel 从关卡文件加载关卡
+// 旧版本的文件按 levelMigrations 逐个版本迁移到当前版本；Index 按数组顺序重新编号
+func LoadLevel(path string) (*Level, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := levelFileVersion(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析关卡文件 %s 失败: %w", path, err)
+	}
+	for ; version < LevelVersion; version++ {
+		if data, err = levelMigrations[version](data); err != nil {
+			return nil, fmt.Errorf("迁移关卡文件 %s（版本 %d）失败: %w", path, version, err)
+		}
+	}
+
+	level := &Level{}
+	if err := json.Unmarshal(data, level); err != nil {
+		return nil, fmt.Errorf("解析关卡文件 %s 失败: %w", path, err)
+	}
+	level.Format, level.Version = levelFormat, LevelVersion
+	level.dir = filepath.Dir(path)
+	if len(level.Items) == 0 {
+		return nil, fmt.Errorf("关卡文件 %s 中没有地图数据", path)
+	}
+
+	for i, item := range level.Items {
+		if item == nil {
+			return nil, fmt.Errorf("关卡文件 %s 第 %d 列为空", path, i)
+		}
+		item.Index = i
+	}
+	if err := ValidateEntities(level.Entities, level.Items, level.Scroll); err != nil {
+		return nil, fmt.Errorf("关卡文件 %s 中的机关无效: %w", path, err)
+	}
+	return level, nil
+}
+
+// SaveLevel 把关卡保存为当前版本的关卡文件（缩进格式方便手工修改）
+func SaveLevel(path string, level *Level) error {
+	level.Format, level.Version = levelFormat, LevelVersion
+	data, err := json.MarshalIndent(level, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"image/color"
+	"log"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// NewGame 的加载步骤数（每个步骤开始时调用一次 LoadingProgress.Step）
+const loadingSteps = 7
+
+const (
+	// 加载进度条的宽度和高度（像素，在屏幕中间）
+	loadingBarWidth  = 400.0
+	loadingBarHeight = 12.0
+)
+
+// LoadingProgress 加载进度：NewGame 在后台协程中每开始一个步骤调用 Step，加载界面在游戏线程中读取
+// 为 nil 时不记录（直接调用 NewGame 的存档选择和测试模式）
+type LoadingProgress struct {
+	mu    sync.Mutex // 保护以下字段（加载协程和游戏线程共用）
+	step  int        // 已经开始的步骤数
+	label string     // 正在加载的内容
+}
+
+// Step 开始下一个加载步骤
+func (p *LoadingProgress) Step(label string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.step++
+	p.label = label
+}
+
+// Current 返回已经完成的比例（0 到 1）和正在加载的内容
+func (p *LoadingProgress) Current() (float64, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return float64(max(p.step-1, 0)) / loadingSteps, p.label
+}
+
+// loadResult 加载协程的结果
+type loadResult struct {
+	game *Game
+	err  error
+}
+
+// LoadingScene 加载界面：从标题开始游戏、重新开始和换新地图时代替直接调用 NewGame，
+// 在后台协程中用 LoadGame 加载地图、图片、音频和目录，期间显示进度条，加载完成后切换到游戏
+// 协程在第一次 Update 时启动（之前的场景此时已经释放，不与加载争用资源）；
+// 必需的资源加载失败时显示错误，按确认键或返回键回到标题
+// 启动选项没有场景管理器时（吸引模式的演示）不切换场景，由创建者通过 Result 取走加载完成的游戏
+type LoadingScene struct {
+	opts     GameOptions
+	progress *LoadingProgress
+	done     chan loadResult // 加载的结果（缓冲 1）
+	started  bool
+	game     *Game // 加载完成、还没有切换过去的游戏
+	err      error // 加载失败的原因
+}
+
+// NewLoadingScene 创建按 opts 加载游戏的加载界面（opts 必须来自场景管理器）
+func NewLoadingScene(opts GameOptions) *LoadingScene {
+	return &LoadingScene{opts: opts, progress: &LoadingProgress{}, done: make(chan loadResult, 1)}
+}
+
+// Update 第一次调用时开始加载，加载完成后切换到游戏，加载失败后等待回到标题
+func (s *LoadingScene) Update() error {
+	if !s.started {
+		s.started = true
+		opts := s.opts
+		opts.loading = s.progress
+		go func() {
+			game, err := LoadGame(opts)
+			s.done <- loadResult{game: game, err: err}
+		}()
+	}
+	if s.err != nil {
+		if s.opts.scenes != nil && (ActionJustPressed(ActionConfirm) || ActionJustPressed(ActionBack)) {
+			s.opts.scenes.ToTitle()
+		}
+		return nil
+	}
+	if s.game != nil {
+		return nil
+	}
+	select {
+	case result := <-s.done:
+		if result.err != nil {
+			log.Printf("警告: 加载游戏失败: %v", result.err)
+			s.err = result.err
+			return nil
+		}
+		s.game = result.game
+		if s.opts.scenes != nil {
+			// 存档记住的窗口模式在游戏线程中应用，标题同样记住存档的设置
+			if s.game.options.Display.Mode != s.opts.Display.Mode {
+				ApplyDisplaySettings(s.game.options.Display)
+			}
+			s.opts.scenes.title.rememberSettings(s.game.options)
+			s.opts.scenes.Switch(s.game)
+		}
+	default:
+	}
+	return nil
+}
+
+// Result 返回加载完成的游戏或加载失败的原因，还在加载时都为 nil（取走的游戏由调用者释放）
+func (s *LoadingScene) Result() (*Game, error) {
+	return s.game, s.err
+}
+
+// Draw 绘制正在加载的内容和进度条，加载失败时绘制错误
+func (s *LoadingScene) Draw(screen *ebiten.Image) {
+	if s.err != nil {
+		s.drawError(screen)
+		return
+	}
+	fraction, label := s.progress.Current()
+	if s.game != nil {
+		fraction = 1
+	}
+	const title = "LOADING..."
+	DrawTextCentered(screen, title, windowWidth/2, windowHeight/2-40)
+	x := float32(windowWidth-loadingBarWidth) / 2
+	y := float32(windowHeight) / 2
+	vector.StrokeRect(screen, x-2, y-2, loadingBarWidth+4, loadingBarHeight+4, 1, color.White, false)
+	vector.FillRect(screen, x, y, loadingBarWidth*float32(fraction), loadingBarHeight, color.White, false)
+	DrawTextCentered(screen, label, windowWidth/2, windowHeight/2+24)
+}
+
+// drawError 绘制加载失败的原因（过长时截断到屏幕宽度）和回到标题的提示
+func (s *LoadingScene) drawError(screen *ebiten.Image) {
+	const title = "LOAD FAILED"
+	DrawTextCentered(screen, title, windowWidth/2, windowHeight/2-40)
+	message := []rune(s.err.Error())
+	if limit := windowWidth/6 - 4; len(message) > limit {
+		message = append(message[:limit-3], []rune("...")...)
+	}
+	DrawTextCentered(screen, string(message), windowWidth/2, windowHeight/2-8)
+	DrawPromptCentered(screen, "{confirm} TITLE", windowWidth/2, windowHeight/2+24)
+}
+
+// Close 退出时等待加载完成并释放还没有切换过去的游戏（已经切换时由游戏自己的 Close 释放，加载失败时已经释放）
+func (s *LoadingScene) Close() {
+	if !s.started || s.game != nil || s.err != nil {
+		return
+	}
+	if result := <-s.done; result.game != nil {
+		result.game.Close()
+	}
+}
+
+// Layout 返回逻辑屏幕尺寸
+func (s *LoadingScene) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return windowWidth, windowHeight
+}
@@ -1,14 +1,54 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 func main() {
-	// 设置窗口大小
-	ebiten.SetWindowSize(windowWidth, windowHeight)
+	// 解析启动选项（命令行参数和环境变量）
+	opts, err := ParseOptions(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+	log.Printf("随机种子: %d", opts.Seed)
+	if opts.Mutators != 0 {
+		log.Printf("突变: %s", opts.Mutators)
+	}
+	log.Printf("种子码: %s", SeedCode(opts.Seed, opts.Mutators))
+
+	// 浸泡测试：不打开窗口，由机器人连续游玩
+	if opts.Soak > 0 {
+		RunSoak(os.Stdout, opts, opts.Soak)
+		return
+	}
+
+	// 关卡包导出和导入：不打开窗口，完成后退出
+	if opts.ExportBundle != "" {
+		bundlePath := strings.TrimSuffix(opts.ExportBundle, filepath.Ext(opts.ExportBundle)) + ".zip"
+		if err := ExportBundle(opts.ExportBundle, bundlePath); err != nil {
+			log.Fatalf("导出关卡包失败: %v", err)
+		}
+		log.Printf("已导出关卡包: %s", bundlePath)
+		return
+	}
+	if opts.ImportBundle != "" {
+		levelPath, err := ImportBundle(opts.ImportBundle, levelsDir)
+		if err != nil {
+			log.Fatalf("导入关卡包失败: %v", err)
+		}
+		log.Printf("已安装关卡: %s", levelPath)
+		return
+	}
 
 	// 设置窗口标题
 	ebiten.SetWindowTitle("雪莉酱の大冒险")
@@ -16,11 +56,51 @@ func main() {
 	// 禁用窗口调整大小
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeDisabled)
 
-	// 创建游戏实例
-	game := NewGame(512)
+	// 应用显示设置（窗口模式、分辨率、显示器）
+	ApplyDisplaySettings(opts.Display)
+
+	// 动画预览（调试用）：不创建游戏
+	if opts.AnimPreview {
+		if err := ebiten.RunGame(NewAnimationPreview(opts.Skin)); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// 回放观看：按回放文件的选项和输入重现一局，不使用存档
+	if opts.ReplayPath != "" {
+		replay, err := LoadReplay(opts.ReplayPath)
+		if err != nil {
+			log.Fatalf("加载回放失败: %v", err)
+		}
+		viewer := NewReplayViewer(opts, replay)
+		err = ebiten.RunGame(viewer)
+		viewer.Close()
+		DisposeArtAnimations()
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// 关卡编辑器：不使用存档，直接创建游戏
+	if opts.Editor {
+		game := NewGame(opts)
+		err := ebiten.RunGame(game)
+		game.Close()
+		DisposeArtAnimations()
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	// 运行游戏
-	if err := ebiten.RunGame(game); err != nil {
+	// 先显示标题界面，按开始键后从菜单开始游戏（存档选择、关卡浏览由 launchScene 按启动选项决定），结束后进入游戏结束场景
+	// 退出后释放所有场景的资源
+	scenes := NewSceneManager(opts)
+	err = ebiten.RunGame(scenes)
+	scenes.Close()
+	if err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// testLoop 在 ebiten 的游戏循环中运行测试：测试在另一个协程中运行，需要图形上下文的部分（创建游戏、读取画面像素）
+// 通过 runOnGameLoop 交给 Update 执行，每次 Update 执行一个
+type testLoop struct {
+	m       *testing.M
+	calls   chan func()
+	started bool
+	code    int
+}
+
+// gameLoop 测试使用的游戏循环（没有图形上下文时为 nil，需要图形的测试跳过）
+var gameLoop *testLoop
+
+// TestMain 有图形上下文时在游戏循环中运行测试，否则直接运行
+func TestMain(m *testing.M) {
+	if !hasDisplay() {
+		os.Exit(m.Run())
+	}
+	loop := &testLoop{m: m, calls: make(chan func())}
+	gameLoop = loop
+	ebiten.SetWindowTitle("my_ai_game test")
+	ebiten.SetRunnableOnUnfocused(true)
+	if err := ebiten.RunGame(loop); err != nil && !loop.started {
+		// 无法打开窗口：不使用游戏循环运行测试
+		gameLoop = nil
+		os.Exit(m.Run())
+	}
+	os.Exit(loop.code)
+}
+
+// hasDisplay 返回是否可能有图形上下文（X11/Wayland 平台上需要显示服务器）
+func hasDisplay() bool {
+	switch runtime.GOOS {
+	case "windows", "darwin", "ios", "android", "js":
+		return true
+	}
+	return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+}
+
+// Update 第一帧开始运行测试，之后每帧执行一个测试交来的函数，测试全部结束后退出
+func (l *testLoop) Update() error {
+	if !l.started {
+		l.started = true
+		go func() {
+			l.code = l.m.Run()
+			close(l.calls)
+		}()
+	}
+	select {
+	case call, ok := <-l.calls:
+		if !ok {
+			return ebiten.Termination
+		}
+		call()
+	default:
+	}
+	return nil
+}
+
+// Draw 测试不绘制窗口
+func (l *testLoop) Draw(screen *ebiten.Image) {}
+
+// Layout 返回游戏逻辑尺寸
+func (l *testLoop) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return windowWidth, windowHeight
+}
+
+// runOnGameLoop 在游戏循环的下一次 Update 中执行 f 并等待完成；没有图形上下文时跳过测试
+// f 中不能调用 t.Fatal 等结束测试协程的方法，错误通过返回值交回
+func runOnGameLoop(t *testing.T, f func() error) error {
+	t.Helper()
+	if gameLoop == nil {
+		t.Skip("没有图形上下文")
+	}
+	done := make(chan error)
+	gameLoop.calls <- func() { done <- f() }
+	return <-done
+}
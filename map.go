@@ -1,9 +1,6 @@
 package main
 
-import (
-	"math/rand"
-	"time"
-)
+import "math/rand"
 
 type MapItem struct {
 	Index       int  // 从左往右数下标为几
@@ -11,10 +8,16 @@ type MapItem struct {
 	HasObstacle bool // 该道路是否有障碍
 	HasMonster  bool // 该道路是否有怪物
 	HasTool     bool // 该道路上是否有道具
+
+	HasPlatform       bool // 该列上方是否有高处路线的平台（与道路无关）
+	HasPlatformHazard bool // 该列的平台上是否有危险（哨兵怪物）
+
+	ScrollTrigger int // 往返滚动关卡的转向触发点编号（从 1 开始按经过顺序编号，0 表示没有）
 }
 
 // GenMap 生成地图
 // count: 生成的地图列数
+// random: 随机数流（来自 RNG 服务），相同种子生成相同的地图
 // 规则：
 //   - 每一列可能有道路，也可能没有道路
 //   - 只有有道路的情况下才能有障碍
@@ -22,14 +25,11 @@ type MapItem struct {
 //   - 怪物不能连续出现
 //   - 怪物不会出现在连续道路段的边缘（道路段的开始和结束位置）
 //   - 最多连续 2 个没有道路
-func GenMap(count int) []*MapItem {
+func GenMap(count int, random *rand.Rand) []*MapItem {
 	if count <= 0 {
 		return nil
 	}
 
-	// 初始化随机数种子
-	random := rand.New(rand.NewSource(time.Now().Unix()))
-
 	result := make([]*MapItem, 0, count)
 	noRoadCount := 0         // 当前连续没有道路的数量
 	prevHasObstacle := false // 上一个位置是否有障碍
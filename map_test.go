@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+const (
+	// 模糊测试生成的地图列数上限
+	mapFuzzMaxLength = 2000
+	// GenMap 保证开头有道路的列数，以及最多连续的缺口列数
+	mapSafeStartColumns = 10
+	mapMaxGapColumns    = 2
+)
+
+// mapFuzzConfig 一次模糊测试的生成配置（失败时输出用于重现）
+type mapFuzzConfig struct {
+	seed   int64
+	length int
+	scroll ScrollMode
+	routes bool // 是否生成高处路线
+}
+
+// String 返回重现这次生成所需的配置
+func (c mapFuzzConfig) String() string {
+	return fmt.Sprintf("seed %d  length %d  scroll %s  routes %t", c.seed, c.length, c.scroll, c.routes)
+}
+
+// generate 按配置生成地图：与开局相同的生成步骤（地图、高处路线、按滚动方式调整），各步骤使用各自的随机数流
+func (c mapFuzzConfig) generate() []*MapItem {
+	rng := NewRNG(c.seed)
+	items := GenMap(c.length, rng.Stream(rngStreamMap))
+	if c.routes {
+		GenHighRoutes(items, rng.Stream(rngStreamRoutes))
+	}
+	PrepareScrollMap(items, c.scroll, rng.Stream(rngStreamScroll))
+	return items
+}
+
+// CheckMapInvariants 检查生成的地图是否满足 GenMap 的规则，返回第一个违反的规则
+//   - 列数等于 count，Index 按顺序编号
+//   - 开头 10 列有道路（玩家出生的位置）
+//   - 最多连续 2 列没有道路
+//   - 障碍物和怪物只在道路上，同一列不会同时有障碍物和怪物
+//   - 障碍物不连续，怪物不连续
+//   - 怪物不在连续道路段的边缘
+func CheckMapInvariants(items []*MapItem, count int) error {
+	if len(items) != max(count, 0) {
+		return fmt.Errorf("列数为 %d，应为 %d", len(items), count)
+	}
+	gaps := 0
+	for i, item := range items {
+		if item.Index != i {
+			return fmt.Errorf("第 %d 列的 Index 为 %d", i, item.Index)
+		}
+		if i < mapSafeStartColumns && !item.HasRoad {
+			return fmt.Errorf("开头的第 %d 列没有道路", i)
+		}
+		if item.HasRoad {
+			gaps = 0
+		} else if gaps++; gaps > mapMaxGapColumns {
+			return fmt.Errorf("第 %d 列是连续的第 %d 个缺口", i, gaps)
+		}
+		if !item.HasRoad && (item.HasObstacle || item.HasMonster) {
+			return fmt.Errorf("第 %d 列没有道路，但有障碍物或怪物", i)
+		}
+		if item.HasObstacle && item.HasMonster {
+			return fmt.Errorf("第 %d 列同时有障碍物和怪物", i)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := items[i-1]
+		if item.HasObstacle && prev.HasObstacle {
+			return fmt.Errorf("第 %d、%d 列有连续的障碍物", i-1, i)
+		}
+		if item.HasMonster && prev.HasMonster {
+			return fmt.Errorf("第 %d、%d 列有连续的怪物", i-1, i)
+		}
+	}
+	for i, item := range items {
+		isEdge := i == 0 || i == len(items)-1 || !items[i-1].HasRoad || !items[i+1].HasRoad
+		if item.HasMonster && isEdge {
+			return fmt.Errorf("第 %d 列的怪物在道路段的边缘", i)
+		}
+	}
+	return nil
+}
+
+// FuzzGenMap 用任意的种子和配置生成地图并检查 GenMap 的规则
+// 种子语料覆盖 0 列、1 列、开头安全区的边界和每种滚动方式；列数超出上限时取余
+func FuzzGenMap(f *testing.F) {
+	for _, length := range []uint16{0, 1, 2, mapSafeStartColumns - 1, mapSafeStartColumns, mapSafeStartColumns + 1, 19, 512, mapFuzzMaxLength} {
+		for scroll := range scrollModeNames {
+			f.Add(int64(length)*31+int64(scroll), length, uint8(scroll), length%2 == 0)
+		}
+	}
+	f.Fuzz(func(t *testing.T, seed int64, length uint16, scroll uint8, routes bool) {
+		config := mapFuzzConfig{
+			seed:   seed,
+			length: int(length) % (mapFuzzMaxLength + 1),
+			scroll: ScrollMode(int(scroll) % len(scrollModeNames)),
+			routes: routes,
+		}
+		if err := CheckMapInvariants(config.generate(), config.length); err != nil {
+			t.Fatalf("%v\n  %s", err, config)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ModHooks 模组钩子注册表，游戏在固定的时机按注册顺序调用各钩子
+type ModHooks struct {
+	mapGenerated []func(items []*MapItem)
+	playerUpdate []func(g *Game, p *Player)
+	draw         []func(screen *ebiten.Image, g *Game)
+}
+
+// OnMapGenerated 注册地图钩子：地图生成（或从关卡文件加载）并应用突变之后、创建障碍物之前调用，可以修改地图数据
+func (h *ModHooks) OnMapGenerated(f func(items []*MapItem)) {
+	h.mapGenerated = append(h.mapGenerated, f)
+}
+
+// OnPlayerUpdate 注册玩家钩子：每帧玩家更新之后调用（暂停和回溯时不调用）
+func (h *ModHooks) OnPlayerUpdate(f func(g *Game, p *Player)) {
+	h.playerUpdate = append(h.playerUpdate, f)
+}
+
+// OnDraw 注册绘制钩子：游戏世界绘制完成之后、HUD 之前调用，绘制到屏幕坐标
+func (h *ModHooks) OnDraw(f func(screen *ebiten.Image, g *Game)) {
+	h.draw = append(h.draw, f)
+}
+
+// MapGenerated 调用所有地图钩子
+func (h *ModHooks) MapGenerated(items []*MapItem) {
+	for _, f := range h.mapGenerated {
+		f(items)
+	}
+}
+
+// PlayerUpdate 调用所有玩家钩子
+func (h *ModHooks) PlayerUpdate(g *Game, p *Player) {
+	for _, f := range h.playerUpdate {
+		f(g, p)
+	}
+}
+
+// Draw 调用所有绘制钩子
+func (h *ModHooks) Draw(screen *ebiten.Image, g *Game) {
+	for _, f := range h.draw {
+		f(screen, g)
+	}
+}
+
+// Mod 模组的安装函数，在创建游戏时调用一次，向注册表添加钩子
+type Mod func(hooks *ModHooks)
+
+// mods 已注册的模组（模组文件在 init 中调用 RegisterMod，用 -mods 按名称启用）
+var mods = map[string]Mod{}
+
+// RegisterMod 注册模组
+func RegisterMod(name string, mod Mod) {
+	mods[name] = mod
+}
+
+// ParseModNames 解析逗号分隔的模组名称，检查模组是否已注册
+func ParseModNames(list string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if mods[name] == nil {
+			return nil, fmt.Errorf("未知的模组: %s（已注册: %s）", name, strings.Join(ModNames(), ", "))
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// ModNames 返回所有已注册模组的名称（按名称排序）
+func ModNames() []string {
+	names := make([]string, 0, len(mods))
+	for name := range mods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// InstallMods 按顺序安装启用的模组，返回钩子注册表（没有启用模组时为空注册表）
+func InstallMods(names []string) *ModHooks {
+	hooks := &ModHooks{}
+	for _, name := range names {
+		mods[name](hooks)
+	}
+	return hooks
+}
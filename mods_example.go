@@ -0,0 +1,37 @@
+//go:build examplemods
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 示例模组（用 -tags examplemods 编译）：
+//   - peaceful: 移除地图上的所有怪物
+//   - airtime: 在屏幕右上角显示玩家本次在空中停留的帧数
+func init() {
+	RegisterMod("peaceful", func(hooks *ModHooks) {
+		hooks.OnMapGenerated(func(items []*MapItem) {
+			for _, item := range items {
+				item.HasMonster = false
+				item.HasPlatformHazard = false
+			}
+		})
+	})
+
+	RegisterMod("airtime", func(hooks *ModHooks) {
+		airFrames := 0
+		hooks.OnPlayerUpdate(func(g *Game, p *Player) {
+			if p.IsOnGround {
+				airFrames = 0
+			} else {
+				airFrames++
+			}
+		})
+		hooks.OnDraw(func(screen *ebiten.Image, g *Game) {
+			DrawText(screen, fmt.Sprintf("AIR: %d", airFrames), windowWidth-80, 10)
+		})
+	})
+}
@@ -0,0 +1,575 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"math"
+	"math/rand"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 怪物目录文件路径
+	monsterCatalogPath = "res/data/monsters.json"
+	// 子弹尺寸（像素）
+	projectileSize = 16.0
+	// 子弹最长存活时间（帧数）
+	projectileLifeFrames = 240
+	// 怪物的更新范围：碰撞盒离开相机视口超过该距离（像素）时不执行行为和动画，回到范围内后继续
+	monsterUpdateMargin = float64(windowWidth)
+)
+
+// MonsterDef 怪物定义（从怪物目录 JSON 加载）
+type MonsterDef struct {
+	Name      string             `json:"name"`       // 怪物名称
+	ImagePath string             `json:"image"`      // 精灵表路径
+	Frames    int                `json:"frames"`     // 精灵表帧数
+	FPS       float64            `json:"fps"`        // 动画播放速度（帧/秒）
+	Speed     float64            `json:"speed"`      // 移动速度（像素/帧）
+	Behavior  string             `json:"behavior"`   // 行为名称（对应 monsterBehaviors 中注册的函数）
+	Collision CollisionBoxDef    `json:"collision"`  // 碰撞盒
+	Points    int                `json:"points"`     // 击败后获得的分数
+	Weight    int                `json:"weight"`     // 地图生成时的权重
+	Params    map[string]float64 `json:"params"`     // 行为参数
+	Sight     *Perception        `json:"perception"` // 感知组件（为空时只按距离判断）
+	Palette   string             `json:"palette"`    // 调色板名称（同一张精灵表换色成不同的怪物，为空时使用原色）
+
+	animation *engine.Animation // 精灵表动画
+	behavior  MonsterBehavior   // 已注册的行为函数
+	missile   bool              // 是否是子弹或落物（由代码生成，不是真正的怪物：击败不计分，不触发靠近提示，离开更新范围后直接移除）
+}
+
+// Param 获取行为参数，不存在时返回默认值
+func (d *MonsterDef) Param(name string, defaultValue float64) float64 {
+	if value, ok := d.Params[name]; ok {
+		return value
+	}
+	return defaultValue
+}
+
+// MonsterBehavior 怪物行为构造函数，为怪物创建其 AI 状态机
+type MonsterBehavior func(o *Obstacle) *StateMachine[*MonsterContext]
+
+// monsterBehaviors 已注册的怪物行为
+// 新增怪物只需在目录 JSON 中添加条目，并在此注册对应的行为函数（由 FSMState 组合而成）
+var monsterBehaviors = map[string]MonsterBehavior{
+	"patrol":     behaviorPatrol,
+	"chase":      behaviorChase,
+	"hop":        behaviorHop,
+	"shoot":      behaviorShoot,
+	"projectile": behaviorProjectile,
+	"static":     behaviorStatic,
+}
+
+// RegisterMonsterBehavior 注册怪物行为
+func RegisterMonsterBehavior(name string, behavior MonsterBehavior) {
+	monsterBehaviors[name] = behavior
+}
+
+// MonsterCatalog 怪物目录
+type MonsterCatalog struct {
+	Defs        []*MonsterDef
+	totalWeight int
+}
+
+// LoadMonsterCatalog 从 JSON 文件加载怪物目录
+func LoadMonsterCatalog(path string) (*MonsterCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var defs []*MonsterDef
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("解析怪物目录失败: %w", err)
+	}
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("怪物目录为空: %s", path)
+	}
+
+	catalog := &MonsterCatalog{Defs: defs}
+	for _, def := range defs {
+		behavior, ok := monsterBehaviors[def.Behavior]
+		if !ok {
+			return nil, fmt.Errorf("怪物 %s 使用了未注册的行为: %s", def.Name, def.Behavior)
+		}
+		def.behavior = behavior
+		animation, err := loadAnimation(def.ImagePath, def.Frames, true, def.FPS, 0, def.Palette)
+		if err != nil {
+			return nil, fmt.Errorf("加载怪物 %s 的动画失败: %w", def.Name, err)
+		}
+		def.animation = animation
+		catalog.totalWeight += def.Weight
+	}
+	return catalog, nil
+}
+
+// Pick 按权重随机选择一种怪物
+func (c *MonsterCatalog) Pick(random *rand.Rand) *MonsterDef {
+	if c.totalWeight <= 0 {
+		return c.Defs[0]
+	}
+
+	n := random.Intn(c.totalWeight)
+	for _, def := range c.Defs {
+		if n < def.Weight {
+			return def
+		}
+		n -= def.Weight
+	}
+	return c.Defs[len(c.Defs)-1]
+}
+
+// Get 按名称获取怪物定义，不存在时返回 nil
+// 权重为 0 的怪物不会被 Pick 随机选中，只能通过名称放置（例如高处路线上的哨兵）
+func (c *MonsterCatalog) Get(name string) *MonsterDef {
+	for _, def := range c.Defs {
+		if def.Name == name {
+			return def
+		}
+	}
+	return nil
+}
+
+// Monster 怪物运行时状态（挂在 Obstacle 上）
+type Monster struct {
+	Def        *MonsterDef                    // 怪物定义
+	HomeX      float64                        // 出生点 X 坐标（碰撞盒左边界）
+	Direction  float64                        // 移动方向：-1 向左，1 向右
+	VelocityX  float64                        // 水平速度（子弹使用）
+	VelocityY  float64                        // 垂直速度
+	IsOnGround bool                           // 是否在地面上
+	Timer      int                            // 行为计时器（帧数）
+	IsDead     bool                           // 是否需要从场景中移除
+	Asleep     bool                           // 本帧是否在更新范围外（没有执行行为）
+	Brain      *StateMachine[*MonsterContext] // AI 状态机
+	frame      float64                        // 当前动画帧（浮点数，用于平滑播放）
+}
+
+// NewMonster 根据怪物定义创建怪物障碍物
+// x: 所在地图块的左边界
+// groundY: 地面高度（怪物底部与之对齐）
+func NewMonster(def *MonsterDef, x, groundY float64) *Obstacle {
+	frameHeight := float64(def.animation.FrameHeight)
+	drawY := groundY - frameHeight
+	box := def.Collision
+
+	obstacle := NewObstacle(x, drawY, x+box.OffsetX, drawY+box.OffsetY, box.Width, box.Height, def.animation.GetFrame(0), ObstacleTypeMonster)
+	obstacle.Monster = &Monster{
+		Def:        def,
+		HomeX:      obstacle.X,
+		Direction:  -1,
+		IsOnGround: true,
+	}
+	obstacle.Monster.Brain = def.behavior(obstacle)
+	return obstacle
+}
+
+// Update 更新怪物动画并执行行为
+func (m *Monster) Update(o *Obstacle, ctx *MonsterContext) {
+	if anim := m.Def.animation; anim != nil {
+		m.frame += anim.FPS / engine.GameFPS
+		if m.frame >= float64(anim.FrameCount) {
+			m.frame -= float64(anim.FrameCount)
+		}
+		o.Image = anim.GetFrame(int(m.frame))
+	}
+
+	m.Brain.Update(ctx)
+	o.FlipX = m.Direction > 0
+
+	// 掉出屏幕下方的怪物进入死亡状态
+	if o.Y > float64(windowHeight) && m.Brain.State() != AIStateDead {
+		m.Brain.ChangeState(AIStateDead, ctx)
+	}
+}
+
+// inUpdateRange 判断怪物的碰撞盒是否在相机视口向外扩展 monsterUpdateMargin 的范围内
+func (o *Obstacle) inUpdateRange(camera *engine.Camera) bool {
+	left, right, top, bottom := o.GetCollisionBox()
+	if right < camera.X-monsterUpdateMargin || left > camera.X+camera.Width+monsterUpdateMargin {
+		return false
+	}
+	return bottom >= camera.Y-monsterUpdateMargin && top <= camera.Y+camera.Height+monsterUpdateMargin
+}
+
+// MonsterContext 怪物行为执行时可访问的场景信息
+type MonsterContext struct {
+	Player    *Player
+	Obstacles []*Obstacle
+	MapItems  []*MapItem
+	Nav       *NavMap // 导航数据（可行走道路段和可跳过的缺口）
+	GroundY   float64 // 道路顶部的 Y 坐标
+
+	spawned []*Obstacle // 本帧新生成的对象（如子弹），由 Game 统一加入场景
+}
+
+// Spawn 在场景中生成新对象
+func (ctx *MonsterContext) Spawn(o *Obstacle) {
+	ctx.spawned = append(ctx.spawned, o)
+}
+
+// GroundYAt 获取给定 X 坐标处的地面高度，没有道路时返回 false
+func (ctx *MonsterContext) GroundYAt(x float64) (float64, bool) {
+	index := int(math.Floor(x / mapItemWidth))
+	if index < 0 || index >= len(ctx.MapItems) || !ctx.MapItems[index].HasRoad {
+		return 0, false
+	}
+	return ctx.GroundY, true
+}
+
+// isBlocked 检查怪物移动到新位置后是否会被障碍物阻挡
+func (ctx *MonsterContext) isBlocked(self *Obstacle, newX float64) bool {
+	box := &Obstacle{X: newX, Y: self.Y, Width: self.Width, Height: self.Height}
+	for _, obstacle := range ctx.Obstacles {
+		if obstacle == self || obstacle.Type != ObstacleTypeObstacle || !obstacle.Solidity.Has(SolidFromSides) {
+			continue
+		}
+		if engine.CheckCollision(box, obstacle) {
+			return true
+		}
+	}
+	return false
+}
+
+// monsterWalk 怪物沿当前方向行走，遇到道路边缘或障碍物时停下
+// 返回 false 表示无法继续前进
+func monsterWalk(o *Obstacle, ctx *MonsterContext, speed float64) bool {
+	m := o.Monster
+	newX := o.X + m.Direction*speed
+
+	// 检查前进方向的边缘是否还有地面，防止走下道路
+	edgeX := newX
+	if m.Direction > 0 {
+		edgeX = newX + o.Width
+	}
+	if _, ok := ctx.GroundYAt(edgeX); !ok {
+		return false
+	}
+	if ctx.isBlocked(o, newX) {
+		return false
+	}
+
+	o.Move(newX-o.X, 0)
+	return true
+}
+
+// applyMonsterGravity 对怪物应用重力，落到道路上时停止下落
+func applyMonsterGravity(o *Obstacle, ctx *MonsterContext) {
+	m := o.Monster
+	m.VelocityY += gravity
+	o.Move(0, m.VelocityY)
+
+	m.IsOnGround = false
+	groundY, ok := ctx.GroundYAt(o.X + o.Width/2.0)
+	if ok && m.VelocityY >= 0 && o.Y+o.Height >= groundY && o.Y+o.Height-m.VelocityY <= groundY {
+		o.Move(0, groundY-(o.Y+o.Height))
+		m.VelocityY = 0
+		m.IsOnGround = true
+	}
+}
+
+// monsterMachine 怪物使用的状态机类型
+type monsterMachine = StateMachine[*MonsterContext]
+
+// monsterState 怪物使用的状态类型
+type monsterState = FSMState[*MonsterContext]
+
+// deadState 死亡状态：标记怪物需要从场景中移除
+func deadState(o *Obstacle) *monsterState {
+	return &monsterState{
+		Enter: func(ctx *MonsterContext) {
+			o.Monster.IsDead = true
+		},
+	}
+}
+
+// facePlayer 让怪物面向玩家，返回玩家相对怪物中心的水平距离
+func facePlayer(o *Obstacle, ctx *MonsterContext) float64 {
+	dx := ctx.Player.X - (o.X + o.Width/2.0)
+	if dx < 0 {
+		o.Monster.Direction = -1
+	} else {
+		o.Monster.Direction = 1
+	}
+	return dx
+}
+
+// canSeePlayer 判断怪物能否发现玩家
+// 有感知组件时按视距、视野和遮挡判断，否则只判断水平距离是否在 fallbackRange 内
+func canSeePlayer(o *Obstacle, ctx *MonsterContext, fallbackRange float64) bool {
+	m := o.Monster
+	if m.Def.Sight == nil {
+		return math.Abs(ctx.Player.X-(o.X+o.Width/2.0)) <= fallbackRange
+	}
+
+	eyeX := o.X + o.Width/2.0
+	eyeY := o.Y + o.Height*0.25
+	// 看向玩家碰撞盒的中心
+	targetY := ctx.Player.Y - playerCollisionHeight/2.0
+	return m.Def.Sight.CanSee(eyeX, eyeY, m.Direction, ctx.Player.X, targetY, ctx.Obstacles)
+}
+
+// tryJumpGap 站在道路段边缘时，如果导航数据显示前方缺口可以跳过，则起跳越过缺口
+// 水平速度按落点距离和滞空时间计算，保证正好落在对面道路段上
+func tryJumpGap(o *Obstacle, ctx *MonsterContext, jumpSpeed float64) bool {
+	m := o.Monster
+	centerX := o.X + o.Width/2.0
+	col := int(math.Floor(centerX / mapItemWidth))
+	landingCol, ok := ctx.Nav.JumpTarget(col, m.Direction)
+	if !ok {
+		return false
+	}
+
+	targetX := (float64(landingCol) + 0.5) * mapItemWidth
+	airFrames := 2 * jumpSpeed / gravity
+	m.VelocityX = (targetX - centerX) / airFrames
+	m.VelocityY = -jumpSpeed
+	m.IsOnGround = false
+	return true
+}
+
+// behaviorPatrol 巡逻：在出生点附近来回走动
+func behaviorPatrol(o *Obstacle) *monsterMachine {
+	m := o.Monster
+	patrolRange := m.Def.Param("patrolRange", mapItemWidth)
+
+	sm := NewStateMachine[*MonsterContext](AIStatePatrol)
+	sm.AddState(AIStatePatrol, &monsterState{
+		Update: func(ctx *MonsterContext) AIState {
+			if !monsterWalk(o, ctx, m.Def.Speed) || math.Abs(o.X-m.HomeX) > patrolRange {
+				m.Direction = -m.Direction
+			}
+			return AIStatePatrol
+		},
+	})
+	sm.AddState(AIStateDead, deadState(o))
+	return sm
+}
+
+// behaviorChase 追击：待机 -> 看到玩家后警觉 -> 反应时间结束后追击 -> 玩家远离或丢失视线后回到待机
+func behaviorChase(o *Obstacle) *monsterMachine {
+	m := o.Monster
+	aggroRange := m.Def.Param("aggroRange", 480)
+	alertFrames := int(m.Def.Param("alertFrames", 20))
+	loseSightFrames := int(m.Def.Param("loseSightFrames", 90))
+	jumpSpeed := m.Def.Param("jumpSpeed", 12)
+
+	sm := NewStateMachine[*MonsterContext](AIStateIdle)
+	sm.AddState(AIStateIdle, &monsterState{
+		Update: func(ctx *MonsterContext) AIState {
+			if canSeePlayer(o, ctx, aggroRange) {
+				return AIStateAlert
+			}
+			return AIStateIdle
+		},
+	})
+	sm.AddState(AIStateAlert, &monsterState{
+		Update: func(ctx *MonsterContext) AIState {
+			facePlayer(o, ctx)
+			if sm.StateFrames() >= alertFrames {
+				return AIStateAttack
+			}
+			return AIStateAlert
+		},
+	})
+	sm.AddState(AIStateAttack, &monsterState{
+		Enter: func(ctx *MonsterContext) {
+			m.Timer = 0 // 追击时连续看不到玩家的帧数
+		},
+		Update: func(ctx *MonsterContext) AIState {
+			// 跳跃越过缺口途中保持起跳时的水平速度，落地后继续追击
+			if !m.IsOnGround {
+				newX := o.X + m.VelocityX
+				if !ctx.isBlocked(o, newX) {
+					o.Move(newX-o.X, 0)
+				}
+				applyMonsterGravity(o, ctx)
+				return AIStateAttack
+			}
+
+			// 玩家跑出警戒范围的 1.5 倍后放弃追击，避免在边界反复切换
+			if math.Abs(facePlayer(o, ctx)) > aggroRange*1.5 {
+				return AIStateIdle
+			}
+
+			// 连续一段时间看不到玩家（例如被障碍物挡住）后放弃追击
+			if canSeePlayer(o, ctx, aggroRange*1.5) {
+				m.Timer = 0
+			} else {
+				m.Timer++
+				if m.Timer >= loseSightFrames {
+					return AIStateIdle
+				}
+			}
+
+			// 走到道路边缘时，尝试跳过前方的缺口，而不是直接走下去
+			if !monsterWalk(o, ctx, m.Def.Speed) {
+				tryJumpGap(o, ctx, jumpSpeed)
+			}
+			applyMonsterGravity(o, ctx)
+			return AIStateAttack
+		},
+	})
+	sm.AddState(AIStateDead, deadState(o))
+	return sm
+}
+
+// behaviorStatic 静止：站在原地不动（不受重力影响，可以放在平台上），始终面向玩家
+func behaviorStatic(o *Obstacle) *monsterMachine {
+	sm := NewStateMachine[*MonsterContext](AIStateIdle)
+	sm.AddState(AIStateIdle, &monsterState{
+		Update: func(ctx *MonsterContext) AIState {
+			facePlayer(o, ctx)
+			return AIStateIdle
+		},
+	})
+	sm.AddState(AIStateDead, deadState(o))
+	return sm
+}
+
+// behaviorHop 跳跃：每隔一段时间向前跳一次，前方落点没有道路时掉头
+func behaviorHop(o *Obstacle) *monsterMachine {
+	m := o.Monster
+	hopSpeed := m.Def.Param("hopSpeed", 12)
+	hopInterval := int(m.Def.Param("hopInterval", 90))
+
+	sm := NewStateMachine[*MonsterContext](AIStatePatrol)
+	sm.AddState(AIStatePatrol, &monsterState{
+		Update: func(ctx *MonsterContext) AIState {
+			if m.IsOnGround {
+				m.Timer++
+				if m.Timer >= hopInterval {
+					m.Timer = 0
+					// 估算落点：滞空时间 = 2 * 起跳速度 / 重力
+					airFrames := 2 * hopSpeed / gravity
+					landingX := o.X + o.Width/2.0 + m.Direction*m.Def.Speed*airFrames
+					if _, ok := ctx.GroundYAt(landingX); !ok {
+						m.Direction = -m.Direction
+					}
+					m.VelocityY = -hopSpeed
+				}
+			} else {
+				newX := o.X + m.Direction*m.Def.Speed
+				if !ctx.isBlocked(o, newX) {
+					o.Move(newX-o.X, 0)
+				}
+			}
+
+			applyMonsterGravity(o, ctx)
+			return AIStatePatrol
+		},
+	})
+	sm.AddState(AIStateDead, deadState(o))
+	return sm
+}
+
+// behaviorShoot 射击：待机 -> 看到玩家后警觉 -> 定时发射子弹 -> 玩家离开射程后回到待机
+func behaviorShoot(o *Obstacle) *monsterMachine {
+	m := o.Monster
+	shootRange := m.Def.Param("shootRange", 720)
+	shootInterval := int(m.Def.Param("shootInterval", 150))
+	alertFrames := int(m.Def.Param("alertFrames", 30))
+
+	sm := NewStateMachine[*MonsterContext](AIStateIdle)
+	sm.AddState(AIStateIdle, &monsterState{
+		Update: func(ctx *MonsterContext) AIState {
+			if canSeePlayer(o, ctx, shootRange) {
+				return AIStateAlert
+			}
+			return AIStateIdle
+		},
+	})
+	sm.AddState(AIStateAlert, &monsterState{
+		Update: func(ctx *MonsterContext) AIState {
+			facePlayer(o, ctx)
+			if sm.StateFrames() >= alertFrames {
+				return AIStateAttack
+			}
+			return AIStateAlert
+		},
+	})
+	sm.AddState(AIStateAttack, &monsterState{
+		Enter: func(ctx *MonsterContext) {
+			// 进入攻击状态后立即发射第一颗子弹
+			m.Timer = shootInterval
+		},
+		Update: func(ctx *MonsterContext) AIState {
+			if math.Abs(facePlayer(o, ctx)) > shootRange {
+				return AIStateIdle
+			}
+
+			m.Timer++
+			if m.Timer >= shootInterval {
+				m.Timer = 0
+				speed := m.Def.Param("projectileSpeed", 6)
+				ctx.Spawn(newProjectile(o.X+o.Width/2.0, o.Y+o.Height/3.0, m.Direction*speed))
+			}
+			return AIStateAttack
+		},
+	})
+	sm.AddState(AIStateDead, deadState(o))
+	return sm
+}
+
+// behaviorProjectile 子弹：匀速直线飞行，超过存活时间后移除
+func behaviorProjectile(o *Obstacle) *monsterMachine {
+	m := o.Monster
+
+	sm := NewStateMachine[*MonsterContext](AIStatePatrol)
+	sm.AddState(AIStatePatrol, &monsterState{
+		Update: func(ctx *MonsterContext) AIState {
+			o.Move(m.VelocityX, 0)
+			if sm.StateFrames() >= projectileLifeFrames {
+				return AIStateDead
+			}
+			return AIStatePatrol
+		},
+	})
+	sm.AddState(AIStateDead, deadState(o))
+	return sm
+}
+
+// projectileDef 子弹定义（由射击行为生成，不参与地图生成）
+var projectileDef = &MonsterDef{
+	Name:     "projectile",
+	Behavior: "projectile",
+	behavior: behaviorProjectile,
+	missile:  true,
+}
+
+// projectileImage 子弹图片（首次使用时生成）
+var projectileImage *ebiten.Image
+
+// newProjectile 创建子弹，子弹与怪物一样触碰即死
+// x, y: 子弹中心坐标
+// velocityX: 水平速度
+func newProjectile(x, y, velocityX float64) *Obstacle {
+	if projectileImage == nil {
+		projectileImage = ebiten.NewImage(int(projectileSize), int(projectileSize))
+		half := float32(projectileSize / 2.0)
+		vector.FillCircle(projectileImage, half, half, half, color.RGBA{R: 0xe0, G: 0x40, B: 0x30, A: 0xff}, true)
+	}
+
+	left := x - projectileSize/2.0
+	top := y - projectileSize/2.0
+	obstacle := NewObstacle(left, top, left, top, projectileSize, projectileSize, projectileImage, ObstacleTypeMonster)
+	obstacle.Monster = &Monster{
+		Def:       projectileDef,
+		HomeX:     left,
+		VelocityX: velocityX,
+	}
+	if velocityX < 0 {
+		obstacle.Monster.Direction = -1
+	} else {
+		obstacle.Monster.Direction = 1
+	}
+	obstacle.Monster.Brain = behaviorProjectile(obstacle)
+	return obstacle
+}
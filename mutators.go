@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// 低重力突变的重力倍数
+	lowGravityScale = 0.6
+	// 双倍速度突变的速度倍数（相机和玩家同时加速，玩家才能跟上相机）
+	doubleSpeedScale = 2.0
+)
+
+// Mutators 本局的突变组合（位掩码，可以叠加）
+type Mutators uint32
+
+const (
+	MutatorLowGravity  Mutators = 1 << iota // 低重力：玩家跳得更高更远
+	MutatorDoubleSpeed                      // 双倍速度：相机自动滚动和玩家移动速度加倍
+	MutatorNoTools                          // 无道具：地图上不出现飞行道具
+	MutatorMirror                           // 镜像：画面水平翻转，左右操作互换，相机在屏幕上向左滚动
+)
+
+// mutatorNames 突变名称（命令行参数使用），按位的顺序排列
+var mutatorNames = []struct {
+	mutator Mutators
+	name    string
+}{
+	{MutatorLowGravity, "lowgravity"},
+	{MutatorDoubleSpeed, "doublespeed"},
+	{MutatorNoTools, "notools"},
+	{MutatorMirror, "mirror"},
+}
+
+// Has 是否包含指定的突变
+func (m Mutators) Has(mutator Mutators) bool {
+	return m&mutator != 0
+}
+
+// String 返回逗号分隔的突变名称，没有突变时返回空字符串
+func (m Mutators) String() string {
+	var names []string
+	for _, entry := range mutatorNames {
+		if m.Has(entry.mutator) {
+			names = append(names, entry.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseMutators 解析逗号分隔的突变名称列表
+func ParseMutators(list string) (Mutators, error) {
+	var mutators Mutators
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, entry := range mutatorNames {
+			if strings.EqualFold(name, entry.name) {
+				mutators |= entry.mutator
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("未知的突变: %s", name)
+		}
+	}
+	return mutators, nil
+}
+
+// PlayerPhysics 按突变调整后的玩家移动参数
+func (m Mutators) PlayerPhysics() PlayerPhysics {
+	physics := DefaultPlayerPhysics()
+	if m.Has(MutatorLowGravity) {
+		physics.Gravity *= lowGravityScale
+	}
+	if m.Has(MutatorDoubleSpeed) {
+		physics.Speed *= doubleSpeedScale
+	}
+	return physics
+}
+
+// CameraSpeed 按突变调整后的相机自动滚动速度（不影响飞行时的相机速度）
+func (m Mutators) CameraSpeed() float64 {
+	if m.Has(MutatorDoubleSpeed) {
+		return cameraSpeed * doubleSpeedScale
+	}
+	return cameraSpeed
+}
+
+// ApplyToMap 按突变修改地图数据（在地图生成或加载之后调用，不影响随机数流）
+func (m Mutators) ApplyToMap(items []*MapItem) {
+	if m.Has(MutatorNoTools) {
+		for _, item := range items {
+			item.HasTool = false
+		}
+	}
+}
+
+// SeedCode 把种子和突变编码成一个种子码，分享种子码即可在相同条件下比较成绩
+// 格式为 "种子-突变"，两部分都是 36 进制（大写）
+func SeedCode(seed int64, mutators Mutators) string {
+	return strings.ToUpper(strconv.FormatUint(uint64(seed), 36) + "-" + strconv.FormatUint(uint64(mutators), 36))
+}
+
+// ParseSeedCode 解析种子码
+func ParseSeedCode(code string) (int64, Mutators, error) {
+	seedPart, mutatorPart, ok := strings.Cut(code, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("无效的种子码: %s", code)
+	}
+	seed, err := strconv.ParseUint(seedPart, 36, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("无效的种子码 %s: %w", code, err)
+	}
+	mutators, err := strconv.ParseUint(mutatorPart, 36, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("无效的种子码 %s: %w", code, err)
+	}
+	if unknown := Mutators(mutators) &^ knownMutators(); unknown != 0 {
+		return 0, 0, fmt.Errorf("无效的种子码 %s: 未知的突变 %#x", code, uint32(unknown))
+	}
+	return int64(seed), Mutators(mutators), nil
+}
+
+// knownMutators 返回 mutatorNames 中所有突变的位掩码（种子码中其他的位无效）
+func knownMutators() Mutators {
+	var known Mutators
+	for _, entry := range mutatorNames {
+		known |= entry.mutator
+	}
+	return known
+}
@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+// TestParseSeedCode 种子码可以还原种子和突变，格式错误或包含未知突变位的种子码返回错误
+func TestParseSeedCode(t *testing.T) {
+	for _, mutators := range []Mutators{0, MutatorLowGravity, MutatorDoubleSpeed | MutatorMirror, knownMutators()} {
+		code := SeedCode(20261016, mutators)
+		seed, got, err := ParseSeedCode(code)
+		if err != nil || seed != 20261016 || got != mutators {
+			t.Errorf("ParseSeedCode(%q) = %d, %v, %v，应为 20261016, %v", code, seed, got, err, mutators)
+		}
+	}
+
+	for _, code := range []string{
+		"",
+		"C1V2O",
+		"C1V2O-",
+		"C1V2O-!",
+		SeedCode(20261016, MutatorMirror<<1),
+		SeedCode(20261016, MutatorLowGravity|1<<31),
+	} {
+		if _, _, err := ParseSeedCode(code); err == nil {
+			t.Errorf("ParseSeedCode(%q) 应返回错误", code)
+		}
+	}
+}
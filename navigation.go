@@ -0,0 +1,100 @@
+package main
+
+const (
+	// 地面怪物最多能跳过的连续缺口列数
+	navMaxJumpColumns = 2
+)
+
+// NavSegment 可行走的连续道路段（列索引闭区间）
+type NavSegment struct {
+	Start, End int // 起止列索引
+	JumpLeft   int // 从左端向左跳过缺口后的落点列，-1 表示无法跳过
+	JumpRight  int // 从右端向右跳过缺口后的落点列，-1 表示无法跳过
+}
+
+// NavMap 由 MapItems 推导出的导航数据
+// 有道路且没有障碍物的列可以行走；两个道路段之间只隔着不超过 navMaxJumpColumns 列的空缺时可以跳过去，
+// 障碍物列视为墙，不能跳过
+type NavMap struct {
+	Segments      []NavSegment
+	columnSegment []int // 每列所属道路段的索引，-1 表示不可行走
+}
+
+// BuildNavMap 根据地图生成导航数据
+func BuildNavMap(items []*MapItem) *NavMap {
+	nav := &NavMap{
+		columnSegment: make([]int, len(items)),
+	}
+
+	// 划分可行走的道路段
+	for i, item := range items {
+		nav.columnSegment[i] = -1
+		if !item.HasRoad || item.HasObstacle {
+			continue
+		}
+
+		if i > 0 && nav.columnSegment[i-1] >= 0 {
+			// 与左侧相连，延长当前段
+			index := nav.columnSegment[i-1]
+			nav.Segments[index].End = i
+			nav.columnSegment[i] = index
+			continue
+		}
+
+		nav.Segments = append(nav.Segments, NavSegment{Start: i, End: i, JumpLeft: -1, JumpRight: -1})
+		nav.columnSegment[i] = len(nav.Segments) - 1
+	}
+
+	// 计算相邻道路段之间能否跳跃
+	for i := 0; i+1 < len(nav.Segments); i++ {
+		left := &nav.Segments[i]
+		right := &nav.Segments[i+1]
+		gap := right.Start - left.End - 1
+		if gap > navMaxJumpColumns {
+			continue
+		}
+
+		// 缺口中只能是没有道路的列，障碍物挡住了跳跃路线
+		jumpable := true
+		for col := left.End + 1; col < right.Start; col++ {
+			if items[col].HasRoad {
+				jumpable = false
+				break
+			}
+		}
+		if jumpable {
+			left.JumpRight = right.Start
+			right.JumpLeft = left.End
+		}
+	}
+
+	return nav
+}
+
+// SegmentAt 获取给定列所属道路段，不可行走时返回 nil
+func (n *NavMap) SegmentAt(col int) *NavSegment {
+	if col < 0 || col >= len(n.columnSegment) || n.columnSegment[col] < 0 {
+		return nil
+	}
+	return &n.Segments[n.columnSegment[col]]
+}
+
+// JumpTarget 获取从给定列沿 direction 方向跳过缺口后的落点列
+// 只有站在道路段边缘（或距边缘一列以内）时才能跳
+func (n *NavMap) JumpTarget(col int, direction float64) (int, bool) {
+	segment := n.SegmentAt(col)
+	if segment == nil {
+		return 0, false
+	}
+
+	if direction > 0 {
+		if segment.JumpRight < 0 || segment.End-col > 1 {
+			return 0, false
+		}
+		return segment.JumpRight, true
+	}
+	if segment.JumpLeft < 0 || col-segment.Start > 1 {
+		return 0, false
+	}
+	return segment.JumpLeft, true
+}
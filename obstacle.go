@@ -1,24 +1,154 @@
 package main
 
-import "github.com/hajimehoshi/ebiten/v2"
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"math/rand"
+	"os"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 障碍物目录路径
+	obstacleCatalogPath = "res/data/obstacles.json"
+
+	// 障碍物目录中必须存在的条目
+	obstacleDefGrass    = "grass"
+	obstacleDefObstacle = "obstacle"
+	obstacleDefTool     = "tool"
+)
 
 // ObstacleType 障碍物类型枚举
 type ObstacleType int
 
 const (
-	ObstacleTypeGrass    ObstacleType = iota // 道路（草地）
-	ObstacleTypeObstacle                     // 障碍物
-	ObstacleTypeMonster                      // 怪物
-	ObstacleTypeTool                         // 道具
+	ObstacleTypeGrass      ObstacleType = iota // 道路（草地）
+	ObstacleTypeObstacle                       // 障碍物
+	ObstacleTypeMonster                        // 怪物
+	ObstacleTypeTool                           // 道具
+	ObstacleTypeCoin                           // 金币
+	ObstacleTypePlatform                       // 单向平台（只能从上方落到上面，不阻挡水平移动和向上穿越）
+	ObstacleTypeDecoration                     // 装饰物（只绘制，不参与碰撞，保存在 Game.Decorations 中）
+	ObstacleTypeSwing                          // 秋千点（不阻挡移动，玩家在空中按住抓取键时抓住）
+	ObstacleTypeSwitch                         // 开关踏板（不阻挡移动，玩家碰到时打开链接的门）
+	ObstacleTypeKey                            // 钥匙（不阻挡移动，由 DeviceSystem 处理拾取）
+)
+
+// obstacleTypeNames 障碍物类型名称（调试输出使用）
+var obstacleTypeNames = map[ObstacleType]string{
+	ObstacleTypeGrass:      "grass",
+	ObstacleTypeObstacle:   "obstacle",
+	ObstacleTypeMonster:    "monster",
+	ObstacleTypeTool:       "tool",
+	ObstacleTypeCoin:       "coin",
+	ObstacleTypePlatform:   "platform",
+	ObstacleTypeDecoration: "decoration",
+	ObstacleTypeSwing:      "swing",
+	ObstacleTypeSwitch:     "switch",
+	ObstacleTypeKey:        "key",
+}
+
+// String 返回障碍物类型名称
+func (t ObstacleType) String() string {
+	return obstacleTypeNames[t]
+}
+
+// Solidity 障碍物从哪些方向阻挡玩家（位掩码，可以叠加）
+type Solidity uint8
+
+const (
+	SolidFromAbove Solidity = 1 << iota // 从上方落下时可以站在上面
+	SolidFromSides                      // 阻挡水平移动（重叠时把玩家推出，可以把玩家挤在屏幕边缘）
+	SolidFromBelow                      // 向上跳起时撞头（天花板，例如洞穴顶部），向上的速度清零
 )
 
-// Obstacle 障碍物类（用于 grass、obstacle、monster 和 tool）
+// solidityNames 阻挡方向名称（障碍物目录使用），按位的顺序排列
+var solidityNames = []struct {
+	solidity Solidity
+	name     string
+}{
+	{SolidFromAbove, "top"},
+	{SolidFromSides, "sides"},
+	{SolidFromBelow, "bottom"},
+}
+
+// Has 是否从指定方向阻挡
+func (s Solidity) Has(solidity Solidity) bool {
+	return s&solidity != 0
+}
+
+// String 返回逗号分隔的阻挡方向名称，不阻挡时返回 "none"
+func (s Solidity) String() string {
+	var names []string
+	for _, entry := range solidityNames {
+		if s.Has(entry.solidity) {
+			names = append(names, entry.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseSolidity 解析逗号分隔的阻挡方向名称列表（"none" 表示不阻挡）
+func ParseSolidity(list string) (Solidity, error) {
+	var solidity Solidity
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || strings.EqualFold(name, "none") {
+			continue
+		}
+		found := false
+		for _, entry := range solidityNames {
+			if strings.EqualFold(name, entry.name) {
+				solidity |= entry.solidity
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("未知的阻挡方向: %s", name)
+		}
+	}
+	return solidity, nil
+}
+
+// defaultSolidity 各类型障碍物默认的阻挡方向：道路和障碍物从上方和侧面阻挡（可以从下方跳穿），
+// 单向平台只从上方阻挡，怪物、道具、金币和装饰物不阻挡（由各自的逻辑处理）
+func defaultSolidity(obstacleType ObstacleType) Solidity {
+	switch obstacleType {
+	case ObstacleTypeGrass, ObstacleTypeObstacle:
+		return SolidFromAbove | SolidFromSides
+	case ObstacleTypePlatform:
+		return SolidFromAbove
+	}
+	return 0
+}
+
+// Obstacle 障碍物类（用于 grass、obstacle、monster、tool、coin、platform、巨石和秋千点）
 type Obstacle struct {
-	Dx, Dy        float64       // 绘制使用的 x y
-	X, Y          float64       // 碰撞检查使用的 x y
-	Width, Height float64       // 碰撞检查使用的 宽度与高度
-	Image         *ebiten.Image // 图片资源
-	Type          ObstacleType  // 障碍物类型
+	Dx, Dy        float64           // 绘制使用的 x y
+	X, Y          float64           // 碰撞检查使用的 x y
+	Width, Height float64           // 碰撞检查使用的 宽度与高度
+	Image         *ebiten.Image     // 图片资源
+	Type          ObstacleType      // 障碍物类型
+	Solidity      Solidity          // 从哪些方向阻挡玩家（默认按类型，障碍物目录可以用 solid 修改）
+	FlipX         bool              // 绘制时是否水平翻转
+	ScaleX        float64           // 绘制时的水平缩放
+	ScaleY        float64           // 绘制时的垂直缩放
+	Rotation      float64           // 绘制时绕图片中心旋转的角度（弧度，巨石滚动时使用）
+	Tint          ebiten.ColorScale // 绘制时的颜色调整（零值为不调整）
+	PowerUp       PowerUp           // 道具的效果（只用于道具，零值为飞行）
+	Monster       *Monster          // 怪物运行时状态（仅怪物和子弹有）
+	Boulder       *Boulder          // 巨石运行时状态（仅巨石有）
+	Device        *Device           // 机关运行时状态（仅开关和门有）
 }
 
 // NewObstacle 创建新障碍物
@@ -29,17 +159,32 @@ type Obstacle struct {
 // obstacleType: 障碍物类型
 func NewObstacle(dx, dy, x, y, width, height float64, image *ebiten.Image, obstacleType ObstacleType) *Obstacle {
 	return &Obstacle{
-		Dx:     dx,
-		Dy:     dy,
-		X:      x,
-		Y:      y,
-		Width:  width,
-		Height: height,
-		Image:  image,
-		Type:   obstacleType,
+		Dx:       dx,
+		Dy:       dy,
+		X:        x,
+		Y:        y,
+		Width:    width,
+		Height:   height,
+		Image:    image,
+		Type:     obstacleType,
+		Solidity: defaultSolidity(obstacleType),
+		ScaleX:   1,
+		ScaleY:   1,
 	}
 }
 
+// onScreen 判断障碍物按缩放后的绘制尺寸是否在相机视口内（使用全局常量）
+func (o *Obstacle) onScreen(camera *engine.Camera) bool {
+	screenX, screenY := camera.WorldToScreen(o.Dx, o.Dy)
+	bounds := o.Image.Bounds()
+	drawWidth := float64(bounds.Dx()) * o.ScaleX
+	drawHeight := float64(bounds.Dy()) * o.ScaleY
+	if screenX+drawWidth < 0 || screenX > float64(windowWidth) {
+		return false
+	}
+	return screenY+drawHeight >= 0 && screenY <= float64(windowHeight)
+}
+
 // GetCollisionBox 获取碰撞盒边界
 // 返回：左边界, 右边界, 上边界, 下边界
 func (o *Obstacle) GetCollisionBox() (left, right, top, bottom float64) {
@@ -61,28 +206,288 @@ func (o *Obstacle) SetPosition(x, y float64) {
 	o.Y = y
 }
 
+// Move 同时移动绘制坐标和碰撞盒坐标
+func (o *Obstacle) Move(dx, dy float64) {
+	o.Dx += dx
+	o.Dy += dy
+	o.X += dx
+	o.Y += dy
+}
+
 // Draw 绘制障碍物
 // screen: 绘制目标
-// cameraX: 相机 X 坐标（用于计算屏幕坐标）
-func (o *Obstacle) Draw(screen *ebiten.Image, cameraX float64) {
+// camera: 相机（用于计算屏幕坐标）
+func (o *Obstacle) Draw(screen *ebiten.Image, camera *engine.Camera) {
 	if o.Image == nil {
 		return
 	}
 
-	// 计算相对于相机的屏幕坐标
-	screenX := o.Dx - cameraX
-	screenY := o.Dy
-
-	// 只绘制窗口内的内容（使用全局常量）
-	if screenX+o.Width < 0 || screenX > float64(windowWidth) {
-		return
-	}
-	if screenY+o.Height < 0 || screenY > float64(windowHeight) {
+	// 只绘制窗口内的内容
+	if !o.onScreen(camera) {
 		return
 	}
 
 	// 绘制障碍物
 	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(screenX, screenY)
+	op.GeoM = o.drawGeoM(camera)
+	op.ColorScale = o.Tint
 	screen.DrawImage(o.Image, op)
 }
+
+// Enqueue 把障碍物加入渲染队列（视口外的不加入），按类型放到对应的绘制层
+func (o *Obstacle) Enqueue(queue *engine.RenderQueue, camera *engine.Camera) {
+	if o.Image == nil || !o.onScreen(camera) {
+		return
+	}
+	queue.Add(renderLayer(o.Type), o.Image, o.drawGeoM(camera), o.Tint)
+}
+
+// drawGeoM 计算图片左上角到屏幕的变换（旋转、水平翻转、缩放、相对于相机的位置）
+func (o *Obstacle) drawGeoM(camera *engine.Camera) ebiten.GeoM {
+	var geom ebiten.GeoM
+	if o.Rotation != 0 {
+		w, h := float64(o.Image.Bounds().Dx()), float64(o.Image.Bounds().Dy())
+		geom.Translate(-w/2, -h/2)
+		geom.Rotate(o.Rotation)
+		geom.Translate(w/2, h/2)
+	}
+	if o.FlipX {
+		// 以图片左上角为轴翻转后，向右移动图片宽度补偿
+		geom.Scale(-1, 1)
+		geom.Translate(float64(o.Image.Bounds().Dx()), 0)
+	}
+	geom.Scale(o.ScaleX, o.ScaleY)
+	screenX, screenY := camera.WorldToScreen(o.Dx, o.Dy)
+	geom.Translate(screenX, screenY)
+	return geom
+}
+
+// 地图的绘制层（渲染队列按层排序，同一层内相同图片合批绘制）
+const (
+	renderLayerDecoration = iota // 装饰物（画在最后面）
+	renderLayerRoad              // 道路和单向平台
+	renderLayerObstacle          // 障碍物
+	renderLayerPickup            // 道具和金币
+	renderLayerMonster           // 怪物和子弹
+)
+
+// renderLayer 返回障碍物类型所在的绘制层
+func renderLayer(obstacleType ObstacleType) int {
+	switch obstacleType {
+	case ObstacleTypeDecoration:
+		return renderLayerDecoration
+	case ObstacleTypeGrass, ObstacleTypePlatform, ObstacleTypeSwitch:
+		return renderLayerRoad
+	case ObstacleTypeTool, ObstacleTypeCoin, ObstacleTypeSwing, ObstacleTypeKey:
+		return renderLayerPickup
+	case ObstacleTypeMonster:
+		return renderLayerMonster
+	}
+	return renderLayerObstacle
+}
+
+// CollisionBoxDef 碰撞盒定义（相对于绘制位置左上角的偏移和尺寸）
+type CollisionBoxDef struct {
+	OffsetX float64 `json:"offsetX"`
+	OffsetY float64 `json:"offsetY"`
+	Width   float64 `json:"width"`
+	Height  float64 `json:"height"`
+}
+
+// ObstacleVariant 障碍物变体（同一种障碍物的不同外观）
+// 每个地图块按权重随机选择变体，避免长段道路看起来完全一样
+type ObstacleVariant struct {
+	ImagePath string           `json:"image"`     // 图片路径，为空时使用障碍物本身的图片
+	ScaleX    float64          `json:"scaleX"`    // 水平缩放，0 表示不缩放
+	ScaleY    float64          `json:"scaleY"`    // 垂直缩放，0 表示不缩放
+	Tint      []float32        `json:"tint"`      // RGB 颜色倍数，为空表示不调整
+	Collision *CollisionBoxDef `json:"collision"` // 碰撞盒（相对缩放后的绘制位置），为空时使用缩放后的整张图片
+	Weight    int              `json:"weight"`    // 选择权重
+
+	image *ebiten.Image
+}
+
+// ObstacleDef 静态障碍物定义（从障碍物目录 JSON 加载）
+// 美术资源改动时只需修改数据文件中的图片和碰撞盒，不需要修改代码
+type ObstacleDef struct {
+	Name       string             `json:"name"`       // 名称
+	ImagePath  string             `json:"image"`      // 图片路径
+	Collision  *CollisionBoxDef   `json:"collision"`  // 碰撞盒，为空时使用整张图片
+	BaseWeight int                `json:"baseWeight"` // 不使用变体（原始外观）的选择权重
+	Variants   []*ObstacleVariant `json:"variants"`   // 外观变体
+	Decoration bool               `json:"decoration"` // 是否是装饰物（随机摆放在道路上，不参与碰撞）
+	Chance     float64            `json:"chance"`     // 装饰物在每个空闲道路块上出现的概率
+	AutoTile   *AutoTileDef       `json:"autotile"`   // 自动拼接（只用于道路，为空时所有道路块使用同一张图片）
+	Solid      string             `json:"solid"`      // 阻挡方向（逗号分隔的 top、sides、bottom 或 none），为空时按类型默认
+
+	solidity *Solidity // 解析后的阻挡方向（没有设置时为 nil）
+	image    *ebiten.Image
+	tiles    [roadTileCount]*ebiten.Image // 各种类的图块（没有自动拼接时为空）
+}
+
+// Image 获取障碍物图片
+func (d *ObstacleDef) Image() *ebiten.Image {
+	return d.image
+}
+
+// Size 获取图片尺寸（用于排布）
+func (d *ObstacleDef) Size() (width, height float64) {
+	bounds := d.image.Bounds()
+	return float64(bounds.Dx()), float64(bounds.Dy())
+}
+
+// NewObstacle 在绘制位置 (x, y) 创建障碍物，碰撞盒按定义偏移
+func (d *ObstacleDef) NewObstacle(x, y float64, obstacleType ObstacleType) *Obstacle {
+	width, height := d.Size()
+	box := CollisionBoxDef{Width: width, Height: height}
+	if d.Collision != nil {
+		box = *d.Collision
+	}
+	return d.applySolidity(NewObstacle(x, y, x+box.OffsetX, y+box.OffsetY, box.Width, box.Height, d.image, obstacleType))
+}
+
+// applySolidity 定义中设置了阻挡方向时覆盖障碍物按类型默认的阻挡方向
+func (d *ObstacleDef) applySolidity(obstacle *Obstacle) *Obstacle {
+	if d.solidity != nil {
+		obstacle.Solidity = *d.solidity
+	}
+	return obstacle
+}
+
+// PickVariant 按权重随机选择变体，返回 nil 表示使用原始外观
+func (d *ObstacleDef) PickVariant(random *rand.Rand) *ObstacleVariant {
+	total := d.BaseWeight
+	for _, v := range d.Variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	n := random.Intn(total)
+	if n < d.BaseWeight {
+		return nil
+	}
+	n -= d.BaseWeight
+	for _, v := range d.Variants {
+		if n < v.Weight {
+			return v
+		}
+		n -= v.Weight
+	}
+	return nil
+}
+
+// VariantSize 获取变体缩放后的绘制尺寸，variant 为 nil 时返回原始图片尺寸
+func (d *ObstacleDef) VariantSize(variant *ObstacleVariant) (width, height float64) {
+	if variant == nil {
+		return d.Size()
+	}
+	bounds := variant.image.Bounds()
+	return float64(bounds.Dx()) * variant.scaleX(), float64(bounds.Dy()) * variant.scaleY()
+}
+
+// PlaceVariant 以 (centerX, bottomY) 为底部中心放置变体，variant 为 nil 时使用原始外观
+// 更宽或更高的变体从底部中心向外扩展，碰撞盒按变体定义计算
+func (d *ObstacleDef) PlaceVariant(variant *ObstacleVariant, centerX, bottomY float64, obstacleType ObstacleType) *Obstacle {
+	width, height := d.VariantSize(variant)
+	x := centerX - width/2.0
+	y := bottomY - height
+	if variant == nil {
+		return d.NewObstacle(x, y, obstacleType)
+	}
+
+	box := CollisionBoxDef{Width: width, Height: height}
+	if variant.Collision != nil {
+		box = *variant.Collision
+	}
+	obstacle := d.applySolidity(NewObstacle(x, y, x+box.OffsetX, y+box.OffsetY, box.Width, box.Height, variant.image, obstacleType))
+	obstacle.ScaleX = variant.scaleX()
+	obstacle.ScaleY = variant.scaleY()
+	if len(variant.Tint) >= 3 {
+		obstacle.Tint.Scale(variant.Tint[0], variant.Tint[1], variant.Tint[2], 1)
+	}
+	return obstacle
+}
+
+// scaleX 获取水平缩放（未设置时为 1）
+func (v *ObstacleVariant) scaleX() float64 {
+	if v.ScaleX == 0 {
+		return 1
+	}
+	return v.ScaleX
+}
+
+// scaleY 获取垂直缩放（未设置时为 1）
+func (v *ObstacleVariant) scaleY() float64 {
+	if v.ScaleY == 0 {
+		return 1
+	}
+	return v.ScaleY
+}
+
+// ObstacleCatalog 障碍物目录
+type ObstacleCatalog struct {
+	defs        map[string]*ObstacleDef
+	Decorations []*ObstacleDef // 装饰物定义（按文件中的顺序，保证随机摆放的结果确定）
+}
+
+// Get 按名称获取障碍物定义，不存在时返回 nil
+func (c *ObstacleCatalog) Get(name string) *ObstacleDef {
+	return c.defs[name]
+}
+
+// LoadObstacleCatalog 从 JSON 文件加载障碍物目录，并加载所有图片
+func LoadObstacleCatalog(path string) (*ObstacleCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var defs []*ObstacleDef
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("解析障碍物目录失败: %w", err)
+	}
+
+	catalog := &ObstacleCatalog{defs: make(map[string]*ObstacleDef, len(defs))}
+	for _, def := range defs {
+		if def.Solid != "" {
+			solidity, err := ParseSolidity(def.Solid)
+			if err != nil {
+				return nil, fmt.Errorf("障碍物 %s: %w", def.Name, err)
+			}
+			def.solidity = &solidity
+		}
+		var src image.Image
+		def.image, src, err = ebitenutil.NewImageFromFile(def.ImagePath)
+		if err != nil {
+			return nil, fmt.Errorf("加载障碍物 %s 的图片失败: %w", def.Name, err)
+		}
+		if def.AutoTile != nil {
+			if err := def.loadTiles(src); err != nil {
+				return nil, fmt.Errorf("障碍物 %s: %w", def.Name, err)
+			}
+		}
+		for _, variant := range def.Variants {
+			variant.image = def.image
+			if variant.ImagePath == "" {
+				continue
+			}
+			variant.image, _, err = ebitenutil.NewImageFromFile(variant.ImagePath)
+			if err != nil {
+				return nil, fmt.Errorf("加载障碍物 %s 的变体图片失败: %w", def.Name, err)
+			}
+		}
+		catalog.defs[def.Name] = def
+		if def.Decoration {
+			catalog.Decorations = append(catalog.Decorations, def)
+		}
+	}
+
+	for _, name := range []string{obstacleDefGrass, obstacleDefObstacle, obstacleDefTool} {
+		if catalog.defs[name] == nil {
+			return nil, fmt.Errorf("障碍物目录缺少 %s: %s", name, path)
+		}
+	}
+	return catalog, nil
+}
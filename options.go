@@ -0,0 +1,269 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// 随机生成地图时的默认列数
+	defaultMapLength = 512
+	// 音频输出采样率的允许范围
+	minSampleRate = 8000
+	maxSampleRate = 192000
+	// 环境变量前缀，例如 MYGAME_SEED=42 等价于 -seed 42
+	envPrefix = "MYGAME_"
+)
+
+// GameOptions 启动选项（命令行参数和环境变量），在创建游戏之前解析
+type GameOptions struct {
+	Profile       string             // 存档名称（为空时打开存档选择，没有存档时使用默认存档）
+	SyncURL       string             // 存档同步地址（http(s)/webdav(s)，为空时不同步）
+	Seed          int64              // 随机种子（地图生成和怪物种类），相同种子生成相同的关卡
+	Mutators      Mutators           // 本局的突变组合
+	Mods          []string           // 启用的模组名称（按顺序安装）
+	LevelPath     string             // 关卡文件路径，为空时随机生成地图
+	Levels        bool               // 是否先打开社区关卡浏览，选择关卡后再开始游戏
+	Stages        bool               // 是否先打开关卡选择（游戏自带的关卡，按顺序解锁），选择关卡后再开始游戏
+	LevelIndex    string             // 远程关卡索引地址（关卡浏览中列出，为空时只列出本地关卡）
+	ExportBundle  string             // 要打包的关卡文件路径（打包后退出，不打开窗口）
+	ImportBundle  string             // 要安装到关卡目录的关卡包路径（安装后退出，不打开窗口）
+	MapLength     int                // 随机生成地图的列数
+	Scroll        ScrollMode         // 随机生成地图的相机滚动方式（关卡文件自带滚动方式）
+	Vertical      bool               // 随机生成地图时是否放置纵向滚动段（只在向右滚动时生效）
+	Display       DisplaySettings    // 显示设置（窗口模式、分辨率、显示器）
+	Theme         string             // 关卡主题名称（主题目录中的名称）
+	Quality       GraphicsQuality    // 画面质量
+	Skin          string             // 玩家皮肤（调色板目录中的名称，为空时使用原色）
+	Skeleton      string             // 玩家骨骼动画文件路径（为空时只使用精灵表动画）
+	TimeScale     float64            // 基础时间倍数（调试慢动作，1 为正常速度）
+	LandingAssist bool               // 是否开启落点预测辅助
+	Captions      bool               // 是否显示声音提示的字幕
+	Announcer     bool               // 是否开启连击播报语音
+	FocusAudio    FocusLossAudioMode // 窗口失去焦点时的音频处理方式
+	AutoPause     bool               // 窗口失去焦点或最小化时是否自动暂停游戏
+	Analytics     bool               // 是否在本地记录跑图统计（保存到 analytics/ 目录）
+	Presence      bool               // 是否显示 Discord 在线状态（需要 -tags discord 编译）
+	SampleRate    int                // 音频输出采样率
+	Mute          bool               // 是否静音
+	MusicVolume   float64            // 背景音乐音量（0 到 1）
+	SFXVolume     float64            // 音效音量（0 到 1）
+	Hitboxes      bool               // 是否绘制碰撞盒（-debug 时同样绘制）
+	Debug         bool               // 是否显示调试信息（碰撞盒等）
+	Dev           bool               // 开发模式（脚本修改后自动重新加载）
+	Editor        bool               // 是否以编辑器模式启动
+	AnimPreview   bool               // 是否打开动画预览（调试用，检查美术资源）
+	ReplayPath    string             // 回放文件路径，不为空时播放该回放
+	Soak          int                // 浸泡测试的帧数（大于 0 时不打开窗口，由机器人连续游玩）
+
+	explicit map[string]bool  // 由命令行参数或环境变量指定的选项（存档记住的设置不覆盖这些选项）
+	autoplay bool             // 是否不由玩家操作（吸引模式的演示和浸泡测试，没有回放时由机器人操作，不是启动选项）
+	golden   bool             // 是否按输入脚本操作玩家并隐藏帧率（渲染测试，不是启动选项）
+	scenes   *SceneManager    // 启动游戏的场景管理器（为 nil 时结算界面之后不切换场景，例如编辑器和测试模式）
+	loading  *LoadingProgress // 加载界面的进度（由加载界面设置，NewGame 用完后清除，不是启动选项）
+	replay   *ReplayPlayer    // 按回放操作玩家（回放观看，不是启动选项）
+}
+
+// ParseOptions 解析启动选项
+// 优先级：命令行参数 > 环境变量 > 默认值；未指定种子时使用当前时间
+func ParseOptions(args []string) (GameOptions, error) {
+	opts := GameOptions{
+		Profile:       envString("PROFILE", ""),
+		SyncURL:       envString("SYNC", ""),
+		Seed:          envInt("SEED", 0),
+		LevelPath:     envString("LEVEL", ""),
+		Levels:        envBool("LEVELS"),
+		Stages:        envBool("STAGES"),
+		LevelIndex:    envString("LEVEL_INDEX", ""),
+		MapLength:     int(envInt("MAP_LENGTH", defaultMapLength)),
+		Theme:         envString("THEME", defaultThemeName),
+		Skin:          envString("SKIN", ""),
+		Skeleton:      envString("SKELETON", ""),
+		TimeScale:     envFloat("TIMESCALE", 1),
+		Vertical:      envBool("VERTICAL"),
+		LandingAssist: envBool("LANDING_ASSIST"),
+		Captions:      envBool("CAPTIONS"),
+		Announcer:     envBoolOr("ANNOUNCER", true),
+		AutoPause:     envBoolOr("PAUSE_ON_FOCUS_LOSS", true),
+		Analytics:     envBool("ANALYTICS"),
+		Presence:      envBool("PRESENCE"),
+		SampleRate:    int(envInt("SAMPLE_RATE", audioSampleRate)),
+		Mute:          envBool("MUTE"),
+		MusicVolume:   envFloat("MUSIC_VOLUME", 1),
+		SFXVolume:     envFloat("SFX_VOLUME", 1),
+		Hitboxes:      envBool("HITBOXES"),
+		Debug:         envBool("DEBUG"),
+		Dev:           envBool("DEV"),
+		Editor:        envBool("EDITOR"),
+		AnimPreview:   envBool("ANIM_PREVIEW"),
+		ReplayPath:    envString("REPLAY", ""),
+		Soak:          int(envInt("SOAK", 0)),
+	}
+
+	fs := flag.NewFlagSet("my_ai_game", flag.ContinueOnError)
+	fs.StringVar(&opts.Profile, "profile", opts.Profile, "存档名称（不存在时新建；为空时打开存档选择）")
+	fs.StringVar(&opts.SyncURL, "sync", opts.SyncURL, "存档同步地址（http、https、webdav 或 webdavs，存档保存为 <地址>/<存档名称>.json）")
+	fs.Int64Var(&opts.Seed, "seed", opts.Seed, "随机种子（0 表示使用当前时间）")
+	mutators := fs.String("mutators", envString("MUTATORS", ""), "逗号分隔的突变：lowgravity、doublespeed、notools、mirror")
+	modList := fs.String("mods", envString("MODS", ""), "逗号分隔的模组名称（按顺序安装）")
+	code := fs.String("code", envString("CODE", ""), "种子码（同时指定种子和突变，覆盖 -seed 和 -mutators）")
+	fs.StringVar(&opts.LevelPath, "level", opts.LevelPath, "关卡文件路径（为空时随机生成地图）")
+	fs.BoolVar(&opts.Levels, "levels", opts.Levels, "打开社区关卡浏览（列出 levels/ 目录中的关卡）")
+	fs.BoolVar(&opts.Stages, "stages", opts.Stages, "打开关卡选择（列出 res/levels/ 目录中游戏自带的关卡，通关一关后解锁下一关）")
+	fs.StringVar(&opts.LevelIndex, "level-index", opts.LevelIndex, "远程关卡索引地址（关卡浏览中一起列出）")
+	fs.StringVar(&opts.ExportBundle, "export-bundle", "", "把关卡文件和它自带的资源打包成同名的 .zip 关卡包后退出")
+	fs.StringVar(&opts.ImportBundle, "import-bundle", "", "把关卡包安装到 levels/ 目录后退出")
+	fs.IntVar(&opts.MapLength, "length", opts.MapLength, "随机生成地图的列数")
+	scroll := fs.String("scroll", envString("SCROLL", ScrollModeRight.String()), "随机生成地图的相机滚动方式：right、left 或 alternating")
+	fullscreen := fs.Bool("fullscreen", envBool("FULLSCREEN"), "全屏启动（等价于 -window fullscreen）")
+	windowMode := fs.String("window", envString("WINDOW", WindowModeWindowed.String()), "窗口模式：windowed、borderless 或 fullscreen")
+	resolution := fs.String("resolution", envString("RESOLUTION", Resolution{windowWidth, windowHeight}.String()), "窗口模式下的分辨率，例如 1600x900")
+	fs.IntVar(&opts.Display.Monitor, "monitor", int(envInt("MONITOR", 0)), "显示器序号（0 为主显示器）")
+	fs.BoolVar(&opts.Vertical, "vertical", opts.Vertical, "随机生成地图时放置纵向滚动的攀爬段（只在向右滚动时生效）")
+	fs.StringVar(&opts.Theme, "theme", opts.Theme, "关卡主题（grassland、cave 等，见 res/data/themes.json）")
+	fs.StringVar(&opts.Skin, "skin", opts.Skin, "玩家皮肤（ember、mint 等，见 res/data/palettes.json）")
+	fs.StringVar(&opts.Skeleton, "skeleton", opts.Skeleton, "玩家骨骼动画文件（JSON，片段名称与动画状态相同，没有的状态使用精灵表动画）")
+	fs.Float64Var(&opts.TimeScale, "timescale", opts.TimeScale, "调试：游戏的基础时间倍数（0.1 到 2，小于 1 为慢动作；-debug 时 F7 在 1、0.5、0.25 之间切换）")
+	quality := fs.String("quality", envString("QUALITY", GraphicsQualityHigh.String()), "画面质量：low（关闭调色）或 high")
+	fs.BoolVar(&opts.LandingAssist, "landing-assist", opts.LandingAssist, "辅助功能：玩家在空中时标出预测的落点")
+	fs.BoolVar(&opts.Captions, "captions", opts.Captions, "辅助功能：播放音效时在屏幕下方显示字幕（[jump]、[monster nearby]、[power-up] 等）")
+	fs.BoolVar(&opts.Announcer, "announcer", opts.Announcer, "连击达到档位时播放播报语音并显示文字（-announcer=false 关闭）")
+	focusAudio := fs.String("focus-audio", envString("FOCUS_AUDIO", FocusLossAudioDuck.String()), "窗口失去焦点时的音频处理：none（继续播放）、duck（压低音量）或 pause（暂停）")
+	fs.BoolVar(&opts.AutoPause, "pause-on-focus-loss", opts.AutoPause, "窗口失去焦点或最小化时自动暂停游戏（-pause-on-focus-loss=false 关闭）")
+	fs.BoolVar(&opts.Analytics, "analytics", opts.Analytics, "在 analytics/ 目录记录匿名的跑图统计（死亡位置、检查点时间、高处路线），用于编辑器热力图")
+	fs.IntVar(&opts.SampleRate, "sample-rate", opts.SampleRate, "音频输出采样率（例如 44100 或 48000），采样率不同的音频文件加载时自动重采样")
+	fs.BoolVar(&opts.Presence, "presence", opts.Presence, "在 Discord 中显示在线状态：当前模式、距离和种子码（需要 -tags discord 编译）")
+	fs.BoolVar(&opts.Mute, "mute", opts.Mute, "静音")
+	fs.Float64Var(&opts.MusicVolume, "music-volume", opts.MusicVolume, "背景音乐音量（0 到 1）")
+	fs.Float64Var(&opts.SFXVolume, "sfx-volume", opts.SFXVolume, "音效音量（0 到 1）")
+	fs.BoolVar(&opts.Hitboxes, "hitboxes", opts.Hitboxes, "绘制碰撞盒（-debug 时同样绘制）")
+	fs.BoolVar(&opts.Debug, "debug", opts.Debug, "显示调试信息")
+	fs.BoolVar(&opts.Dev, "dev", opts.Dev, "开发模式：关卡脚本修改后自动重新加载")
+	fs.BoolVar(&opts.Editor, "editor", opts.Editor, "以编辑器模式启动")
+	fs.BoolVar(&opts.AnimPreview, "anim-preview", opts.AnimPreview, "调试：打开动画预览，检查所有动画的帧、原点和碰撞盒")
+	fs.StringVar(&opts.ReplayPath, "replay", opts.ReplayPath, "播放回放文件")
+	fs.IntVar(&opts.Soak, "soak", opts.Soak, "浸泡测试：不打开窗口，由机器人连续游玩指定的帧数（从 -seed 开始每局换一个种子）")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+
+	// 记录显式指定的选项（环境变量名称是选项名称的大写，- 换成 _）
+	opts.explicit = make(map[string]bool)
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, ok := os.LookupEnv(envPrefix + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))); ok {
+			opts.explicit[f.Name] = true
+		}
+	})
+	fs.Visit(func(f *flag.Flag) {
+		opts.explicit[f.Name] = true
+	})
+
+	// 参数值无效时与 flag 包一样输出错误和用法
+	fail := func(err error) (GameOptions, error) {
+		fmt.Fprintln(fs.Output(), err)
+		fs.Usage()
+		return opts, err
+	}
+
+	var err error
+	if opts.Display.Mode, err = ParseWindowMode(*windowMode); err != nil {
+		return fail(err)
+	}
+	if *fullscreen {
+		opts.Display.Mode = WindowModeFullscreen
+	}
+	if opts.Display.Resolution, err = ParseResolution(*resolution); err != nil {
+		return fail(err)
+	}
+	if opts.Scroll, err = ParseScrollMode(*scroll); err != nil {
+		return fail(err)
+	}
+	if opts.Quality, err = ParseGraphicsQuality(*quality); err != nil {
+		return fail(err)
+	}
+	if opts.FocusAudio, err = ParseFocusLossAudioMode(*focusAudio); err != nil {
+		return fail(err)
+	}
+
+	if opts.Profile != "" {
+		if err := ValidateProfileName(opts.Profile); err != nil {
+			return fail(err)
+		}
+	}
+	if opts.SyncURL != "" {
+		if _, err := NewSaveSyncBackend(opts.SyncURL); err != nil {
+			return fail(err)
+		}
+	}
+	if opts.SampleRate < minSampleRate || opts.SampleRate > maxSampleRate {
+		return fail(fmt.Errorf("采样率必须在 %d 到 %d 之间: %d", minSampleRate, maxSampleRate, opts.SampleRate))
+	}
+	if opts.MusicVolume < 0 || opts.MusicVolume > 1 || opts.SFXVolume < 0 || opts.SFXVolume > 1 {
+		return fail(fmt.Errorf("音量必须在 0 到 1 之间: %g, %g", opts.MusicVolume, opts.SFXVolume))
+	}
+	if opts.TimeScale < minTimeScale || opts.TimeScale > maxTimeScale {
+		return fail(fmt.Errorf("时间倍数必须在 %g 到 %g 之间: %g", minTimeScale, maxTimeScale, opts.TimeScale))
+	}
+
+	if opts.Mutators, err = ParseMutators(*mutators); err != nil {
+		return fail(err)
+	}
+	if opts.Mods, err = ParseModNames(*modList); err != nil {
+		return fail(err)
+	}
+	if *code != "" {
+		if opts.Seed, opts.Mutators, err = ParseSeedCode(*code); err != nil {
+			return fail(err)
+		}
+	}
+
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	return opts, nil
+}
+
+// envString 读取字符串环境变量
+func envString(name, defaultValue string) string {
+	if value, ok := os.LookupEnv(envPrefix + name); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// envInt 读取整数环境变量，无法解析时使用默认值
+func envInt(name string, defaultValue int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(envPrefix+name), 10, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// envFloat 读取浮点数环境变量，无法解析时使用默认值
+func envFloat(name string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(envPrefix+name), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// envBool 读取布尔环境变量（1/true 等），无法解析时为 false
+func envBool(name string) bool {
+	value, _ := strconv.ParseBool(os.Getenv(envPrefix + name))
+	return value
+}
+
+// envBoolOr 读取默认开启的布尔环境变量（0/false 关闭），无法解析时使用默认值
+func envBoolOr(name string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(envPrefix + name))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
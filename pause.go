@@ -0,0 +1,124 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// 暂停菜单的位置（在 PAUSED 和种子码下方）
+const pauseMenuY = windowHeight/2 + 48
+
+// pauseMenuItem 暂停菜单的一项
+type pauseMenuItem int
+
+const (
+	pauseMenuResume pauseMenuItem = iota
+	pauseMenuSettings
+	pauseMenuRestart
+	pauseMenuQuit
+)
+
+// pauseMenuNames 暂停菜单项的名称
+var pauseMenuNames = map[pauseMenuItem]string{
+	pauseMenuResume:   "RESUME",
+	pauseMenuSettings: "SETTINGS",
+	pauseMenuRestart:  "RESTART",
+	pauseMenuQuit:     "QUIT",
+}
+
+// String 返回菜单项的名称
+func (m pauseMenuItem) String() string {
+	return pauseMenuNames[m]
+}
+
+// PauseMenu 暂停菜单（从标题开始的游戏才创建，由场景管理器重新开始或回到标题）：
+// 按返回键（Esc 或手柄右边的按钮）或 P 暂停，游戏冻结（与失去焦点的暂停相同，所有系统停止更新），背景音乐随之暂停；
+// 菜单可以继续、打开设置页（修改立即应用到这一局，之后重新开始和回到标题也使用）、用同样的启动选项重新开始，
+// 或者保存存档后回到标题；再按一次返回键或 P 继续
+// 回溯、存档点回放和结算界面期间不能暂停
+type PauseMenu struct {
+	open     bool
+	selected int
+	bgm      bool           // 打开时背景音乐是否在播放（死亡时背景音乐已经暂停，继续时不恢复）
+	settings *SettingsScene // 打开的设置页（为 nil 时显示菜单）
+}
+
+// Open 暂停菜单是否打开（m 为 nil 时没有暂停菜单）
+func (m *PauseMenu) Open() bool {
+	return m != nil && m.open
+}
+
+// Update 打开、关闭暂停菜单，处理菜单输入
+func (m *PauseMenu) Update(g *Game) {
+	toggle := ActionJustPressed(ActionBack) || inpututil.IsKeyJustPressed(ebiten.KeyP)
+	if !m.open {
+		if toggle && !g.isRewinding && !g.scrubber.Active() && g.continues.Results() == nil {
+			m.open = true
+			m.selected = 0
+			m.bgm = g.audioManager.IsBGMPlaying()
+			g.isPaused = true
+			g.audioManager.PauseBGM()
+		}
+		return
+	}
+	if m.settings != nil {
+		if m.settings.Update(); m.settings.Done() {
+			m.settings = nil
+			g.options.scenes.title.rememberSettings(g.options)
+			g.profile.RememberSettings(g.options)
+		}
+		return
+	}
+
+	count := len(pauseMenuNames)
+	switch {
+	case toggle:
+		m.resume(g)
+	case titleMenuUp():
+		m.selected--
+	case titleMenuDown():
+		m.selected++
+	case ActionJustPressed(ActionConfirm):
+		switch pauseMenuItem(m.selected) {
+		case pauseMenuResume:
+			m.resume(g)
+		case pauseMenuSettings:
+			m.settings = NewSettingsScene(&g.options, g, pauseMenuY)
+		case pauseMenuRestart:
+			g.profile.Close()
+			g.options.scenes.SwitchWith(NewLoadingScene(g.options), TransitionWipe)
+		case pauseMenuQuit:
+			g.profile.Close()
+			g.options.scenes.ToTitle()
+		}
+	}
+	m.selected = (m.selected + count) % count
+}
+
+// resume 关闭菜单，继续游戏并恢复背景音乐
+func (m *PauseMenu) resume(g *Game) {
+	m.open = false
+	g.isPaused = false
+	if m.bgm {
+		g.audioManager.ResumeBGM()
+	}
+}
+
+// Draw 在暂停遮罩上绘制菜单（菜单没有打开时不绘制）
+func (m *PauseMenu) Draw(screen *ebiten.Image) {
+	if !m.Open() {
+		return
+	}
+	if m.settings != nil {
+		m.settings.Draw(screen)
+		return
+	}
+	for i := 0; i < len(pauseMenuNames); i++ {
+		line := pauseMenuItem(i).String()
+		if i == m.selected {
+			line = "> " + line + " <"
+		}
+		DrawTextCentered(screen, line, windowWidth/2, pauseMenuY+i*titleMenuSpacing)
+	}
+	DrawPromptCentered(screen, "{navigate} SELECT  {confirm} OK  {back} RESUME", windowWidth/2, pauseMenuY+(len(pauseMenuNames)+1)*titleMenuSpacing)
+}
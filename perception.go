@@ -0,0 +1,46 @@
+package main
+
+import (
+	"math"
+
+	"my_ai_game/internal/engine"
+)
+
+// Perception 感知组件：怪物只能看到视距内、面向方向视野内且没有被障碍物遮挡的目标
+type Perception struct {
+	Range float64 `json:"range"` // 视距（像素）
+	FOV   float64 `json:"fov"`   // 视野半角（度），以面向方向为中心
+}
+
+// CanSee 判断从眼睛位置面向 facing 方向能否看到目标点
+// eyeX, eyeY: 眼睛位置
+// facing: 面向方向（-1 向左，1 向右）
+// targetX, targetY: 目标点
+// obstacles: 可能遮挡视线的对象（只有障碍物和道路会遮挡）
+func (p *Perception) CanSee(eyeX, eyeY, facing, targetX, targetY float64, obstacles []*Obstacle) bool {
+	dx := targetX - eyeX
+	dy := targetY - eyeY
+	distance := math.Hypot(dx, dy)
+	if distance > p.Range {
+		return false
+	}
+
+	// 视野角度检查：目标方向与面向方向的夹角不超过视野半角
+	if distance > 0 {
+		cosAngle := dx * facing / distance
+		if cosAngle < math.Cos(p.FOV*math.Pi/180.0) {
+			return false
+		}
+	}
+
+	// 视线遮挡检查
+	for _, obstacle := range obstacles {
+		if obstacle.Type != ObstacleTypeObstacle && obstacle.Type != ObstacleTypeGrass {
+			continue
+		}
+		if engine.SegmentIntersectsBox(eyeX, eyeY, targetX, targetY, obstacle) {
+			return false
+		}
+	}
+	return true
+}
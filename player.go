@@ -1,8 +1,12 @@
 package main
 
 import (
+	"log"
+	"math"
+
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/audio"
+
+	"my_ai_game/internal/engine"
 )
 
 const (
@@ -19,61 +23,156 @@ const (
 	flySpeed = 15.0
 	// 飞行持续时间（帧数）
 	flyDurationFrames = 300
+	// 飞行高度（相对相机顶部，像素）和拾取道具后移到飞行高度所用的帧数
+	flyHeight      = 240.0
+	flyEntryFrames = 20
+	// 跳跃缓冲：落地前这么多帧内按下跳跃键，落地时自动起跳
+	jumpBufferFrames = 6
+	// 土狼时间：离开地面后这么多帧内仍然可以起跳
+	coyoteFrames = 6
+	// 贴地容差（像素）：在地面上行走时，相邻道路块高度相差不超过这个值时直接走上去或贴着落下，
+	// 不会被挡住、短暂离地或上下抖动
+	groundSnapDistance = 8.0
+	// 默认死亡平面在道路顶部以下的距离（像素），关卡文件可以修改
+	defaultKillPlaneDepth = 80.0
+	// 坠落死亡后淡出的帧数
+	fallDeathFadeFrames = 40
+)
+
+// DeathCause 玩家的死亡原因
+type DeathCause int
+
+const (
+	DeathCauseNone      DeathCause = iota // 未死亡
+	DeathCauseOffScreen                   // 被相机挤出屏幕
+	DeathCauseFall                        // 掉进缺口，越过死亡平面
+	DeathCauseCrush                       // 被障碍物挤在屏幕边缘（障碍物和屏幕后方边缘之间放不下玩家）
+	DeathCauseMonster                     // 碰到怪物
+	DeathCauseScript                      // 被关卡脚本杀死（触发点返回 kill）
 )
 
+// deathCauseNames 死亡原因名称（统计记录使用）
+var deathCauseNames = map[DeathCause]string{
+	DeathCauseNone:      "none",
+	DeathCauseOffScreen: "offscreen",
+	DeathCauseFall:      "fall",
+	DeathCauseCrush:     "crush",
+	DeathCauseMonster:   "monster",
+	DeathCauseScript:    "script",
+}
+
+// String 返回死亡原因名称
+func (c DeathCause) String() string {
+	return deathCauseNames[c]
+}
+
+// PlayerPhysics 玩家的移动参数（突变可以修改）
+type PlayerPhysics struct {
+	Speed   float64 // 水平移动速度（像素/帧）
+	Gravity float64 // 重力加速度（像素/帧²）
+}
+
+// DefaultPlayerPhysics 返回默认的玩家移动参数
+func DefaultPlayerPhysics() PlayerPhysics {
+	return PlayerPhysics{Speed: playerSpeed, Gravity: gravity}
+}
+
+// JumpInfo 一次起跳的诊断信息（用于调试跳跃手感）
+type JumpInfo struct {
+	BufferedFrames int  // 按下跳跃键到实际起跳经过的帧数（0 表示按下当帧起跳）
+	CoyoteFrames   int  // 离开地面后经过的帧数（0 表示在地面上起跳）
+	UsedBuffer     bool // 是否依靠跳跃缓冲起跳
+	UsedCoyote     bool // 是否依靠土狼时间起跳
+}
+
 // Player 玩家结构体
 type Player struct {
-	X                 float64              // X 坐标（原点在底部中心）
-	Y                 float64              // Y 坐标（原点在底部中心）
-	VelocityY         float64              // 垂直速度
-	IsOnGround        bool                 // 是否在地面上
-	wasSpaceDown      bool                 // 上一帧是否按下了空格键
-	wasOnGround       bool                 // 上一帧是否在地面上
-	FacingLeft        bool                 // 是否面向左边
-	Animation         *AnimationController // 动画控制器
-	jumpSound         *audio.Player        // 跳跃音效播放器
-	dieSound          *audio.Player        // 死亡音效播放器
-	IsDead            bool                 // 是否死亡
-	hasPlayedDieSound bool                 // 是否已播放死亡音效
-	IsFlying          bool                 // 是否处于飞行状态
-	flyFrameCount     int                  // 飞行帧计数器
+	X            float64              // X 坐标（原点在底部中心）
+	Y            float64              // Y 坐标（原点在底部中心）
+	VelocityY    float64              // 垂直速度
+	IsOnGround   bool                 // 是否在地面上
+	wasSpaceDown bool                 // 上一帧是否按下了空格键
+	wasOnGround  bool                 // 上一帧是否在地面上
+	FacingLeft   bool                 // 是否面向左边
+	Animation    *AnimationController // 动画控制器
+	Skeleton     *engine.Skeleton     // 骨骼动画（可选，为 nil 或当前状态没有片段时绘制精灵表动画）
+	events       *EventBus            // 事件总线（发布起跳、死亡等事件）
+	clock        *engine.Clock        // 游戏时钟（动画按游戏时间平滑推进，暂停时不前进，慢动作时变慢）
+	IsDead       bool                 // 是否死亡
+	DeathCause   DeathCause           // 死亡原因
+	deathFrames  int                  // 死亡后经过的帧数（坠落死亡的淡出动画）
+	KillPlaneY   float64              // 死亡平面的 Y 坐标（世界坐标），脚底越过即坠落死亡
+	IsFlying     bool                 // 是否处于飞行状态
+	flyTimer     engine.Timer         // 飞行计时器（拾取道具时开始，到时结束飞行）
+	Shield       bool                 // 是否有护盾（抵挡一次怪物或子弹的致命触碰）
+	shieldGrace  engine.Timer         // 护盾破裂或接关后的无敌时间
+	FlyDirection float64              // 飞行方向：1 向右，-1 向左（与拾取道具时的相机滚动方向一致）
+	Swing        *Obstacle            // 正在抓住的秋千点（为 nil 时没有摆动）
+	Physics      PlayerPhysics        // 移动参数（速度和重力）
+
+	// 跳跃宽容（跳跃缓冲和土狼时间）
+	jumpBufferTimer int      // 剩余的跳跃缓冲帧数，大于 0 表示有未处理的跳跃输入
+	jumpPressFrames int      // 跳跃输入已缓冲的帧数
+	airFrames       int      // 离开地面后经过的帧数
+	canCoyoteJump   bool     // 离开地面后是否还没有跳过（走下平台时为 true）
+	Coins           int      // 本局收集的金币数
+	JumpCount       int      // 起跳次数
+	LastJump        JumpInfo // 最近一次起跳的诊断信息
+
+	// 秋千（见 swing.go）
+	swingLength   float64 // 绳长
+	swingAngle    float64 // 摆角（弧度，竖直向下为 0，向右为正）
+	swingVelocity float64 // 角速度（弧度/帧）
+	momentumX     float64 // 松手后空中的水平惯性（像素/帧）
+	grabLocked    bool    // 按跳跃键松手后，直到松开抓取键都不能再抓住
 }
 
 // NewPlayer 创建新玩家
 // x: 初始 X 坐标
 // y: 初始 Y 坐标
-// audioManager: 音频管理器，用于加载音效
-func NewPlayer(x, y float64, audioManager *AudioManager) *Player {
-	player := &Player{
-		X:           x,
-		Y:           y,
-		Animation:   NewAnimationController(),
-		FacingLeft:  false,
-		wasOnGround: true,
-	}
-
-	// 从音频管理器加载跳跃音效
-	player.jumpSound = audioManager.LoadJumpSound()
-	// 从音频管理器加载死亡音效
-	player.dieSound = audioManager.LoadDieSound()
-
-	return player
+// events: 事件总线，音效等由订阅者处理
+// clock: 游戏时钟
+// 加载玩家动画失败时返回错误
+func NewPlayer(x, y float64, events *EventBus, clock *engine.Clock) (*Player, error) {
+	animation, err := NewPlayerAnimationController("")
+	if err != nil {
+		return nil, err
+	}
+	return &Player{
+		X:            x,
+		Y:            y,
+		Animation:    animation,
+		FacingLeft:   false,
+		wasOnGround:  true,
+		events:       events,
+		clock:        clock,
+		FlyDirection: 1,
+		KillPlaneY:   math.Inf(1), // 由 Game 按道路高度设置
+		Physics:      DefaultPlayerPhysics(),
+	}, nil
 }
 
-// Update 更新玩家状态（处理移动和重力）
+// Update 更新玩家状态（处理移动和重力），每次调用模拟一帧的游戏时间（慢动作时不是每帧都调用）；动画帧由 Animate 推进
+// input: 本帧玩家输入
 // obstacles: 障碍物列表，用于碰撞检测
 // mapWidth: 地图总宽度，用于限制玩家移动范围
-// cameraX: 相机 X 坐标，用于检测玩家是否移出屏幕
-func (p *Player) Update(obstacles []*Obstacle, mapWidth float64, cameraX float64) {
-	// 检查玩家是否死亡（碰撞盒完全移出屏幕）
+// camera: 相机，用于检测玩家是否移出屏幕或被挤在屏幕边缘
+// scrollDir: 相机当前的滚动方向（1 向右，-1 向左），与障碍物重叠时把玩家推向滚动方向的后方
+func (p *Player) Update(input PlayerInput, obstacles []*Obstacle, mapWidth float64, camera *engine.Camera, scrollDir float64) {
+	// 检查玩家是否死亡（越过死亡平面，或碰撞盒完全移出屏幕）
 	if !p.IsDead {
-		p.checkDeath(cameraX)
+		p.checkDeath(camera)
 	}
+	p.shieldGrace.Tick(1)
 
-	// 如果玩家已死亡，只更新动画，不再处理其他操作
+	// 如果玩家已死亡，不再处理其他操作（动画由 Animate 继续播放）
 	if p.IsDead {
-		// 继续更新动画，直到死亡动画播放完毕
-		p.Animation.Update()
+		p.deathFrames++
+		// 坠落死亡时继续下坠并淡出
+		if p.DeathCause == DeathCauseFall {
+			p.VelocityY += p.Physics.Gravity
+			p.Y += p.VelocityY
+		}
 		return
 	}
 
@@ -82,53 +181,60 @@ func (p *Player) Update(obstacles []*Obstacle, mapWidth float64, cameraX float64
 		p.updateFlyingState(mapWidth)
 		// 更新动画状态（飞行状态）
 		p.updateAnimationState(false)
-		// 更新动画帧
-		p.Animation.Update()
+		return
+	}
+
+	// 处理摆动状态（在空中按住抓取键时抓住附近的秋千点）
+	if p.Swing != nil || p.tryGrab(input, obstacles) {
+		if p.Swing != nil {
+			p.updateSwing(input, obstacles)
+		}
+		p.updateAnimationState(false)
 		return
 	}
 
 	isMoving := false
 
 	// 处理左右移动（移动前检查碰撞和地图边界）
-	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) || ebiten.IsKeyPressed(ebiten.KeyA) {
+	if input.Left {
 		// 尝试向左移动
-		newX := p.X - playerSpeed
+		newX := p.X - p.Physics.Speed
 		// 检查是否超出地图左边界（玩家碰撞盒的左边界不能小于0）
 		minX := playerCollisionWidth / 2.0
-		if newX >= minX && !p.wouldCollideHorizontal(newX, obstacles) {
-			p.X = newX
+		if newX >= minX && p.tryMoveHorizontal(newX, obstacles) {
 			p.FacingLeft = true
 		}
 		isMoving = true
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) || ebiten.IsKeyPressed(ebiten.KeyD) {
+	if input.Right {
 		// 尝试向右移动
-		newX := p.X + playerSpeed
+		newX := p.X + p.Physics.Speed
 		// 检查是否超出地图右边界（玩家碰撞盒的右边界不能大于地图宽度）
 		maxX := mapWidth - playerCollisionWidth/2.0
-		if newX <= maxX && !p.wouldCollideHorizontal(newX, obstacles) {
-			p.X = newX
+		if newX <= maxX && p.tryMoveHorizontal(newX, obstacles) {
 			p.FacingLeft = false
 		}
 		isMoving = true
 	}
 
-	// 处理跳跃（只有在地面上才能跳跃，且只在按键按下时触发一次）
-	spacePressed := ebiten.IsKeyPressed(ebiten.KeySpace)
-	if p.IsOnGround && spacePressed && !p.wasSpaceDown {
-		p.VelocityY = jumpSpeed
-		p.IsOnGround = false
-		// 播放跳跃音效
-		if p.jumpSound != nil {
-			// 重置到开头并播放
-			p.jumpSound.Rewind()
-			p.jumpSound.Play()
-		}
+	// 从秋千上松手后在空中保持水平惯性
+	if p.IsOnGround {
+		p.momentumX = 0
+	} else {
+		p.applyMomentum(obstacles, mapWidth)
+	}
+
+	// 处理跳跃（只在按键按下时触发一次）
+	spacePressed := input.Jump
+	if spacePressed && !p.wasSpaceDown {
+		p.jumpBufferTimer = jumpBufferFrames
+		p.jumpPressFrames = 0
 	}
 	p.wasSpaceDown = spacePressed
+	p.updateJump()
 
 	// 应用重力
-	p.VelocityY += gravity
+	p.VelocityY += p.Physics.Gravity
 
 	// 更新 Y 坐标（向上方向不检查碰撞，允许穿越）
 	p.Y += p.VelocityY
@@ -136,33 +242,83 @@ func (p *Player) Update(obstacles []*Obstacle, mapWidth float64, cameraX float64
 	// 检查与障碍物的碰撞（只检查向下和左右，不检查向上）
 	p.checkCollisionWithObstacles(obstacles)
 
+	// 上一帧在地面上、本帧没有起跳却离开了地面时，贴到容差范围内的地面上
+	p.snapToGround(obstacles)
+
+	// 仍与实心障碍物重叠时把玩家推出去，再检查是否被挤在障碍物和屏幕边缘之间
+	if !p.IsDead {
+		p.resolvePushOut(obstacles, scrollDir)
+		p.checkCrush(obstacles, camera, scrollDir)
+	}
+
 	// 更新动画状态（根据玩家状态切换）
 	p.updateAnimationState(isMoving)
+}
 
-	// 更新动画帧
-	p.Animation.Update()
+// Animate 按本帧的游戏时间推进动画帧（每帧调用一次，与 Update 的模拟步数无关，慢动作时动画平滑变慢）
+// 死亡后继续推进，直到死亡动画播放完毕
+func (p *Player) Animate() {
+	p.Animation.Advance(p.clock.Delta())
+}
+
+// updateJump 处理跳跃缓冲和土狼时间，满足条件时起跳
+// 在地面上或刚离开地面不超过 coyoteFrames 帧时，消耗缓冲中的跳跃输入
+func (p *Player) updateJump() {
+	if p.IsOnGround {
+		p.airFrames = 0
+		p.canCoyoteJump = true
+	} else {
+		p.airFrames++
+	}
+
+	if p.jumpBufferTimer <= 0 {
+		return
+	}
+
+	canJump := p.IsOnGround || (p.canCoyoteJump && p.airFrames <= coyoteFrames)
+	if canJump {
+		p.LastJump = JumpInfo{
+			BufferedFrames: p.jumpPressFrames,
+			CoyoteFrames:   p.airFrames,
+			UsedBuffer:     p.jumpPressFrames > 0,
+			UsedCoyote:     !p.IsOnGround,
+		}
+		p.JumpCount++
+
+		p.VelocityY = jumpSpeed
+		p.IsOnGround = false
+		p.canCoyoteJump = false
+		p.jumpBufferTimer = 0
+		p.events.Publish(Event{Type: EventPlayerJumped, X: p.X, Y: p.Y})
+		return
+	}
+
+	p.jumpBufferTimer--
+	p.jumpPressFrames++
+}
+
+// StopFlying 结束飞行，转换为 jump_loop 状态并恢复重力影响
+func (p *Player) StopFlying() {
+	p.IsFlying = false
+	p.flyTimer.Stop()
+	p.canCoyoteJump = false // 飞行结束时在空中，不能使用土狼时间
+	p.Animation.SetState(StateJumpLoop)
 }
 
 // updateFlyingState 更新飞行状态
 func (p *Player) updateFlyingState(mapWidth float64) {
-	// 增加飞行帧计数器
-	p.flyFrameCount++
-
-	// 检查飞行帧数是否超过设定值
-	if p.flyFrameCount >= flyDurationFrames {
-		// 飞行结束，转换为 jump_loop 状态
-		p.IsFlying = false
-		p.flyFrameCount = 0
-		p.Animation.SetState(StateJumpLoop)
-		// 恢复重力影响
+	// 飞行计时器到时后结束飞行
+	if p.flyTimer.Tick(1) {
+		p.StopFlying()
 		return
 	}
 
-	// 飞行状态下每帧向右移动 10 像素
-	newX := p.X + flySpeed
-	// 检查是否超出地图右边界
+	// 飞行状态下每帧沿飞行方向移动 flySpeed 像素
+	newX := p.X + flySpeed*p.FlyDirection
+	// 检查是否超出地图左右边界
+	minX := playerCollisionWidth / 2.0
 	maxX := mapWidth - playerCollisionWidth/2.0
-	if newX <= maxX {
+	if newX >= minX && newX <= maxX {
 		p.X = newX
 	}
 	// 飞行状态下不受重力影响，VelocityY 保持不变
@@ -187,6 +343,14 @@ func (p *Player) updateAnimationState(isMoving bool) {
 		return
 	}
 
+	// 摆动时保持摆动动画（松手时由 releaseSwing 切换到 jump_loop）
+	if p.Swing != nil {
+		if currentState != StateSwing {
+			p.Animation.SetState(StateSwing)
+		}
+		return
+	}
+
 	// 检测从地面到空中的过渡
 	if p.wasOnGround && !p.IsOnGround {
 		// 从地面到空中，转到JumpBefore（过渡动画）
@@ -241,8 +405,106 @@ func (p *Player) updateAnimationState(isMoving bool) {
 	p.wasOnGround = p.IsOnGround
 }
 
+// tryMoveHorizontal 尝试水平移动到 newX，成功时返回 true
+// 在地面上被挡住时，如果挡住的只是高出脚底不超过 groundSnapDistance 的台阶，则走上台阶
+func (p *Player) tryMoveHorizontal(newX float64, obstacles []*Obstacle) bool {
+	if !p.wouldCollideHorizontal(newX, obstacles) {
+		p.X = newX
+		return true
+	}
+	if !p.IsOnGround {
+		return false
+	}
+	if p.pushBoulder(newX, obstacles) {
+		return true
+	}
+
+	// 找出挡住的障碍物中最高的顶部，高出脚底太多时不是台阶
+	oldX := p.X
+	p.X = newX
+	stepY := p.Y
+	for _, obstacle := range obstacles {
+		if !obstacle.Solidity.Has(SolidFromSides) || !engine.CheckCollision(p, obstacle) {
+			continue
+		}
+		_, _, top, _ := obstacle.GetCollisionBox()
+		stepY = min(stepY, top)
+	}
+	p.X = oldX
+	if p.Y-stepY > groundSnapDistance {
+		return false
+	}
+
+	// 站到台阶上之后不能再碰到其他障碍物
+	oldY := p.Y
+	p.Y = stepY
+	if p.wouldCollideHorizontal(newX, obstacles) {
+		p.Y = oldY
+		return false
+	}
+	p.X = newX
+	return true
+}
+
+// pushBoulder 在地面上走向挡住去路的巨石时推动巨石，并走到紧挨巨石的位置（之后跟着巨石前进），返回是否推动
+func (p *Player) pushBoulder(newX float64, obstacles []*Obstacle) bool {
+	oldX := p.X
+	p.X = newX
+	var boulder *Obstacle
+	for _, obstacle := range obstacles {
+		if obstacle.Boulder != nil && engine.CheckCollision(p, obstacle) {
+			boulder = obstacle
+			break
+		}
+	}
+	p.X = oldX
+	if boulder == nil {
+		return false
+	}
+
+	direction := math.Copysign(1, newX-oldX)
+	boulder.Boulder.Push(direction)
+	// 紧挨巨石的位置不能碰到其他障碍物，否则只推不走
+	contactX := boulder.X - playerCollisionWidth/2.0
+	if direction < 0 {
+		contactX = boulder.X + boulder.Width + playerCollisionWidth/2.0
+	}
+	if (contactX-oldX)*direction > 0 && !p.wouldCollideHorizontal(contactX, obstacles) {
+		p.X = contactX
+	}
+	return true
+}
+
+// snapToGround 上一帧在地面上、本帧没有起跳，但脚下的地面比上一帧低了不超过 groundSnapDistance 时，
+// 直接贴到地面上（走过稍低的道路块时不会短暂离地，IsOnGround 和动画不会闪烁）
+func (p *Player) snapToGround(obstacles []*Obstacle) {
+	if p.IsOnGround || !p.wasOnGround || p.VelocityY < 0 || p.IsDead {
+		return
+	}
+	left, right, _, _ := p.GetCollisionBox()
+	prevY := p.Y - p.VelocityY
+	groundY, found := 0.0, false
+	for _, obstacle := range obstacles {
+		if !obstacle.Solidity.Has(SolidFromAbove) {
+			continue
+		}
+		oLeft, oRight, top, _ := obstacle.GetCollisionBox()
+		if oLeft >= right || oRight <= left || top < prevY || top > prevY+groundSnapDistance {
+			continue
+		}
+		if !found || top < groundY {
+			groundY, found = top, true
+		}
+	}
+	if found {
+		p.Y = groundY
+		p.VelocityY = 0
+		p.IsOnGround = true
+	}
+}
+
 // wouldCollideHorizontal 检查水平移动是否会碰撞
-// 怪物和道具不阻挡水平移动，允许玩家移动到碰撞位置以触发相应逻辑
+// 只有从侧面阻挡（SolidFromSides）的障碍物阻挡水平移动，怪物、道具、金币允许玩家移动到碰撞位置以触发相应逻辑
 func (p *Player) wouldCollideHorizontal(newX float64, obstacles []*Obstacle) bool {
 	// 临时保存原位置
 	oldX := p.X
@@ -250,12 +512,11 @@ func (p *Player) wouldCollideHorizontal(newX float64, obstacles []*Obstacle) boo
 
 	// 使用 CheckCollision 检查是否会与障碍物碰撞
 	for _, obstacle := range obstacles {
-		// 怪物和道具不阻挡水平移动
-		if obstacle.Type == ObstacleTypeMonster || obstacle.Type == ObstacleTypeTool {
+		if !obstacle.Solidity.Has(SolidFromSides) {
 			continue
 		}
 
-		if CheckCollision(p, obstacle) {
+		if engine.CheckCollision(p, obstacle) {
 			// 恢复原位置
 			p.X = oldX
 			return true
@@ -268,40 +529,81 @@ func (p *Player) wouldCollideHorizontal(newX float64, obstacles []*Obstacle) boo
 }
 
 // handleDeath 处理玩家死亡逻辑（提取公共方法）
-func (p *Player) handleDeath() {
-	if !p.IsDead {
-		// 玩家刚死亡，切换到死亡动画状态
-		p.IsDead = true
-		p.Animation.SetState(StateDie)
+func (p *Player) handleDeath(cause DeathCause) {
+	if p.IsDead {
+		return
 	}
-	// 播放死亡音效（只播放一次）
-	if !p.hasPlayedDieSound && p.dieSound != nil {
-		p.dieSound.Rewind()
-		p.dieSound.Play()
-		p.hasPlayedDieSound = true
+	// 玩家刚死亡，切换到死亡动画状态，并发布死亡事件（只发布一次）
+	p.IsDead = true
+	p.DeathCause = cause
+	p.deathFrames = 0
+	p.Swing = nil
+	p.Animation.SetState(StateDie)
+	p.events.Publish(Event{Type: EventPlayerDied, X: p.X, Y: p.Y})
+}
+
+// revive 接关复活：清除死亡状态并开始 graceFrames 帧的无敌时间（与护盾破裂后的无敌时间相同，玩家闪烁）
+// 复活的位置由调用方决定（接关时直接进入飞行状态，离开死亡的位置）
+func (p *Player) revive(graceFrames float64) {
+	p.IsDead = false
+	p.DeathCause = DeathCauseNone
+	p.deathFrames = 0
+	p.IsFlying = false
+	p.Swing = nil
+	p.momentumX = 0
+	p.VelocityY = 0
+	p.shieldGrace.Start(graceFrames)
+}
+
+// absorbHit 护盾抵挡一次致命的触碰：消耗护盾、移除碰到的怪物或子弹并开始无敌时间，返回是否抵挡
+// 无敌时间内的触碰直接忽略（同一帧可能同时碰到多个怪物）
+// 掉进缺口、移出屏幕和被挤死不算触碰，护盾不能抵挡
+func (p *Player) absorbHit(obstacle *Obstacle) bool {
+	if p.shieldGrace.Running() {
+		return true
+	}
+	if !p.Shield {
+		return false
+	}
+	p.Shield = false
+	p.shieldGrace.Start(shieldGraceFrames)
+	p.events.Publish(Event{Type: EventShieldPopped, X: p.X, Y: p.Y, Obstacle: obstacle})
+	if obstacle.Monster != nil {
+		// 护盾撞击击败怪物（子弹和落物只是被挡掉，不算命中）
+		obstacle.Monster.IsDead = true
+		if !obstacle.Monster.Def.missile {
+			left, right, top, _ := obstacle.GetCollisionBox()
+			p.events.Publish(Event{Type: EventMonsterHit, X: (left + right) / 2, Y: top, Obstacle: obstacle, Value: attackShieldBash.Damage, Attack: attackShieldBash})
+		}
 	}
+	return true
 }
 
-// checkDeath 检查玩家是否死亡（碰撞盒完全移出屏幕）
-func (p *Player) checkDeath(cameraX float64) {
+// checkDeath 检查玩家是否死亡（越过死亡平面，或碰撞盒完全移出屏幕）
+// 相机可以向任意方向滚动，碰撞盒离开视口任一侧都算死亡（纵向滚动时掉出相机下边缘也会死亡）
+func (p *Player) checkDeath(camera *engine.Camera) {
+	// 掉进缺口越过死亡平面时立即死亡，不用等碰撞盒完全离开屏幕
+	if p.Y > p.KillPlaneY {
+		p.handleDeath(DeathCauseFall)
+		return
+	}
+
 	// 获取玩家碰撞盒边界
 	left, right, top, bottom := p.GetCollisionBox()
 
 	// 计算碰撞盒在屏幕上的位置（相对于相机）
-	screenLeft := left - cameraX
-	screenRight := right - cameraX
-	screenTop := top
-	screenBottom := bottom
+	screenLeft, screenTop := camera.WorldToScreen(left, top)
+	screenRight, screenBottom := camera.WorldToScreen(right, bottom)
 
 	// 检查碰撞盒是否完全移出屏幕
 	// 完全移出屏幕的条件：右边界在屏幕左边，或左边界在屏幕右边，或下边界在屏幕上边，或上边界在屏幕下边
 	if screenRight < 0 || screenLeft > float64(windowWidth) || screenBottom < 0 || screenTop > float64(windowHeight) {
-		p.handleDeath()
+		p.handleDeath(DeathCauseOffScreen)
 	}
 }
 
 // checkCollisionWithObstacles 检查玩家与障碍物的碰撞
-// 只检查向下和左右方向的碰撞，不检查向上方向（允许向上穿越）
+// 向下：落到从上方阻挡（SolidFromAbove）的障碍物上；向上：只有从下方阻挡（SolidFromBelow）的障碍物会撞头，其余允许向上穿越
 // 怪物：触碰到怪物立即死亡
 func (p *Player) checkCollisionWithObstacles(obstacles []*Obstacle) {
 	p.IsOnGround = false
@@ -309,27 +611,44 @@ func (p *Player) checkCollisionWithObstacles(obstacles []*Obstacle) {
 	// 遍历所有障碍物检查碰撞
 	for _, obstacle := range obstacles {
 		// 使用 CheckCollision 检查是否发生碰撞
-		if !CheckCollision(p, obstacle) {
+		if !engine.CheckCollision(p, obstacle) {
 			continue
 		}
 
 		// 根据障碍物类型处理
 		switch obstacle.Type {
 		case ObstacleTypeMonster:
-			// 如果是怪物，触碰到立即死亡
-			p.handleDeath()
+			// 如果是怪物，触碰到立即死亡（护盾可以抵挡一次）
+			if p.absorbHit(obstacle) {
+				continue
+			}
+			p.handleDeath(DeathCauseMonster)
 			// 触碰到怪物后不再检查其他障碍物
 			return
-		case ObstacleTypeTool:
-			// 如果是道具，跳过（由 Game.Update 处理移除）
+		case ObstacleTypeTool, ObstacleTypeCoin, ObstacleTypeSwing, ObstacleTypeSwitch, ObstacleTypeKey:
+			// 如果是道具或金币，跳过（由 PickupSystem 处理移除）；秋千点只用于抓住，开关和钥匙由 DeviceSystem 处理
 			continue
 		}
 
-		// 普通障碍物：检查向下方向的碰撞
-		_, _, obstacleTop, _ := obstacle.GetCollisionBox()
+		_, _, obstacleTop, obstacleBottom := obstacle.GetCollisionBox()
 
-		// 只检查向下方向的碰撞（玩家正在下落）
-		if p.VelocityY >= 0 && p.Y > obstacleTop {
+		// 向上撞头：上一帧头顶还在障碍物底部以下时，把玩家放到障碍物下方并清零向上的速度
+		if p.VelocityY < 0 {
+			if obstacle.Solidity.Has(SolidFromBelow) && p.Y-playerCollisionHeight-p.VelocityY >= obstacleBottom {
+				p.Y = obstacleBottom + playerCollisionHeight
+				p.VelocityY = 0
+			}
+			continue
+		}
+
+		// 只有上一帧脚还在顶部以上时才能落在上面
+		// 从侧面嵌进实心障碍物时不吸附到顶部，由 resolvePushOut 水平推出
+		if !obstacle.Solidity.Has(SolidFromAbove) || p.Y-p.VelocityY > obstacleTop {
+			continue
+		}
+
+		// 玩家正在下落，落到障碍物上
+		if p.Y > obstacleTop {
 			// 玩家站在障碍物上
 			p.Y = obstacleTop
 			p.VelocityY = 0
@@ -338,6 +657,70 @@ func (p *Player) checkCollisionWithObstacles(obstacles []*Obstacle) {
 	}
 }
 
+// resolvePushOut 玩家与从侧面阻挡的障碍物重叠时（例如从侧面嵌进障碍物，或模组、脚本修改了玩家或障碍物的位置），
+// 沿滚动方向的后方把玩家推到障碍物外侧；推出后可能与下一个障碍物重叠，继续推直到不再重叠
+// 向上跳起时允许穿过不从下方阻挡的障碍物，不推出
+func (p *Player) resolvePushOut(obstacles []*Obstacle, scrollDir float64) {
+	halfWidth := playerCollisionWidth / 2.0
+	// 每次推动都沿同一方向移到障碍物外侧，每个障碍物最多推一次
+	for range obstacles {
+		pushed := false
+		for _, obstacle := range obstacles {
+			if !obstacle.Solidity.Has(SolidFromSides) || !engine.CheckCollision(p, obstacle) {
+				continue
+			}
+			if p.VelocityY < 0 && !obstacle.Solidity.Has(SolidFromBelow) {
+				continue
+			}
+			left, right, _, _ := obstacle.GetCollisionBox()
+			if scrollDir < 0 {
+				p.X = right + halfWidth
+			} else {
+				p.X = left - halfWidth
+			}
+			pushed = true
+		}
+		if !pushed {
+			return
+		}
+	}
+}
+
+// checkCrush 检查玩家是否被挤在从侧面阻挡的障碍物和屏幕后方边缘之间（明确的挤压判定）：
+// 前方紧挨着的障碍物与屏幕后方边缘之间的空隙比玩家的碰撞盒窄时，玩家被挤死
+// 没有障碍物挡住时屏幕边缘不推动玩家，玩家完全移出屏幕后由 checkDeath 判定死亡
+func (p *Player) checkCrush(obstacles []*Obstacle, camera *engine.Camera, scrollDir float64) {
+	_, _, top, bottom := p.GetCollisionBox()
+	for _, obstacle := range obstacles {
+		if !obstacle.Solidity.Has(SolidFromSides) {
+			continue
+		}
+		left, right, obstacleTop, obstacleBottom := obstacle.GetCollisionBox()
+		if top >= obstacleBottom || bottom <= obstacleTop {
+			continue
+		}
+		// 障碍物朝向屏幕后方的一面在屏幕上的位置，和它到屏幕后方边缘的空隙
+		var gap float64
+		if scrollDir < 0 {
+			if right > p.X {
+				continue
+			}
+			screenX, _ := camera.WorldToScreen(right, 0)
+			gap = float64(windowWidth) - screenX
+		} else {
+			if left < p.X {
+				continue
+			}
+			screenX, _ := camera.WorldToScreen(left, 0)
+			gap = screenX
+		}
+		if gap < playerCollisionWidth {
+			p.handleDeath(DeathCauseCrush)
+			return
+		}
+	}
+}
+
 // GetCollisionBox 获取碰撞盒边界
 // 返回：左边界, 右边界, 上边界, 下边界
 func (p *Player) GetCollisionBox() (left, right, top, bottom float64) {
@@ -360,39 +743,74 @@ func (p *Player) SetPosition(x, y float64) {
 	p.Y = y
 }
 
+// SetSkin 换成指定皮肤（调色板名称，为空时使用原色）的动画，加载失败时保留原来的动画并返回错误
+func (p *Player) SetSkin(skin string) error {
+	animation, err := NewPlayerAnimationController(skin)
+	if err != nil {
+		return err
+	}
+	p.Animation = animation
+	return nil
+}
+
+// SetSkeleton 使用指定文件中的骨骼动画，加载失败时给出警告并继续使用精灵表动画
+func (p *Player) SetSkeleton(path string) {
+	skeleton, err := LoadPlayerSkeleton(path)
+	if err != nil {
+		log.Printf("警告: 加载骨骼动画 %s 失败，使用精灵表动画: %v", path, err)
+		return
+	}
+	p.Skeleton = skeleton
+}
+
 // Draw 绘制玩家动画
 // screen: 绘制目标
-// cameraX: 相机 X 坐标（用于计算屏幕坐标）
-func (p *Player) Draw(screen *ebiten.Image, cameraX float64) {
+// camera: 相机（用于计算屏幕坐标）
+func (p *Player) Draw(screen *ebiten.Image, camera *engine.Camera) {
+	// 坠落死亡后逐渐淡出
+	var colorScale ebiten.ColorScale
+	if p.DeathCause == DeathCauseFall {
+		colorScale.ScaleAlpha(float32(max(0, 1-float64(p.deathFrames)/fallDeathFadeFrames)))
+	}
+	// 护盾破裂后的无敌时间内闪烁
+	if p.shieldGrace.Running() && int(p.shieldGrace.Elapsed())/4%2 == 0 {
+		colorScale.ScaleAlpha(0.4)
+	}
+
+	// 当前状态有骨骼动画片段时绘制骨骼动画（原点为玩家底部中心）
+	if p.Skeleton != nil && p.Skeleton.Pose(p.Animation.GetState().String(), p.Animation.GetProgress()) {
+		var geom ebiten.GeoM
+		if p.FacingLeft {
+			geom.Scale(-1, 1)
+		}
+		screenX, screenY := camera.WorldToScreen(p.X, p.Y)
+		geom.Translate(screenX, screenY)
+		p.Skeleton.Draw(screen, geom, colorScale)
+		return
+	}
+
 	frame := p.Animation.GetCurrentFrame()
 	if frame == nil {
 		return
 	}
 
+	// 帧尺寸和原点偏移已经是绘制尺寸（加载时按美术清单缩小，见 art.go）
 	frameWidth, frameHeight := p.Animation.GetFrameSize()
 
-	// 缩放比例
-	scale := 0.5
-
-	// 计算缩放后的尺寸
-	scaledWidth := float64(frameWidth) * scale
-	scaledHeight := float64(frameHeight) * scale
-
 	// 获取当前动画的原点Y偏移
 	originOffsetY := p.Animation.GetCurrentOriginOffsetY()
 
-	// 计算绘制位置（缩放后图像的左上角位置）
+	// 计算绘制位置（帧图像的左上角位置）
 	// 以帧动画中间最下方为原点与玩家原地对齐
 	// 玩家原点在底部中心，所以：
-	// - X: 玩家X - 缩放后帧宽度/2
-	// - Y: 玩家Y - 缩放后帧高度 + 原点Y偏移（偏移是相对于帧底部的，需要缩放）
-	screenX := p.X - scaledWidth/2.0 - cameraX
-	screenY := p.Y - scaledHeight + originOffsetY*scale
+	// - X: 玩家X - 帧宽度/2
+	// - Y: 玩家Y - 帧高度 + 原点Y偏移（偏移是相对于帧底部的）
+	screenX, screenY := camera.WorldToScreen(p.X-float64(frameWidth)/2.0, p.Y-float64(frameHeight)+originOffsetY)
 
 	// 创建绘制选项
 	op := &ebiten.DrawImageOptions{}
 
-	// 如果面向左边，先翻转（以图像原点，即左上角(0,0)为轴）
+	// 如果面向左边，翻转（以图像原点，即左上角(0,0)为轴）
 	if p.FacingLeft {
 		// 图像原点在(0,0)，水平翻转会绕(0,0)翻转
 		op.GeoM.Scale(-1, 1)
@@ -400,14 +818,10 @@ func (p *Player) Draw(screen *ebiten.Image, cameraX float64) {
 		op.GeoM.Translate(float64(frameWidth), 0)
 	}
 
-	// 然后移动到图片中心，缩放（以中心为原点）
-	op.GeoM.Translate(-float64(frameWidth)/2.0, -float64(frameHeight)/2.0)
-	op.GeoM.Scale(scale, scale)
-	op.GeoM.Translate(float64(frameWidth)/2.0*scale, float64(frameHeight)/2.0*scale)
-
 	// 移动到绘制位置
 	op.GeoM.Translate(screenX, screenY)
 
 	// 绘制当前帧
+	op.ColorScale = colorScale
 	screen.DrawImage(frame, op)
 }
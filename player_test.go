@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+const (
+	// 贴地测试的随机次数
+	groundSnapCases = 10000
+	// 随机玩家位置的坐标范围（像素）
+	groundSnapRange = 2000.0
+)
+
+// TestSnapToGround 检查玩家贴地：上一帧在地面上、本帧下落的玩家，脚下（水平重叠）的可站立地面比上一帧的脚底
+// 低 0～groundSnapDistance 时贴到其中最高的地面上并落地；更低、水平没有重叠、不能从上方站立或者正在起跳时不变
+func TestSnapToGround(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < groundSnapCases; i++ {
+		velocityY := random.Float64() * 20
+		if random.Intn(8) == 0 {
+			velocityY = -random.Float64() * 20 // 起跳
+		}
+		player := &Player{X: (random.Float64()*2 - 1) * groundSnapRange, Y: (random.Float64()*2 - 1) * groundSnapRange, VelocityY: velocityY, wasOnGround: true}
+		left, right, _, _ := player.GetCollisionBox()
+		prevY := player.Y - player.VelocityY
+
+		var obstacles []*Obstacle
+		groundY, found := 0.0, false
+		for n := random.Intn(4); n > 0; n-- {
+			drop := (random.Float64()*2 - 0.5) * groundSnapDistance // 比上一帧的脚底高或低，包括超出贴地距离
+			if random.Intn(4) == 0 {
+				drop = math.Round(drop)
+			}
+			width := 20 + random.Float64()*200
+			x := left - width + random.Float64()*(right-left+width) // 大部分与玩家水平重叠
+			if random.Intn(4) == 0 {
+				x = right + random.Float64()*100 // 在玩家右边，没有重叠
+			}
+			obstacleType := []ObstacleType{ObstacleTypeGrass, ObstacleTypePlatform, ObstacleTypeCoin}[random.Intn(3)]
+			top := prevY + drop
+			obstacles = append(obstacles, NewObstacle(x, top, x, top, width, 40, nil, obstacleType))
+
+			overlaps := x < right && x+width > left
+			if overlaps && obstacleType != ObstacleTypeCoin && drop >= 0 && drop <= groundSnapDistance && velocityY >= 0 && (!found || top < groundY) {
+				groundY, found = top, true
+			}
+		}
+
+		x, y := player.X, player.Y
+		player.snapToGround(obstacles)
+		switch {
+		case found && (player.Y != groundY || !player.IsOnGround || player.VelocityY != 0):
+			t.Fatalf("没有贴到地面 %g: 玩家 (%g, %g) 速度 %g，结果 y %g 落地 %t", groundY, x, y, velocityY, player.Y, player.IsOnGround)
+		case !found && (player.Y != y || player.IsOnGround || player.VelocityY != velocityY):
+			t.Fatalf("不应贴地: 玩家 (%g, %g) 速度 %g，结果 y %g 落地 %t", x, y, velocityY, player.Y, player.IsOnGround)
+		}
+	}
+}
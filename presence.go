@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// 每隔多少帧更新一次在线状态（Discord 限制每 20 秒最多更新 5 次）
+	presenceUpdateFrames = 15 * 60
+)
+
+// PresenceActivity 在线状态中显示的内容
+type PresenceActivity struct {
+	Details string    // 第一行：当前模式
+	State   string    // 第二行：距离和种子码
+	Start   time.Time // 本局开始时间（显示已玩时长）
+}
+
+// PresenceClient 在线状态客户端（例如 Discord Rich Presence）
+type PresenceClient interface {
+	SetActivity(activity PresenceActivity) error // 更新在线状态
+	Close() error                                // 断开连接（在线状态随之清除）
+}
+
+// newPresenceClient 创建在线状态客户端，为 nil 时表示没有编译在线状态支持（Discord 需要 -tags discord 编译）
+var newPresenceClient func() (PresenceClient, error)
+
+// errNoPresenceClient 没有编译在线状态支持
+var errNoPresenceClient = errors.New("没有编译在线状态支持（需要使用 -tags discord 编译）")
+
+// PresenceSystem 在线状态系统（设置中开启后才创建）：定期报告当前模式、距离和种子码
+// 连接和发送在后台协程中进行，不阻塞游戏；Discord 没有运行时每次更新重新尝试连接
+type PresenceSystem struct {
+	details  string // 当前模式（创建时确定）
+	seedCode string
+	start    time.Time
+	startX   float64
+	started  bool
+	frames   int                   // 距离上次更新经过的帧数
+	updates  chan PresenceActivity // 发给后台协程的在线状态（只保留最新的一条）
+}
+
+// NewPresenceSystem 创建在线状态系统，没有编译在线状态支持时返回错误
+func NewPresenceSystem(opts GameOptions) (*PresenceSystem, error) {
+	if newPresenceClient == nil {
+		return nil, errNoPresenceClient
+	}
+	s := &PresenceSystem{
+		details:  presenceDetails(opts),
+		seedCode: SeedCode(opts.Seed, opts.Mutators),
+		start:    time.Now(),
+		frames:   presenceUpdateFrames,
+		updates:  make(chan PresenceActivity, 1),
+	}
+	go s.run()
+	return s, nil
+}
+
+// presenceDetails 返回当前模式的描述：关卡名称（文件名）或随机地图的列数和突变
+func presenceDetails(opts GameOptions) string {
+	if opts.LevelPath != "" {
+		return "Level: " + strings.TrimSuffix(filepath.Base(opts.LevelPath), filepath.Ext(opts.LevelPath))
+	}
+	details := fmt.Sprintf("Endless run (%d columns)", opts.MapLength)
+	if opts.Mutators != 0 {
+		details += " " + opts.Mutators.String()
+	}
+	return details
+}
+
+// Update 每隔 presenceUpdateFrames 帧把当前距离发给后台协程
+func (s *PresenceSystem) Update(g *Game) {
+	if g.Player == nil {
+		return
+	}
+	if !s.started {
+		s.startX = g.Player.X
+		s.started = true
+	}
+	if s.frames++; s.frames < presenceUpdateFrames {
+		return
+	}
+	s.frames = 0
+
+	distance := int(math.Abs(g.Player.X-s.startX) / mapItemWidth)
+	activity := PresenceActivity{
+		Details: s.details,
+		State:   fmt.Sprintf("%d columns · seed %s", distance, s.seedCode),
+		Start:   s.start,
+	}
+	if g.Player.IsDead {
+		activity.State = fmt.Sprintf("Died at %d columns · seed %s", distance, s.seedCode)
+	}
+
+	// 后台协程还没取走上一条时用新的替换
+	select {
+	case <-s.updates:
+	default:
+	}
+	s.updates <- activity
+}
+
+// Close 结束后台协程（断开客户端，在线状态随之清除）
+func (s *PresenceSystem) Close() {
+	close(s.updates)
+}
+
+// run 后台协程：按需连接客户端并发送在线状态，失败时断开，下次更新时重新连接（只警告一次）；Close 后断开并退出
+func (s *PresenceSystem) run() {
+	var client PresenceClient
+	warned := false
+	for activity := range s.updates {
+		if client == nil {
+			var err error
+			if client, err = newPresenceClient(); err != nil {
+				if !warned {
+					log.Printf("警告: 无法连接在线状态服务: %v", err)
+					warned = true
+				}
+				continue
+			}
+		}
+		if err := client.SetActivity(activity); err != nil {
+			if !warned {
+				log.Printf("警告: 更新在线状态失败: %v", err)
+				warned = true
+			}
+			client.Close()
+			client = nil
+		}
+	}
+	if client != nil {
+		client.Close()
+	}
+}
@@ -0,0 +1,138 @@
+//go:build discord
+
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	// Discord 开发者后台中应用的 ID（可以用环境变量 MYGAME_DISCORD_CLIENT_ID 覆盖）
+	discordClientID = ""
+	// Discord 客户端的 IPC 管道编号范围（同时运行多个客户端时依次递增）
+	discordIPCSlots = 10
+
+	// IPC 消息类型
+	discordOpHandshake = 0
+	discordOpFrame     = 1
+)
+
+// discordClient 通过本地 IPC（Windows 命名管道、其他系统 Unix 套接字）与 Discord 客户端通信
+type discordClient struct {
+	conn  io.ReadWriteCloser
+	nonce int
+}
+
+func init() {
+	newPresenceClient = newDiscordClient
+}
+
+// newDiscordClient 连接正在运行的 Discord 客户端并握手
+func newDiscordClient() (PresenceClient, error) {
+	clientID := envString("DISCORD_CLIENT_ID", discordClientID)
+	if clientID == "" {
+		return nil, errors.New("没有设置 Discord 应用 ID（MYGAME_DISCORD_CLIENT_ID）")
+	}
+	conn, err := dialDiscord()
+	if err != nil {
+		return nil, err
+	}
+	c := &discordClient{conn: conn}
+	if err := c.send(discordOpHandshake, map[string]any{"v": 1, "client_id": clientID}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// dialDiscord 依次尝试 discord-ipc-0 到 discord-ipc-9
+func dialDiscord() (io.ReadWriteCloser, error) {
+	for i := 0; i < discordIPCSlots; i++ {
+		name := fmt.Sprintf("discord-ipc-%d", i)
+		if runtime.GOOS == "windows" {
+			if file, err := os.OpenFile(`\\.\pipe\`+name, os.O_RDWR, 0); err == nil {
+				return file, nil
+			}
+			continue
+		}
+		for _, dir := range discordSocketDirs() {
+			if conn, err := net.Dial("unix", filepath.Join(dir, name)); err == nil {
+				return conn, nil
+			}
+		}
+	}
+	return nil, errors.New("Discord 没有运行")
+}
+
+// discordSocketDirs 返回 Discord 可能放置 IPC 套接字的目录
+func discordSocketDirs() []string {
+	var dirs []string
+	for _, name := range []string{"XDG_RUNTIME_DIR", "TMPDIR", "TMP", "TEMP"} {
+		if dir := os.Getenv(name); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return append(dirs, "/tmp")
+}
+
+// send 发送一条消息（小端序的类型和长度，后面是 JSON）并读取回复（只检查是否出错）
+func (c *discordClient) send(op uint32, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, 8)
+	binary.LittleEndian.PutUint32(header[0:], op)
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(data)))
+	if _, err := c.conn.Write(append(header, data...)); err != nil {
+		return err
+	}
+
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return err
+	}
+	reply := make([]byte, binary.LittleEndian.Uint32(header[4:]))
+	if _, err := io.ReadFull(c.conn, reply); err != nil {
+		return err
+	}
+	var response struct {
+		Evt  string `json:"evt"`
+		Data struct {
+			Message string `json:"message"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(reply, &response); err == nil && response.Evt == "ERROR" {
+		return fmt.Errorf("Discord 返回错误: %s", response.Data.Message)
+	}
+	return nil
+}
+
+// SetActivity 更新 Discord 中显示的在线状态
+func (c *discordClient) SetActivity(activity PresenceActivity) error {
+	c.nonce++
+	return c.send(discordOpFrame, map[string]any{
+		"cmd":   "SET_ACTIVITY",
+		"nonce": fmt.Sprint(c.nonce),
+		"args": map[string]any{
+			"pid": os.Getpid(),
+			"activity": map[string]any{
+				"details":    activity.Details,
+				"state":      activity.State,
+				"timestamps": map[string]any{"start": activity.Start.Unix()},
+			},
+		},
+	})
+}
+
+// Close 断开连接，Discord 随之清除在线状态
+func (c *discordClient) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 预览中调整播放速度的步长（帧/秒）和上限
+	previewFPSStep = 1.0
+	previewMaxFPS  = 120.0
+)
+
+var (
+	previewBackgroundColor = color.RGBA{R: 0x30, G: 0x30, B: 0x38, A: 0xff}
+	previewGroundColor     = color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}
+	previewFrameColor      = color.RGBA{R: 0x40, G: 0xc0, B: 0xff, A: 0xff}
+	previewOriginColor     = color.RGBA{R: 0xff, G: 0xe0, B: 0x40, A: 0xff}
+	previewCollisionColor  = color.RGBA{G: 0xff, A: 0xff}
+)
+
+// previewEntry 预览列表中的一个动画
+type previewEntry struct {
+	name      string
+	anim      *engine.Animation
+	collision CollisionBoxDef // 碰撞盒（相对帧左上角）
+}
+
+// AnimationPreview 动画预览场景（调试用，-anim-preview 启动）
+// 列出玩家（-skin 指定的皮肤）和怪物目录中已加载的动画，按可调的播放速度循环播放，
+// 叠加显示帧边框、原点和碰撞盒，美术不用进入游戏就能检查资源
+type AnimationPreview struct {
+	entries  []previewEntry
+	selected int
+	frame    float64 // 当前帧（浮点数，用于平滑播放）
+	fps      float64 // 播放速度（帧/秒），切换动画时恢复为动画自己的速度
+	paused   bool
+}
+
+// NewAnimationPreview 创建动画预览场景，加载玩家动画和怪物目录
+func NewAnimationPreview(skin string) *AnimationPreview {
+	p := &AnimationPreview{}
+
+	// 玩家的碰撞盒底部中心在原点，帧底部中心对齐原点后向下偏移 OriginOffsetY
+	controller, err := NewPlayerAnimationController(skin)
+	if err != nil {
+		log.Fatalf("加载玩家动画失败: %v", err)
+	}
+	for state := StateIdle; state <= StateSwing; state++ {
+		anim := controller.Animation(state)
+		box := CollisionBoxDef{
+			OffsetX: float64(anim.FrameWidth)/2 - playerCollisionWidth/2,
+			OffsetY: float64(anim.FrameHeight) - anim.OriginOffsetY - playerCollisionHeight,
+			Width:   playerCollisionWidth,
+			Height:  playerCollisionHeight,
+		}
+		p.entries = append(p.entries, previewEntry{name: "player/" + state.String(), anim: anim, collision: box})
+	}
+
+	catalog, err := LoadMonsterCatalog(monsterCatalogPath)
+	if err != nil {
+		log.Fatalf("加载怪物目录失败: %v", err)
+	}
+	for _, def := range catalog.Defs {
+		p.entries = append(p.entries, previewEntry{name: "monster/" + def.Name, anim: def.animation, collision: def.Collision})
+	}
+
+	p.fps = p.entries[0].anim.FPS
+	return p
+}
+
+// Update 切换动画、调整播放速度、暂停和逐帧播放
+func (p *AnimationPreview) Update() error {
+	selected := p.selected
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) || inpututil.IsKeyJustPressed(ebiten.KeyW):
+		selected--
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) || inpututil.IsKeyJustPressed(ebiten.KeyS):
+		selected++
+	}
+	selected = (selected + len(p.entries)) % len(p.entries)
+	if selected != p.selected {
+		p.selected = selected
+		p.frame = 0
+		p.fps = p.entries[selected].anim.FPS
+	}
+	anim := p.entries[p.selected].anim
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) || inpututil.IsKeyJustPressed(ebiten.KeyA):
+		p.fps = max(p.fps-previewFPSStep, 0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) || inpututil.IsKeyJustPressed(ebiten.KeyD):
+		p.fps = min(p.fps+previewFPSStep, previewMaxFPS)
+	case inpututil.IsKeyJustPressed(ebiten.KeyR):
+		p.fps = anim.FPS
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace):
+		p.paused = !p.paused
+	}
+
+	// 暂停时用 , 和 . 逐帧查看
+	if p.paused {
+		if inpututil.IsKeyJustPressed(ebiten.KeyComma) {
+			p.frame = float64((int(p.frame) - 1 + anim.FrameCount) % anim.FrameCount)
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyPeriod) {
+			p.frame = float64((int(p.frame) + 1) % anim.FrameCount)
+		}
+		return nil
+	}
+
+	// 预览中所有动画都循环播放
+	p.frame += p.fps / engine.GameFPS
+	for p.frame >= float64(anim.FrameCount) {
+		p.frame -= float64(anim.FrameCount)
+	}
+	return nil
+}
+
+// Draw 绘制当前动画帧和叠加信息：帧边框（蓝）、原点和地面（黄）、碰撞盒（绿）
+func (p *AnimationPreview) Draw(screen *ebiten.Image) {
+	screen.Fill(previewBackgroundColor)
+	entry := p.entries[p.selected]
+	anim := entry.anim
+
+	// 原点放在屏幕中间偏下，帧底部中心对齐原点后向下偏移 OriginOffsetY（与 Player.Draw 一致）
+	originX, originY := float64(windowWidth)/2, float64(windowHeight)*3/4
+	left := originX - float64(anim.FrameWidth)/2
+	top := originY - float64(anim.FrameHeight) + anim.OriginOffsetY
+
+	vector.StrokeLine(screen, 0, float32(originY), windowWidth, float32(originY), 1, previewGroundColor, false)
+	if frame := anim.GetFrame(int(p.frame)); frame != nil {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(left, top)
+		screen.DrawImage(frame, op)
+	}
+	vector.StrokeRect(screen, float32(left), float32(top), float32(anim.FrameWidth), float32(anim.FrameHeight), 1, previewFrameColor, false)
+	box := entry.collision
+	vector.StrokeRect(screen, float32(left+box.OffsetX), float32(top+box.OffsetY), float32(box.Width), float32(box.Height), 1, previewCollisionColor, false)
+	vector.StrokeLine(screen, float32(originX-8), float32(originY), float32(originX+8), float32(originY), 2, previewOriginColor, false)
+	vector.StrokeLine(screen, float32(originX), float32(originY-8), float32(originX), float32(originY+8), 2, previewOriginColor, false)
+
+	ebitenutil.DebugPrintAt(screen, "ANIMATION PREVIEW  UP/DOWN SELECT  LEFT/RIGHT FPS  R RESET FPS  SPACE PAUSE  ,/. STEP", 10, 10)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%d/%d %s", p.selected+1, len(p.entries), entry.name), 10, 30)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("FRAME %d/%d  FPS %.0f (DEFAULT %.0f)  LOOP %v", int(p.frame)+1, anim.FrameCount, p.fps, anim.FPS, anim.Loop), 10, 46)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("SIZE %dx%d  ART SCALE %.2f  ORIGIN OFFSET Y %.1f", anim.FrameWidth, anim.FrameHeight, anim.Scale, anim.OriginOffsetY), 10, 62)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("COLLISION %.0f,%.0f %.0fx%.0f", box.OffsetX, box.OffsetY, box.Width, box.Height), 10, 78)
+	if p.paused {
+		ebitenutil.DebugPrintAt(screen, "PAUSED", 10, 94)
+	}
+}
+
+// Layout 返回游戏逻辑尺寸
+func (p *AnimationPreview) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return windowWidth, windowHeight
+}
@@ -0,0 +1,551 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	// 存档目录，每个存档一个子目录（存档名称即目录名）
+	profilesDir = "profiles"
+	// 存档文件名
+	profileFileName = "profile.json"
+	// 没有任何存档且未指定 -profile 时使用的存档名称
+	defaultProfileName = "player"
+	// 存档名称的最大长度
+	maxProfileNameLength = 16
+	// 游戏中每隔多少帧保存一次存档（有变化时）
+	profileSaveIntervalFrames = 600
+)
+
+// ProfileStats 存档的累计统计
+type ProfileStats struct {
+	Runs       int `json:"runs"`       // 开始的局数
+	Deaths     int `json:"deaths"`     // 死亡次数
+	Jumps      int `json:"jumps"`      // 起跳次数
+	Coins      int `json:"coins"`      // 拾取的金币数
+	BestColumn int `json:"bestColumn"` // 一局中离起点最远的列数
+	PlayFrames int `json:"playFrames"` // 游戏时长（帧数，暂停和回溯时不计）
+}
+
+// ProfileSettings 存档记住的设置，下次用这个存档启动时作为默认值（命令行参数和环境变量优先）
+type ProfileSettings struct {
+	Skin          string   `json:"skin"`
+	Quality       string   `json:"quality"`
+	LandingAssist bool     `json:"landingAssist"`
+	Captions      bool     `json:"captions"`
+	Mute          bool     `json:"mute"`
+	Announcer     *bool    `json:"announcer,omitempty"`        // 连击播报（旧存档没有这一项，为 nil 时保持默认开启）
+	FocusAudio    string   `json:"focusAudio,omitempty"`       // 失去焦点时的音频处理方式（为空时保持默认压低音量）
+	AutoPause     *bool    `json:"pauseOnFocusLoss,omitempty"` // 失去焦点时自动暂停（为 nil 时保持默认开启）
+	MusicVolume   *float64 `json:"musicVolume,omitempty"`      // 背景音乐音量（旧存档没有这一项，为 nil 时保持启动选项的音量）
+	SFXVolume     *float64 `json:"sfxVolume,omitempty"`        // 音效音量（为 nil 时保持启动选项的音量）
+	Window        string   `json:"window,omitempty"`           // 窗口模式（为空时保持启动选项的窗口模式）
+}
+
+// Profile 本地存档：名称、统计、解锁和设置
+// 每个存档的文件都在自己的目录中（profiles/<名称>/），共用一台电脑的玩家互不影响
+type Profile struct {
+	Name       string          `json:"name"`
+	LastPlayed time.Time       `json:"lastPlayed"`
+	SavedAt    time.Time       `json:"savedAt"` // 最近一次保存的时间（同步时按它解决冲突）
+	Stats      ProfileStats    `json:"stats"`
+	Unlocks    []string        `json:"unlocks"`
+	Settings   ProfileSettings `json:"settings"`
+	// 每张地图最好的评级（键与跑图统计的地图标识 analyticsMapKey 相同：随机地图按种子码、列数和滚动方式区分，关卡按相对 levels/ 的路径区分）
+	Grades map[string]ProfileGrade `json:"grades,omitempty"`
+}
+
+// ProfileGrade 一张地图最好的成绩
+type ProfileGrade struct {
+	Grade      string  `json:"grade"`
+	Score      int     `json:"score"`
+	BestFrames float64 `json:"bestFrames,omitempty"` // 最快通关的游戏时间（帧，没有通关过时为 0）
+}
+
+// ProfileUnlock 解锁项：累计统计达到条件时解锁
+type ProfileUnlock struct {
+	ID          string
+	Description string
+	Reached     func(stats ProfileStats) bool
+}
+
+// profileUnlocks 所有解锁项
+var profileUnlocks = []ProfileUnlock{
+	{ID: "skin:ember", Description: "collect 100 coins", Reached: func(s ProfileStats) bool { return s.Coins >= 100 }},
+	{ID: "skin:mint", Description: "reach column 256", Reached: func(s ProfileStats) bool { return s.BestColumn >= 256 }},
+	{ID: "veteran", Description: "play 50 runs", Reached: func(s ProfileStats) bool { return s.Runs >= 50 }},
+}
+
+// ValidateProfileName 检查存档名称：1～16 个字母、数字、- 或 _（名称用作目录名）
+func ValidateProfileName(name string) error {
+	if name == "" || len(name) > maxProfileNameLength {
+		return fmt.Errorf("存档名称必须是 1 到 %d 个字符: %q", maxProfileNameLength, name)
+	}
+	for _, r := range name {
+		if !isProfileNameRune(r) {
+			return fmt.Errorf("存档名称只能包含字母、数字、- 和 _: %q", name)
+		}
+	}
+	return nil
+}
+
+// isProfileNameRune 字符是否可以用在存档名称中
+func isProfileNameRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_'
+}
+
+// profileDir 返回存档的目录
+func profileDir(name string) string {
+	return filepath.Join(profilesDir, name)
+}
+
+// LoadProfile 读取存档，存档不存在时返回的错误满足 errors.Is(err, os.ErrNotExist)
+func LoadProfile(name string) (*Profile, error) {
+	data, err := os.ReadFile(filepath.Join(profileDir(name), profileFileName))
+	if err != nil {
+		return nil, err
+	}
+	var profile Profile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("解析存档 %s 失败: %w", name, err)
+	}
+	profile.Name = name
+	return &profile, nil
+}
+
+// OpenProfile 读取存档，不存在时创建新存档（第一次保存时才写入文件）
+func OpenProfile(name string) (*Profile, error) {
+	if err := ValidateProfileName(name); err != nil {
+		return nil, err
+	}
+	profile, err := LoadProfile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Profile{Name: name}, nil
+	}
+	return profile, err
+}
+
+// ListProfiles 列出所有存档，最近玩过的在前；无法读取的存档跳过并给出警告
+func ListProfiles() []*Profile {
+	paths, err := filepath.Glob(filepath.Join(profilesDir, "*", profileFileName))
+	if err != nil {
+		log.Printf("警告: 读取存档目录 %s 失败: %v", profilesDir, err)
+		return nil
+	}
+	var profiles []*Profile
+	for _, path := range paths {
+		profile, err := LoadProfile(filepath.Base(filepath.Dir(path)))
+		if err != nil {
+			log.Printf("警告: 跳过存档: %v", err)
+			continue
+		}
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].LastPlayed.After(profiles[j].LastPlayed)
+	})
+	return profiles
+}
+
+// Path 返回存档目录中的文件路径（存档自己的文件都保存在这里）
+func (p *Profile) Path(file string) string {
+	return filepath.Join(profileDir(p.Name), file)
+}
+
+// Save 记录保存时间并保存存档
+func (p *Profile) Save() error {
+	p.SavedAt = time.Now()
+	return p.write()
+}
+
+// write 写入存档文件（先写临时文件再替换，写入中途退出不会损坏原存档），不修改保存时间
+func (p *Profile) write() error {
+	if err := os.MkdirAll(profileDir(p.Name), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := p.Path(profileFileName)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Unlocked 是否已经解锁
+func (p *Profile) Unlocked(id string) bool {
+	for _, unlock := range p.Unlocks {
+		if unlock == id {
+			return true
+		}
+	}
+	return false
+}
+
+// checkUnlocks 按累计统计解锁新的解锁项，返回本次新解锁的项
+func (p *Profile) checkUnlocks() []ProfileUnlock {
+	var unlocked []ProfileUnlock
+	for _, unlock := range profileUnlocks {
+		if !p.Unlocked(unlock.ID) && unlock.Reached(p.Stats) {
+			p.Unlocks = append(p.Unlocks, unlock.ID)
+			unlocked = append(unlocked, unlock)
+		}
+	}
+	return unlocked
+}
+
+// recordGrade 记录一局的评级，比这张地图之前最好的分数高时更新并返回 true（保留最快通关时间）
+func (p *Profile) recordGrade(key string, r *RunResults) bool {
+	best, ok := p.Grades[key]
+	if ok && best.Score >= r.Score {
+		return false
+	}
+	if p.Grades == nil {
+		p.Grades = make(map[string]ProfileGrade)
+	}
+	best.Grade, best.Score = r.Grade.String(), r.Score
+	p.Grades[key] = best
+	return true
+}
+
+// recordClearTime 记录通关的游戏时间，比这张地图之前最快的通关更快时更新并返回 true（没有通关时不记录）
+func (p *Profile) recordClearTime(key string, r *RunResults) bool {
+	best := p.Grades[key]
+	if !r.Cleared || (best.BestFrames > 0 && best.BestFrames <= r.Frames) {
+		return false
+	}
+	if p.Grades == nil {
+		p.Grades = make(map[string]ProfileGrade)
+	}
+	best.BestFrames = r.Frames
+	p.Grades[key] = best
+	return true
+}
+
+// ApplyTo 把存档记住的设置作为启动选项的默认值（命令行参数或环境变量指定的选项不覆盖）
+func (p *Profile) ApplyTo(opts GameOptions) GameOptions {
+	if p == nil || p.Stats.Runs == 0 {
+		return opts
+	}
+	s := p.Settings
+	if !opts.explicit["skin"] {
+		opts.Skin = s.Skin
+	}
+	if quality, err := ParseGraphicsQuality(s.Quality); err == nil && !opts.explicit["quality"] {
+		opts.Quality = quality
+	}
+	if !opts.explicit["landing-assist"] {
+		opts.LandingAssist = s.LandingAssist
+	}
+	if !opts.explicit["captions"] {
+		opts.Captions = s.Captions
+	}
+	if !opts.explicit["mute"] {
+		opts.Mute = s.Mute
+	}
+	if s.Announcer != nil && !opts.explicit["announcer"] {
+		opts.Announcer = *s.Announcer
+	}
+	if mode, err := ParseFocusLossAudioMode(s.FocusAudio); err == nil && !opts.explicit["focus-audio"] {
+		opts.FocusAudio = mode
+	}
+	if s.AutoPause != nil && !opts.explicit["pause-on-focus-loss"] {
+		opts.AutoPause = *s.AutoPause
+	}
+	if s.MusicVolume != nil && !opts.explicit["music-volume"] {
+		opts.MusicVolume = min(max(*s.MusicVolume, 0), 1)
+	}
+	if s.SFXVolume != nil && !opts.explicit["sfx-volume"] {
+		opts.SFXVolume = min(max(*s.SFXVolume, 0), 1)
+	}
+	if mode, err := ParseWindowMode(s.Window); err == nil && !opts.explicit["window"] && !opts.explicit["fullscreen"] {
+		opts.Display.Mode = mode
+	}
+	return opts
+}
+
+// Remember 记住本局使用的设置
+func (p *Profile) Remember(opts GameOptions) {
+	p.Settings = ProfileSettings{
+		Skin:          opts.Skin,
+		Quality:       opts.Quality.String(),
+		LandingAssist: opts.LandingAssist,
+		Captions:      opts.Captions,
+		Mute:          opts.Mute,
+		Announcer:     &opts.Announcer,
+		FocusAudio:    opts.FocusAudio.String(),
+		AutoPause:     &opts.AutoPause,
+		MusicVolume:   &opts.MusicVolume,
+		SFXVolume:     &opts.SFXVolume,
+		Window:        opts.Display.Mode.String(),
+	}
+}
+
+// ProfileSystem 存档系统：累计本局的统计，定期、死亡时和关闭窗口时保存存档
+type ProfileSystem struct {
+	profile *Profile
+	sync    SaveSyncBackend // 存档同步后端（没有开启同步时为 nil），关闭窗口时上传
+	startX  float64         // 玩家的起点 X 坐标（计算离起点最远的列数）
+	started bool
+	dirty   bool // 上次保存后统计是否有变化
+	frames  int  // 距离上次保存的帧数
+}
+
+// NewProfileSystem 开始新的一局：记住本局的设置、局数加一并保存，订阅起跳、死亡和拾取金币事件
+func NewProfileSystem(profile *Profile, opts GameOptions, bus *EventBus) *ProfileSystem {
+	s := &ProfileSystem{profile: profile}
+	profile.Remember(opts)
+	profile.LastPlayed = time.Now()
+	profile.Stats.Runs++
+	s.dirty = true
+	s.Save()
+
+	bus.Subscribe(EventPlayerJumped, func(e Event) {
+		profile.Stats.Jumps++
+		s.dirty = true
+	})
+	bus.Subscribe(EventCoinCollected, func(e Event) {
+		profile.Stats.Coins++
+		s.dirty = true
+	})
+	bus.Subscribe(EventPlayerDied, func(e Event) {
+		profile.Stats.Deaths++
+		s.dirty = true
+		s.Save()
+	})
+	return s
+}
+
+// Update 累计游戏时长和离起点最远的列数，每隔 profileSaveIntervalFrames 帧保存一次
+func (s *ProfileSystem) Update(g *Game) {
+	if g.isPaused || g.isRewinding || g.Player == nil || g.Player.IsDead {
+		return
+	}
+	if !s.started {
+		s.startX = g.Player.X
+		s.started = true
+	}
+	stats := &s.profile.Stats
+	stats.PlayFrames++
+	if column := int(math.Abs(g.Player.X-s.startX) / mapItemWidth); column > stats.BestColumn {
+		stats.BestColumn = column
+	}
+	s.dirty = true
+
+	if s.frames++; s.frames >= profileSaveIntervalFrames {
+		s.Save()
+	}
+}
+
+// RecordGrade 记录本局的评级和通关时间，刷新了这张地图最好的分数时返回 true；有任何刷新时立即保存（最快通关时间用于解锁下一关）
+// 允许在 nil 上调用（没有使用存档时不记录，返回 false）
+func (s *ProfileSystem) RecordGrade(key string, r *RunResults) bool {
+	if s == nil {
+		return false
+	}
+	faster := s.profile.recordClearTime(key, r)
+	best := s.profile.recordGrade(key, r)
+	if faster || best {
+		s.dirty = true
+		s.Save()
+	}
+	return best
+}
+
+// RememberSettings 记住暂停菜单中修改的设置（音量、窗口模式等）并立即保存，下次用这个存档启动时使用
+// 允许在 nil 上调用（没有使用存档时不记录）
+func (s *ProfileSystem) RememberSettings(opts GameOptions) {
+	if s == nil {
+		return
+	}
+	s.profile.Remember(opts)
+	s.dirty = true
+	s.Save()
+}
+
+// Save 有变化时检查解锁并保存存档，保存失败时给出警告
+// 允许在 nil 上调用（没有使用存档时）
+func (s *ProfileSystem) Save() {
+	if s == nil || !s.dirty {
+		return
+	}
+	for _, unlock := range s.profile.checkUnlocks() {
+		log.Printf("存档 %s 解锁: %s（%s）", s.profile.Name, unlock.ID, unlock.Description)
+	}
+	if err := s.profile.Save(); err != nil {
+		log.Printf("警告: 保存存档 %s 失败: %v", s.profile.Name, err)
+	}
+	s.dirty = false
+	s.frames = 0
+}
+
+// Close 关闭游戏时保存存档，开启同步时上传，上传失败时给出警告（下次启动同步时再上传）
+// 允许在 nil 上调用
+func (s *ProfileSystem) Close() {
+	if s == nil {
+		return
+	}
+	s.Save()
+	if s.sync == nil {
+		return
+	}
+	if err := UploadProfile(s.sync, s.profile); err != nil {
+		log.Printf("警告: 上传存档 %s 失败: %v", s.profile.Name, err)
+	}
+}
+
+// ProfileSelect 存档选择场景（启动时未指定 -profile 且已有存档时打开）
+// 列出已有的存档，可以新建存档；选中后通过加载界面按该存档创建 Game（-stages 时切换到关卡选择，-levels 时切换到关卡浏览）
+type ProfileSelect struct {
+	opts     GameOptions
+	profiles []*Profile
+	selected int         // 选中的行（最后一行是新建存档）
+	naming   bool        // 是否正在输入新存档的名称
+	name     []rune      // 正在输入的名称
+	message  string      // 名称无效等提示
+	attract  AttractMode // 无操作时播放的演示
+}
+
+// NewProfileSelect 创建存档选择场景
+func NewProfileSelect(opts GameOptions) *ProfileSelect {
+	return &ProfileSelect{opts: opts, profiles: ListProfiles()}
+}
+
+// Update 选择或新建存档，返回键回到标题
+func (s *ProfileSelect) Update() error {
+	if s.attract.Update(s.opts) {
+		return nil
+	}
+	if s.naming {
+		s.updateNaming()
+		return nil
+	}
+
+	rows := len(s.profiles) + 1
+	switch {
+	case ActionJustPressed(ActionBack):
+		s.opts.scenes.ToTitle()
+	case titleMenuUp():
+		s.selected--
+	case titleMenuDown():
+		s.selected++
+	case ActionJustPressed(ActionConfirm):
+		if s.selected < len(s.profiles) {
+			s.launch(s.profiles[s.selected].Name)
+		} else {
+			s.naming = true
+			s.name = append(s.name[:0], []rune(suggestProfileName())...)
+			s.message = ""
+		}
+	}
+	s.selected = (s.selected + rows) % rows
+	return nil
+}
+
+// updateNaming 输入新存档的名称：确认键确认，返回键取消，Backspace 删除
+// 名称预先填好一个没有使用的默认名称，只用手柄时直接确认即可
+func (s *ProfileSelect) updateNaming() {
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if isProfileNameRune(r) && len(s.name) < maxProfileNameLength {
+			s.name = append(s.name, r)
+		}
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(s.name) > 0:
+		s.name = s.name[:len(s.name)-1]
+	case ActionJustPressed(ActionBack):
+		s.naming = false
+	case ActionJustPressed(ActionConfirm):
+		name := string(s.name)
+		if err := ValidateProfileName(name); err != nil {
+			s.message = "INVALID NAME"
+			return
+		}
+		if _, err := os.Stat(profileDir(name)); err == nil {
+			s.message = "PROFILE EXISTS: " + name
+			return
+		}
+		s.launch(name)
+	}
+}
+
+// suggestProfileName 返回新建存档时预先填写的名称：默认存档名称，已经存在时依次加上 2、3…
+func suggestProfileName() string {
+	name := defaultProfileName
+	for i := 2; ; i++ {
+		if _, err := os.Stat(profileDir(name)); errors.Is(err, os.ErrNotExist) {
+			return name
+		}
+		name = fmt.Sprintf("%s%d", defaultProfileName, i)
+	}
+}
+
+// launch 使用选中的存档启动游戏
+func (s *ProfileSelect) launch(name string) {
+	opts := s.opts
+	opts.Profile = name
+	switch {
+	case opts.Stages:
+		s.opts.scenes.Switch(NewLevelSelect(opts))
+	case opts.Levels:
+		s.opts.scenes.Switch(NewLevelBrowser(opts, opts.LevelIndex))
+	default:
+		s.opts.scenes.Switch(NewLoadingScene(opts))
+	}
+}
+
+// Draw 绘制存档列表
+func (s *ProfileSelect) Draw(screen *ebiten.Image) {
+	if s.attract.Draw(screen) {
+		return
+	}
+
+	DrawPrompt(screen, "PROFILES  ({navigate} SELECT, {confirm} PLAY, {back} TITLE)", 40, 40)
+	DrawText(screen, fmt.Sprintf("  %-16s %6s %6s %6s %6s  %s", "NAME", "RUNS", "DEATHS", "COINS", "BEST", "UNLOCKS"), 40, 72)
+	for i, profile := range s.profiles {
+		cursor := " "
+		if i == s.selected {
+			cursor = ">"
+		}
+		stats := profile.Stats
+		line := fmt.Sprintf("%s %-16s %6d %6d %6d %6d  %s", cursor, profile.Name, stats.Runs, stats.Deaths, stats.Coins, stats.BestColumn, strings.Join(profile.Unlocks, ","))
+		DrawText(screen, line, 40, 92+i*16)
+	}
+
+	y := 92 + len(s.profiles)*16
+	switch {
+	case s.naming:
+		DrawPrompt(screen, "> NAME: "+string(s.name)+"_  ({confirm} CREATE, {back} CANCEL)", 40, y)
+	case s.selected == len(s.profiles):
+		DrawText(screen, "> NEW PROFILE", 40, y)
+	default:
+		DrawText(screen, "  NEW PROFILE", 40, y)
+	}
+	if s.message != "" {
+		DrawText(screen, s.message, 40, y+32)
+	}
+}
+
+// Close 结束正在播放的演示
+func (s *ProfileSelect) Close() {
+	s.attract.Close()
+}
+
+// Layout 返回游戏逻辑尺寸
+func (s *ProfileSelect) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return windowWidth, windowHeight
+}
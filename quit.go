@@ -0,0 +1,99 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 退出确认对话框的位置（在 PAUSED 和种子码下方，与暂停菜单相同）
+const quitDialogY = pauseMenuY
+
+// quitDialogItem 退出确认对话框的一项
+type quitDialogItem int
+
+const (
+	quitDialogYes quitDialogItem = iota
+	quitDialogNo
+)
+
+// quitDialogNames 退出确认对话框选项的名称
+var quitDialogNames = map[quitDialogItem]string{
+	quitDialogYes: "YES",
+	quitDialogNo:  "NO",
+}
+
+// String 返回选项的名称
+func (m quitDialogItem) String() string {
+	return quitDialogNames[m]
+}
+
+// QuitDialog 退出确认对话框：一局进行中关闭窗口时打开（由场景管理器或使用存档的游戏接管关闭窗口），
+// 游戏冻结（与暂停相同），背景音乐随之暂停；选择 YES 保存（和上传）存档后退出，选择 NO 或按返回键回到之前的状态（暂停菜单打开时仍然暂停）
+// 对话框打开时再次关闭窗口直接保存并退出
+type QuitDialog struct {
+	open      bool
+	selected  int
+	wasPaused bool // 打开时游戏是否已经暂停（暂停菜单、失去焦点）
+	bgm       bool // 打开时背景音乐是否在播放
+}
+
+// Open 退出确认对话框是否打开
+func (d *QuitDialog) Open() bool {
+	return d.open
+}
+
+// show 打开对话框（默认选中 NO，避免误按确认键退出），暂停游戏和背景音乐
+func (d *QuitDialog) show(g *Game) {
+	d.open = true
+	d.selected = int(quitDialogNo)
+	d.wasPaused = g.isPaused
+	d.bgm = g.audioManager.IsBGMPlaying()
+	g.isPaused = true
+	g.audioManager.PauseBGM()
+}
+
+// Update 处理对话框输入，选择退出时保存存档并返回 ebiten.Termination
+func (d *QuitDialog) Update(g *Game) error {
+	count := len(quitDialogNames)
+	switch {
+	case titleMenuUp():
+		d.selected--
+	case titleMenuDown():
+		d.selected++
+	case ActionJustPressed(ActionBack):
+		d.cancel(g)
+	case ActionJustPressed(ActionConfirm):
+		if quitDialogItem(d.selected) == quitDialogYes {
+			g.profile.Close()
+			return ebiten.Termination
+		}
+		d.cancel(g)
+	}
+	d.selected = (d.selected + count) % count
+	return nil
+}
+
+// cancel 关闭对话框，恢复打开前的暂停状态和背景音乐
+func (d *QuitDialog) cancel(g *Game) {
+	d.open = false
+	g.isPaused = d.wasPaused
+	if d.bgm && !d.wasPaused {
+		g.audioManager.ResumeBGM()
+	}
+}
+
+// Draw 在暂停遮罩上绘制对话框（使用存档时提示会保存存档）
+func (d *QuitDialog) Draw(screen *ebiten.Image, g *Game) {
+	title := "QUIT?"
+	if g.profile != nil {
+		title = "SAVE AND QUIT?"
+	}
+	DrawTextCentered(screen, title, windowWidth/2, quitDialogY)
+	for i := 0; i < len(quitDialogNames); i++ {
+		line := quitDialogItem(i).String()
+		if i == d.selected {
+			line = "> " + line + " <"
+		}
+		DrawTextCentered(screen, line, windowWidth/2, quitDialogY+(i+1)*titleMenuSpacing)
+	}
+	DrawPromptCentered(screen, "{navigate} SELECT  {confirm} OK  {back} CANCEL", windowWidth/2, quitDialogY+(len(quitDialogNames)+2)*titleMenuSpacing)
+}
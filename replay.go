@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 回放文件保存的目录（每局一个文件）
+const replaysDir = "replays"
+
+// 回放中每帧输入的各位（PlayerInput 的各个按键和这一帧游戏是否暂停）
+const (
+	replayBitLeft byte = 1 << iota
+	replayBitRight
+	replayBitJump
+	replayBitGrab
+	replayBitRewind
+	replayBitContinue
+	replayBitSkip
+	replayBitPaused
+)
+
+// Replay 回放：重现一局需要的启动选项和每帧的输入（游戏是确定性的，同样的选项和输入得到同样的过程）
+// 输入按帧记录（包括暂停的帧，暂停期间顿帧等计时仍在推进），相同的连续帧合并为一段
+type Replay struct {
+	Version   string      `json:"version"`
+	Seed      int64       `json:"seed"`
+	Mutators  Mutators    `json:"mutators"`
+	Mods      []string    `json:"mods,omitempty"`
+	LevelPath string      `json:"level,omitempty"`
+	MapLength int         `json:"mapLength"`
+	Scroll    ScrollMode  `json:"scroll"`
+	Vertical  bool        `json:"vertical,omitempty"`
+	TimeScale float64     `json:"timeScale"`
+	Theme     string      `json:"theme,omitempty"`
+	Skin      string      `json:"skin,omitempty"`
+	Signature string      `json:"signature,omitempty"` // 结果签名（RunSignature）
+	Inputs    []replayRun `json:"inputs"`
+}
+
+// replayRun 连续若干帧相同的输入
+type replayRun struct {
+	Bits   byte `json:"b"`
+	Frames int  `json:"n"`
+}
+
+// LoadReplay 读取回放文件
+func LoadReplay(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var replay Replay
+	if err := json.Unmarshal(data, &replay); err != nil {
+		return nil, fmt.Errorf("解析回放 %s 失败: %w", path, err)
+	}
+	return &replay, nil
+}
+
+// Frames 返回回放的总帧数
+func (r *Replay) Frames() int {
+	total := 0
+	for _, run := range r.Inputs {
+		total += run.Frames
+	}
+	return total
+}
+
+// Options 返回播放回放的启动选项：回放记录的地图和玩法选项，显示和音频设置使用当前的启动选项
+// 不使用存档、统计和在线状态，也不开启调试（存档点回放会改变过程）
+func (r *Replay) Options(opts GameOptions) GameOptions {
+	return GameOptions{
+		Seed:        r.Seed,
+		Mutators:    r.Mutators,
+		Mods:        r.Mods,
+		LevelPath:   r.LevelPath,
+		MapLength:   r.MapLength,
+		Scroll:      r.Scroll,
+		Vertical:    r.Vertical,
+		Display:     opts.Display,
+		Theme:       r.Theme,
+		Quality:     opts.Quality,
+		Skin:        r.Skin,
+		TimeScale:   r.TimeScale,
+		SampleRate:  opts.SampleRate,
+		Mute:        opts.Mute,
+		Announcer:   opts.Announcer,
+		FocusAudio:  opts.FocusAudio,
+		AutoPause:   opts.AutoPause,
+		MusicVolume: opts.MusicVolume,
+		SFXVolume:   opts.SFXVolume,
+		Hitboxes:    opts.Hitboxes,
+	}
+}
+
+// replayBits 把一帧的输入和暂停状态编码成一个字节
+func replayBits(input PlayerInput, paused bool) byte {
+	var bits byte
+	for i, pressed := range []bool{input.Left, input.Right, input.Jump, input.Grab, input.Rewind, input.Continue, input.Skip, paused} {
+		if pressed {
+			bits |= 1 << i
+		}
+	}
+	return bits
+}
+
+// ReplayRecorder 回放录制：每帧记录玩家输入和游戏是否暂停，一局结束（结算）时保存到 replays/ 目录
+// 放在暂停菜单和离开检测之后、时间倍数之前（这时本帧的暂停状态已经确定）；退出确认对话框打开时游戏冻结，不记录
+// 只在从标题开始的游戏中创建，调试模式下不录制（存档点回放和 F7 切换时间倍数不在输入中）
+type ReplayRecorder struct {
+	replay *Replay
+}
+
+// NewReplayRecorder 按游戏的启动选项（应用存档设置之后）创建回放录制
+func NewReplayRecorder(opts GameOptions) *ReplayRecorder {
+	return &ReplayRecorder{replay: &Replay{
+		Version:   gameVersion,
+		Seed:      opts.Seed,
+		Mutators:  opts.Mutators,
+		Mods:      opts.Mods,
+		LevelPath: opts.LevelPath,
+		MapLength: opts.MapLength,
+		Scroll:    opts.Scroll,
+		Vertical:  opts.Vertical,
+		TimeScale: opts.TimeScale,
+		Theme:     opts.Theme,
+		Skin:      opts.Skin,
+	}}
+}
+
+// Update 记录本帧的输入（结算之后不再记录）
+func (r *ReplayRecorder) Update(g *Game) {
+	if g.continues.Results() != nil {
+		return
+	}
+	bits := replayBits(g.Input, g.isPaused)
+	if n := len(r.replay.Inputs); n > 0 && r.replay.Inputs[n-1].Bits == bits {
+		r.replay.Inputs[n-1].Frames++
+		return
+	}
+	r.replay.Inputs = append(r.replay.Inputs, replayRun{Bits: bits, Frames: 1})
+}
+
+// Save 保存回放（文件名为结束的时间），失败时给出警告；允许在 nil 上调用
+func (r *ReplayRecorder) Save(signature string) {
+	if r == nil {
+		return
+	}
+	r.replay.Signature = signature
+	path := filepath.Join(replaysDir, time.Now().Format("20060102-150405")+".json")
+	data, err := json.Marshal(r.replay)
+	if err == nil {
+		if err = os.MkdirAll(replaysDir, 0o755); err == nil {
+			err = os.WriteFile(path, data, 0o644)
+		}
+	}
+	if err != nil {
+		log.Printf("警告: 无法保存回放: %v", err)
+		return
+	}
+	log.Printf("已保存回放: %s（-replay %s 播放）", path, path)
+}
+
+// ReplayPlayer 回放播放：按帧返回记录的输入和暂停状态，播放完后返回空输入
+type ReplayPlayer struct {
+	replay *Replay
+	run    int // 当前所在的段
+	frame  int // 当前段中已经播放的帧数
+	played int // 已经播放的总帧数
+}
+
+// NewReplayPlayer 创建从第一帧开始的回放播放
+func NewReplayPlayer(replay *Replay) *ReplayPlayer {
+	return &ReplayPlayer{replay: replay}
+}
+
+// Next 返回本帧的输入和游戏是否暂停，并前进一帧
+func (p *ReplayPlayer) Next() (PlayerInput, bool) {
+	if p.Done() {
+		return PlayerInput{}, false
+	}
+	bits := p.replay.Inputs[p.run].Bits
+	if p.frame++; p.frame >= p.replay.Inputs[p.run].Frames {
+		p.run, p.frame = p.run+1, 0
+	}
+	p.played++
+	input := PlayerInput{
+		Left:     bits&replayBitLeft != 0,
+		Right:    bits&replayBitRight != 0,
+		Jump:     bits&replayBitJump != 0,
+		Grab:     bits&replayBitGrab != 0,
+		Rewind:   bits&replayBitRewind != 0,
+		Continue: bits&replayBitContinue != 0,
+		Skip:     bits&replayBitSkip != 0,
+	}
+	return input, bits&replayBitPaused != 0
+}
+
+// Played 返回已经播放的帧数
+func (p *ReplayPlayer) Played() int {
+	return p.played
+}
+
+// Done 是否已经播放完所有输入
+func (p *ReplayPlayer) Done() bool {
+	return p.run >= len(p.replay.Inputs)
+}
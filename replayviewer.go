@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// 自由相机每帧平移的距离（像素）
+const replayCameraPanSpeed = 8.0
+
+// replaySpeeds 回放观看的播放速度（按 1、2、3 切换）
+var replaySpeeds = []float64{0.5, 1, 2}
+
+// ReplayViewer 回放观看（-replay 启动）：按回放的启动选项创建游戏，由回放操作玩家，观看者不参与游戏
+// 空格暂停和继续，暂停时按 . 前进一帧；1、2、3 切换 0.5 倍、1 倍、2 倍速度（慢速时隔帧更新，加速时一帧更新多次）；
+// C 切换自由相机，方向键或 WASD 平移画面（只在绘制时偏移，不影响游戏中的相机，回放过程不变）；Esc 退出
+// 播放到结算界面时比较重新模拟得到的结果签名和回放文件中保存的签名
+type ReplayViewer struct {
+	game     *Game
+	player   *ReplayPlayer
+	replay   *Replay
+	frames   int     // 回放的总帧数
+	speed    int     // replaySpeeds 的下标
+	budget   float64 // 还没有执行的更新次数（按速度累计）
+	paused   bool
+	free     bool    // 是否使用自由相机
+	offsetX  float64 // 自由相机相对游戏相机的偏移
+	offsetY  float64
+	verified bool // 是否已经比较过结果签名
+}
+
+// NewReplayViewer 创建回放观看，显示和音频设置使用 opts
+func NewReplayViewer(opts GameOptions, replay *Replay) *ReplayViewer {
+	if replay.Version != gameVersion {
+		log.Printf("警告: 回放由版本 %s 录制，当前版本为 %s，可能无法准确重现", replay.Version, gameVersion)
+	}
+	player := NewReplayPlayer(replay)
+	gameOpts := replay.Options(opts)
+	gameOpts.replay = player
+	return &ReplayViewer{
+		game:   NewGame(gameOpts),
+		player: player,
+		replay: replay,
+		frames: replay.Frames(),
+		speed:  1,
+	}
+}
+
+// Update 处理播放控制，按速度更新游戏
+func (v *ReplayViewer) Update() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		return ebiten.Termination
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		v.paused = !v.paused
+	}
+	for i, key := range []ebiten.Key{ebiten.Key1, ebiten.Key2, ebiten.Key3} {
+		if inpututil.IsKeyJustPressed(key) {
+			v.speed = i
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		v.free = !v.free
+		v.offsetX, v.offsetY = 0, 0
+	}
+	if v.free {
+		v.updateFreeCamera()
+	}
+
+	switch {
+	case v.paused:
+		v.budget = 0
+		if inpututil.IsKeyJustPressed(ebiten.KeyPeriod) {
+			return v.step()
+		}
+	default:
+		v.budget += replaySpeeds[v.speed]
+		for ; v.budget >= 1; v.budget-- {
+			if err := v.step(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// step 更新一帧游戏，出现结算界面时比较结果签名
+func (v *ReplayViewer) step() error {
+	if err := v.game.Update(); err != nil {
+		return err
+	}
+	if results := v.game.continues.Results(); results != nil && !v.verified {
+		v.verified = true
+		switch {
+		case v.replay.Signature == "":
+			log.Printf("回放没有保存结果签名，重新模拟的签名为 %s", results.Signature)
+		case results.Signature == v.replay.Signature:
+			log.Printf("结果签名一致: %s", results.Signature)
+		default:
+			log.Printf("警告: 结果签名不一致（回放 %s，重新模拟 %s），回放没有准确重现", v.replay.Signature, results.Signature)
+		}
+	}
+	return nil
+}
+
+// updateFreeCamera 方向键或 WASD 平移自由相机
+func (v *ReplayViewer) updateFreeCamera() {
+	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) || ebiten.IsKeyPressed(ebiten.KeyA) {
+		v.offsetX -= replayCameraPanSpeed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) || ebiten.IsKeyPressed(ebiten.KeyD) {
+		v.offsetX += replayCameraPanSpeed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) || ebiten.IsKeyPressed(ebiten.KeyW) {
+		v.offsetY -= replayCameraPanSpeed
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowDown) || ebiten.IsKeyPressed(ebiten.KeyS) {
+		v.offsetY += replayCameraPanSpeed
+	}
+}
+
+// Draw 绘制游戏（自由相机时临时偏移游戏相机）和播放状态
+func (v *ReplayViewer) Draw(screen *ebiten.Image) {
+	camera := v.game.Camera
+	camera.X += v.offsetX
+	camera.Y += v.offsetY
+	v.game.Draw(screen)
+	camera.X -= v.offsetX
+	camera.Y -= v.offsetY
+
+	status := fmt.Sprintf("REPLAY  %d/%d  %gx", v.player.Played(), v.frames, replaySpeeds[v.speed])
+	if v.paused {
+		status += "  PAUSED"
+	}
+	if v.free {
+		status += "  FREE CAM"
+	}
+	if v.player.Done() {
+		status += "  END"
+	}
+	ebitenutil.DebugPrintAt(screen, status, windowWidth-len(status)*6-10, 10)
+	const controls = "SPACE PAUSE  . STEP  1/2/3 SPEED  C CAMERA  ESC QUIT"
+	ebitenutil.DebugPrintAt(screen, controls, windowWidth/2-len(controls)*3, windowHeight-20)
+}
+
+// Close 释放回放中的游戏
+func (v *ReplayViewer) Close() {
+	v.game.Close()
+}
+
+// Layout 返回逻辑屏幕尺寸
+func (v *ReplayViewer) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return windowWidth, windowHeight
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// resultsOverlayColor 接关倒数和结算界面的半透明遮罩
+var resultsOverlayColor = color.RGBA{A: 160}
+
+// RunResults 一局的结果（结算界面显示）
+type RunResults struct {
+	Distance  int        // 离起点的列数
+	Coins     int        // 结束时剩余的金币数
+	Frames    float64    // 游戏时间（帧，慢动作时比真实时间短）
+	Cause     DeathCause // 最后一次死亡的原因
+	Cleared   bool       // 是否碰到终点通关
+	Continues int        // 接关的次数
+	Deaths    int        // 死亡的次数（包括回溯和接关之前的死亡）
+	Saves     int        // 避免的死亡次数（护盾抵挡的触碰）
+	Score     int        // 分数（RunScore）
+	Grade     Grade      // 评级
+	NewBest   bool       // 是否刷新了存档中这张地图的最好评级
+	Signature string     // 结果签名（RunSignature，用于核对成绩；演示和测试中为空）
+
+	stamp *GradeStamp
+}
+
+// NewRunResults 按当前的游戏状态记录本局结果并评级
+// startX: 玩家起点的 X 坐标；continues、deaths、saves: 本局接关、死亡和避免死亡的次数
+func NewRunResults(g *Game, startX float64, continues, deaths, saves int) *RunResults {
+	r := &RunResults{
+		Distance:  int(math.Abs(g.Player.X-startX) / mapItemWidth),
+		Coins:     g.Player.Coins,
+		Frames:    g.clock.Now(),
+		Cause:     g.Player.DeathCause,
+		Cleared:   g.cleared,
+		Continues: continues,
+		Deaths:    deaths,
+		Saves:     saves,
+	}
+	r.Score = RunScore(r)
+	r.Grade = GradeForScore(r.Score)
+	r.stamp = NewGradeStamp(r.Grade)
+	return r
+}
+
+// Update 推进评级印章的动画
+func (r *RunResults) Update() {
+	r.stamp.Update()
+}
+
+// Draw 绘制结算界面（通关时标题为 STAGE CLEAR，不显示死亡原因）
+func (r *RunResults) Draw(screen *ebiten.Image) {
+	vector.FillRect(screen, 0, 0, windowWidth, windowHeight, resultsOverlayColor, false)
+	seconds := int(r.Frames / 60)
+	title, cause := "GAME OVER", fmt.Sprintf("CAUSE     %7s", r.Cause)
+	if r.Cleared {
+		title, cause = "STAGE CLEAR!", fmt.Sprintf("CLEAR BONUS%6d", gradeClearPoints)
+	}
+	lines := []string{
+		title,
+		"",
+		fmt.Sprintf("DISTANCE   %6d", r.Distance),
+		fmt.Sprintf("COINS      %6d", r.Coins),
+		fmt.Sprintf("TIME       %3d:%02d", seconds/60, seconds%60),
+		fmt.Sprintf("SAVES      %6d", r.Saves),
+		fmt.Sprintf("DEATHS     %6d", r.Deaths),
+		fmt.Sprintf("CONTINUES  %6d", r.Continues),
+		cause,
+		"",
+		fmt.Sprintf("SCORE      %6d", r.Score),
+	}
+	if r.NewBest {
+		lines = append(lines, "NEW BEST!")
+	}
+	if r.Signature != "" {
+		lines = append(lines, "", "SIGNATURE "+r.Signature)
+	}
+	y := windowHeight/2 - len(lines)*16/2
+	for _, line := range lines {
+		DrawTextCentered(screen, line, windowWidth/2, y)
+		y += 16
+	}
+	// 评级印章盖在统计的右边
+	r.stamp.Draw(screen, windowWidth/2+140, windowHeight/2)
+}
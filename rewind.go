@@ -0,0 +1,231 @@
+package main
+
+import "my_ai_game/internal/engine"
+
+const (
+	// 回溯缓冲的帧数（约 5 秒）
+	rewindBufferFrames = 300
+	// 按住回溯键时每帧回退的快照数（回溯速度是正常速度的倍数）
+	rewindFramesPerUpdate = 2
+	// 道具是 1UP 道具的概率（在子弹时间和护盾道具之外，只在开启回溯时出现）
+	extraLifeChance = 0.04
+)
+
+// extraLifeTint 1UP 道具的颜色倍数（偏绿，与其他道具区分）
+var extraLifeTint = [3]float32{0.6, 1.4, 0.6}
+
+// monsterSnapshot 单个怪物在某一帧的状态
+type monsterSnapshot struct {
+	obstacle *Obstacle
+	value    Obstacle
+	monster  Monster
+	brain    StateMachine[*MonsterContext]
+}
+
+// boulderSnapshot 单个巨石在某一帧的状态
+type boulderSnapshot struct {
+	obstacle *Obstacle
+	value    Obstacle
+	boulder  Boulder
+}
+
+// gameSnapshot 某一帧的可回溯状态（玩家、相机和所有会变化的障碍物）
+// 静态的道路和障碍物不会变化，只记录障碍物列表本身（用于恢复被拾取的道具和被移除的怪物）；怪物和巨石另外记录数值
+type gameSnapshot struct {
+	player    Player
+	animation engine.AnimationSnapshot[AnimationState]
+	camera    engine.Camera  // 相机位置和滚动速度倍数
+	vertical  VerticalScroll // 纵向滚动段进度
+	scroll    CameraScroll   // 相机滚动方向和转向触发点进度
+	chunks    int            // 已创建的区块数量
+	obstacles []*Obstacle
+	monsters  []monsterSnapshot
+	boulders  []boulderSnapshot
+	hazards   HazardScheduler // 落物点的触发状态和正在预警、下落的落物
+	devices   []deviceState   // 门的打开计时和开关的踩下状态
+}
+
+// RewindBuffer 固定长度的快照环形缓冲
+type RewindBuffer struct {
+	snapshots []gameSnapshot
+	start     int // 最早的快照下标
+	count     int // 有效快照数量
+}
+
+// NewRewindBuffer 创建可保存 capacity 帧的回溯缓冲
+func NewRewindBuffer(capacity int) *RewindBuffer {
+	return &RewindBuffer{snapshots: make([]gameSnapshot, capacity)}
+}
+
+// Record 记录当前帧的状态，缓冲已满时覆盖最早的快照
+// 复用被覆盖快照的切片，避免每帧分配内存
+func (b *RewindBuffer) Record(g *Game) {
+	index := (b.start + b.count) % len(b.snapshots)
+	if b.count < len(b.snapshots) {
+		b.count++
+	} else {
+		b.start = (b.start + 1) % len(b.snapshots)
+	}
+
+	snapshot := &b.snapshots[index]
+	snapshot.player = *g.Player
+	snapshot.animation = g.Player.Animation.Snapshot()
+	snapshot.camera = *g.Camera
+	snapshot.vertical = g.vertical
+	snapshot.scroll = g.scroll
+	snapshot.chunks = g.chunks.loaded
+	snapshot.obstacles = append(snapshot.obstacles[:0], g.Obstacles...)
+	snapshot.hazards = g.hazards.snapshot(snapshot.hazards)
+	snapshot.devices = g.devices.snapshot(snapshot.devices)
+	snapshot.monsters = snapshot.monsters[:0]
+	snapshot.boulders = snapshot.boulders[:0]
+	for _, obstacle := range g.Obstacles {
+		if obstacle.Boulder != nil {
+			snapshot.boulders = append(snapshot.boulders, boulderSnapshot{obstacle: obstacle, value: *obstacle, boulder: *obstacle.Boulder})
+		}
+		if obstacle.Monster == nil {
+			continue
+		}
+		snapshot.monsters = append(snapshot.monsters, monsterSnapshot{
+			obstacle: obstacle,
+			value:    *obstacle,
+			monster:  *obstacle.Monster,
+			brain:    *obstacle.Monster.Brain,
+		})
+	}
+}
+
+// Pop 取出最近的一帧快照，缓冲为空时返回 nil
+// 返回的快照在下一次 Record 之前有效
+func (b *RewindBuffer) Pop() *gameSnapshot {
+	if b.count == 0 {
+		return nil
+	}
+	b.count--
+	return &b.snapshots[(b.start+b.count)%len(b.snapshots)]
+}
+
+// At 返回第 i 个快照（0 为最早），返回的快照在下一次 Record 之前有效
+func (b *RewindBuffer) At(i int) *gameSnapshot {
+	return &b.snapshots[(b.start+i)%len(b.snapshots)]
+}
+
+// Truncate 只保留最早的 n 个快照
+func (b *RewindBuffer) Truncate(n int) {
+	b.count = min(b.count, n)
+}
+
+// Len 返回缓冲中的快照数量
+func (b *RewindBuffer) Len() int {
+	return b.count
+}
+
+// Clear 清空缓冲
+func (b *RewindBuffer) Clear() {
+	b.start = 0
+	b.count = 0
+}
+
+// restore 把游戏恢复到快照时的状态
+// 怪物的状态机回调捕获的是障碍物指针，所以按原指针写回数值，而不是创建新的对象
+func (s *gameSnapshot) restore(g *Game) {
+	animation := g.Player.Animation
+	*g.Player = s.player
+	g.Player.Animation = animation
+	animation.Restore(s.animation)
+
+	// 回溯前开始的补间（例如进入飞行）不再继续修改恢复后的状态
+	g.tweens.Clear()
+
+	*g.Camera = s.camera
+	g.vertical = s.vertical
+	g.scroll = s.scroll
+	g.Obstacles = append(g.Obstacles[:0], s.obstacles...)
+	g.restoreChunks(s.chunks)
+	g.hazards.restore(&s.hazards)
+	g.devices.restore(s.devices)
+	for _, m := range s.monsters {
+		*m.obstacle = m.value
+		*m.obstacle.Monster = m.monster
+		*m.obstacle.Monster.Brain = m.brain
+	}
+	for _, b := range s.boulders {
+		*b.obstacle = b.value
+		*b.obstacle.Boulder = b.boulder
+	}
+}
+
+// RewindSystem 回溯系统：记录最近约 5 秒的状态，玩家死亡后按住 R 键（或手柄左边的按钮）回溯到失误之前
+// 每次回溯消耗一次回溯次数（Settings.RewindCharges，为 0 时关闭回溯），作为休闲模式下检查点的替代
+type RewindSystem struct {
+	buffer    *RewindBuffer
+	charges   int  // 剩余回溯次数
+	rewinding bool // 是否正在回溯
+}
+
+// NewRewindSystem 创建回溯系统，订阅拾取道具事件：拾取 1UP 道具时增加一次回溯次数
+// 回溯次数就是这个游戏的“命”：拾取后回溯到拾取之前要消耗一次，再次拾取又加回来，不会凭空多出次数
+func NewRewindSystem(charges int, events *EventBus) *RewindSystem {
+	s := &RewindSystem{
+		buffer:  NewRewindBuffer(rewindBufferFrames),
+		charges: charges,
+	}
+	events.Subscribe(EventToolCollected, func(e Event) {
+		if e.Obstacle != nil && e.Obstacle.PowerUp == PowerUpExtraLife {
+			s.charges++
+		}
+	})
+	return s
+}
+
+// Update 玩家存活时记录状态，死亡后处理回溯输入
+// 回溯期间设置 Game.isRewinding，物理、拾取和相机系统暂停更新
+func (s *RewindSystem) Update(g *Game) {
+	if g.isPaused || g.Player == nil {
+		return
+	}
+
+	held := g.Input.Rewind
+	if s.rewinding {
+		if held {
+			s.step(g)
+			return
+		}
+		// 松开回溯键，从当前位置继续游戏
+		s.rewinding = false
+		g.isRewinding = false
+		g.events.Publish(Event{Type: EventPlayerRewound, X: g.Player.X, Y: g.Player.Y})
+	}
+
+	if !g.Player.IsDead {
+		// 慢动作时只在有模拟步的帧记录（没有模拟步的帧状态不变）
+		if g.clock.Steps() > 0 {
+			s.buffer.Record(g)
+		}
+		return
+	}
+
+	// 死亡后按下回溯键开始回溯，消耗一次回溯次数
+	if held && s.charges > 0 && s.buffer.Len() > 0 {
+		s.charges--
+		s.rewinding = true
+		g.isRewinding = true
+		s.step(g)
+	}
+}
+
+// step 回退若干帧，缓冲用完时停在最早的状态
+func (s *RewindSystem) step(g *Game) {
+	var snapshot *gameSnapshot
+	for i := 0; i < rewindFramesPerUpdate && s.buffer.Len() > 0; i++ {
+		snapshot = s.buffer.Pop()
+	}
+	if snapshot != nil {
+		snapshot.restore(g)
+	}
+}
+
+// Charges 返回剩余回溯次数
+func (s *RewindSystem) Charges() int {
+	return s.charges
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"hash/fnv"
+	"math/rand"
+)
+
+// 随机数流名称
+// 每个系统使用自己的流，新增系统或某个系统多取了随机数都不会影响其他系统的结果，
+// 保证相同种子（回放、每日挑战）在加入新功能后仍然生成相同的关卡
+const (
+	rngStreamMap         = "map"         // 地图生成
+	rngStreamMonsters    = "monsters"    // 怪物种类选择
+	rngStreamCoins       = "coins"       // 金币轨迹摆放
+	rngStreamRoutes      = "routes"      // 高处路线生成
+	rngStreamVariants    = "variants"    // 障碍物外观变体
+	rngStreamDecorations = "decorations" // 装饰物摆放
+	rngStreamScroll      = "scroll"      // 往返滚动的转向触发点
+	rngStreamVertical    = "vertical"    // 纵向滚动段的位置和攀爬平台
+	rngStreamPowerUps    = "powerups"    // 道具效果（飞行或子弹时间）
+	rngStreamBoulders    = "boulders"    // 障碍物换成巨石
+	rngStreamSwings      = "swings"      // 缺口上方的秋千点
+	rngStreamHazards     = "hazards"     // 落物点
+	rngStreamGates       = "gates"       // 开关和门
+	rngStreamKeys        = "keys"        // 钥匙和上锁的门
+)
+
+// RNG 随机数服务，每局游戏按种子创建一次
+type RNG struct {
+	seed    int64
+	streams map[string]*rand.Rand
+}
+
+// NewRNG 创建随机数服务
+func NewRNG(seed int64) *RNG {
+	return &RNG{
+		seed:    seed,
+		streams: make(map[string]*rand.Rand),
+	}
+}
+
+// Seed 返回本局的随机种子
+func (r *RNG) Seed() int64 {
+	return r.seed
+}
+
+// Stream 获取指定名称的随机数流，第一次获取时创建
+// 流的种子由本局种子和流名称共同决定，与获取顺序无关
+func (r *RNG) Stream(name string) *rand.Rand {
+	if stream, ok := r.streams[name]; ok {
+		return stream
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	stream := rand.New(rand.NewSource(r.seed ^ int64(h.Sum64())))
+	r.streams[name] = stream
+	return stream
+}
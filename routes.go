@@ -0,0 +1,59 @@
+package main
+
+import "math/rand"
+
+const (
+	// 高处路线平台顶部距离道路顶部的高度（像素），在玩家跳跃高度以内
+	highRouteHeight = 200.0
+	// 平台厚度（像素）
+	platformThickness = 30.0
+	// 每列开始一条高处路线的概率
+	highRouteChance = 0.04
+	// 高处路线的最短和最长列数
+	highRouteMinLength = 6
+	highRouteMaxLength = 12
+	// 高处路线每列放置危险的概率（不在两端，不连续）
+	highRouteHazardChance = 0.3
+	// 地图开头和结尾不生成高处路线的列数
+	highRouteMargin = 20
+	// 高处路线上使用的怪物（静止哨兵，只能通过名称放置）
+	highRouteHazardMonster = "sentry"
+)
+
+// GenHighRoutes 在地图上生成与地面路线并行的高处路线
+// 高处路线由单向平台组成，金币更多但有哨兵怪物；低处原有的路线保持不变，作为安全路线。
+// 路线两端的地面列（分岔点和汇合点）保证是没有障碍物和怪物的道路，玩家可以在分岔点起跳选择高处路线，
+// 并在路线结束后安全落地
+func GenHighRoutes(items []*MapItem, random *rand.Rand) {
+	for start := highRouteMargin; start < len(items)-highRouteMargin; start++ {
+		if random.Float64() >= highRouteChance {
+			continue
+		}
+
+		length := highRouteMinLength + random.Intn(highRouteMaxLength-highRouteMinLength+1)
+		end := start + length // 汇合点（路线后的第一列）
+		if end >= len(items)-highRouteMargin {
+			break
+		}
+
+		// 分岔点和汇合点清理为安全的道路
+		for _, col := range []int{start - 1, end} {
+			items[col].HasRoad = true
+			items[col].HasObstacle = false
+			items[col].HasMonster = false
+		}
+
+		prevHazard := false
+		for col := start; col < end; col++ {
+			item := items[col]
+			item.HasPlatform = true
+			// 两端的平台不放危险，保证上下平台的位置安全
+			isEdge := col == start || col == end-1
+			item.HasPlatformHazard = !isEdge && !prevHazard && random.Float64() < highRouteHazardChance
+			prevHazard = item.HasPlatformHazard
+		}
+
+		// 两条路线之间至少间隔一段地面路线
+		start = end + highRouteMinLength
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// 同步存档的网络请求超时时间
+	saveSyncTimeout = 5 * time.Second
+)
+
+// ErrNoRemoteSave 远程还没有这个存档
+var ErrNoRemoteSave = errors.New("远程没有存档")
+
+// SaveSyncBackend 存档同步后端：按存档名称上传和下载存档文件的内容
+// 后端只负责传输，冲突由 SyncProfile 按保存时间解决
+type SaveSyncBackend interface {
+	// Download 下载存档，远程没有时返回 ErrNoRemoteSave
+	Download(name string) ([]byte, error)
+	// Upload 上传存档，覆盖远程的存档
+	Upload(name string, data []byte) error
+}
+
+// SaveSyncFactory 按同步地址创建后端
+type SaveSyncFactory func(u *url.URL) (SaveSyncBackend, error)
+
+// saveSyncBackends 按地址协议注册的同步后端
+var saveSyncBackends = map[string]SaveSyncFactory{}
+
+// RegisterSaveSyncBackend 注册同步后端（与怪物行为注册表相同的方式）
+// scheme: 同步地址的协议，例如 https
+func RegisterSaveSyncBackend(scheme string, factory SaveSyncFactory) {
+	saveSyncBackends[scheme] = factory
+}
+
+func init() {
+	// 普通 HTTP 服务器和 WebDAV 都用 GET/PUT 读写文件；S3 兼容存储可以用允许读写的公开存储桶地址，
+	// 需要签名的存储注册自己的后端
+	RegisterSaveSyncBackend("http", newHTTPSyncBackend)
+	RegisterSaveSyncBackend("https", newHTTPSyncBackend)
+	RegisterSaveSyncBackend("webdav", newHTTPSyncBackend)
+	RegisterSaveSyncBackend("webdavs", newHTTPSyncBackend)
+}
+
+// NewSaveSyncBackend 按同步地址的协议创建后端
+func NewSaveSyncBackend(rawURL string) (SaveSyncBackend, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("无效的同步地址: %w", err)
+	}
+	factory, ok := saveSyncBackends[u.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("不支持的同步地址协议: %s", u.Scheme)
+	}
+	return factory(u)
+}
+
+// httpSyncBackend HTTP/WebDAV 同步后端：存档保存为 <地址>/<存档名称>.json
+// 地址中的用户名和密码用于基本认证（WebDAV 常用）
+type httpSyncBackend struct {
+	client   *http.Client
+	base     string
+	user     string
+	password string
+}
+
+// newHTTPSyncBackend 创建 HTTP 同步后端，webdav/webdavs 协议分别按 http/https 访问
+func newHTTPSyncBackend(u *url.URL) (SaveSyncBackend, error) {
+	base := *u
+	switch base.Scheme {
+	case "webdav":
+		base.Scheme = "http"
+	case "webdavs":
+		base.Scheme = "https"
+	}
+	b := &httpSyncBackend{client: &http.Client{Timeout: saveSyncTimeout}}
+	if base.User != nil {
+		b.user = base.User.Username()
+		b.password, _ = base.User.Password()
+		base.User = nil
+	}
+	b.base = strings.TrimSuffix(base.String(), "/")
+	return b, nil
+}
+
+// request 发送请求（带基本认证）
+func (b *httpSyncBackend) request(method, name string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, b.base+"/"+url.PathEscape(name)+".json", reader)
+	if err != nil {
+		return nil, err
+	}
+	if b.user != "" {
+		req.SetBasicAuth(b.user, b.password)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return b.client.Do(req)
+}
+
+// Download 下载存档
+func (b *httpSyncBackend) Download(name string) ([]byte, error) {
+	resp, err := b.request(http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, ErrNoRemoteSave
+	default:
+		return nil, fmt.Errorf("下载存档 %s 失败: %s", name, resp.Status)
+	}
+}
+
+// Upload 上传存档
+func (b *httpSyncBackend) Upload(name string, data []byte) error {
+	resp, err := b.request(http.MethodPut, name, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	default:
+		return fmt.Errorf("上传存档 %s 失败: %s", name, resp.Status)
+	}
+}
+
+// SyncProfile 同步存档：远程的存档保存时间更晚时用它替换本地存档，否则上传本地存档
+// 本地和远程都没有存档时什么也不做
+func SyncProfile(backend SaveSyncBackend, name string) error {
+	local, err := LoadProfile(name)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	data, err := backend.Download(name)
+	if err != nil && !errors.Is(err, ErrNoRemoteSave) {
+		return err
+	}
+	if err == nil {
+		var remote Profile
+		if err := json.Unmarshal(data, &remote); err != nil {
+			return fmt.Errorf("解析远程存档 %s 失败: %w", name, err)
+		}
+		remote.Name = name
+		if local == nil || remote.SavedAt.After(local.SavedAt) {
+			return remote.write()
+		}
+		if remote.SavedAt.Equal(local.SavedAt) {
+			return nil
+		}
+	}
+	return UploadProfile(backend, local)
+}
+
+// UploadProfile 上传本地存档（存档还没有保存过时什么也不做）
+func UploadProfile(backend SaveSyncBackend, profile *Profile) error {
+	if profile == nil || profile.SavedAt.IsZero() {
+		return nil
+	}
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return err
+	}
+	return backend.Upload(profile.Name, data)
+}
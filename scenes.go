@@ -0,0 +1,240 @@
+package main
+
+import (
+	"log"
+	"slices"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"my_ai_game/internal/engine"
+)
+
+// 游戏结束菜单的位置（在结算界面的下方）
+const gameOverMenuY = windowHeight - 140
+
+// SceneManager 场景管理器：作为 ebiten.Game 运行当前场景（标题、开始游戏后接管的场景、游戏结束），
+// 场景之间用 Switch 切换：过渡层先把当前场景渐出到黑色（这期间不更新当前场景），完全变黑时切换，再渐入新的场景，
+// 切换后释放之前的场景（标题除外）
+// 管理器接管关闭窗口：当前场景实现了 RequestQuit 时交给它（游戏在一局进行中先确认，保存存档），否则马上退出；退出后由 main 调用 Close
+// 调试模式下每次回到标题时检查资源有没有泄漏（见 checkLeaks）
+type SceneManager struct {
+	current ebiten.Game
+	next    ebiten.Game
+	title   *TitleScene // 回到标题时复用（保留选项页的修改）
+	fade    Transition  // 切换场景时的过渡
+
+	debug     bool
+	resources engine.ResourceCounts // 第一次回到标题时没有释放的资源
+	baseline  *leakSample           // 第一次回到标题时的协程数量和堆内存（为 nil 时还没有记录）
+}
+
+// NewSceneManager 创建场景管理器，从标题场景开始；启动选项记住管理器，之后创建的游戏结束时切换到游戏结束场景
+func NewSceneManager(opts GameOptions) *SceneManager {
+	m := &SceneManager{debug: opts.Debug}
+	opts.scenes = m
+	m.title = NewTitleScene(opts)
+	m.current = m.title
+	ebiten.SetWindowClosingHandled(true)
+	return m
+}
+
+// Switch 渐暗后切换到 scene
+func (m *SceneManager) Switch(scene ebiten.Game) {
+	m.SwitchWith(scene, TransitionFade)
+}
+
+// SwitchWith 按 style 的过渡切换到 scene（重新开始使用擦除，和回到标题、游戏结束区分）
+func (m *SceneManager) SwitchWith(scene ebiten.Game, style TransitionStyle) {
+	m.next = scene
+	m.fade.Start(style)
+}
+
+// ToTitle 渐暗后回到标题菜单
+func (m *SceneManager) ToTitle() {
+	m.Switch(m.title)
+}
+
+// Close 释放当前场景和等待切换的场景，以及缓存的动画和字形（退出时调用）
+func (m *SceneManager) Close() {
+	closeScene(m.current)
+	if m.next != nil && m.next != m.current {
+		closeScene(m.next)
+	}
+	DisposeArtAnimations()
+	DisposeFonts()
+}
+
+// closeScene 释放场景拥有的资源（场景实现了 Close 时，例如游戏、加载界面和播放着演示的存档选择、关卡浏览）
+func closeScene(scene ebiten.Game) {
+	if closer, ok := scene.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
+// checkLeaks 回到标题时（之前的场景已经释放）检查资源：第一次记录基线（缓存在第一局加载），之后没有释放的资源比基线多、
+// 或者协程比基线多出 leakCheckGoroutineSlack 个以上时给出警告
+func (m *SceneManager) checkLeaks() {
+	live, sample := engine.LiveResources(), takeLeakSample()
+	if m.baseline == nil {
+		m.resources, m.baseline = live, &sample
+		return
+	}
+	if live.AudioPlayers > m.resources.AudioPlayers || live.Images > m.resources.Images {
+		log.Printf("警告: 回到标题后多了 %d 个音频播放器、%d 张图片没有释放", live.AudioPlayers-m.resources.AudioPlayers, live.Images-m.resources.Images)
+	}
+	if sample.goroutines > m.baseline.goroutines+leakCheckGoroutineSlack {
+		log.Printf("警告: 回到标题后协程比第一次回到标题时多了 %d 个", sample.goroutines-m.baseline.goroutines)
+	}
+}
+
+// Update 推进过渡：渐出期间不更新当前场景，完全变黑时切换到等待的场景（释放之前的场景），之后更新当前场景
+func (m *SceneManager) Update() error {
+	if m.fade.Update() && m.next != nil {
+		if m.current != m.title {
+			closeScene(m.current)
+		}
+		m.current, m.next = m.next, nil
+		if m.debug && m.current == m.title {
+			m.checkLeaks()
+		}
+	}
+	// 渐出期间之前的场景已经结束（切换前已经保存存档），关闭窗口时马上退出
+	if ebiten.IsWindowBeingClosed() && (m.fade.Covering() || requestQuit(m.current)) {
+		return ebiten.Termination
+	}
+	if m.fade.Covering() {
+		return nil
+	}
+	return m.current.Update()
+}
+
+// requestQuit 关闭窗口时询问场景是否马上退出（场景实现了 RequestQuit 时，例如游戏），否则马上退出
+func requestQuit(scene ebiten.Game) bool {
+	if quitter, ok := scene.(interface{ RequestQuit() bool }); ok {
+		return quitter.RequestQuit()
+	}
+	return true
+}
+
+// Draw 绘制当前场景和过渡的遮罩
+func (m *SceneManager) Draw(screen *ebiten.Image) {
+	m.current.Draw(screen)
+	m.fade.Draw(screen)
+}
+
+// Layout 返回游戏逻辑尺寸
+func (m *SceneManager) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return windowWidth, windowHeight
+}
+
+// gameOverMenuItem 游戏结束菜单的一项
+type gameOverMenuItem int
+
+const (
+	gameOverMenuRetry gameOverMenuItem = iota
+	gameOverMenuNewMap
+	gameOverMenuTitle
+)
+
+// gameOverMenuNames 游戏结束菜单项的名称
+var gameOverMenuNames = map[gameOverMenuItem]string{
+	gameOverMenuRetry:  "RETRY",
+	gameOverMenuNewMap: "NEW MAP",
+	gameOverMenuTitle:  "TITLE",
+}
+
+// String 返回菜单项的名称
+func (m gameOverMenuItem) String() string {
+	return gameOverMenuNames[m]
+}
+
+// GameOverScene 游戏结束场景：结算界面之后按确认键进入，显示这一局最后的画面（结算界面）和菜单，
+// 可以用同样的启动选项（同一张地图、同一个存档）重新开始，用新的种子重新生成地图开始（只有随机生成的地图，回溯键同样），
+// 或者回到标题菜单（返回键同样回到标题）；重新开始创建新的游戏，玩家、相机和音频都从头开始
+// 通关时画面是通关的结算界面（STAGE CLEAR），随机生成的地图默认选中 NEW MAP
+type GameOverScene struct {
+	scenes   *SceneManager
+	opts     GameOptions        // 这一局的启动选项（重新开始时使用）
+	frame    *ebiten.Image      // 这一局最后的画面
+	items    []gameOverMenuItem // 显示的菜单项（关卡文件没有 NEW MAP）
+	selected int
+}
+
+// NewGameOverScene 创建游戏结束场景，保存游戏当前的画面
+func NewGameOverScene(g *Game) *GameOverScene {
+	frame := ebiten.NewImage(windowWidth, windowHeight)
+	g.Draw(frame)
+	items := []gameOverMenuItem{gameOverMenuRetry, gameOverMenuNewMap, gameOverMenuTitle}
+	if g.options.LevelPath != "" {
+		items = slices.DeleteFunc(items, func(item gameOverMenuItem) bool { return item == gameOverMenuNewMap })
+	}
+	s := &GameOverScene{scenes: g.options.scenes, opts: g.options, frame: frame, items: items}
+	if g.cleared {
+		s.selected = max(slices.Index(items, gameOverMenuNewMap), 0)
+	}
+	return s
+}
+
+// Update 处理菜单输入
+func (s *GameOverScene) Update() error {
+	count := len(s.items)
+	switch {
+	case titleMenuUp():
+		s.selected--
+	case titleMenuDown():
+		s.selected++
+	case ActionJustPressed(ActionBack):
+		s.scenes.ToTitle()
+	case ActionJustPressed(ActionRewind):
+		s.newMap()
+	case ActionJustPressed(ActionConfirm):
+		switch s.items[s.selected] {
+		case gameOverMenuRetry:
+			s.scenes.SwitchWith(NewLoadingScene(s.opts), TransitionWipe)
+		case gameOverMenuNewMap:
+			s.newMap()
+		case gameOverMenuTitle:
+			s.scenes.ToTitle()
+		}
+	}
+	s.selected = (s.selected + count) % count
+	return nil
+}
+
+// newMap 用新的种子重新生成地图开始（其余启动选项不变）；关卡文件没有随机地图，重新开始同一个关卡
+func (s *GameOverScene) newMap() {
+	opts := s.opts
+	if opts.LevelPath == "" {
+		opts.Seed = time.Now().UnixNano()
+		log.Printf("种子码: %s", SeedCode(opts.Seed, opts.Mutators))
+	}
+	s.scenes.SwitchWith(NewLoadingScene(opts), TransitionWipe)
+}
+
+// Draw 绘制最后的画面和菜单
+func (s *GameOverScene) Draw(screen *ebiten.Image) {
+	screen.DrawImage(s.frame, nil)
+	for i, item := range s.items {
+		line := item.String()
+		if i == s.selected {
+			line = "> " + line + " <"
+		}
+		DrawTextCentered(screen, line, windowWidth/2, gameOverMenuY+i*titleMenuSpacing)
+	}
+	prompt := "{navigate} SELECT  {confirm} OK  {rewind} NEW MAP  {back} TITLE"
+	if s.opts.LevelPath != "" {
+		prompt = "{navigate} SELECT  {confirm} OK  {rewind} RETRY  {back} TITLE"
+	}
+	DrawPromptCentered(screen, prompt, windowWidth/2, gameOverMenuY+(len(s.items)+1)*titleMenuSpacing)
+}
+
+// Close 释放保存的画面
+func (s *GameOverScene) Close() {
+	s.frame.Deallocate()
+}
+
+// Layout 返回游戏逻辑尺寸
+func (s *GameOverScene) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return windowWidth, windowHeight
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	// 开发模式下检查脚本文件是否修改的间隔（帧数）
+	scriptReloadFrames = 30
+)
+
+// ScriptValues 在游戏和脚本之间传递的数值表
+// 脚本函数收到一张表，可以直接修改其中的字段，调用结束后游戏读回修改后的值（布尔值读回为 0/1）
+type ScriptValues map[string]float64
+
+// ScriptRuntime 脚本运行时
+// 脚本文件返回一个模块表（例如 Lua 的 `return { update = function(self) ... end }`），游戏按函数名调用
+type ScriptRuntime interface {
+	Load(path string) error                                                // 加载（或重新加载）脚本文件
+	Call(path, function string, values ScriptValues) (ScriptValues, error) // 调用脚本模块中的函数，返回修改后的数值表
+	Close()                                                                // 释放运行时
+}
+
+// newScriptRuntime 创建脚本运行时，为 nil 时表示没有编译脚本后端（Lua 后端需要 -tags lua 编译）
+var newScriptRuntime func() ScriptRuntime
+
+// errNoScriptRuntime 没有编译脚本后端
+var errNoScriptRuntime = errors.New("没有编译脚本支持（需要使用 -tags lua 编译）")
+
+// ScriptTrigger 关卡中的脚本触发点：玩家第一次到达该列时调用脚本函数
+type ScriptTrigger struct {
+	Column   int    `json:"column"`   // 触发的列
+	Script   string `json:"script"`   // 脚本文件（相对于关卡文件所在的目录）
+	Function string `json:"function"` // 调用的函数（为空时为 on_trigger）
+}
+
+// ScriptHost 管理脚本运行时和已加载的脚本文件，开发模式下修改脚本文件后自动重新加载
+type ScriptHost struct {
+	runtime ScriptRuntime
+	files   map[string]time.Time // 已加载的脚本文件及其修改时间
+	dev     bool                 // 是否在开发模式（热重载）
+	frames  int                  // 距离上次检查热重载经过的帧数
+	failed  map[string]bool      // 调用失败过的脚本函数（只警告一次）
+}
+
+// NewScriptHost 创建脚本宿主，没有编译脚本后端时返回错误
+func NewScriptHost(dev bool) (*ScriptHost, error) {
+	if newScriptRuntime == nil {
+		return nil, errNoScriptRuntime
+	}
+	return &ScriptHost{
+		runtime: newScriptRuntime(),
+		files:   make(map[string]time.Time),
+		dev:     dev,
+		failed:  make(map[string]bool),
+	}, nil
+}
+
+// Load 加载脚本文件（已加载的文件不重复加载）
+func (h *ScriptHost) Load(path string) error {
+	if _, ok := h.files[path]; ok {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := h.runtime.Load(path); err != nil {
+		return fmt.Errorf("加载脚本 %s 失败: %w", path, err)
+	}
+	h.files[path] = info.ModTime()
+	return nil
+}
+
+// Call 调用脚本函数；出错时给出一次警告并返回 false（之后继续尝试调用，热重载修复后恢复）
+func (h *ScriptHost) Call(path, function string, values ScriptValues) (ScriptValues, bool) {
+	result, err := h.runtime.Call(path, function, values)
+	key := path + ":" + function
+	if err != nil {
+		if !h.failed[key] {
+			log.Printf("警告: 调用脚本 %s 的 %s 失败: %v", path, function, err)
+			h.failed[key] = true
+		}
+		return nil, false
+	}
+	delete(h.failed, key)
+	return result, true
+}
+
+// Update 开发模式下定期检查脚本文件是否修改，修改后重新加载
+func (h *ScriptHost) Update() {
+	if !h.dev {
+		return
+	}
+	if h.frames++; h.frames < scriptReloadFrames {
+		return
+	}
+	h.frames = 0
+	for path, modTime := range h.files {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().After(modTime) {
+			continue
+		}
+		h.files[path] = info.ModTime()
+		if err := h.runtime.Load(path); err != nil {
+			log.Printf("警告: 重新加载脚本 %s 失败: %v", path, err)
+			continue
+		}
+		log.Printf("已重新加载脚本: %s", path)
+	}
+}
+
+// Close 释放脚本运行时
+func (h *ScriptHost) Close() {
+	h.runtime.Close()
+}
+
+// scriptedBehavior 由脚本驱动的怪物行为：每帧调用脚本的 update(self)
+// self 中是怪物的状态（x、y、vx、vy、direction、on_ground、timer）和场景信息（player_x、player_y、ground_y），
+// 脚本修改 direction、vx（水平移动，被障碍物挡住时不移动）、jump（在地面上时以该速度起跳）、timer 和 dead（非 0 时移除怪物）
+func scriptedBehavior(host *ScriptHost, path string) MonsterBehavior {
+	return func(o *Obstacle) *monsterMachine {
+		m := o.Monster
+		sm := NewStateMachine[*MonsterContext](AIStateAttack)
+		sm.AddState(AIStateAttack, &monsterState{
+			Update: func(ctx *MonsterContext) AIState {
+				self := ScriptValues{
+					"x":         o.X,
+					"y":         o.Y,
+					"vx":        0,
+					"vy":        m.VelocityY,
+					"direction": m.Direction,
+					"on_ground": boolValue(m.IsOnGround),
+					"timer":     float64(m.Timer),
+					"player_x":  ctx.Player.X,
+					"player_y":  ctx.Player.Y,
+					"ground_y":  ctx.GroundY,
+				}
+				if result, ok := host.Call(path, "update", self); ok {
+					self = result
+				}
+
+				if self["direction"] < 0 {
+					m.Direction = -1
+				} else if self["direction"] > 0 {
+					m.Direction = 1
+				}
+				m.Timer = int(self["timer"])
+				if newX := o.X + self["vx"]; self["vx"] != 0 && !ctx.isBlocked(o, newX) {
+					o.Move(newX-o.X, 0)
+				}
+				if jump := self["jump"]; jump > 0 && m.IsOnGround {
+					m.VelocityY = -jump
+					m.IsOnGround = false
+				}
+				applyMonsterGravity(o, ctx)
+
+				if self["dead"] != 0 {
+					return AIStateDead
+				}
+				return AIStateAttack
+			},
+		})
+		sm.AddState(AIStateDead, deadState(o))
+		return sm
+	}
+}
+
+// boolValue 把布尔值转换为脚本数值（true 为 1）
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// ScriptSystem 脚本系统：玩家到达触发点时调用脚本函数，开发模式下热重载脚本
+type ScriptSystem struct {
+	host     *ScriptHost
+	triggers []ScriptTrigger // 触发点（脚本路径已经按关卡目录解析）
+	fired    []bool          // 各触发点是否已经触发
+}
+
+// NewScriptSystem 创建脚本系统
+func NewScriptSystem(host *ScriptHost, triggers []ScriptTrigger) *ScriptSystem {
+	return &ScriptSystem{host: host, triggers: triggers, fired: make([]bool, len(triggers))}
+}
+
+// Update 检查触发点
+// 触发函数收到 column、player_x、player_y、coins，可以修改 coins（金币数）、speed_scale（相机速度倍数）和 kill（非 0 时玩家死亡）
+func (s *ScriptSystem) Update(g *Game) {
+	s.host.Update()
+	if g.isPaused || g.isRewinding || g.Player == nil || g.Player.IsDead {
+		return
+	}
+
+	col := int(g.Player.X / mapItemWidth)
+	for i, trigger := range s.triggers {
+		if s.fired[i] || trigger.Column != col {
+			continue
+		}
+		s.fired[i] = true
+
+		function := trigger.Function
+		if function == "" {
+			function = "on_trigger"
+		}
+		values := ScriptValues{
+			"column":      float64(trigger.Column),
+			"player_x":    g.Player.X,
+			"player_y":    g.Player.Y,
+			"coins":       float64(g.Player.Coins),
+			"speed_scale": g.Camera.SpeedScale,
+		}
+		result, ok := s.host.Call(trigger.Script, function, values)
+		if !ok {
+			continue
+		}
+		g.Player.Coins = int(result["coins"])
+		g.Camera.SpeedScale = result["speed_scale"]
+		if result["kill"] != 0 {
+			g.Player.handleDeath(DeathCauseScript)
+		}
+	}
+}
+
+// initScripts 加载关卡引用的脚本：替换怪物行为，注册触发点
+// 脚本是可选的：没有编译脚本后端或脚本加载失败时给出警告，怪物保持原来的行为
+func (g *Game) initScripts(level *Level) {
+	if len(level.Behaviors) == 0 && len(level.Triggers) == 0 {
+		return
+	}
+	host, err := NewScriptHost(g.options.Dev)
+	if err != nil {
+		log.Printf("警告: 关卡中的脚本不会执行: %v", err)
+		return
+	}
+
+	for name, script := range level.Behaviors {
+		def := g.monsterCatalog.Get(name)
+		if def == nil {
+			log.Printf("警告: 关卡脚本引用了不存在的怪物: %s", name)
+			continue
+		}
+		path := level.AssetPath(script)
+		if err := host.Load(path); err != nil {
+			log.Printf("警告: %v", err)
+			continue
+		}
+		def.behavior = scriptedBehavior(host, path)
+	}
+
+	var triggers []ScriptTrigger
+	for _, trigger := range level.Triggers {
+		trigger.Script = level.AssetPath(trigger.Script)
+		if err := host.Load(trigger.Script); err != nil {
+			log.Printf("警告: %v", err)
+			continue
+		}
+		triggers = append(triggers, trigger)
+	}
+	g.systems = append(g.systems, NewScriptSystem(host, triggers))
+	g.releases = append(g.releases, host.Close)
+}
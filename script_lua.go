@@ -0,0 +1,78 @@
+//go:build lua
+
+package main
+
+import (
+	"fmt"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// luaRuntime 基于 gopher-lua 的脚本运行时
+// 每个脚本文件执行后返回一个模块表，按文件路径保存
+type luaRuntime struct {
+	state   *lua.LState
+	modules map[string]*lua.LTable
+}
+
+func init() {
+	newScriptRuntime = func() ScriptRuntime {
+		return &luaRuntime{state: lua.NewState(), modules: make(map[string]*lua.LTable)}
+	}
+}
+
+// Load 执行脚本文件，保存它返回的模块表（重新加载时替换旧的模块表）
+func (r *luaRuntime) Load(path string) error {
+	fn, err := r.state.LoadFile(path)
+	if err != nil {
+		return err
+	}
+	r.state.Push(fn)
+	if err := r.state.PCall(0, 1, nil); err != nil {
+		return err
+	}
+	ret := r.state.Get(-1)
+	r.state.Pop(1)
+	module, ok := ret.(*lua.LTable)
+	if !ok {
+		return fmt.Errorf("脚本没有返回模块表")
+	}
+	r.modules[path] = module
+	return nil
+}
+
+// Call 以数值表为参数调用模块中的函数，读回函数修改后的表
+func (r *luaRuntime) Call(path, function string, values ScriptValues) (ScriptValues, error) {
+	module := r.modules[path]
+	if module == nil {
+		return nil, fmt.Errorf("脚本未加载")
+	}
+	fn, ok := module.RawGetString(function).(*lua.LFunction)
+	if !ok {
+		return nil, fmt.Errorf("脚本中没有函数 %s", function)
+	}
+
+	table := r.state.NewTable()
+	for key, value := range values {
+		table.RawSetString(key, lua.LNumber(value))
+	}
+	if err := r.state.CallByParam(lua.P{Fn: fn, NRet: 0, Protect: true}, table); err != nil {
+		return nil, err
+	}
+
+	result := make(ScriptValues, len(values))
+	table.ForEach(func(key, value lua.LValue) {
+		switch v := value.(type) {
+		case lua.LNumber:
+			result[key.String()] = float64(v)
+		case lua.LBool:
+			result[key.String()] = boolValue(bool(v))
+		}
+	})
+	return result, nil
+}
+
+// Close 关闭 Lua 虚拟机
+func (r *luaRuntime) Close() {
+	r.state.Close()
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 往返滚动关卡中向前（向右）一段的列数范围
+	scrollForwardMinColumns = 48
+	scrollForwardMaxColumns = 64
+	// 往返滚动关卡中折返（向左）一段的列数范围
+	scrollBackMinColumns = 12
+	scrollBackMaxColumns = 20
+	// 地图两端不放置转向触发点的列数
+	scrollTriggerMargin = 20
+	// 向左滚动的关卡在地图右端保证有道路的列数（玩家在右侧出生）
+	scrollSafeStartColumns = 10
+)
+
+// ScrollMode 关卡的相机自动滚动方式
+type ScrollMode int
+
+const (
+	ScrollModeRight       ScrollMode = iota // 向右滚动（默认）
+	ScrollModeLeft                          // 从地图右端出发向左滚动
+	ScrollModeAlternating                   // 向右出发，经过转向触发点时反向
+)
+
+// scrollModeNames 滚动方式名称（命令行参数和关卡文件使用）
+var scrollModeNames = map[ScrollMode]string{
+	ScrollModeRight:       "right",
+	ScrollModeLeft:        "left",
+	ScrollModeAlternating: "alternating",
+}
+
+// String 返回滚动方式名称
+func (m ScrollMode) String() string {
+	return scrollModeNames[m]
+}
+
+// ParseScrollMode 根据名称解析滚动方式
+func ParseScrollMode(name string) (ScrollMode, error) {
+	for mode, modeName := range scrollModeNames {
+		if strings.EqualFold(name, modeName) {
+			return mode, nil
+		}
+	}
+	return ScrollModeRight, fmt.Errorf("未知的滚动方式: %s", name)
+}
+
+// MarshalText 关卡文件中以名称保存滚动方式
+func (m ScrollMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText 从关卡文件中的名称解析滚动方式
+func (m *ScrollMode) UnmarshalText(text []byte) error {
+	mode, err := ParseScrollMode(string(text))
+	if err != nil {
+		return err
+	}
+	*m = mode
+	return nil
+}
+
+// PrepareScrollMap 按滚动方式调整随机生成的地图（在 GenMap 之后调用）
+// 向左滚动时保证地图右端有道路；往返滚动时生成转向触发点
+func PrepareScrollMap(items []*MapItem, mode ScrollMode, random *rand.Rand) {
+	switch mode {
+	case ScrollModeLeft:
+		for i := len(items) - scrollSafeStartColumns; i < len(items); i++ {
+			if i >= 0 {
+				items[i].HasRoad = true
+			}
+		}
+	case ScrollModeAlternating:
+		GenScrollTriggers(items, random)
+	}
+}
+
+// GenScrollTriggers 为往返滚动的关卡生成转向触发点
+// 触发点按经过的顺序编号（MapItem.ScrollTrigger 从 1 开始），相机只响应下一个编号的触发点：
+// 向右前进 48～64 列后向左折返 12～20 列，再向右前进，依此类推
+func GenScrollTriggers(items []*MapItem, random *rand.Rand) {
+	number := 1
+	pos := 0
+	for {
+		forward := pos + scrollForwardMinColumns + random.Intn(scrollForwardMaxColumns-scrollForwardMinColumns+1)
+		if forward >= len(items)-scrollTriggerMargin {
+			return
+		}
+		items[forward].ScrollTrigger = number
+		number++
+
+		pos = forward - scrollBackMinColumns - random.Intn(scrollBackMaxColumns-scrollBackMinColumns+1)
+		items[pos].ScrollTrigger = number
+		number++
+	}
+}
+
+// CameraScroll 相机自动滚动状态（方向和下一个转向触发点），随回溯快照一起恢复
+type CameraScroll struct {
+	Mode        ScrollMode
+	Direction   float64 // 当前滚动方向：1 向右，-1 向左
+	nextTrigger int     // 下一个生效的转向触发点编号（从 1 开始）
+	triggers    []int   // 各编号触发点所在的列（下标为编号 - 1），只读
+}
+
+// NewCameraScroll 按滚动方式创建滚动状态，并把相机放到起始位置
+func NewCameraScroll(mode ScrollMode, items []*MapItem, camera *engine.Camera) CameraScroll {
+	scroll := CameraScroll{Mode: mode, Direction: 1, nextTrigger: 1}
+	camera.X = camera.MinX
+	if mode == ScrollModeLeft {
+		scroll.Direction = -1
+		camera.X = camera.MaxX
+	}
+	if mode == ScrollModeAlternating {
+		for _, item := range items {
+			if item.ScrollTrigger <= 0 {
+				continue
+			}
+			for len(scroll.triggers) < item.ScrollTrigger {
+				scroll.triggers = append(scroll.triggers, -1)
+			}
+			scroll.triggers[item.ScrollTrigger-1] = item.Index
+		}
+	}
+	return scroll
+}
+
+// Update 检查转向触发点：相机中心沿当前方向经过下一个触发点所在列的中心时反向
+func (s *CameraScroll) Update(camera *engine.Camera) {
+	if s.nextTrigger > len(s.triggers) {
+		return
+	}
+	col := s.triggers[s.nextTrigger-1]
+	if col < 0 {
+		// 编号不连续（关卡文件缺少某个编号），跳过
+		s.nextTrigger++
+		return
+	}
+	triggerX := (float64(col) + 0.5) * mapItemWidth
+	centerX := camera.X + camera.Width/2.0
+	if (s.Direction > 0 && centerX >= triggerX) || (s.Direction < 0 && centerX <= triggerX) {
+		s.Direction = -s.Direction
+		s.nextTrigger++
+	}
+}
+
+// Streamable 是否只向右滚动（只有这种情况可以释放相机后方的区块）
+func (s *CameraScroll) Streamable() bool {
+	return s.Mode == ScrollModeRight
+}
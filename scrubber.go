@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 存档点的间隔（游戏时间，帧）和数量（最近 2 分钟）
+	scrubIntervalFrames = engine.GameFPS
+	scrubSnapshots      = 120
+)
+
+// ScrubberSystem 存档点回放（调试用，-debug 启动时创建）：每秒游戏时间记录一次完整的可回溯状态（与回溯系统的快照相同），
+// 按 [ 冻结游戏并逐个退回更早的存档点，按 ] 前进到较晚的存档点，按 Enter 从当前存档点继续运行，
+// 用来反复重现某一时刻附近的问题
+// 从较早的存档点继续时，之后的存档点和回溯缓冲都作废（之后的时间线已经改变）
+type ScrubberSystem struct {
+	buffer     *RewindBuffer
+	nextRecord float64 // 下一次记录时的游戏时间（帧）
+	active     bool    // 是否正在回放（游戏冻结）
+	cursor     int     // 当前恢复的存档点下标（0 为最早）
+}
+
+// NewScrubberSystem 创建存档点回放
+func NewScrubberSystem() *ScrubberSystem {
+	return &ScrubberSystem{buffer: NewRewindBuffer(scrubSnapshots)}
+}
+
+// Active 是否正在回放（s 为 nil 时没有开启）
+func (s *ScrubberSystem) Active() bool {
+	return s != nil && s.active
+}
+
+// Update 按游戏时间记录存档点，处理回放按键
+func (s *ScrubberSystem) Update(g *Game) {
+	if g.Player == nil || g.pause.Open() {
+		return
+	}
+	// 失去焦点后重新获得焦点会取消暂停，视为从当前存档点继续
+	if s.active && !g.isPaused {
+		s.resume(g)
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft):
+		if !s.active {
+			if s.buffer.Len() == 0 {
+				return
+			}
+			s.active = true
+			s.cursor = s.buffer.Len() - 1
+			g.isPaused = true
+		} else if s.cursor > 0 {
+			s.cursor--
+		}
+		s.buffer.At(s.cursor).restore(g)
+		return
+	case s.active && inpututil.IsKeyJustPressed(ebiten.KeyBracketRight):
+		if s.cursor < s.buffer.Len()-1 {
+			s.cursor++
+			s.buffer.At(s.cursor).restore(g)
+		}
+		return
+	case s.active && inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		s.resume(g)
+		g.isPaused = false
+		return
+	}
+
+	if g.isPaused || g.isRewinding {
+		return
+	}
+	if now := g.clock.Now(); now >= s.nextRecord {
+		s.buffer.Record(g)
+		s.nextRecord = now + scrubIntervalFrames
+	}
+}
+
+// resume 从当前存档点继续：丢弃之后的存档点和回溯缓冲
+func (s *ScrubberSystem) resume(g *Game) {
+	s.active = false
+	s.buffer.Truncate(s.cursor + 1)
+	s.nextRecord = g.clock.Now() + scrubIntervalFrames
+	g.rewind.buffer.Clear()
+}
+
+// Draw 回放时在屏幕底部显示当前存档点和按键说明（s 为 nil 时没有开启）
+func (s *ScrubberSystem) Draw(screen *ebiten.Image) {
+	if !s.Active() {
+		return
+	}
+	ago := s.buffer.Len() - 1 - s.cursor
+	text := fmt.Sprintf("SCRUB %d/%d (-%ds)   [ BACK   ] FORWARD   ENTER RESUME", s.cursor+1, s.buffer.Len(), ago)
+	DrawText(screen, text, 10, windowHeight-58)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FocusLossAudioMode 窗口失去焦点时的音频处理方式
+type FocusLossAudioMode int
+
+const (
+	FocusLossAudioNone  FocusLossAudioMode = iota // 不处理，继续正常播放
+	FocusLossAudioDuck                            // 压低所有音频的音量
+	FocusLossAudioPause                           // 暂停所有音频
+)
+
+// focusLossAudioNames 失去焦点时音频处理方式的名称（命令行参数和存档使用）
+var focusLossAudioNames = map[FocusLossAudioMode]string{
+	FocusLossAudioNone:  "none",
+	FocusLossAudioDuck:  "duck",
+	FocusLossAudioPause: "pause",
+}
+
+// String 返回音频处理方式名称
+func (m FocusLossAudioMode) String() string {
+	return focusLossAudioNames[m]
+}
+
+// ParseFocusLossAudioMode 根据名称解析失去焦点时的音频处理方式
+func ParseFocusLossAudioMode(name string) (FocusLossAudioMode, error) {
+	for mode, modeName := range focusLossAudioNames {
+		if strings.EqualFold(name, modeName) {
+			return mode, nil
+		}
+	}
+	return FocusLossAudioDuck, fmt.Errorf("未知的失去焦点音频处理方式: %s", name)
+}
+
+// GraphicsQuality 画面质量
+type GraphicsQuality int
+
+const (
+	GraphicsQualityLow  GraphicsQuality = iota // 低配：关闭调色等后期效果
+	GraphicsQualityHigh                        // 高配：开启所有效果
+)
+
+// graphicsQualityNames 画面质量名称（命令行参数使用）
+var graphicsQualityNames = map[GraphicsQuality]string{
+	GraphicsQualityLow:  "low",
+	GraphicsQualityHigh: "high",
+}
+
+// String 返回画面质量名称
+func (q GraphicsQuality) String() string {
+	return graphicsQualityNames[q]
+}
+
+// ParseGraphicsQuality 根据名称解析画面质量
+func ParseGraphicsQuality(name string) (GraphicsQuality, error) {
+	for quality, qualityName := range graphicsQualityNames {
+		if strings.EqualFold(name, qualityName) {
+			return quality, nil
+		}
+	}
+	return GraphicsQualityHigh, fmt.Errorf("未知的画面质量: %s", name)
+}
+
+// AccessibilitySettings 辅助功能设置
+type AccessibilitySettings struct {
+	LandingPredictor bool // 玩家在空中时是否在预测的落点绘制标记
+	Captions         bool // 播放音效时是否在屏幕下方显示字幕（例如 "[jump]"）
+}
+
+// Settings 游戏设置
+type Settings struct {
+	FocusLossAudio   FocusLossAudioMode    // 窗口失去焦点时的音频处理方式
+	PauseOnFocusLoss bool                  // 窗口失去焦点或最小化时是否自动暂停游戏（避免相机自动滚动导致玩家死亡）
+	IdlePauseMinutes int                   // 游戏中连续多少分钟没有输入后自动暂停（玩家离开时避免被自动滚动杀死，0 表示关闭）
+	Display          DisplaySettings       // 显示设置
+	RewindCharges    int                   // 每局可回溯的次数（休闲模式，0 表示关闭回溯）
+	Announcer        bool                  // 是否开启连击播报语音
+	Quality          GraphicsQuality       // 画面质量（低配时关闭主题调色）
+	Accessibility    AccessibilitySettings // 辅助功能设置
+	Analytics        bool                  // 是否在本地记录匿名的跑图统计（死亡位置、检查点时间、高处路线），默认关闭
+	RichPresence     bool                  // 是否在 Discord 等显示在线状态（当前模式、距离和种子码），默认关闭
+	SampleRate       int                   // 音频输出采样率（采样率不同的音频文件加载时自动重采样）
+	MusicVolume      float64               // 背景音乐音量（0 到 1）
+	SFXVolume        float64               // 音效音量（0 到 1）
+	ShowHitboxes     bool                  // 是否绘制碰撞盒（调试）
+}
+
+// DefaultSettings 返回默认设置
+func DefaultSettings() Settings {
+	return Settings{
+		FocusLossAudio:   FocusLossAudioDuck,
+		PauseOnFocusLoss: true,
+		IdlePauseMinutes: 2,
+		RewindCharges:    3,
+		Announcer:        true,
+		Quality:          GraphicsQualityHigh,
+		SampleRate:       audioSampleRate,
+		MusicVolume:      1,
+		SFXVolume:        1,
+		Display: DisplaySettings{
+			Mode:       WindowModeWindowed,
+			Resolution: Resolution{Width: windowWidth, Height: windowHeight},
+			Monitor:    0,
+		},
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// 设置页每次调整音量的幅度
+const settingsVolumeStep = 0.1
+
+// settingsMenuItem 设置页的一项
+type settingsMenuItem int
+
+const (
+	settingsMenuMusic settingsMenuItem = iota
+	settingsMenuSFX
+	settingsMenuFullscreen
+	settingsMenuHitboxes
+)
+
+// settingsMenuNames 设置页各项的名称
+var settingsMenuNames = map[settingsMenuItem]string{
+	settingsMenuMusic:      "MUSIC",
+	settingsMenuSFX:        "SOUND",
+	settingsMenuFullscreen: "FULLSCREEN",
+	settingsMenuHitboxes:   "HITBOXES",
+}
+
+// settingsMenuFlags 设置项对应的命令行参数名（从标题修改后按命令行指定处理，存档记住的设置不覆盖）
+var settingsMenuFlags = map[settingsMenuItem]string{
+	settingsMenuMusic:      "music-volume",
+	settingsMenuSFX:        "sfx-volume",
+	settingsMenuFullscreen: "window",
+}
+
+// String 返回设置项的名称
+func (m settingsMenuItem) String() string {
+	return settingsMenuNames[m]
+}
+
+// SettingsScene 设置页（标题菜单的 OPTIONS 和暂停菜单的 SETTINGS 打开）：背景音乐和音效的音量、全屏、碰撞盒显示
+// 从标题打开时是选项页，先列出 titleOptions 中的开关（静音、字幕等，只影响之后开始的游戏）
+// 修改的是启动选项（之后开始的游戏使用）；从标题修改的项按命令行指定处理，存档记住的设置不覆盖；
+// 从暂停菜单打开时同时通过 Game 的设置接口应用到正在进行的游戏
+// 上下选择，左右调整音量，确认键或左右切换开关，返回键关闭
+type SettingsScene struct {
+	opts     *GameOptions
+	game     *Game         // 从暂停菜单打开时的游戏（从标题打开时为 nil）
+	toggles  []titleOption // 设置项之前的开关（从标题打开时为 titleOptions）
+	y        int           // 第一行的位置
+	selected int
+	done     bool
+}
+
+// NewSettingsScene 创建设置页，修改 opts（从暂停菜单打开时 game 不为 nil，opts 为游戏的启动选项），从 y 开始绘制
+func NewSettingsScene(opts *GameOptions, game *Game, y int) *SettingsScene {
+	s := &SettingsScene{opts: opts, game: game, y: y}
+	if game == nil {
+		s.toggles = titleOptions
+	}
+	return s
+}
+
+// rows 返回设置页的行数（开关和设置项）
+func (s *SettingsScene) rows() int {
+	return len(s.toggles) + len(settingsMenuNames)
+}
+
+// Update 处理设置页的输入
+func (s *SettingsScene) Update() {
+	count := s.rows()
+	left := inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) || GamepadJustPressed(ebiten.StandardGamepadButtonLeftLeft)
+	right := inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) || GamepadJustPressed(ebiten.StandardGamepadButtonLeftRight)
+	switch {
+	case titleMenuUp():
+		s.selected--
+	case titleMenuDown():
+		s.selected++
+	case ActionJustPressed(ActionBack):
+		s.done = true
+	case left:
+		s.change(-1)
+	case right || ActionJustPressed(ActionConfirm):
+		s.change(1)
+	}
+	s.selected = (s.selected + count) % count
+}
+
+// change 调整选中的设置：音量按方向增减（限制在 0 到 1），开关切换
+func (s *SettingsScene) change(direction float64) {
+	if s.selected < len(s.toggles) {
+		toggle := s.toggles[s.selected]
+		value := toggle.value(s.opts)
+		*value = !*value
+		s.opts.explicit[toggle.flag] = true
+		return
+	}
+
+	step := func(volume float64) float64 {
+		return math.Round(min(max(volume+direction*settingsVolumeStep, 0), 1)*10) / 10
+	}
+	item := s.item()
+	if flag := settingsMenuFlags[item]; flag != "" && s.game == nil {
+		s.opts.explicit[flag] = true
+	}
+	switch item {
+	case settingsMenuMusic:
+		s.opts.MusicVolume = step(s.opts.MusicVolume)
+		if s.game != nil {
+			s.game.SetMusicVolume(s.opts.MusicVolume)
+		}
+	case settingsMenuSFX:
+		s.opts.SFXVolume = step(s.opts.SFXVolume)
+		if s.game != nil {
+			s.game.SetSFXVolume(s.opts.SFXVolume)
+		}
+	case settingsMenuFullscreen:
+		fullscreen := s.opts.Display.Mode != WindowModeFullscreen
+		s.opts.Display.Mode = WindowModeWindowed
+		if fullscreen {
+			s.opts.Display.Mode = WindowModeFullscreen
+		}
+		if s.game != nil {
+			s.game.SetFullscreen(fullscreen)
+		} else {
+			ApplyDisplaySettings(s.opts.Display)
+		}
+	case settingsMenuHitboxes:
+		s.opts.Hitboxes = !s.opts.Hitboxes
+		if s.game != nil {
+			s.game.SetShowHitboxes(s.opts.Hitboxes || s.opts.Debug)
+		}
+	}
+}
+
+// item 返回选中的设置项（选中开关时无意义）
+func (s *SettingsScene) item() settingsMenuItem {
+	return settingsMenuItem(s.selected - len(s.toggles))
+}
+
+// Done 是否已经关闭
+func (s *SettingsScene) Done() bool {
+	return s.done
+}
+
+// Draw 绘制设置项（音量显示为 10 格的进度条和百分比）
+func (s *SettingsScene) Draw(screen *ebiten.Image) {
+	onOff := func(on bool) string {
+		if on {
+			return "ON"
+		}
+		return "OFF"
+	}
+	volume := func(v float64) string {
+		filled := int(math.Round(v * 10))
+		return fmt.Sprintf("[%s%s] %3d%%", strings.Repeat("#", filled), strings.Repeat("-", 10-filled), int(math.Round(v*100)))
+	}
+	for i := 0; i < s.rows(); i++ {
+		if i < len(s.toggles) {
+			s.drawRow(screen, i, s.toggles[i].name, onOff(*s.toggles[i].value(s.opts)))
+			continue
+		}
+		item := settingsMenuItem(i - len(s.toggles))
+		var value string
+		switch item {
+		case settingsMenuMusic:
+			value = volume(s.opts.MusicVolume)
+		case settingsMenuSFX:
+			value = volume(s.opts.SFXVolume)
+		case settingsMenuFullscreen:
+			value = onOff(s.opts.Display.Mode == WindowModeFullscreen)
+		case settingsMenuHitboxes:
+			value = onOff(s.opts.Hitboxes || s.opts.Debug)
+		}
+		s.drawRow(screen, i, item.String(), value)
+	}
+	DrawPromptCentered(screen, "{navigate} SELECT  {confirm} CHANGE  {back} BACK", windowWidth/2, s.y+(s.rows()+1)*titleMenuSpacing)
+}
+
+// drawRow 绘制第 row 行（名称左对齐，值右对齐，选中的行两侧加箭头）
+func (s *SettingsScene) drawRow(screen *ebiten.Image, row int, name, value string) {
+	line := fmt.Sprintf("%-14s %17s", name, value)
+	if row == s.selected {
+		line = "> " + line + " <"
+	} else {
+		line = "  " + line + "  "
+	}
+	DrawTextCentered(screen, line, windowWidth/2, s.y+row*titleMenuSpacing)
+}
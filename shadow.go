@@ -0,0 +1,99 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 阴影贴图的边长（像素），绘制时缩放成椭圆
+	shadowTextureSize = 64
+	// 玩家贴地时阴影椭圆的宽度和高度（像素）
+	shadowWidth  = 90.0
+	shadowHeight = 18.0
+	// 阴影最深时的不透明度
+	shadowAlpha = 0.45
+	// 离地这么高（像素）时阴影缩到最小
+	shadowFadeHeight = 400.0
+	// 阴影最小的缩放比例
+	shadowMinScale = 0.35
+)
+
+// Shadow 玩家脚下的柔和阴影（投射到正下方最近的地面上，离地越高越小越淡）
+type Shadow struct {
+	texture *ebiten.Image // 径向渐变的圆形贴图
+}
+
+// NewShadow 创建阴影，生成径向渐变贴图（中心最深，边缘透明）
+func NewShadow() *Shadow {
+	pixels := make([]byte, shadowTextureSize*shadowTextureSize*4)
+	center := float64(shadowTextureSize) / 2
+	for y := 0; y < shadowTextureSize; y++ {
+		for x := 0; x < shadowTextureSize; x++ {
+			dx := (float64(x) + 0.5 - center) / center
+			dy := (float64(y) + 0.5 - center) / center
+			d := math.Hypot(dx, dy)
+			if d >= 1 {
+				continue
+			}
+			// smoothstep 衰减，边缘柔和
+			t := 1 - d
+			a := byte(t * t * (3 - 2*t) * 255)
+			// 预乘 alpha：黑色只需要写 alpha 通道
+			pixels[(y*shadowTextureSize+x)*4+3] = a
+		}
+	}
+	texture := ebiten.NewImage(shadowTextureSize, shadowTextureSize)
+	texture.WritePixels(pixels)
+	return &Shadow{texture: texture}
+}
+
+// surfaceBelow 从世界坐标 (x, y) 向下做射线检测，返回正下方最近的可站立表面的顶部 Y
+// 从上方阻挡（SolidFromAbove）的障碍物都算地面；下方没有地面（缺口）时返回 false
+func surfaceBelow(x, y float64, obstacles []*Obstacle) (float64, bool) {
+	groundY, found := 0.0, false
+	for _, obstacle := range obstacles {
+		if !obstacle.Solidity.Has(SolidFromAbove) {
+			continue
+		}
+		left, right, top, _ := obstacle.GetCollisionBox()
+		if x < left || x > right || top < y {
+			continue
+		}
+		if !found || top < groundY {
+			groundY, found = top, true
+		}
+	}
+	return groundY, found
+}
+
+// Draw 把阴影绘制到玩家正下方的地面上，离地越高阴影越小、越淡
+func (s *Shadow) Draw(screen *ebiten.Image, camera *engine.Camera, player *Player, obstacles []*Obstacle) {
+	if player.IsDead {
+		return
+	}
+	groundY, ok := surfaceBelow(player.X, player.Y, obstacles)
+	if !ok {
+		return
+	}
+
+	// 离地高度映射到 [shadowMinScale, 1]
+	scale := max(shadowMinScale, 1-(groundY-player.Y)/shadowFadeHeight)
+	width, height := shadowWidth*scale, shadowHeight*scale
+
+	s.drawEllipse(screen, camera, player.X, groundY, width, height, shadowAlpha*scale)
+}
+
+// drawEllipse 以世界坐标 (centerX, centerY) 为中心绘制给定尺寸和不透明度的阴影椭圆（落物的预警阴影也使用）
+func (s *Shadow) drawEllipse(screen *ebiten.Image, camera *engine.Camera, centerX, centerY, width, height, alpha float64) {
+	screenX, screenY := camera.WorldToScreen(centerX-width/2, centerY-height/2)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(width/shadowTextureSize, height/shadowTextureSize)
+	op.GeoM.Translate(screenX, screenY)
+	op.ColorScale.ScaleAlpha(float32(alpha))
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(s.texture, op)
+}
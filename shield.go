@@ -0,0 +1,85 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 道具是护盾道具的概率（在子弹时间道具之外）
+	shieldChance = 0.25
+	// 护盾抵挡致命触碰后的无敌帧数（期间再碰到怪物不会死亡，玩家闪烁）
+	shieldGraceFrames = 45
+	// 护盾气泡贴图的边长（像素），绘制时缩放到 shieldDiameter
+	shieldTextureSize = 128
+	// 护盾气泡的直径（像素，覆盖玩家碰撞盒的大部分高度）
+	shieldDiameter = 300.0
+	// 护盾破裂动画的帧数和气泡最终放大的倍数
+	shieldPopFrames = 18
+	shieldPopScale  = 1.5
+)
+
+var (
+	// 护盾气泡的填充色和边缘色（半透明）
+	shieldFillColor = color.RGBA{R: 0x50, G: 0xa0, B: 0xff, A: 0x40}
+	shieldEdgeColor = color.RGBA{R: 0xa0, G: 0xd8, B: 0xff, A: 0xc0}
+	// 护盾道具的颜色倍数（偏金色，与飞行道具区分）
+	shieldTint = [3]float32{1.3, 1.15, 0.5}
+)
+
+// ShieldBubble 护盾的显示：玩家有护盾时在身体周围画半透明气泡，护盾破裂时气泡放大并淡出
+// 护盾本身是玩家状态（Player.Shield），这里只负责绘制
+type ShieldBubble struct {
+	texture    *ebiten.Image
+	pop        engine.Timer // 破裂动画（游戏时间）
+	popX, popY float64      // 破裂时气泡中心的世界坐标
+}
+
+// NewShieldBubble 创建护盾显示，订阅护盾破裂事件
+func NewShieldBubble(events *EventBus) *ShieldBubble {
+	texture := ebiten.NewImage(shieldTextureSize, shieldTextureSize)
+	half := float32(shieldTextureSize) / 2
+	vector.FillCircle(texture, half, half, half-2, shieldFillColor, true)
+	vector.StrokeCircle(texture, half, half, half-3, 3, shieldEdgeColor, true)
+
+	b := &ShieldBubble{texture: texture}
+	events.Subscribe(EventShieldPopped, func(e Event) {
+		b.popX, b.popY = e.X, e.Y-playerCollisionHeight/2
+		b.pop.Start(shieldPopFrames)
+	})
+	return b
+}
+
+// Update 推进破裂动画
+// dt: 本帧的游戏时间（帧）
+func (b *ShieldBubble) Update(dt float64) {
+	b.pop.Tick(dt)
+}
+
+// Draw 绘制玩家身上的气泡和正在播放的破裂动画
+func (b *ShieldBubble) Draw(screen *ebiten.Image, camera *engine.Camera, player *Player) {
+	if player.Shield && !player.IsDead {
+		b.drawBubble(screen, camera, player.X, player.Y-playerCollisionHeight/2, 1, 1)
+	}
+	if b.pop.Running() {
+		t := b.pop.Progress()
+		scale := 1 + (shieldPopScale-1)*engine.EaseOutCubic(t)
+		b.drawBubble(screen, camera, b.popX, b.popY, scale, 1-t)
+	}
+}
+
+// drawBubble 以世界坐标 (x, y) 为中心绘制气泡
+func (b *ShieldBubble) drawBubble(screen *ebiten.Image, camera *engine.Camera, x, y, scale, alpha float64) {
+	size := shieldDiameter * scale
+	screenX, screenY := camera.WorldToScreen(x-size/2, y-size/2)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(size/shieldTextureSize, size/shieldTextureSize)
+	op.GeoM.Translate(screenX, screenY)
+	op.ColorScale.ScaleAlpha(float32(alpha))
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(b.texture, op)
+}
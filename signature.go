@@ -0,0 +1,53 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"strings"
+)
+
+// InputHashSystem 输入摘要：每个模拟步把本步的玩家输入累计到 FNV-1a 摘要中（暂停和回溯时没有模拟步，不累计），
+// 结果签名用它代表这一局的全部输入；同样的启动选项和输入重新模拟应得到相同的摘要和结果
+// 放在模拟步的最前面（物理系统读取同一份输入之前）
+type InputHashSystem struct {
+	hash  hash.Hash64
+	steps int // 累计的模拟步数
+}
+
+// NewInputHashSystem 创建输入摘要
+func NewInputHashSystem() *InputHashSystem {
+	return &InputHashSystem{hash: fnv.New64a()}
+}
+
+// Update 累计本步的输入（每个按键一位，与回放的编码相同）
+func (s *InputHashSystem) Update(g *Game) {
+	s.hash.Write([]byte{replayBits(g.Input, false)})
+	s.steps++
+}
+
+// Sum 返回输入摘要（16 位十六进制）
+func (s *InputHashSystem) Sum() string {
+	return fmt.Sprintf("%016x", s.hash.Sum64())
+}
+
+// RunSignature 结果签名：对游戏版本、地图标识（种子码包含突变）、突变、输入摘要和结算的统计做 SHA-256，
+// 取前 16 位十六进制；提交成绩时附上签名，服务器或其他玩家用同样的启动选项和输入重新模拟后比较签名即可验证
+// 签名没有密钥，只用于核对成绩与输入是否一致（签名同时保存在回放文件中；游戏还没有排行榜）
+func RunSignature(opts GameOptions, inputs *InputHashSystem, r *RunResults) string {
+	fields := []string{
+		"version=" + gameVersion,
+		"map=" + analyticsMapKey(opts),
+		"mutators=" + opts.Mutators.String(),
+		"inputs=" + inputs.Sum(),
+		fmt.Sprintf("steps=%d", inputs.steps),
+		fmt.Sprintf("distance=%d", r.Distance),
+		fmt.Sprintf("coins=%d", r.Coins),
+		fmt.Sprintf("cleared=%t", r.Cleared),
+		fmt.Sprintf("score=%d", r.Score),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, ";")))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,107 @@
+package main
+
+const (
+	// 慢速区域和快速区域的滚动速度倍数
+	speedZoneSlowScale = 0.7
+	speedZoneFastScale = 1.1 // 玩家移动速度只比相机快 10%，快速区域不能再快
+	// 进入新区域时速度倍数每帧的最大变化量（约 1 秒从慢速过渡到正常速度）
+	speedZoneBlendPerFrame = 0.005
+	// 判断困难段的窗口列数，窗口内的危险列（缺口、障碍物、怪物）达到阈值即为困难段
+	speedZoneHardWindow    = 8
+	speedZoneHardThreshold = 3
+	// 困难段之前的减速列数
+	speedZoneSlowLeadIn = 6
+	// 连续安全道路达到这么多列时中间部分为快速区域，两端各留 speedZoneFastInset 列
+	speedZoneFastMinRun = 20
+	speedZoneFastInset  = 4
+)
+
+// SpeedZone 相机速度区域：相机中心位于这些列时自动滚动速度乘以 Scale
+type SpeedZone struct {
+	Column  int     `json:"column"`  // 区域的第一列
+	Columns int     `json:"columns"` // 区域宽度（列数）
+	Scale   float64 `json:"scale"`   // 速度倍数
+}
+
+// isHazardColumn 该列是否有危险（缺口、障碍物或怪物）
+func isHazardColumn(item *MapItem) bool {
+	return !item.HasRoad || item.HasObstacle || item.HasMonster
+}
+
+// GenSpeedZones 按地图难度生成速度区域（不使用随机数，同一张地图总是得到相同的区域）
+// 困难段（连续 8 列中至少 3 列有危险）及其之前 6 列为慢速区域；
+// 连续 20 列以上的安全道路中间为快速区域；两者重叠时慢速优先
+func GenSpeedZones(items []*MapItem) []SpeedZone {
+	scales := make([]float64, len(items))
+	for i := range scales {
+		scales[i] = 1
+	}
+
+	// 快速区域：连续的安全道路
+	for start := 0; start < len(items); {
+		end := start
+		for end < len(items) && !isHazardColumn(items[end]) && !items[end].HasPlatform {
+			end++
+		}
+		if end-start >= speedZoneFastMinRun {
+			for i := start + speedZoneFastInset; i < end-speedZoneFastInset; i++ {
+				scales[i] = speedZoneFastScale
+			}
+		}
+		start = end + 1
+	}
+
+	// 慢速区域：困难段和困难段之前的几列
+	hazards := 0
+	for i, item := range items {
+		if isHazardColumn(item) {
+			hazards++
+		}
+		if i >= speedZoneHardWindow && isHazardColumn(items[i-speedZoneHardWindow]) {
+			hazards--
+		}
+		if hazards < speedZoneHardThreshold {
+			continue
+		}
+		for j := max(0, i-speedZoneHardWindow+1-speedZoneSlowLeadIn); j <= i; j++ {
+			scales[j] = speedZoneSlowScale
+		}
+	}
+
+	// 合并相同倍数的连续列
+	var zones []SpeedZone
+	for i := 0; i < len(scales); {
+		j := i
+		for j < len(scales) && scales[j] == scales[i] {
+			j++
+		}
+		if scales[i] != 1 {
+			zones = append(zones, SpeedZone{Column: i, Columns: j - i, Scale: scales[i]})
+		}
+		i = j
+	}
+	return zones
+}
+
+// BuildSpeedScales 把速度区域展开为每列的目标速度倍数（没有区域的列为 1）
+func BuildSpeedScales(columns int, zones []SpeedZone) []float64 {
+	scales := make([]float64, columns)
+	for i := range scales {
+		scales[i] = 1
+	}
+	for _, zone := range zones {
+		for col := max(0, zone.Column); col < min(columns, zone.Column+zone.Columns); col++ {
+			scales[col] = zone.Scale
+		}
+	}
+	return scales
+}
+
+// speedScaleAt 相机中心所在列的目标速度倍数
+func (g *Game) speedScaleAt(cameraX float64) float64 {
+	col := int((cameraX + float64(windowWidth)/2.0) / mapItemWidth)
+	if col < 0 || col >= len(g.speedScales) {
+		return 1
+	}
+	return g.speedScales[col]
+}
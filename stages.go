@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 游戏自带的关卡目录（按文件名排序依次解锁，例如 01-meadow.json、02-cliffs.json）
+const stagesDir = "res/levels"
+
+// isStagePath 关卡文件是否是游戏自带的关卡（直接放在 stagesDir 中）
+func isStagePath(path string) bool {
+	return filepath.Dir(filepath.Clean(path)) == filepath.Clean(stagesDir)
+}
+
+// StageInfo 关卡选择中的一关
+type StageInfo struct {
+	LevelInfo
+	Key      string       // 存档中记录成绩的地图标识（analyticsMapKey）
+	Best     ProfileGrade // 存档中最好的成绩（没有玩过时为零值）
+	Unlocked bool         // 是否已经解锁（第一关总是解锁，之后的关卡在上一关通关后解锁）
+}
+
+// Cleared 是否通关过
+func (s StageInfo) Cleared() bool {
+	return s.Best.BestFrames > 0
+}
+
+// ScanStages 按文件名顺序读取关卡目录中的关卡，按存档中的成绩标记解锁状态（profile 为 nil 时只解锁第一关）
+// 无法加载的文件跳过并给出警告
+func ScanStages(dir string, profile *Profile) []StageInfo {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		log.Printf("警告: 读取关卡目录 %s 失败: %v", dir, err)
+		return nil
+	}
+	slices.Sort(paths)
+	var stages []StageInfo
+	for _, path := range paths {
+		level, err := LoadLevel(path)
+		if err != nil {
+			log.Printf("警告: 跳过关卡文件: %v", err)
+			continue
+		}
+		stage := StageInfo{LevelInfo: levelInfo(path, level), Key: analyticsMapKey(GameOptions{LevelPath: path})}
+		if profile != nil {
+			stage.Best = profile.Grades[stage.Key]
+		}
+		stage.Unlocked = len(stages) == 0 || stages[len(stages)-1].Cleared()
+		stages = append(stages, stage)
+	}
+	return stages
+}
+
+// formatStageTime 把游戏时间（帧）格式化为 分:秒.百分秒
+func formatStageTime(frames float64) string {
+	seconds := frames / 60
+	minutes := int(seconds) / 60
+	return fmt.Sprintf("%d:%05.2f", minutes, seconds-float64(minutes*60))
+}
+
+// LevelSelect 关卡选择场景（标题菜单的 STAGES 或 -stages 打开）：列出游戏自带的关卡、解锁状态、最好评级和最快通关时间，
+// 选中已解锁的关卡后通过加载界面按该关卡创建 Game；返回键回到标题
+// 成绩来自使用的存档（Profile.Grades），通关一关后解锁下一关
+type LevelSelect struct {
+	opts     GameOptions
+	stages   []StageInfo
+	selected int
+	message  string      // 选中未解锁关卡时的提示
+	attract  AttractMode // 无操作时播放的演示
+}
+
+// NewLevelSelect 创建关卡选择场景，读取存档中的成绩（存档无法读取时只解锁第一关）
+func NewLevelSelect(opts GameOptions) *LevelSelect {
+	profile, err := OpenProfile(opts.Profile)
+	if err != nil {
+		log.Printf("警告: 无法读取存档，只解锁第一关: %v", err)
+		profile = nil
+	}
+	return &LevelSelect{opts: opts, stages: ScanStages(stagesDir, profile)}
+}
+
+// Update 选择关卡
+func (s *LevelSelect) Update() error {
+	if s.attract.Update(s.opts) {
+		return nil
+	}
+	count := len(s.stages)
+	switch {
+	case ActionJustPressed(ActionBack):
+		s.opts.scenes.ToTitle()
+	case count == 0:
+	case titleMenuUp():
+		s.selected--
+		s.message = ""
+	case titleMenuDown():
+		s.selected++
+		s.message = ""
+	case ActionJustPressed(ActionConfirm):
+		s.launch(s.stages[s.selected])
+	}
+	if count > 0 {
+		s.selected = (s.selected + count) % count
+	}
+	return nil
+}
+
+// launch 开始选中的关卡，未解锁时提示先通关上一关
+func (s *LevelSelect) launch(stage StageInfo) {
+	if !stage.Unlocked {
+		s.message = "LOCKED: CLEAR " + s.stages[s.selected-1].Name + " FIRST"
+		return
+	}
+	opts := s.opts
+	opts.LevelPath = stage.Path
+	opts.Stages = false
+	s.opts.scenes.Switch(NewLoadingScene(opts))
+}
+
+// Draw 绘制关卡列表
+func (s *LevelSelect) Draw(screen *ebiten.Image) {
+	if s.attract.Draw(screen) {
+		return
+	}
+
+	DrawPrompt(screen, "STAGES  ({navigate} SELECT, {confirm} PLAY, {back} TITLE)", 40, 40)
+	if len(s.stages) == 0 {
+		DrawText(screen, "NO STAGES IN "+stagesDir+"/", 40, 72)
+		return
+	}
+
+	DrawText(screen, fmt.Sprintf("  %-3s %-24s %-10s %5s  %s", "NO", "NAME", "DIFFICULTY", "GRADE", "BEST TIME"), 40, 72)
+	for i, stage := range s.stages {
+		cursor := " "
+		if i == s.selected {
+			cursor = ">"
+		}
+		line := fmt.Sprintf("%s %-3d %-24s LOCKED", cursor, i+1, stage.Name)
+		if stage.Unlocked {
+			grade, best := "-", "-"
+			if stage.Best.Grade != "" {
+				grade = stage.Best.Grade
+			}
+			if stage.Cleared() {
+				best = formatStageTime(stage.Best.BestFrames)
+			}
+			line = fmt.Sprintf("%s %-3d %-24s %-10s %5s  %s", cursor, i+1, stage.Name, stage.Difficulty, grade, best)
+		}
+		DrawText(screen, line, 40, 92+i*16)
+	}
+	if s.message != "" {
+		DrawText(screen, s.message, 40, 92+len(s.stages)*16+16)
+	}
+}
+
+// Close 结束正在播放的演示
+func (s *LevelSelect) Close() {
+	s.attract.Close()
+}
+
+// Layout 返回游戏逻辑尺寸
+func (s *LevelSelect) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return windowWidth, windowHeight
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestBundledStages 游戏自带的关卡都能加载并符合地图生成的规则，没有存档时只解锁第一关
+func TestBundledStages(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(stagesDir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) < 2 {
+		t.Fatalf("%s 中只有 %d 个关卡，至少应有 2 个", stagesDir, len(paths))
+	}
+	for _, path := range paths {
+		level, err := LoadLevel(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if level.Name == "" {
+			t.Errorf("%s 没有关卡名称", path)
+		}
+		if err := CheckMapInvariants(level.Items, len(level.Items)); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+
+	stages := ScanStages(stagesDir, nil)
+	if len(stages) != len(paths) {
+		t.Fatalf("ScanStages 读取了 %d 个关卡，应为 %d 个", len(stages), len(paths))
+	}
+	for i, stage := range stages {
+		if stage.Unlocked != (i == 0) {
+			t.Errorf("第 %d 关 %s 的解锁状态为 %v", i+1, stage.Name, stage.Unlocked)
+		}
+	}
+}
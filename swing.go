@@ -0,0 +1,207 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 缺口上方放置秋千点的概率（单独的随机数流，不改变其他生成结果）
+	swingPointChance = 0.4
+	// 秋千点在道路顶部以上的高度（像素）：最长的绳子垂直挂着时玩家的脚仍然在道路顶部以上
+	swingPointHeight = 560.0
+	// 秋千点图片的直径（像素）
+	swingPointSize = 24.0
+	// 玩家抓手（碰撞盒顶部中心）离秋千点不超过这个距离时可以抓住，抓住时的距离就是绳长
+	swingCatchRadius = 200.0
+	// 最短绳长（离得太近时把玩家推到这个距离）
+	swingMinRopeLength = 60.0
+	// 摆角的范围（弧度，约 80 度），到达时角速度清零
+	swingMaxAngle = 1.4
+	// 每帧保留的角速度（空气阻力）
+	swingDamping = 0.995
+	// 按住左右键时沿切线方向的加速度（像素/帧²，荡秋千时借力）
+	swingPumpAccel = 0.25
+	// 按跳跃键松手时额外的向上速度（像素/帧）
+	swingJumpBoost = -6.0
+	// 松手后空中的水平惯性每帧保留的比例
+	swingMomentumDecay = 0.99
+)
+
+// swingRopeColor 绳子的颜色
+var swingRopeColor = color.RGBA{R: 0xc8, G: 0xa0, B: 0x70, A: 0xff}
+
+// swingPointImage 秋千点图片（第一次创建秋千点时绘制）
+var swingPointImage *ebiten.Image
+
+// NewSwingPoint 创建以 (x, y) 为中心的秋千点（不阻挡移动，只用于抓住）
+func NewSwingPoint(x, y float64) *Obstacle {
+	if swingPointImage == nil {
+		swingPointImage = ebiten.NewImage(int(swingPointSize), int(swingPointSize))
+		half := float32(swingPointSize / 2.0)
+		vector.StrokeCircle(swingPointImage, half, half, half-3, 4, color.RGBA{R: 0xa0, G: 0xa0, B: 0xb0, A: 0xff}, true)
+	}
+
+	left := x - swingPointSize/2.0
+	top := y - swingPointSize/2.0
+	return NewObstacle(left, top, left, top, swingPointSize, swingPointSize, swingPointImage, ObstacleTypeSwing)
+}
+
+// swingAnchor 返回秋千点的中心（绳子的固定点）
+func swingAnchor(o *Obstacle) (x, y float64) {
+	return o.X + o.Width/2.0, o.Y + o.Height/2.0
+}
+
+// gripPosition 返回玩家抓绳子的位置（碰撞盒顶部中心）
+func (p *Player) gripPosition() (x, y float64) {
+	return p.X, p.Y - playerCollisionHeight
+}
+
+// tryGrab 在空中按住抓取键时抓住范围内最近的秋千点，进入摆动状态，返回是否抓住
+// 抓住时的速度（水平惯性和垂直速度）沿切线方向的分量变成摆动的角速度
+func (p *Player) tryGrab(input PlayerInput, obstacles []*Obstacle) bool {
+	if !input.Grab {
+		p.grabLocked = false
+		return false
+	}
+	if p.grabLocked || p.IsOnGround {
+		return false
+	}
+
+	gripX, gripY := p.gripPosition()
+	var nearest *Obstacle
+	nearestDistance := swingCatchRadius
+	for _, obstacle := range obstacles {
+		if obstacle.Type != ObstacleTypeSwing {
+			continue
+		}
+		anchorX, anchorY := swingAnchor(obstacle)
+		if distance := math.Hypot(gripX-anchorX, gripY-anchorY); distance <= nearestDistance {
+			nearest, nearestDistance = obstacle, distance
+		}
+	}
+	if nearest == nil {
+		return false
+	}
+
+	anchorX, anchorY := swingAnchor(nearest)
+	p.Swing = nearest
+	p.swingLength = max(nearestDistance, swingMinRopeLength)
+	// 摆角从竖直向下开始计算，向右为正
+	p.swingAngle = math.Atan2(gripX-anchorX, gripY-anchorY)
+	p.swingAngle = max(-swingMaxAngle, min(swingMaxAngle, p.swingAngle))
+	velocityX := p.momentumX
+	if input.Left != input.Right {
+		velocityX += p.Physics.Speed * boolSign(input.Right)
+	}
+	// 切线方向为 (cos, -sin)
+	p.swingVelocity = (velocityX*math.Cos(p.swingAngle) - p.VelocityY*math.Sin(p.swingAngle)) / p.swingLength
+	p.momentumX = 0
+	p.placeOnRope()
+	p.Animation.SetState(StateSwing)
+	return true
+}
+
+// boolSign 返回 1（true）或 -1（false）
+func boolSign(b bool) float64 {
+	if b {
+		return 1
+	}
+	return -1
+}
+
+// placeOnRope 按摆角和绳长把玩家的抓手放到绳子末端
+func (p *Player) placeOnRope() {
+	anchorX, anchorY := swingAnchor(p.Swing)
+	p.X = anchorX + p.swingLength*math.Sin(p.swingAngle)
+	p.Y = anchorY + p.swingLength*math.Cos(p.swingAngle) + playerCollisionHeight
+}
+
+// updateSwing 摆动状态：按单摆积分摆角和角速度（左右键沿切线借力），松开抓取键或按跳跃键时松手
+// 摆动时撞到从侧面阻挡的障碍物会弹回，落到障碍物上时松手站在上面
+func (p *Player) updateSwing(input PlayerInput, obstacles []*Obstacle) {
+	jumpPressed := input.Jump && !p.wasSpaceDown
+	p.wasSpaceDown = input.Jump
+	if !input.Grab || jumpPressed {
+		p.releaseSwing(jumpPressed)
+		return
+	}
+
+	// 单摆：角加速度 = -g/L·sin(θ)，再加上左右键的借力
+	accel := -p.Physics.Gravity / p.swingLength * math.Sin(p.swingAngle)
+	if input.Left != input.Right {
+		accel += boolSign(input.Right) * swingPumpAccel / p.swingLength
+	}
+	p.swingVelocity = (p.swingVelocity + accel) * swingDamping
+
+	oldAngle, oldX, oldY := p.swingAngle, p.X, p.Y
+	p.swingAngle += p.swingVelocity
+	if math.Abs(p.swingAngle) > swingMaxAngle {
+		p.swingAngle = math.Copysign(swingMaxAngle, p.swingAngle)
+		p.swingVelocity = 0
+	}
+	p.placeOnRope()
+	p.VelocityY = p.Y - oldY
+	if p.X != oldX {
+		p.FacingLeft = p.X < oldX
+	}
+
+	// 碰到怪物时和平常一样死亡（护盾可以抵挡），落到障碍物上时松手
+	p.checkCollisionWithObstacles(obstacles)
+	if p.IsDead {
+		return
+	}
+	if p.IsOnGround {
+		p.Swing = nil
+		p.VelocityY = 0
+		return
+	}
+	if p.wouldCollideHorizontal(p.X, obstacles) {
+		p.swingAngle, p.X, p.Y = oldAngle, oldX, oldY
+		p.swingVelocity = -p.swingVelocity * 0.3
+	}
+}
+
+// releaseSwing 松手：保留绳子末端的速度（水平方向变成空中的惯性），按跳跃键松手时额外向上跳起
+// 之后直到松开抓取键都不能再抓住秋千点
+func (p *Player) releaseSwing(jumped bool) {
+	speed := p.swingVelocity * p.swingLength
+	p.momentumX = speed * math.Cos(p.swingAngle)
+	p.VelocityY = -speed * math.Sin(p.swingAngle)
+	if jumped {
+		p.VelocityY += swingJumpBoost
+		p.grabLocked = true
+	}
+	p.Swing = nil
+	p.canCoyoteJump = false
+	p.Animation.SetState(StateJumpLoop)
+}
+
+// applyMomentum 松手后在空中保持水平惯性，碰到障碍物、离开地图或落地时清零
+func (p *Player) applyMomentum(obstacles []*Obstacle, mapWidth float64) {
+	if p.momentumX == 0 {
+		return
+	}
+	newX := p.X + p.momentumX
+	halfWidth := playerCollisionWidth / 2.0
+	if newX < halfWidth || newX > mapWidth-halfWidth || !p.tryMoveHorizontal(newX, obstacles) {
+		p.momentumX = 0
+		return
+	}
+	p.momentumX *= swingMomentumDecay
+}
+
+// drawRope 玩家摆动时从秋千点到抓手画一条绳子
+func (p *Player) drawRope(screen *ebiten.Image, camera *engine.Camera) {
+	if p.Swing == nil {
+		return
+	}
+	anchorX, anchorY := camera.WorldToScreen(swingAnchor(p.Swing))
+	gripX, gripY := camera.WorldToScreen(p.gripPosition())
+	vector.StrokeLine(screen, float32(anchorX), float32(anchorY), float32(gripX), float32(gripY), 3, swingRopeColor, true)
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"my_ai_game/internal/engine"
+)
+
+// System 游戏系统
+// Game.Update 按注册顺序依次调用各系统，每个系统只负责一类逻辑
+type System interface {
+	Update(g *Game)
+}
+
+// PlayerInput 玩家一帧的输入状态
+type PlayerInput struct {
+	Left  bool // 向左移动
+	Right bool // 向右移动
+	Jump  bool // 跳跃键是否按下
+	Grab  bool // 抓取键是否按住（抓住秋千点）
+
+	Rewind   bool // 回溯键是否按住（死亡后回溯）
+	Continue bool // 确认键是否在本帧按下（接关倒数中接关）
+	Skip     bool // 跳过键是否在本帧按下（接关倒数跳过一秒）
+}
+
+// InputSystem 输入系统：读取键盘和手柄输入（记录最近使用的输入设备）和窗口焦点状态，处理窗口模式切换快捷键
+type InputSystem struct {
+	bot    *Bot          // 由机器人操作玩家时不为 nil（吸引模式的演示和浸泡测试）：不读取键盘、手柄和窗口焦点
+	script *InputScript  // 按输入脚本操作玩家时不为 nil（渲染测试）：同样不读取键盘、手柄和窗口焦点
+	replay *ReplayPlayer // 按回放操作玩家时不为 nil（回放观看）：不读取键盘、手柄和窗口焦点，暂停状态同样来自回放
+}
+
+// Update 读取本帧输入
+func (s *InputSystem) Update(g *Game) {
+	if s.bot != nil {
+		g.Input = s.bot.Input(g)
+		return
+	}
+	if s.script != nil {
+		g.Input = s.script.Input()
+		return
+	}
+	if s.replay != nil {
+		g.Input, g.isPaused = s.replay.Next()
+		return
+	}
+
+	// 窗口失去焦点或被最小化时，按设置自动暂停游戏；重新获得焦点时恢复
+	focused := ebiten.IsFocused() && !ebiten.IsWindowMinimized()
+	if focused != g.isFocused {
+		g.isFocused = focused
+		if !focused && g.settings.PauseOnFocusLoss {
+			g.isPaused = true
+		} else if focused && !g.pause.Open() && !g.idle.Idle() {
+			g.isPaused = false
+		}
+	}
+
+	// F11 切换窗口模式（窗口 → 无边框 → 全屏）
+	if inpututil.IsKeyJustPressed(ebiten.KeyF11) {
+		g.settings.Display.Mode = NextWindowMode(g.settings.Display.Mode)
+		ApplyDisplaySettings(g.settings.Display)
+	}
+	// F10 在窗口模式下切换分辨率预设
+	if inpututil.IsKeyJustPressed(ebiten.KeyF10) && g.settings.Display.Mode == WindowModeWindowed {
+		g.settings.Display.Resolution = NextResolution(g.settings.Display.Resolution)
+		ApplyDisplaySettings(g.settings.Display)
+	}
+
+	// 手柄使用标准布局：十字键或左摇杆移动，下方的按钮跳跃，上方的按钮抓取
+	lastInput.Update()
+	direction := GamepadDirection()
+	g.Input = PlayerInput{
+		Left:  ebiten.IsKeyPressed(ebiten.KeyArrowLeft) || ebiten.IsKeyPressed(ebiten.KeyA) || direction < 0,
+		Right: ebiten.IsKeyPressed(ebiten.KeyArrowRight) || ebiten.IsKeyPressed(ebiten.KeyD) || direction > 0,
+		Jump:  ActionPressed(ActionJump),
+		Grab:  ActionPressed(ActionGrab),
+
+		Rewind:   ActionPressed(ActionRewind),
+		Continue: ActionJustPressed(ActionConfirm),
+		Skip:     ActionJustPressed(ActionSkip),
+	}
+
+	// 镜像模式下画面水平翻转，屏幕上的左对应世界中的右，左右操作互换
+	if g.options.Mutators.Has(MutatorMirror) {
+		g.Input.Left, g.Input.Right = g.Input.Right, g.Input.Left
+	}
+}
+
+// ClockSystem 时钟系统：暂停、回溯和通关之后游戏时钟不前进，否则按时间倍数推进一帧，并推进游戏时间的补间
+// 放在输入系统之后（输入系统处理失去焦点时的自动暂停）
+type ClockSystem struct{}
+
+// Update 推进游戏时钟和补间
+func (s *ClockSystem) Update(g *Game) {
+	g.clock.SetPaused(g.isPaused || g.isRewinding || g.cleared)
+	g.clock.Tick()
+	g.tweens.Update(g.clock.Delta())
+}
+
+// SteppedSystems 按游戏时钟的模拟步数更新的一组系统（物理、拾取、相机等）
+// 正常速度每帧一步；慢动作时部分帧没有模拟步，加速时一帧多步；暂停和回溯时没有模拟步
+type SteppedSystems []System
+
+// Update 执行本帧的模拟步
+func (s SteppedSystems) Update(g *Game) {
+	for step := 0; step < g.clock.Steps(); step++ {
+		for _, system := range s {
+			system.Update(g)
+		}
+	}
+}
+
+// AnimationSystem 动画系统：按本帧的游戏时间推进玩家动画和护盾破裂动画（不跟随模拟步，慢动作时动画平滑变慢）
+type AnimationSystem struct{}
+
+// Update 推进玩家动画、护盾破裂动画和伤害数字
+func (s *AnimationSystem) Update(g *Game) {
+	if g.isPaused || g.isRewinding || g.Player == nil {
+		return
+	}
+	g.Player.Animate()
+	g.shieldBubble.Update(g.clock.Delta())
+	// 伤害数字按真实时间推进，顿帧期间也继续上浮
+	g.damageNumbers.Update(1)
+}
+
+// PhysicsSystem 物理系统：更新怪物、巨石和玩家的移动、重力与碰撞
+type PhysicsSystem struct{}
+
+// Update 更新怪物和玩家
+func (s *PhysicsSystem) Update(g *Game) {
+	if g.isPaused || g.isRewinding || g.Player == nil {
+		return
+	}
+
+	// 更新怪物行为和巨石（在玩家之前更新，保证碰撞检测使用本帧的怪物和巨石位置）
+	s.updateMonsters(g)
+	s.updateBoulders(g)
+
+	// 更新玩家状态（传入障碍物列表和地图宽度用于碰撞检测和边界限制，以及相机和滚动方向用于推出和死亡检测）
+	mapWidth := float64(len(g.MapItems)) * mapItemWidth
+	g.Player.Update(g.Input, g.Obstacles, mapWidth, g.Camera, g.scroll.Direction)
+	g.mods.PlayerUpdate(g, g.Player)
+}
+
+// updateMonsters 更新所有怪物，加入新生成的子弹并移除已失效的怪物
+func (s *PhysicsSystem) updateMonsters(g *Game) {
+	ctx := &MonsterContext{
+		Player:    g.Player,
+		Obstacles: g.Obstacles,
+		MapItems:  g.MapItems,
+		Nav:       g.navMap,
+		GroundY:   g.groundY,
+	}
+
+	// 远离相机的怪物跳过行为和动画，只记录休眠状态；子弹和落物离开更新范围后不会再回来，直接移除
+	for _, obstacle := range g.Obstacles {
+		m := obstacle.Monster
+		if m == nil {
+			continue
+		}
+		m.Asleep = !obstacle.inUpdateRange(g.Camera)
+		if !m.Asleep {
+			m.Update(obstacle, ctx)
+		} else if m.Def.missile {
+			m.IsDead = true
+		}
+	}
+
+	// 原地过滤掉已失效的怪物
+	alive := g.Obstacles[:0]
+	for _, obstacle := range g.Obstacles {
+		if obstacle.Monster == nil || !obstacle.Monster.IsDead {
+			alive = append(alive, obstacle)
+		}
+	}
+	g.Obstacles = append(alive, ctx.spawned...)
+}
+
+// updateBoulders 更新更新范围内的巨石，移除掉出地图的巨石和被压扁的怪物
+func (s *PhysicsSystem) updateBoulders(g *Game) {
+	for _, obstacle := range g.Obstacles {
+		if obstacle.Boulder != nil && obstacle.inUpdateRange(g.Camera) {
+			obstacle.Boulder.Update(obstacle, g)
+		}
+	}
+
+	alive := g.Obstacles[:0]
+	for _, obstacle := range g.Obstacles {
+		if (obstacle.Boulder == nil || !obstacle.Boulder.Gone) && (obstacle.Monster == nil || !obstacle.Monster.IsDead) {
+			alive = append(alive, obstacle)
+		}
+	}
+	g.Obstacles = alive
+}
+
+// PickupSystem 拾取系统：移除玩家触碰到的道具和金币，道具触发飞行状态
+type PickupSystem struct{}
+
+// Update 检查玩家与道具、金币的碰撞
+func (s *PickupSystem) Update(g *Game) {
+	if g.isPaused || g.isRewinding || g.Player == nil || g.Player.IsDead {
+		return
+	}
+
+	// 从后往前遍历，避免删除时索引错乱
+	for i := len(g.Obstacles) - 1; i >= 0; i-- {
+		obstacle := g.Obstacles[i]
+		if obstacle.Type != ObstacleTypeTool && obstacle.Type != ObstacleTypeCoin {
+			continue
+		}
+		if !engine.CheckCollision(g.Player, obstacle) {
+			continue
+		}
+
+		if obstacle.Type == ObstacleTypeTool {
+			// 飞行道具触发飞行状态，护盾道具给玩家加上护盾；子弹时间道具由 TimeScaleSystem 订阅拾取事件处理
+			if obstacle.PowerUp == PowerUpFlight {
+				g.startFlight()
+			}
+			if obstacle.PowerUp == PowerUpShield {
+				g.Player.Shield = true
+			}
+			g.events.Publish(Event{Type: EventToolCollected, X: obstacle.X, Y: obstacle.Y, Obstacle: obstacle})
+		} else {
+			g.Player.Coins++
+			g.events.Publish(Event{Type: EventCoinCollected, X: obstacle.X, Y: obstacle.Y, Obstacle: obstacle, Value: g.Player.Coins})
+		}
+		// 从切片中移除该元素
+		g.Obstacles = append(g.Obstacles[:i], g.Obstacles[i+1:]...)
+	}
+}
+
+// startFlight 让玩家进入飞行状态（已经在飞行时重新开始计时）
+func (g *Game) startFlight() {
+	if !g.Player.IsFlying {
+		g.Player.IsFlying = true
+		g.Player.Swing = nil
+		g.Player.momentumX = 0
+		g.Player.FlyDirection = g.scroll.Direction
+		// 水平方向直接移到屏幕中心（飞行时每帧还会前进），垂直方向平滑移到飞行高度
+		g.Player.X = g.Camera.X + float64(windowWidth)/2.0
+		g.tweens.StopTarget(&g.Player.Y)
+		g.tweens.Start(engine.NewTween(flyEntryFrames, engine.EaseOutCubic).Float(&g.Player.Y, g.Camera.Y+flyHeight))
+		g.Player.Animation.SetState(StateFly)
+	}
+	g.Player.flyTimer.Start(flyDurationFrames)
+}
+
+// CameraSystem 相机系统：相机自动向右移动
+// 相机每帧向右移动，速度根据玩家飞行状态调整
+// 范围：0 ～ 生成地图块数量 * 120 - 屏幕宽度
+// 如果玩家死亡，相机停止移动
+type CameraSystem struct{}
+
+// Update 更新相机位置
+func (s *CameraSystem) Update(g *Game) {
+	// 暂停、回溯或玩家死亡时，相机停止移动（回溯时相机位置由快照恢复）
+	if g.isPaused || g.isRewinding || (g.Player != nil && g.Player.IsDead) {
+		return
+	}
+
+	// 速度区域：相机中心所在列的速度倍数，进入新区域时平滑过渡
+	g.Camera.ApproachSpeedScale(g.speedScaleAt(g.Camera.X), speedZoneBlendPerFrame)
+
+	// 根据玩家飞行状态调整相机移动速度
+	var currentSpeed float64
+	if g.Player != nil && g.Player.IsFlying {
+		currentSpeed = flySpeed // 飞行状态下与玩家飞行速度同步
+	} else {
+		currentSpeed = g.options.Mutators.CameraSpeed() * g.Camera.SpeedScale // 正常状态下每帧 5 像素（双倍速度突变时加倍），再乘以速度区域的倍数
+	}
+
+	// 纵向滚动段：攀爬时相机只向上移动，到顶后随下降台阶回到地面高度
+	if g.vertical.Update(g.Camera, currentSpeed) {
+		// 攀爬区域的相机不再横向移动，飞行中的玩家会飞出屏幕，到达攀爬区域时结束飞行
+		if g.vertical.Climbing() && g.Player != nil && g.Player.IsFlying {
+			g.tweens.StopTarget(&g.Player.Y)
+			g.Player.StopFlying()
+		}
+		return
+	}
+
+	// 相机沿当前滚动方向移动，到达地图边界后停止（范围在 NewGame 中按地图宽度设置）
+	// 往返滚动的关卡经过转向触发点时反向
+	g.Camera.ScrollX(currentSpeed * g.scroll.Direction)
+	g.scroll.Update(g.Camera)
+}
+
+// AudioSystem 音频系统：窗口失去焦点时按设置压低或暂停音频，重新获得焦点时恢复；怪物靠近玩家时播放提示音
+// 起跳、死亡等音效由 AudioManager 订阅事件处理
+type AudioSystem struct {
+	wasFocused bool               // 上一帧窗口是否拥有焦点
+	nearby     map[*Obstacle]bool // 上一帧在玩家附近的怪物
+}
+
+// Update 根据焦点变化调整音频
+func (s *AudioSystem) Update(g *Game) {
+	g.sfx.Update()
+	if track := g.audioManager.Update(); track != "" && !g.options.Mute {
+		g.nowPlaying.Show(track)
+	}
+	g.nowPlaying.Update()
+	g.captions.Update()
+	s.checkMonsters(g)
+
+	if g.isFocused == s.wasFocused {
+		return
+	}
+	s.wasFocused = g.isFocused
+
+	if !g.isFocused {
+		switch g.settings.FocusLossAudio {
+		case FocusLossAudioDuck:
+			g.audioManager.SetDucked(true)
+		case FocusLossAudioPause:
+			g.audioManager.PauseAll()
+		}
+		return
+	}
+
+	// 重新获得焦点，恢复音频（两种操作都是幂等的，无需区分失去焦点时的设置；离开暂停中保持压低）
+	g.audioManager.SetDucked(g.idle.Idle())
+	g.audioManager.ResumeAll()
+}
+
+// checkMonsters 有怪物进入玩家附近时播放怪物靠近提示音（子弹和落物不算，一直在附近的怪物不重复提示）
+func (s *AudioSystem) checkMonsters(g *Game) {
+	if g.isPaused || g.isRewinding || g.Player == nil || g.Player.IsDead {
+		return
+	}
+
+	nearby := make(map[*Obstacle]bool)
+	entered := false
+	for _, obstacle := range g.Obstacles {
+		if obstacle.Monster == nil || obstacle.Monster.Def.missile || obstacle.Monster.IsDead {
+			continue
+		}
+		dx := obstacle.X + obstacle.Width/2 - g.Player.X
+		dy := obstacle.Y + obstacle.Height/2 - (g.Player.Y - playerCollisionHeight/2)
+		if math.Hypot(dx, dy) > monsterNearbyDistance {
+			continue
+		}
+		nearby[obstacle] = true
+		if !s.nearby[obstacle] {
+			entered = true
+		}
+	}
+	s.nearby = nearby
+
+	if entered {
+		g.sfx.Play(sfxMonsterNearby)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 主题目录文件
+	themeCatalogPath = "res/data/themes.json"
+	// 默认主题
+	defaultThemeName = "grassland"
+)
+
+// ThemeDef 关卡主题定义（来自主题目录文件）
+type ThemeDef struct {
+	Name     string            `json:"name"`
+	LUT      string            `json:"lut"`      // 调色查找表图片路径（可选，为空时按 Grade 生成）
+	Grade    engine.ColorGrade `json:"grade"`    // 生成查找表的调色参数
+	Strength float32           `json:"strength"` // 调色强度（0～1，0 表示不调色）
+	Music    []string          `json:"music"`    // 背景音乐播放列表（为空时使用默认背景音乐）
+	Order    string            `json:"order"`    // 播放顺序：sequential（默认）或 shuffle
+
+	order engine.PlaylistMode
+}
+
+// NewColorGrading 创建主题的调色效果，主题不调色时返回 nil
+func (t *ThemeDef) NewColorGrading() (*engine.ColorGrading, error) {
+	if t.Strength <= 0 {
+		return nil, nil
+	}
+	if t.LUT == "" {
+		return engine.NewColorGrading(engine.BakeLUT(t.Grade), t.Strength)
+	}
+	lut, err := engine.LoadLUT(t.LUT)
+	if err != nil {
+		return nil, fmt.Errorf("加载主题 %s 的查找表失败: %w", t.Name, err)
+	}
+	return engine.NewColorGrading(lut, t.Strength)
+}
+
+// ThemeCatalog 主题目录
+type ThemeCatalog struct {
+	defs map[string]*ThemeDef
+}
+
+// Get 按名称获取主题定义，不存在时返回 nil
+func (c *ThemeCatalog) Get(name string) *ThemeDef {
+	return c.defs[name]
+}
+
+// LoadThemeCatalog 从 JSON 文件加载主题目录
+func LoadThemeCatalog(path string) (*ThemeCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var defs []*ThemeDef
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("解析主题目录失败: %w", err)
+	}
+
+	catalog := &ThemeCatalog{defs: make(map[string]*ThemeDef, len(defs))}
+	for _, def := range defs {
+		if def.order, err = engine.ParsePlaylistMode(def.Order); err != nil {
+			return nil, fmt.Errorf("主题 %s: %w", def.Name, err)
+		}
+		catalog.defs[def.Name] = def
+	}
+	return catalog, nil
+}
+
+// loadTheme 加载主题目录并返回指定主题
+// 主题是可选的：主题目录加载失败或主题不存在时给出警告并返回 nil（不调色，使用默认背景音乐）
+func loadTheme(name string) *ThemeDef {
+	catalog, err := LoadThemeCatalog(themeCatalogPath)
+	if err != nil {
+		log.Printf("警告: 加载主题目录失败，不使用主题: %v", err)
+		return nil
+	}
+	theme := catalog.Get(name)
+	if theme == nil {
+		log.Printf("警告: 未知的主题 %s，不使用主题", name)
+	}
+	return theme
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 玩家死亡后的慢动作：开始时的时间倍数和恢复到正常速度所用的帧数（真实时间）
+	deathSlowMoScale  = 0.3
+	deathSlowMoFrames = 60
+	// 子弹时间道具：时间倍数和持续的帧数（真实时间）
+	bulletTimeScale  = 0.5
+	bulletTimeFrames = 360
+	// 道具是子弹时间道具的概率
+	bulletTimeChance = 0.3
+	// 时间倍数选项的允许范围
+	minTimeScale = 0.1
+	maxTimeScale = 2.0
+)
+
+// bulletTimeTint 子弹时间道具的颜色倍数（偏蓝，与飞行道具区分）
+var bulletTimeTint = [3]float32{0.6, 0.8, 1.4}
+
+// debugTimeScales 调试模式下 F7 依次切换的基础时间倍数
+var debugTimeScales = []float64{1, 0.5, 0.25}
+
+// PowerUp 道具效果
+type PowerUp int
+
+const (
+	PowerUpFlight     PowerUp = iota // 飞行：无视碰撞沿滚动方向飞行一段时间
+	PowerUpBulletTime                // 子弹时间：一段时间内游戏变慢（界面不受影响）
+	PowerUpShield                    // 护盾：抵挡一次怪物或子弹的致命触碰
+	PowerUpExtraLife                 // 1UP：增加一次回溯次数
+)
+
+// powerUpNames 道具效果名称
+var powerUpNames = map[PowerUp]string{
+	PowerUpFlight:     "flight",
+	PowerUpBulletTime: "bullet_time",
+	PowerUpShield:     "shield",
+	PowerUpExtraLife:  "extra_life",
+}
+
+// String 返回道具效果名称
+func (p PowerUp) String() string {
+	return powerUpNames[p]
+}
+
+// SetTimeScale 设置游戏的时间倍数（1 为正常速度，小于 1 为慢动作）
+// 影响物理、相机、怪物、动画和游戏时间的计时器与补间，不影响界面（菜单、提示文字、诊断界面）
+// 通常由 TimeScaleSystem 每帧按当前效果设置，其他地方直接调用会在下一帧被覆盖
+func (g *Game) SetTimeScale(scale float64) {
+	g.clock.SetScale(scale)
+}
+
+// TimeScale 返回游戏的时间倍数
+func (g *Game) TimeScale() float64 {
+	return g.clock.Scale()
+}
+
+// TimeScaleSystem 时间倍数系统：按调试慢动作、子弹时间、死亡慢动作和攻击命中的顿帧计算本帧的时间倍数
+// 放在时钟系统之前；效果的持续时间按真实帧计算（慢动作不会让慢动作本身变长）
+type TimeScaleSystem struct {
+	base       float64      // 基础倍数（-timescale 启动选项，调试模式下 F7 切换）
+	slowMo     engine.Timer // 死亡慢动作
+	bulletTime engine.Timer // 子弹时间
+	hitStop    engine.Timer // 攻击命中的顿帧
+}
+
+// NewTimeScaleSystem 创建时间倍数系统，订阅玩家死亡、怪物受击和拾取道具事件
+// base: 基础时间倍数
+func NewTimeScaleSystem(base float64, events *EventBus) *TimeScaleSystem {
+	s := &TimeScaleSystem{base: base}
+	events.Subscribe(EventPlayerDied, func(e Event) {
+		s.slowMo.Start(deathSlowMoFrames)
+	})
+	events.Subscribe(EventMonsterHit, func(e Event) {
+		if e.Attack != nil && e.Attack.HitStopFrames > 0 {
+			s.hitStop.Start(float64(e.Attack.HitStopFrames))
+		}
+	})
+	events.Subscribe(EventToolCollected, func(e Event) {
+		if e.Obstacle != nil && e.Obstacle.PowerUp == PowerUpBulletTime {
+			s.bulletTime.Start(bulletTimeFrames)
+		}
+	})
+	return s
+}
+
+// Update 推进效果计时并设置本帧的时间倍数
+func (s *TimeScaleSystem) Update(g *Game) {
+	if g.options.Debug && inpututil.IsKeyJustPressed(ebiten.KeyF7) {
+		s.base = nextDebugTimeScale(s.base)
+	}
+
+	// 回溯到死亡之前后不再慢动作
+	if g.isRewinding {
+		s.slowMo.Stop()
+	}
+	if !g.isPaused && !g.isRewinding {
+		s.slowMo.Tick(1)
+		s.bulletTime.Tick(1)
+	}
+	// 顿帧期间游戏完全停住（倍数为 0，没有模拟步），放在计时之后：开始顿帧的那一帧起停 HitStopFrames 帧
+	if s.hitStop.Running() {
+		g.SetTimeScale(0)
+		s.hitStop.Tick(1)
+		return
+	}
+
+	scale := s.base
+	if s.bulletTime.Running() {
+		scale *= bulletTimeScale
+	}
+	// 死亡慢动作从 deathSlowMoScale 逐渐恢复到正常速度
+	if s.slowMo.Running() {
+		slowMo := deathSlowMoScale + (1-deathSlowMoScale)*engine.EaseInQuad(s.slowMo.Progress())
+		scale = min(scale, s.base*slowMo)
+	}
+	g.SetTimeScale(scale)
+}
+
+// nextDebugTimeScale 返回 F7 切换后的基础倍数（不在列表中的倍数切换到列表的第一个）
+func nextDebugTimeScale(current float64) float64 {
+	for i, scale := range debugTimeScales {
+		if scale == current {
+			return debugTimeScales[(i+1)%len(debugTimeScales)]
+		}
+	}
+	return debugTimeScales[0]
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"log"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 标题文字（调试字体只有 ASCII）和放大的倍数
+	titleLogoText  = "SHERRY-CHAN'S ADVENTURE"
+	titleLogoScale = 5.0
+	// 标志弹出动画的帧数，之后上下浮动的幅度（像素）和周期（帧）
+	titleLogoEnterFrames = 40
+	titleLogoBobHeight   = 6.0
+	titleLogoBobFrames   = 120
+	// 背景每帧滚动的距离（像素，相当于相机位置）和背景层的视差系数
+	titleScrollSpeed = 1.0
+	titleParallax    = 0.5
+	// 菜单的位置和行高
+	titleMenuY       = 400
+	titleMenuSpacing = 20
+	// 开始提示闪烁的周期（帧）
+	titlePromptBlinkFrames = 60
+)
+
+// gameVersion 游戏版本（发布时用 -ldflags "-X main.gameVersion=1.2.3" 设置）
+var gameVersion = "dev"
+
+// titleMenuItem 标题菜单的一项
+type titleMenuItem int
+
+const (
+	titleMenuStart titleMenuItem = iota
+	titleMenuStages
+	titleMenuDaily
+	titleMenuLevels
+	titleMenuOptions
+	titleMenuCredits
+	titleMenuQuit
+)
+
+// titleMenuNames 标题菜单项的名称
+var titleMenuNames = map[titleMenuItem]string{
+	titleMenuStart:   "START",
+	titleMenuStages:  "STAGES",
+	titleMenuDaily:   "DAILY",
+	titleMenuLevels:  "LEVELS",
+	titleMenuOptions: "OPTIONS",
+	titleMenuCredits: "CREDITS",
+	titleMenuQuit:    "QUIT",
+}
+
+// String 返回菜单项的名称
+func (m titleMenuItem) String() string {
+	return titleMenuNames[m]
+}
+
+// titleMenuItems 返回标题菜单显示的项：游戏自带的关卡目录中没有关卡时不显示 STAGES
+func titleMenuItems() []titleMenuItem {
+	var items []titleMenuItem
+	for item := titleMenuStart; item <= titleMenuQuit; item++ {
+		if item == titleMenuStages && len(ScanStages(stagesDir, nil)) == 0 {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// titleOption 标题界面选项页中的一项开关（修改的是之后开始的游戏的启动选项）
+type titleOption struct {
+	name  string // 显示的名称
+	flag  string // 对应的命令行参数名（设置后按命令行指定处理，存档记住的设置不覆盖）
+	value func(opts *GameOptions) *bool
+}
+
+// titleOptions 选项页中的开关（列在音量、全屏等设置项之前，见 SettingsScene）
+var titleOptions = []titleOption{
+	{name: "MUTE", flag: "mute", value: func(opts *GameOptions) *bool { return &opts.Mute }},
+	{name: "CAPTIONS", flag: "captions", value: func(opts *GameOptions) *bool { return &opts.Captions }},
+	{name: "ANNOUNCER", flag: "announcer", value: func(opts *GameOptions) *bool { return &opts.Announcer }},
+	{name: "AUTO PAUSE", flag: "pause-on-focus-loss", value: func(opts *GameOptions) *bool { return &opts.AutoPause }},
+	{name: "LANDING ASSIST", flag: "landing-assist", value: func(opts *GameOptions) *bool { return &opts.LandingAssist }},
+}
+
+// DailySeed 返回某一天的每日挑战种子（按 UTC 日期，例如 20261016），同一天所有玩家得到同一张地图
+func DailySeed(t time.Time) int64 {
+	y, m, d := t.UTC().Date()
+	return int64(y*10000 + int(m)*100 + d)
+}
+
+// TitleScene 标题场景：启动后首先显示（编辑器模式除外），背景按视差缓慢滚动，标志弹出后上下浮动，
+// 先闪烁显示 "PRESS SPACE TO START"，按跳跃键或确认键后显示菜单；
+// 菜单可以开始游戏、开始每日挑战、打开关卡浏览、修改选项、播放制作人员名单或退出；选中后由场景管理器切换到存档选择、关卡浏览或游戏
+type TitleScene struct {
+	opts       GameOptions
+	background *engine.ScrollingLayer
+	logo       *ebiten.Image // 标题文字（按界面字体的大小绘制，显示时放大）
+	logoScale  float64       // 标志弹出动画的额外倍数（从 0 变到 1）
+	tweens     engine.Tweener
+	frames     int
+	items      []titleMenuItem // 菜单中显示的项（没有游戏自带的关卡时不显示 STAGES）
+	selected   int
+	started    bool           // 是否已经按下开始键（之前只显示开始提示，不显示菜单）
+	settings   *SettingsScene // 打开的选项页（为 nil 时显示菜单）
+	credits    *CreditsScene  // 正在播放的制作人员名单（为 nil 时显示菜单）
+	attract    AttractMode    // 无操作时播放的演示
+}
+
+// NewTitleScene 创建标题场景
+func NewTitleScene(opts GameOptions) *TitleScene {
+	bgImage, _, err := ebitenutil.NewImageFromFile(defaultBackgroundPath)
+	if err != nil {
+		log.Fatalf("加载背景图片失败: %v", err)
+	}
+	background := engine.NewScrollingLayer(bgImage, windowWidth)
+	background.Parallax = titleParallax
+
+	logo := NewTextImage(titleLogoText)
+
+	// 复制命令行指定的选项集合，选项页修改时不影响调用方
+	opts.explicit = maps.Clone(opts.explicit)
+	s := &TitleScene{opts: opts, background: background, logo: logo, items: titleMenuItems()}
+	s.tweens.Start(engine.NewTween(titleLogoEnterFrames, engine.EaseOutBack).Float(&s.logoScale, 1))
+	return s
+}
+
+// Update 处理开始键和菜单输入
+func (s *TitleScene) Update() error {
+	lastInput.Update()
+	if s.credits != nil {
+		if s.credits.Update(); s.credits.Done() {
+			s.credits = nil
+		}
+		return nil
+	}
+	if s.attract.Update(s.opts) {
+		return nil
+	}
+	s.frames++
+	s.tweens.Update(1)
+
+	if !s.started {
+		s.started = ActionJustPressed(ActionJump) || ActionJustPressed(ActionConfirm)
+		return nil
+	}
+	if s.settings != nil {
+		if s.settings.Update(); s.settings.Done() {
+			s.settings = nil
+			s.selected = slices.Index(s.items, titleMenuOptions)
+		}
+		return nil
+	}
+
+	count := len(s.items)
+	switch {
+	case titleMenuUp():
+		s.selected--
+	case titleMenuDown():
+		s.selected++
+	case ActionJustPressed(ActionConfirm):
+		return s.choose(s.items[s.selected])
+	}
+	s.selected = (s.selected + count) % count
+	return nil
+}
+
+// choose 执行菜单项
+func (s *TitleScene) choose(item titleMenuItem) error {
+	opts := s.opts
+	switch item {
+	case titleMenuStart:
+		opts.Levels = false
+		opts.Stages = false
+	case titleMenuStages:
+		opts.Stages = true
+		opts.Levels = false
+	case titleMenuDaily:
+		// 每日挑战：当天的种子、随机生成的地图，不带突变
+		opts.Seed = DailySeed(time.Now())
+		opts.Mutators = 0
+		opts.LevelPath = ""
+		opts.Levels = false
+		opts.Stages = false
+		log.Printf("每日挑战种子码: %s", SeedCode(opts.Seed, opts.Mutators))
+	case titleMenuLevels:
+		opts.Levels = true
+		opts.Stages = false
+	case titleMenuOptions:
+		s.settings = NewSettingsScene(&s.opts, nil, titleMenuY)
+		return nil
+	case titleMenuCredits:
+		s.credits = NewCreditsScene(opts.SampleRate, opts.Mute, opts.MusicVolume)
+		return nil
+	case titleMenuQuit:
+		return ebiten.Termination
+	}
+	s.opts.scenes.Switch(launchScene(opts))
+	return nil
+}
+
+// titleMenuUp 是否按下了菜单向上（方向键、W 或手柄十字键）
+func titleMenuUp() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) || GamepadJustPressed(ebiten.StandardGamepadButtonLeftTop)
+}
+
+// titleMenuDown 是否按下了菜单向下（方向键、S 或手柄十字键）
+func titleMenuDown() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) || GamepadJustPressed(ebiten.StandardGamepadButtonLeftBottom)
+}
+
+// launchScene 按启动选项创建开始游戏的场景：
+// 没有指定存档且已有存档时先打开存档选择；-stages（或菜单选择 STAGES）时先打开关卡选择，-levels（或菜单选择关卡）时先打开关卡浏览；
+// 否则通过加载界面开始游戏
+func launchScene(opts GameOptions) ebiten.Game {
+	if opts.Profile == "" {
+		if len(ListProfiles()) > 0 {
+			return NewProfileSelect(opts)
+		}
+		opts.Profile = defaultProfileName
+	}
+	if opts.Stages {
+		return NewLevelSelect(opts)
+	}
+	if opts.Levels {
+		return NewLevelBrowser(opts, opts.LevelIndex)
+	}
+	return NewLoadingScene(opts)
+}
+
+// Draw 绘制背景、标志、开始提示或菜单和版本号
+func (s *TitleScene) Draw(screen *ebiten.Image) {
+	if s.credits != nil {
+		s.credits.Draw(screen)
+		return
+	}
+	if s.attract.Draw(screen) {
+		return
+	}
+
+	s.background.Draw(screen, float64(s.frames)*titleScrollSpeed)
+	s.drawLogo(screen)
+
+	switch {
+	case !s.started:
+		if s.frames/(titlePromptBlinkFrames/2)%2 == 0 {
+			DrawPromptCentered(screen, "PRESS {jump} TO START", windowWidth/2, titleMenuY+2*titleMenuSpacing)
+		}
+	case s.settings != nil:
+		s.settings.Draw(screen)
+	default:
+		for i, item := range s.items {
+			line := item.String()
+			if i == s.selected {
+				line = "> " + line + " <"
+			}
+			DrawTextCentered(screen, line, windowWidth/2, titleMenuY+i*titleMenuSpacing)
+		}
+		DrawPromptCentered(screen, "{navigate} SELECT  {confirm} OK", windowWidth/2, titleMenuY+(len(s.items)+1)*titleMenuSpacing)
+	}
+
+	version := "v" + gameVersion
+	DrawText(screen, version, windowWidth-TextWidth(version)-10, windowHeight-26)
+}
+
+// drawLogo 绘制标志：弹出后以正弦曲线上下浮动
+func (s *TitleScene) drawLogo(screen *ebiten.Image) {
+	w, h := s.logo.Bounds().Dx(), s.logo.Bounds().Dy()
+	scale := titleLogoScale * s.logoScale
+	bob := math.Sin(float64(s.frames)*2*math.Pi/titleLogoBobFrames) * titleLogoBobHeight
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-float64(w)/2, -float64(h)/2)
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(windowWidth/2, 200+bob)
+	screen.DrawImage(s.logo, op)
+}
+
+// rememberSettings 记住设置页修改的选项（从暂停菜单修改或存档应用后，回到标题和之后开始的游戏同样使用）
+func (s *TitleScene) rememberSettings(opts GameOptions) {
+	s.opts.MusicVolume = opts.MusicVolume
+	s.opts.SFXVolume = opts.SFXVolume
+	s.opts.Display.Mode = opts.Display.Mode
+	s.opts.Hitboxes = opts.Hitboxes
+}
+
+// Close 结束正在播放的演示和制作人员名单（退出时调用）
+func (s *TitleScene) Close() {
+	s.attract.Close()
+	if s.credits != nil {
+		s.credits.Close()
+		s.credits = nil
+	}
+}
+
+// Layout 返回游戏逻辑尺寸
+func (s *TitleScene) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return windowWidth, windowHeight
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my_ai_game/internal/engine"
+)
+
+// 场景过渡每一半（渐出或渐入）的帧数
+const transitionFrames = 15
+
+// TransitionStyle 场景过渡的样式
+type TransitionStyle int
+
+const (
+	TransitionFade TransitionStyle = iota // 渐暗到黑色再从黑色渐亮
+	TransitionWipe                        // 黑色从左向右盖住画面，再向右退出
+)
+
+// transitionStyleNames 过渡样式的名称
+var transitionStyleNames = map[TransitionStyle]string{
+	TransitionFade: "fade",
+	TransitionWipe: "wipe",
+}
+
+// String 返回过渡样式的名称
+func (s TransitionStyle) String() string {
+	return transitionStyleNames[s]
+}
+
+// transitionPhase 场景过渡的阶段
+type transitionPhase int
+
+const (
+	transitionNone transitionPhase = iota // 没有过渡
+	transitionOut                         // 之前的场景渐出（不更新）
+	transitionIn                          // 新的场景渐入（照常更新）
+)
+
+// Transition 场景管理器的过渡层：切换场景时先把当前场景渐出到黑色（这期间不更新当前场景），
+// 完全变黑时切换，再把新的场景渐入；渐入期间再次切换时从当前的遮挡程度开始渐出，画面不会跳变
+type Transition struct {
+	style  TransitionStyle
+	phase  transitionPhase
+	frames int // 当前阶段经过的帧数
+}
+
+// Start 开始渐出（渐入期间从当前的遮挡程度继续）
+func (t *Transition) Start(style TransitionStyle) {
+	switch t.phase {
+	case transitionIn:
+		t.frames = transitionFrames - t.frames
+	case transitionNone:
+		t.frames = 0
+	}
+	t.style = style
+	t.phase = transitionOut
+}
+
+// Update 推进一帧，返回是否刚刚完全变黑（此时切换场景）
+func (t *Transition) Update() bool {
+	if t.phase == transitionNone {
+		return false
+	}
+	if t.frames++; t.frames < transitionFrames {
+		return false
+	}
+	if t.phase == transitionIn {
+		t.phase = transitionNone
+		return false
+	}
+	t.phase, t.frames = transitionIn, 0
+	return true
+}
+
+// Covering 是否正在渐出（当前场景不更新）
+func (t *Transition) Covering() bool {
+	return t.phase == transitionOut
+}
+
+// Draw 按遮挡程度绘制黑色遮罩（渐暗为整屏的透明度，擦除为从左边盖住的宽度）
+func (t *Transition) Draw(screen *ebiten.Image) {
+	if t.phase == transitionNone {
+		return
+	}
+	progress := engine.EaseInOutQuad(float64(t.frames) / transitionFrames)
+	if t.phase == transitionIn {
+		progress = 1 - progress
+	}
+	switch t.style {
+	case TransitionWipe:
+		width := float32(progress * windowWidth)
+		x := float32(0)
+		if t.phase == transitionIn {
+			x = windowWidth - width
+		}
+		vector.FillRect(screen, x, 0, width, windowHeight, color.Black, false)
+	default:
+		vector.FillRect(screen, 0, 0, windowWidth, windowHeight, color.RGBA{A: uint8(progress * 255)}, false)
+	}
+}
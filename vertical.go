@@ -0,0 +1,198 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+
+	"my_ai_game/internal/engine"
+)
+
+const (
+	// 攀爬平台的行数和行间距（像素），行间距在玩家跳跃高度以内
+	verticalRows       = 8
+	verticalRowSpacing = 150.0
+	// 攀爬平台的宽度（列数）
+	verticalPlatformColumns = 2
+	// 相邻两行平台的最大错开列数，保证可以跳上去
+	verticalMaxShift = 2
+	// 攀爬时相机向上移动的速度（像素/帧）
+	verticalClimbSpeed = 1.5
+	// 下降台阶每级的高度（像素）和宽度（列数）
+	verticalStepDrop    = 120.0
+	verticalStepColumns = 2
+	// 第一个纵向滚动段之前和两个纵向滚动段之间的最少列数
+	verticalMinGap = 80
+	// 纵向滚动段的随机额外间隔（列数）
+	verticalExtraGap = 40
+	// 地图结尾不放置纵向滚动段的列数
+	verticalEndMargin = 20
+)
+
+// PlatformSpot 纵向滚动段中的一块平台
+type PlatformSpot struct {
+	Column  int     `json:"column"`  // 平台的第一列
+	Columns int     `json:"columns"` // 平台宽度（列数）
+	Height  float64 `json:"height"`  // 平台顶部距离道路顶部的高度（像素）
+}
+
+// VerticalSegment 纵向滚动段
+// 相机左边界到达 Column 后停止横向滚动并向上移动 Height 像素，玩家沿平台向上攀爬；
+// 到顶后相机恢复横向滚动，同时随下降台阶回到地面高度
+type VerticalSegment struct {
+	Column    int            `json:"column"`    // 攀爬区域的第一列（相机左边界对齐这一列）
+	Height    float64        `json:"height"`    // 攀爬高度（像素）
+	Platforms []PlatformSpot `json:"platforms"` // 攀爬平台和下降台阶
+}
+
+// climbColumns 攀爬区域的列数（覆盖整个视口宽度）
+func climbColumns() int {
+	return int(math.Ceil(float64(windowWidth) / mapItemWidth))
+}
+
+// StartX 攀爬区域左边界的世界坐标
+func (s *VerticalSegment) StartX() float64 {
+	return float64(s.Column) * mapItemWidth
+}
+
+// EndX 攀爬区域右边界（下降台阶开始）的世界坐标
+func (s *VerticalSegment) EndX() float64 {
+	return float64(s.Column+climbColumns()) * mapItemWidth
+}
+
+// Columns 纵向滚动段占用的总列数（攀爬区域加下降台阶）
+func (s *VerticalSegment) Columns() int {
+	steps := int(s.Height / verticalStepDrop)
+	return climbColumns() + steps*verticalStepColumns
+}
+
+// GenVerticalSegments 在随机生成的地图上放置纵向滚动段（在 GenHighRoutes 之后调用）
+// 纵向滚动段占用的列被清理为没有障碍物、怪物、道具和高处路线的道路，穿过这些列的高处路线整条移除
+func GenVerticalSegments(items []*MapItem, random *rand.Rand) []*VerticalSegment {
+	var segments []*VerticalSegment
+	column := verticalMinGap
+	for {
+		column += random.Intn(verticalExtraGap + 1)
+		segment := genVerticalSegment(column, random)
+		end := column + segment.Columns()
+		if end >= len(items)-verticalEndMargin {
+			return segments
+		}
+
+		// 清理占用的列（前后各多一列作为安全的入口和出口）
+		first, last := column-1, end
+		for first > 0 && items[first-1].HasPlatform {
+			first--
+		}
+		for last < len(items)-1 && items[last+1].HasPlatform {
+			last++
+		}
+		for i := first; i <= last; i++ {
+			item := items[i]
+			item.HasPlatform = false
+			item.HasPlatformHazard = false
+			if i >= column-1 && i <= end {
+				item.HasRoad = true
+				item.HasObstacle = false
+				item.HasMonster = false
+				item.HasTool = false
+			}
+		}
+
+		segments = append(segments, segment)
+		column = end + verticalMinGap
+	}
+}
+
+// genVerticalSegment 生成一个纵向滚动段的平台
+// 攀爬平台每行在攀爬区域内随机位置（与上一行最多错开 verticalMaxShift 列），最上面一行铺满攀爬区域；
+// 下降台阶从攀爬区域右侧开始，每级下降 verticalStepDrop，直到接近地面
+func genVerticalSegment(column int, random *rand.Rand) *VerticalSegment {
+	segment := &VerticalSegment{Column: column, Height: verticalRows * verticalRowSpacing}
+	width := climbColumns()
+
+	prev := column + (width-verticalPlatformColumns)/2
+	for row := 1; row < verticalRows; row++ {
+		low := max(column, prev-verticalMaxShift)
+		high := min(column+width-verticalPlatformColumns, prev+verticalMaxShift)
+		col := low + random.Intn(high-low+1)
+		segment.Platforms = append(segment.Platforms, PlatformSpot{Column: col, Columns: verticalPlatformColumns, Height: float64(row) * verticalRowSpacing})
+		prev = col
+	}
+
+	// 顶部平台铺满攀爬区域
+	segment.Platforms = append(segment.Platforms, PlatformSpot{Column: column, Columns: width, Height: segment.Height})
+
+	// 下降台阶
+	steps := int(segment.Height / verticalStepDrop)
+	for step := 1; step < steps; step++ {
+		col := column + width + (step-1)*verticalStepColumns
+		segment.Platforms = append(segment.Platforms, PlatformSpot{Column: col, Columns: verticalStepColumns, Height: segment.Height - float64(step)*verticalStepDrop})
+	}
+	return segment
+}
+
+// verticalPhase 纵向滚动段的相机阶段
+type verticalPhase int
+
+const (
+	verticalPhaseNone    verticalPhase = iota // 横向滚动，尚未到达下一个纵向滚动段
+	verticalPhaseClimb                        // 相机停止横向滚动，向上移动
+	verticalPhaseDescend                      // 相机恢复横向滚动，随下降台阶回到地面高度
+)
+
+// VerticalScroll 纵向滚动段的相机状态（随回溯快照一起恢复）
+type VerticalScroll struct {
+	segments []*VerticalSegment // 按列排序，只读
+	next     int                // 下一个（或当前）纵向滚动段的下标
+	phase    verticalPhase
+}
+
+// NewVerticalScroll 创建纵向滚动状态
+func NewVerticalScroll(segments []*VerticalSegment) VerticalScroll {
+	return VerticalScroll{segments: segments}
+}
+
+// Climbing 相机是否处于攀爬阶段（不横向滚动）
+func (v *VerticalScroll) Climbing() bool {
+	return v.phase == verticalPhaseClimb
+}
+
+// Update 按纵向滚动段更新相机，返回 true 表示本帧相机已由纵向滚动段移动（调用方不再横向滚动）
+// speed: 本帧的横向滚动速度
+func (v *VerticalScroll) Update(camera *engine.Camera, speed float64) bool {
+	if v.next >= len(v.segments) {
+		return false
+	}
+	segment := v.segments[v.next]
+
+	switch v.phase {
+	case verticalPhaseClimb:
+		camera.Y -= verticalClimbSpeed
+		if camera.Y <= -segment.Height {
+			camera.Y = -segment.Height
+			v.phase = verticalPhaseDescend
+		}
+		return true
+
+	case verticalPhaseDescend:
+		// 相机中心到达攀爬区域右边界时开始下降，下降斜率与台阶一致
+		camera.ScrollX(speed)
+		descent := (camera.X + camera.Width/2.0 - segment.EndX()) * verticalStepDrop / (verticalStepColumns * mapItemWidth)
+		descent = math.Max(0, math.Min(segment.Height, descent))
+		camera.Y = descent - segment.Height
+		if descent >= segment.Height {
+			camera.Y = 0
+			v.phase = verticalPhaseNone
+			v.next++
+		}
+		return true
+	}
+
+	// 到达攀爬区域时对齐相机，开始攀爬
+	if camera.X+speed >= segment.StartX() {
+		camera.X = segment.StartX()
+		v.phase = verticalPhaseClimb
+		return true
+	}
+	return false
+}